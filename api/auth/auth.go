@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+)
+
+// Authenticator gets user login from request credentials
+// Empty login is returned if request has no credentials supported by authenticator
+type Authenticator interface {
+	Authenticate(request *http.Request) (string, *api.ErrorResponse)
+}
+
+// Authentication checks request credentials with authenticators in configured order
+type Authentication struct {
+	authenticators []Authenticator
+	allowAnonymous bool
+	admins         map[string]bool
+}
+
+// NewAuthentication creates authenticators of given methods
+// Header method can't be combined with other methods, otherwise any client could bypass token checks by setting header,
+// so requests without credentials are allowed only with header method to keep reverse-proxy setups working
+func NewAuthentication(config api.Config, database moira.Database) (*Authentication, error) {
+	if len(config.AuthMethods) == 0 {
+		return nil, fmt.Errorf("No authentication methods configured")
+	}
+	for _, method := range config.AuthMethods {
+		if method == api.AuthHeader && len(config.AuthMethods) > 1 {
+			return nil, fmt.Errorf("Authentication method '%s' can't be combined with other methods", api.AuthHeader)
+		}
+	}
+	authentication := &Authentication{admins: make(map[string]bool, len(config.Admins))}
+	for _, login := range config.Admins {
+		authentication.admins[login] = true
+	}
+	for _, method := range config.AuthMethods {
+		switch method {
+		case api.AuthHeader:
+			authentication.authenticators = append(authentication.authenticators, &HeaderAuthenticator{})
+			authentication.allowAnonymous = true
+		case api.AuthToken:
+			authentication.authenticators = append(authentication.authenticators, NewTokenAuthenticator(database))
+		case api.AuthJWT:
+			authenticator, err := NewJWTAuthenticator(config.JWT)
+			if err != nil {
+				return nil, err
+			}
+			authentication.authenticators = append(authentication.authenticators, authenticator)
+		default:
+			return nil, fmt.Errorf("Unknown authentication method '%s'", method)
+		}
+	}
+	return authentication, nil
+}
+
+// Authenticate returns user login from first authenticator which found credentials in request
+func (authentication *Authentication) Authenticate(request *http.Request) (string, *api.ErrorResponse) {
+	for _, authenticator := range authentication.authenticators {
+		login, err := authenticator.Authenticate(request)
+		if err != nil {
+			return "", err
+		}
+		if login != "" {
+			return login, nil
+		}
+	}
+	if authentication.allowAnonymous {
+		return "", nil
+	}
+	return "", api.ErrorUnauthorized("Authentication required")
+}
+
+// IsAdmin checks that authenticated user is api administrator
+func (authentication *Authentication) IsAdmin(login string) bool {
+	return login != "" && authentication.admins[login]
+}
+
+// HeaderAuthenticator trusts user login from x-webauth-user header, it must be used only behind authenticating reverse proxy
+type HeaderAuthenticator struct{}
+
+// Authenticate returns x-webauth-user header value
+func (*HeaderAuthenticator) Authenticate(request *http.Request) (string, *api.ErrorResponse) {
+	return request.Header.Get("x-webauth-user"), nil
+}
+
+// getBearerToken returns token from Authorization header with Bearer scheme
+func getBearerToken(request *http.Request) string {
+	header := request.Header.Get("Authorization")
+	if len(header) < 7 || !strings.EqualFold(header[:7], "bearer ") {
+		return ""
+	}
+	return strings.TrimSpace(header[7:])
+}
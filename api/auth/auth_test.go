@@ -0,0 +1,99 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestAuthentication(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	token, hash, err := GenerateAPIToken()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Unknown method, should return error", t, func() {
+		_, err := NewAuthentication(api.Config{AuthMethods: []string{"basic"}}, dataBase)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Header method only", t, func() {
+		authentication, err := NewAuthentication(api.Config{AuthMethods: []string{api.AuthHeader}}, dataBase)
+		So(err, ShouldBeNil)
+
+		request, _ := http.NewRequest("GET", "/api/user", nil)
+		login, authErr := authentication.Authenticate(request)
+		So(authErr, ShouldBeNil)
+		So(login, ShouldBeEmpty)
+
+		request.Header.Set("x-webauth-user", "user")
+		login, authErr = authentication.Authenticate(request)
+		So(authErr, ShouldBeNil)
+		So(login, ShouldEqual, "user")
+	})
+
+	Convey("Token method only", t, func() {
+		authentication, err := NewAuthentication(api.Config{AuthMethods: []string{api.AuthToken}}, dataBase)
+		So(err, ShouldBeNil)
+
+		Convey("Without credentials, should return unauthorized", func() {
+			request, _ := http.NewRequest("GET", "/api/user", nil)
+			request.Header.Set("x-webauth-user", "user")
+			_, authErr := authentication.Authenticate(request)
+			So(authErr, ShouldResemble, api.ErrorUnauthorized("Authentication required"))
+		})
+
+		Convey("Valid token, should return owner login", func() {
+			dataBase.EXPECT().GetAPITokenByHash(hash).Return(moira.APIToken{ID: "1", Login: "user", Hash: hash}, nil)
+			login, authErr := authentication.Authenticate(bearerRequest(token))
+			So(authErr, ShouldBeNil)
+			So(login, ShouldEqual, "user")
+		})
+
+		Convey("Unknown token, should return unauthorized", func() {
+			dataBase.EXPECT().GetAPITokenByHash(hash).Return(moira.APIToken{}, database.ErrNil)
+			_, authErr := authentication.Authenticate(bearerRequest(token))
+			So(authErr, ShouldResemble, api.ErrorUnauthorized("Invalid api token"))
+		})
+
+		Convey("Database error, should return internal server error", func() {
+			expected := fmt.Errorf("Oooops! Can not get token")
+			dataBase.EXPECT().GetAPITokenByHash(hash).Return(moira.APIToken{}, expected)
+			_, authErr := authentication.Authenticate(bearerRequest(token))
+			So(authErr, ShouldResemble, api.ErrorInternalServer(expected))
+		})
+	})
+
+	Convey("Token and header methods, should return error", t, func() {
+		_, err := NewAuthentication(api.Config{AuthMethods: []string{api.AuthToken, api.AuthHeader}}, dataBase)
+		So(err, ShouldResemble, fmt.Errorf("Authentication method 'header' can't be combined with other methods"))
+		_, err = NewAuthentication(api.Config{AuthMethods: []string{api.AuthHeader, api.AuthJWT}}, dataBase)
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Administrators are configured by login", t, func() {
+		authentication, err := NewAuthentication(api.Config{AuthMethods: []string{api.AuthHeader}, Admins: []string{"admin"}}, dataBase)
+		So(err, ShouldBeNil)
+		So(authentication.IsAdmin("admin"), ShouldBeTrue)
+		So(authentication.IsAdmin("user"), ShouldBeFalse)
+		So(authentication.IsAdmin(""), ShouldBeFalse)
+	})
+
+	Convey("Generated token", t, func() {
+		So(token, ShouldStartWith, apiTokenPrefix)
+		So(token, ShouldHaveLength, len(apiTokenPrefix)+40)
+		So(HashAPIToken(token), ShouldEqual, hash)
+	})
+}
@@ -0,0 +1,299 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/moira-alert/moira/api"
+)
+
+// jwtLeeway is allowed clock skew between token issuer and api
+const jwtLeeway = 60 * time.Second
+
+// jwtAlgorithms are supported asymmetric signature algorithms, none and HMAC are never accepted
+var jwtAlgorithms = map[string]crypto.Hash{
+	"RS256": crypto.SHA256,
+	"RS384": crypto.SHA384,
+	"RS512": crypto.SHA512,
+	"ES256": crypto.SHA256,
+	"ES384": crypto.SHA384,
+	"ES512": crypto.SHA512,
+}
+
+// JWTAuthenticator validates JWT bearer tokens signed by keys from JWKS file
+type JWTAuthenticator struct {
+	issuer     string
+	audience   string
+	loginClaim string
+	keys       map[string]crypto.PublicKey
+	now        func() time.Time
+}
+
+type jsonWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// NewJWTAuthenticator reads signing keys from JWKS file and creates new JWTAuthenticator
+func NewJWTAuthenticator(config api.JWTConfig) (*JWTAuthenticator, error) {
+	if config.KeysFile == "" {
+		return nil, fmt.Errorf("JWT keys file is not specified")
+	}
+	data, err := ioutil.ReadFile(config.KeysFile)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to read JWT keys file: %s", err.Error())
+	}
+	keys, err := parseJWKS(data)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to parse JWT keys file: %s", err.Error())
+	}
+	loginClaim := config.LoginClaim
+	if loginClaim == "" {
+		loginClaim = "sub"
+	}
+	return &JWTAuthenticator{
+		issuer:     config.Issuer,
+		audience:   config.Audience,
+		loginClaim: loginClaim,
+		keys:       keys,
+		now:        time.Now,
+	}, nil
+}
+
+// Authenticate returns login claim of valid JWT bearer token
+func (authenticator *JWTAuthenticator) Authenticate(request *http.Request) (string, *api.ErrorResponse) {
+	token := getBearerToken(request)
+	if token == "" || strings.HasPrefix(token, apiTokenPrefix) {
+		return "", nil
+	}
+	login, err := authenticator.validate(token)
+	if err != nil {
+		return "", api.ErrorUnauthorized(fmt.Sprintf("Invalid JWT: %s", err.Error()))
+	}
+	return login, nil
+}
+
+func (authenticator *JWTAuthenticator) validate(token string) (string, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return "", fmt.Errorf("malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return "", fmt.Errorf("malformed header")
+	}
+	hash, ok := jwtAlgorithms[header.Alg]
+	if !ok {
+		return "", fmt.Errorf("unsupported algorithm '%s'", header.Alg)
+	}
+	key, err := authenticator.getKey(header.Kid)
+	if err != nil {
+		return "", err
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return "", fmt.Errorf("malformed signature")
+	}
+	if err := verifySignature(header.Alg, hash, key, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return "", err
+	}
+
+	var claims map[string]interface{}
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return "", fmt.Errorf("malformed claims")
+	}
+	if err := authenticator.validateClaims(claims); err != nil {
+		return "", err
+	}
+	login, ok := claims[authenticator.loginClaim].(string)
+	if !ok || login == "" {
+		return "", fmt.Errorf("claim '%s' is missing", authenticator.loginClaim)
+	}
+	return login, nil
+}
+
+func (authenticator *JWTAuthenticator) validateClaims(claims map[string]interface{}) error {
+	now := authenticator.now()
+	exp, ok := claims["exp"].(float64)
+	if !ok {
+		return fmt.Errorf("claim 'exp' is missing")
+	}
+	if now.Add(-jwtLeeway).After(time.Unix(int64(exp), 0)) {
+		return fmt.Errorf("token is expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return fmt.Errorf("token is not valid yet")
+	}
+	if authenticator.issuer != "" {
+		if iss, _ := claims["iss"].(string); iss != authenticator.issuer {
+			return fmt.Errorf("invalid issuer")
+		}
+	}
+	if authenticator.audience != "" && !hasAudience(claims["aud"], authenticator.audience) {
+		return fmt.Errorf("invalid audience")
+	}
+	return nil
+}
+
+func (authenticator *JWTAuthenticator) getKey(kid string) (crypto.PublicKey, error) {
+	if kid == "" {
+		if len(authenticator.keys) == 1 {
+			for _, key := range authenticator.keys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("key id is missing")
+	}
+	key, ok := authenticator.keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown key '%s'", kid)
+	}
+	return key, nil
+}
+
+func hasAudience(aud interface{}, audience string) bool {
+	switch value := aud.(type) {
+	case string:
+		return value == audience
+	case []interface{}:
+		for _, item := range value {
+			if item == audience {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func verifySignature(alg string, hash crypto.Hash, key crypto.PublicKey, signed []byte, signature []byte) error {
+	hasher := hash.New()
+	hasher.Write(signed)
+	digest := hasher.Sum(nil)
+	switch publicKey := key.(type) {
+	case *rsa.PublicKey:
+		if !strings.HasPrefix(alg, "RS") {
+			return fmt.Errorf("algorithm '%s' does not match key type", alg)
+		}
+		if err := rsa.VerifyPKCS1v15(publicKey, hash, digest, signature); err != nil {
+			return fmt.Errorf("invalid signature")
+		}
+	case *ecdsa.PublicKey:
+		if !strings.HasPrefix(alg, "ES") {
+			return fmt.Errorf("algorithm '%s' does not match key type", alg)
+		}
+		size := (publicKey.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(publicKey, digest, r, s) {
+			return fmt.Errorf("invalid signature")
+		}
+	default:
+		return fmt.Errorf("unsupported key type")
+	}
+	return nil
+}
+
+func decodeSegment(segment string, value interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, value)
+}
+
+func parseJWKS(data []byte) (map[string]crypto.PublicKey, error) {
+	var jwks struct {
+		Keys []jsonWebKey `json:"keys"`
+	}
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, err
+	}
+	keys := make(map[string]crypto.PublicKey)
+	for _, jwk := range jwks.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := parseJWK(jwk)
+		if err != nil {
+			return nil, fmt.Errorf("key '%s': %s", jwk.Kid, err.Error())
+		}
+		keys[jwk.Kid] = key
+	}
+	if len(keys) == 0 {
+		return nil, fmt.Errorf("no signing keys found")
+	}
+	return keys, nil
+}
+
+func parseJWK(jwk jsonWebKey) (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBigInt(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBigInt(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: n, E: int(e.Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve '%s'", jwk.Crv)
+		}
+		x, err := decodeBigInt(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBigInt(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		if !curve.IsOnCurve(x, y) {
+			return nil, fmt.Errorf("point is not on curve")
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type '%s'", jwk.Kty)
+	}
+}
+
+func decodeBigInt(value string) (*big.Int, error) {
+	data, err := base64.RawURLEncoding.DecodeString(value)
+	if err != nil || len(data) == 0 {
+		return nil, fmt.Errorf("invalid key parameter")
+	}
+	return new(big.Int).SetBytes(data), nil
+}
@@ -0,0 +1,197 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"testing"
+	"time"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/api"
+)
+
+func encodeSegment(value interface{}) string {
+	data, _ := json.Marshal(value)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func signRS256(key *rsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": "RS256", "kid": kid, "typ": "JWT"}) + "." + encodeSegment(claims)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signed))
+	signature, _ := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest.Sum(nil))
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func signES256(key *ecdsa.PrivateKey, kid string, claims map[string]interface{}) string {
+	signed := encodeSegment(map[string]string{"alg": "ES256", "kid": kid}) + "." + encodeSegment(claims)
+	digest := crypto.SHA256.New()
+	digest.Write([]byte(signed))
+	r, s, _ := ecdsa.Sign(rand.Reader, key, digest.Sum(nil))
+	signature := make([]byte, 64)
+	rBytes, sBytes := r.Bytes(), s.Bytes()
+	copy(signature[32-len(rBytes):32], rBytes)
+	copy(signature[64-len(sBytes):], sBytes)
+	return signed + "." + base64.RawURLEncoding.EncodeToString(signature)
+}
+
+func writeJWKS(t *testing.T, rsaKey *rsa.PrivateKey, ecKey *ecdsa.PrivateKey) string {
+	jwks := map[string]interface{}{
+		"keys": []map[string]string{
+			{
+				"kty": "RSA",
+				"kid": "rsa-key",
+				"use": "sig",
+				"n":   base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+				"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(rsaKey.E)).Bytes()),
+			},
+			{
+				"kty": "EC",
+				"kid": "ec-key",
+				"crv": "P-256",
+				"x":   base64.RawURLEncoding.EncodeToString(ecKey.X.Bytes()),
+				"y":   base64.RawURLEncoding.EncodeToString(ecKey.Y.Bytes()),
+			},
+		},
+	}
+	file, err := ioutil.TempFile("", "jwks")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	if err := json.NewEncoder(file).Encode(jwks); err != nil {
+		t.Fatal(err)
+	}
+	return file.Name()
+}
+
+func bearerRequest(token string) *http.Request {
+	request, _ := http.NewRequest("GET", "/api/user", nil)
+	request.Header.Set("Authorization", "Bearer "+token)
+	return request
+}
+
+func TestJWTAuthenticator(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keysFile := writeJWKS(t, rsaKey, ecKey)
+	defer os.Remove(keysFile)
+
+	authenticator, err := NewJWTAuthenticator(api.JWTConfig{Issuer: "https://sso", Audience: "moira", KeysFile: keysFile})
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	validClaims := func() map[string]interface{} {
+		return map[string]interface{}{
+			"sub": "user",
+			"iss": "https://sso",
+			"aud": []string{"grafana", "moira"},
+			"exp": now.Add(time.Hour).Unix(),
+		}
+	}
+
+	Convey("Valid tokens, should return login", t, func() {
+		login, err := authenticator.Authenticate(bearerRequest(signRS256(rsaKey, "rsa-key", validClaims())))
+		So(err, ShouldBeNil)
+		So(login, ShouldEqual, "user")
+
+		login, err = authenticator.Authenticate(bearerRequest(signES256(ecKey, "ec-key", validClaims())))
+		So(err, ShouldBeNil)
+		So(login, ShouldEqual, "user")
+	})
+
+	Convey("No bearer token or api token, should return empty login", t, func() {
+		request, _ := http.NewRequest("GET", "/api/user", nil)
+		login, err := authenticator.Authenticate(request)
+		So(err, ShouldBeNil)
+		So(login, ShouldBeEmpty)
+
+		login, err = authenticator.Authenticate(bearerRequest("moira_0123"))
+		So(err, ShouldBeNil)
+		So(login, ShouldBeEmpty)
+	})
+
+	Convey("Invalid tokens, should return unauthorized", t, func() {
+		Convey("Expired", func() {
+			claims := validClaims()
+			claims["exp"] = now.Add(-time.Hour).Unix()
+			_, err := authenticator.Authenticate(bearerRequest(signRS256(rsaKey, "rsa-key", claims)))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: token is expired"))
+		})
+		Convey("Without exp", func() {
+			claims := validClaims()
+			delete(claims, "exp")
+			_, err := authenticator.Authenticate(bearerRequest(signRS256(rsaKey, "rsa-key", claims)))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: claim 'exp' is missing"))
+		})
+		Convey("Not valid yet", func() {
+			claims := validClaims()
+			claims["nbf"] = now.Add(time.Hour).Unix()
+			_, err := authenticator.Authenticate(bearerRequest(signRS256(rsaKey, "rsa-key", claims)))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: token is not valid yet"))
+		})
+		Convey("Wrong issuer", func() {
+			claims := validClaims()
+			claims["iss"] = "https://evil"
+			_, err := authenticator.Authenticate(bearerRequest(signRS256(rsaKey, "rsa-key", claims)))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: invalid issuer"))
+		})
+		Convey("Wrong audience", func() {
+			claims := validClaims()
+			claims["aud"] = "grafana"
+			_, err := authenticator.Authenticate(bearerRequest(signRS256(rsaKey, "rsa-key", claims)))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: invalid audience"))
+		})
+		Convey("Unknown key", func() {
+			_, err := authenticator.Authenticate(bearerRequest(signRS256(rsaKey, "other-key", validClaims())))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: unknown key 'other-key'"))
+		})
+		Convey("Signed by other key", func() {
+			otherKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+			_, err := authenticator.Authenticate(bearerRequest(signRS256(otherKey, "rsa-key", validClaims())))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: invalid signature"))
+		})
+		Convey("Algorithm none", func() {
+			token := fmt.Sprintf("%s.%s.", encodeSegment(map[string]string{"alg": "none"}), encodeSegment(validClaims()))
+			_, err := authenticator.Authenticate(bearerRequest(token))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: unsupported algorithm 'none'"))
+		})
+		Convey("Malformed", func() {
+			_, err := authenticator.Authenticate(bearerRequest("not-a-token"))
+			So(err, ShouldResemble, api.ErrorUnauthorized("Invalid JWT: malformed token"))
+		})
+	})
+
+	Convey("Custom login claim", t, func() {
+		customAuthenticator, err := NewJWTAuthenticator(api.JWTConfig{KeysFile: keysFile, LoginClaim: "email"})
+		So(err, ShouldBeNil)
+		claims := validClaims()
+		claims["email"] = "user@example.com"
+		login, authErr := customAuthenticator.Authenticate(bearerRequest(signRS256(rsaKey, "rsa-key", claims)))
+		So(authErr, ShouldBeNil)
+		So(login, ShouldEqual, "user@example.com")
+	})
+
+	Convey("Keys file does not exist, should return error", t, func() {
+		_, err := NewJWTAuthenticator(api.JWTConfig{KeysFile: keysFile + ".missing"})
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,58 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/database"
+)
+
+// apiTokenPrefix distinguishes personal api tokens from JWT in Authorization header
+const apiTokenPrefix = "moira_"
+
+// TokenAuthenticator checks personal api tokens, tokens are looked up by hash because secrets are not stored
+type TokenAuthenticator struct {
+	database moira.Database
+}
+
+// NewTokenAuthenticator creates new TokenAuthenticator
+func NewTokenAuthenticator(database moira.Database) *TokenAuthenticator {
+	return &TokenAuthenticator{database: database}
+}
+
+// Authenticate returns login of api token owner
+func (authenticator *TokenAuthenticator) Authenticate(request *http.Request) (string, *api.ErrorResponse) {
+	token := getBearerToken(request)
+	if !strings.HasPrefix(token, apiTokenPrefix) {
+		return "", nil
+	}
+	apiToken, err := authenticator.database.GetAPITokenByHash(HashAPIToken(token))
+	if err != nil {
+		if err == database.ErrNil {
+			return "", api.ErrorUnauthorized("Invalid api token")
+		}
+		return "", api.ErrorInternalServer(err)
+	}
+	return apiToken.Login, nil
+}
+
+// GenerateAPIToken returns new random api token secret and its hash
+func GenerateAPIToken() (string, string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", "", err
+	}
+	token := apiTokenPrefix + hex.EncodeToString(secret)
+	return token, HashAPIToken(token), nil
+}
+
+// HashAPIToken returns hash of api token secret which is stored in database
+func HashAPIToken(token string) string {
+	hash := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,137 @@
+// Package client is typed client of moira api, routes are described in api/openapi.yml
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Client sends requests to moira api
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	login      string
+	token      string
+}
+
+// Error is moira api error response
+type Error struct {
+	StatusCode int    `json:"-"`
+	Status     string `json:"status"`
+	Text       string `json:"error,omitempty"`
+}
+
+func (err *Error) Error() string {
+	if err.Text == "" {
+		return fmt.Sprintf("moira api: %d %s", err.StatusCode, err.Status)
+	}
+	return fmt.Sprintf("moira api: %d %s: %s", err.StatusCode, err.Status, err.Text)
+}
+
+// NewClient creates client of api with given base url, e.g. http://moira.example.com/api
+// http.DefaultClient is used if httpClient is nil
+func NewClient(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// SetAPIToken sets user api token sent in Authorization header
+func (client *Client) SetAPIToken(token string) {
+	client.token = token
+}
+
+// SetLogin sets user login sent in x-webauth-user header, it is used if api trusts reverse proxy header
+func (client *Client) SetLogin(login string) {
+	client.login = login
+}
+
+// doJSON sends request with json encoded body and decodes json response to result, nil body and result are skipped
+func (client *Client) doJSON(method string, path string, query url.Values, body interface{}, result interface{}) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reader = bytes.NewReader(data)
+	}
+	return client.do(method, path, query, "application/json", reader, result)
+}
+
+func (client *Client) do(method string, path string, query url.Values, contentType string, body io.Reader, result interface{}) error {
+	requestURL := client.baseURL + path
+	if len(query) > 0 {
+		requestURL += "?" + query.Encode()
+	}
+	request, err := http.NewRequest(method, requestURL, body)
+	if err != nil {
+		return err
+	}
+	if body != nil {
+		request.Header.Set("Content-Type", contentType)
+	}
+	request.Header.Set("Accept", "application/json")
+	if client.login != "" {
+		request.Header.Set("x-webauth-user", client.login)
+	}
+	if client.token != "" {
+		request.Header.Set("Authorization", "Bearer "+client.token)
+	}
+
+	response, err := client.httpClient.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return err
+	}
+
+	if response.StatusCode < 200 || response.StatusCode >= 300 {
+		apiError := &Error{StatusCode: response.StatusCode}
+		if err := json.Unmarshal(data, apiError); err != nil || apiError.Status == "" {
+			apiError.Status = http.StatusText(response.StatusCode)
+		}
+		return apiError
+	}
+	if result == nil || len(data) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(data, result); err != nil {
+		return fmt.Errorf("Failed to decode response of %s %s: %s", method, path, err.Error())
+	}
+	return nil
+}
+
+// pagination returns page query of paginated routes
+func pagination(page int64, size int64) url.Values {
+	query := url.Values{}
+	query.Set("p", fmt.Sprint(page))
+	query.Set("size", fmt.Sprint(size))
+	return query
+}
+
+// commentQuery returns query with trigger history comment if it is set
+func commentQuery(comment string) url.Values {
+	query := url.Values{}
+	if comment != "" {
+		query.Set("comment", comment)
+	}
+	return query
+}
+
+func escape(segment string) string {
+	return url.PathEscape(segment)
+}
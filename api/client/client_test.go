@@ -0,0 +1,143 @@
+package client
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
+	"github.com/moira-alert/moira/api/handler"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+// testRequest is request received by test server
+type testRequest struct {
+	method      string
+	path        string
+	query       map[string][]string
+	header      http.Header
+	contentType string
+	body        string
+}
+
+// newTestServer starts server which records requests and responds with given status and body
+func newTestServer(status int, responseBody string) (*httptest.Server, *testRequest) {
+	received := &testRequest{}
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		body, _ := ioutil.ReadAll(request.Body)
+		*received = testRequest{
+			method:      request.Method,
+			path:        request.URL.EscapedPath(),
+			query:       request.URL.Query(),
+			header:      request.Header,
+			contentType: request.Header.Get("Content-Type"),
+			body:        string(body),
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		writer.WriteHeader(status)
+		writer.Write([]byte(responseBody))
+	}))
+	return server, received
+}
+
+func TestClient(t *testing.T) {
+	Convey("Base url trailing slash is trimmed", t, func() {
+		server, received := newTestServer(200, `{"list":[]}`)
+		defer server.Close()
+		client := NewClient(server.URL+"/api/", nil)
+		_, err := client.GetTags()
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/api/tag")
+	})
+
+	Convey("Auth headers", t, func() {
+		server, received := newTestServer(200, `{"list":[]}`)
+		defer server.Close()
+		client := NewClient(server.URL+"/api", server.Client())
+
+		Convey("Are not sent by default", func() {
+			_, err := client.GetTags()
+			So(err, ShouldBeNil)
+			So(received.header.Get("Authorization"), ShouldBeEmpty)
+			So(received.header.Get("x-webauth-user"), ShouldBeEmpty)
+		})
+
+		Convey("Token and login are sent", func() {
+			client.SetAPIToken("secret")
+			client.SetLogin("john")
+			_, err := client.GetTags()
+			So(err, ShouldBeNil)
+			So(received.header.Get("Authorization"), ShouldEqual, "Bearer secret")
+			So(received.header.Get("x-webauth-user"), ShouldEqual, "john")
+		})
+	})
+
+	Convey("Error responses", t, func() {
+		Convey("Api error is decoded", func() {
+			server, _ := newTestServer(404, `{"status":"Resource not found","error":"Trigger not found"}`)
+			defer server.Close()
+			_, err := NewClient(server.URL, nil).GetTrigger("id")
+			So(err, ShouldResemble, &Error{StatusCode: 404, Status: "Resource not found", Text: "Trigger not found"})
+			So(err.Error(), ShouldEqual, "moira api: 404 Resource not found: Trigger not found")
+		})
+
+		Convey("Not json error uses http status text", func() {
+			server, _ := newTestServer(502, `bad gateway`)
+			defer server.Close()
+			err := NewClient(server.URL, nil).RemoveTrigger("id", "")
+			So(err, ShouldResemble, &Error{StatusCode: 502, Status: "Bad Gateway"})
+			So(err.Error(), ShouldEqual, "moira api: 502 Bad Gateway")
+		})
+
+		Convey("Invalid json response", func() {
+			server, _ := newTestServer(200, `{"list":`)
+			defer server.Close()
+			result, err := NewClient(server.URL, nil).GetTags()
+			So(err, ShouldNotBeNil)
+			So(result, ShouldBeNil)
+		})
+	})
+
+	Convey("Empty response body is allowed", t, func() {
+		server, received := newTestServer(200, ``)
+		defer server.Close()
+		err := NewClient(server.URL, nil).RemoveContact("contact id")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "DELETE")
+		So(received.path, ShouldEqual, "/contact/contact%20id")
+	})
+}
+
+func TestClientWithAPIHandler(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("api")
+	authentication, err := auth.NewAuthentication(api.Config{AuthMethods: []string{api.AuthHeader}}, dataBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(handler.NewHandler(dataBase, logger, authentication))
+	defer server.Close()
+	client := NewClient(server.URL+"/api", nil)
+
+	Convey("Client requests are routed by api handler", t, func() {
+		dataBase.EXPECT().GetTagNames().Return([]string{"a", "b"}, nil)
+		result, err := client.GetTags()
+		So(err, ShouldBeNil)
+		So(result.TagNames, ShouldResemble, []string{"a", "b"})
+	})
+
+	Convey("Api handler errors are returned", t, func() {
+		result, err := client.GetTriggerPage(0, 10, TriggerPageFilter{Sort: "unknown"})
+		So(err, ShouldHaveSameTypeAs, &Error{})
+		So(err.(*Error).StatusCode, ShouldEqual, 400)
+		So(result, ShouldBeNil)
+	})
+}
@@ -0,0 +1,51 @@
+package client
+
+import (
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// GetContacts gets all contacts
+func (client *Client) GetContacts() (*dto.ContactList, error) {
+	result := &dto.ContactList{}
+	if err := client.doJSON("GET", "/contact", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateContact creates contact of current user or of contact team
+func (client *Client) CreateContact(contact *dto.Contact) (*dto.Contact, error) {
+	result := &dto.Contact{}
+	if err := client.doJSON("PUT", "/contact", nil, contact, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateContact updates contact
+func (client *Client) UpdateContact(contactID string, contact *dto.Contact) (*dto.Contact, error) {
+	result := &dto.Contact{}
+	if err := client.doJSON("PUT", "/contact/"+escape(contactID), nil, contact, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveContact removes contact
+func (client *Client) RemoveContact(contactID string) error {
+	return client.doJSON("DELETE", "/contact/"+escape(contactID), nil, nil, nil)
+}
+
+// TestContact sends test notification to contact
+func (client *Client) TestContact(contactID string) error {
+	return client.doJSON("POST", "/contact/"+escape(contactID)+"/test", nil, nil, nil)
+}
+
+// GetContactDeliveries gets page of notification deliveries to contact
+func (client *Client) GetContactDeliveries(contactID string, page int64, size int64) (*dto.DeliveriesList, error) {
+	result := &dto.DeliveriesList{}
+	if err := client.doJSON("GET", "/contact/"+escape(contactID)+"/deliveries", pagination(page, size), nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
@@ -0,0 +1,64 @@
+package client
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+func TestContacts(t *testing.T) {
+	Convey("Get contacts", t, func() {
+		server, received := newTestServer(200, `{"list":[{"id":"contact-id","type":"mail","value":"john@example.com","user":"john"}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetContacts()
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/contact")
+		So(result.List, ShouldResemble, []*moira.ContactData{
+			{ID: "contact-id", Type: "mail", Value: "john@example.com", User: "john"},
+		})
+	})
+
+	Convey("Create contact", t, func() {
+		server, received := newTestServer(200, `{"id":"contact-id","type":"mail","value":"john@example.com","user":"john"}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).CreateContact(&dto.Contact{Type: "mail", Value: "john@example.com"})
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/contact")
+		So(received.body, ShouldEqual, `{"type":"mail","value":"john@example.com"}`)
+		So(result, ShouldResemble, &dto.Contact{ID: "contact-id", Type: "mail", Value: "john@example.com", User: "john"})
+	})
+
+	Convey("Update contact with invalid value", t, func() {
+		server, received := newTestServer(400, `{"status":"Invalid request","error":"contact value is empty"}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).UpdateContact("contact-id", &dto.Contact{Type: "mail"})
+		So(err, ShouldResemble, &Error{StatusCode: 400, Status: "Invalid request", Text: "contact value is empty"})
+		So(result, ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/contact/contact-id")
+	})
+
+	Convey("Test contact", t, func() {
+		server, received := newTestServer(200, ``)
+		defer server.Close()
+		So(NewClient(server.URL, nil).TestContact("contact-id"), ShouldBeNil)
+		So(received.method, ShouldEqual, "POST")
+		So(received.path, ShouldEqual, "/contact/contact-id/test")
+	})
+
+	Convey("Get contact deliveries", t, func() {
+		server, received := newTestServer(200, `{"page":0,"size":10,"total":1,"list":[{"timestamp":100,"trigger_id":"trigger-id","events_count":2,"result":"OK","attempt":1,"contact":{"id":"contact-id"}}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetContactDeliveries("contact-id", 0, 10)
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/contact/contact-id/deliveries")
+		So(received.query, ShouldResemble, map[string][]string{"p": {"0"}, "size": {"10"}})
+		So(result.List, ShouldResemble, []moira.DeliveryData{
+			{Timestamp: 100, TriggerID: "trigger-id", EventsCount: 2, Result: moira.DeliveryOK, Attempt: 1, Contact: moira.ContactData{ID: "contact-id"}},
+		})
+	})
+}
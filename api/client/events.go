@@ -0,0 +1,61 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// EventsFilter filters events of all triggers, From and To are graphite-like time, Metric is graphite glob
+type EventsFilter struct {
+	From     string
+	To       string
+	Tags     []string
+	State    string
+	OldState string
+	Metric   string
+}
+
+// GetTriggerEvents gets page of trigger events, latest event first
+func (client *Client) GetTriggerEvents(triggerID string, page int64, size int64) (*dto.EventsList, error) {
+	result := &dto.EventsList{}
+	if err := client.doJSON("GET", "/event/"+escape(triggerID), pagination(page, size), nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEvents gets page of events of all triggers matched by filter, latest event first
+func (client *Client) GetEvents(page int64, size int64, filter EventsFilter) (*dto.EventsList, error) {
+	query := pagination(page, size)
+	filter.setQuery(query)
+	result := &dto.EventsList{}
+	if err := client.doJSON("GET", "/events", query, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetEventCounts gets counts of events of all triggers matched by filter per trigger and interval in seconds
+func (client *Client) GetEventCounts(filter EventsFilter, interval int64) (*dto.EventCounts, error) {
+	query := url.Values{}
+	filter.setQuery(query)
+	query.Set("interval", fmt.Sprint(interval))
+	result := &dto.EventCounts{}
+	if err := client.doJSON("GET", "/events/counts", query, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (filter EventsFilter) setQuery(query url.Values) {
+	for name, value := range map[string]string{"from": filter.From, "to": filter.To, "state": filter.State, "oldState": filter.OldState, "metric": filter.Metric} {
+		if value != "" {
+			query.Set(name, value)
+		}
+	}
+	for i, tag := range filter.Tags {
+		query.Set(fmt.Sprintf("tags[%d]", i), tag)
+	}
+}
@@ -0,0 +1,50 @@
+package client
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+func TestEvents(t *testing.T) {
+	Convey("Get trigger events", t, func() {
+		server, received := newTestServer(200, `{"page":2,"size":5,"total":11,"list":[{"timestamp":100,"metric":"my.metric","state":"ERROR","old_state":"OK","trigger_id":"trigger-id"}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetTriggerEvents("trigger-id", 2, 5)
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "GET")
+		So(received.path, ShouldEqual, "/event/trigger-id")
+		So(received.query, ShouldResemble, map[string][]string{"p": {"2"}, "size": {"5"}})
+		So(result.Total, ShouldEqual, 11)
+		So(result.List, ShouldResemble, []moira.NotificationEvent{
+			{Timestamp: 100, Metric: "my.metric", State: "ERROR", OldState: "OK", TriggerID: "trigger-id"},
+		})
+	})
+
+	Convey("Get events of all triggers", t, func() {
+		server, received := newTestServer(200, `{"page":0,"size":10,"total":1,"list":[{"timestamp":100,"metric":"my.metric","state":"ERROR","old_state":"OK","trigger_id":"trigger-id"}]}`)
+		defer server.Close()
+		filter := EventsFilter{From: "-2hours", Tags: []string{"dc-eu"}, State: "ERROR", OldState: "OK", Metric: "my.*"}
+		result, err := NewClient(server.URL, nil).GetEvents(0, 10, filter)
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "GET")
+		So(received.path, ShouldEqual, "/events")
+		So(received.query, ShouldResemble, map[string][]string{
+			"p": {"0"}, "size": {"10"}, "from": {"-2hours"}, "tags[0]": {"dc-eu"}, "state": {"ERROR"}, "oldState": {"OK"}, "metric": {"my.*"},
+		})
+		So(result.Total, ShouldEqual, 1)
+	})
+
+	Convey("Get event counts", t, func() {
+		server, received := newTestServer(200, `{"from":0,"to":7200,"interval":3600,"total":3,"list":[{"trigger_id":"trigger-id","timestamp":3600,"count":3}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetEventCounts(EventsFilter{From: "0", To: "7200"}, 3600)
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/events/counts")
+		So(received.query, ShouldResemble, map[string][]string{"from": {"0"}, "to": {"7200"}, "interval": {"3600"}})
+		So(result.List, ShouldResemble, []dto.EventCount{{TriggerID: "trigger-id", Timestamp: 3600, Count: 3}})
+	})
+}
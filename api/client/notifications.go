@@ -0,0 +1,31 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// GetNotifications gets scheduled notifications from start to end index, end -1 means the last one
+func (client *Client) GetNotifications(start int64, end int64) (*dto.NotificationsList, error) {
+	query := url.Values{}
+	query.Set("start", fmt.Sprint(start))
+	query.Set("end", fmt.Sprint(end))
+	result := &dto.NotificationsList{}
+	if err := client.doJSON("GET", "/notification", query, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveNotification removes scheduled notification by its key
+func (client *Client) RemoveNotification(notificationKey string) (*dto.NotificationDeleteResponse, error) {
+	query := url.Values{}
+	query.Set("id", notificationKey)
+	result := &dto.NotificationDeleteResponse{}
+	if err := client.doJSON("DELETE", "/notification", query, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
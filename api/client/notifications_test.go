@@ -0,0 +1,33 @@
+package client
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/api/dto"
+)
+
+func TestNotifications(t *testing.T) {
+	Convey("Get notifications", t, func() {
+		server, received := newTestServer(200, `{"total":1,"list":[{"event":{"trigger_id":"trigger-id","state":"ERROR"},"contact":{"id":"contact-id"},"timestamp":100,"send_fail":1}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetNotifications(0, -1)
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/notification")
+		So(received.query, ShouldResemble, map[string][]string{"start": {"0"}, "end": {"-1"}})
+		So(result.Total, ShouldEqual, 1)
+		So(result.List[0].Event.TriggerID, ShouldEqual, "trigger-id")
+		So(result.List[0].SendFail, ShouldEqual, 1)
+	})
+
+	Convey("Remove notification", t, func() {
+		server, received := newTestServer(200, `{"result":1}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).RemoveNotification("100contact-idtrigger-id")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "DELETE")
+		So(received.query, ShouldResemble, map[string][]string{"id": {"100contact-idtrigger-id"}})
+		So(result, ShouldResemble, &dto.NotificationDeleteResponse{Result: 1})
+	})
+}
@@ -0,0 +1,45 @@
+package client
+
+import (
+	"strings"
+
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// GetSubscriptions gets subscriptions of current user
+func (client *Client) GetSubscriptions() (*dto.SubscriptionList, error) {
+	result := &dto.SubscriptionList{}
+	if err := client.doJSON("GET", "/subscription", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SaveSubscription creates subscription or updates subscription with given id
+func (client *Client) SaveSubscription(subscription *dto.Subscription) (*dto.Subscription, error) {
+	result := &dto.Subscription{}
+	if err := client.doJSON("PUT", "/subscription", nil, subscription, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveSubscription removes subscription
+func (client *Client) RemoveSubscription(subscriptionID string) error {
+	return client.doJSON("DELETE", "/subscription/"+escape(subscriptionID), nil, nil, nil)
+}
+
+// TestSubscription sends test notification to subscription contacts
+func (client *Client) TestSubscription(subscriptionID string) error {
+	return client.doJSON("PUT", "/subscription/"+escape(subscriptionID)+"/test", nil, nil, nil)
+}
+
+// ImportSubscriptionHolidays imports subscription holiday dates from iCalendar data
+func (client *Client) ImportSubscriptionHolidays(subscriptionID string, iCalData string) (*dto.Subscription, error) {
+	result := &dto.Subscription{}
+	path := "/subscription/" + escape(subscriptionID) + "/holidays"
+	if err := client.do("PUT", path, nil, "text/calendar", strings.NewReader(iCalData), result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
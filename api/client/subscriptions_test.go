@@ -0,0 +1,65 @@
+package client
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+func TestSubscriptions(t *testing.T) {
+	Convey("Get subscriptions", t, func() {
+		server, received := newTestServer(200, `{"list":[{"id":"subscription-id","contacts":["contact-id"],"tags":["tag"],"enabled":true,"user":"john"}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetSubscriptions()
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "GET")
+		So(received.path, ShouldEqual, "/subscription")
+		So(result.List, ShouldResemble, []moira.SubscriptionData{
+			{ID: "subscription-id", Contacts: []string{"contact-id"}, Tags: []string{"tag"}, Enabled: true, User: "john"},
+		})
+	})
+
+	Convey("Save subscription", t, func() {
+		server, received := newTestServer(200, `{"id":"subscription-id","contacts":["contact-id"],"tags":["tag"],"enabled":true,"user":"john"}`)
+		defer server.Close()
+		subscription := &dto.Subscription{Contacts: []string{"contact-id"}, Tags: []string{"tag"}, Enabled: true}
+		result, err := NewClient(server.URL, nil).SaveSubscription(subscription)
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/subscription")
+		So(received.body, ShouldContainSubstring, `"contacts":["contact-id"]`)
+		So(result.ID, ShouldEqual, "subscription-id")
+	})
+
+	Convey("Remove subscription", t, func() {
+		server, received := newTestServer(200, ``)
+		defer server.Close()
+		So(NewClient(server.URL, nil).RemoveSubscription("subscription-id"), ShouldBeNil)
+		So(received.method, ShouldEqual, "DELETE")
+		So(received.path, ShouldEqual, "/subscription/subscription-id")
+	})
+
+	Convey("Test subscription", t, func() {
+		server, received := newTestServer(200, ``)
+		defer server.Close()
+		So(NewClient(server.URL, nil).TestSubscription("subscription-id"), ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/subscription/subscription-id/test")
+	})
+
+	Convey("Import subscription holidays", t, func() {
+		server, received := newTestServer(200, `{"id":"subscription-id","holidays":{"dates":["2018-01-01"]}}`)
+		defer server.Close()
+		iCalData := "BEGIN:VCALENDAR\r\nEND:VCALENDAR\r\n"
+		result, err := NewClient(server.URL, nil).ImportSubscriptionHolidays("subscription-id", iCalData)
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/subscription/subscription-id/holidays")
+		So(received.contentType, ShouldEqual, "text/calendar")
+		So(received.body, ShouldEqual, iCalData)
+		So(result.Holidays, ShouldResemble, &moira.HolidaysData{Dates: []string{"2018-01-01"}})
+	})
+}
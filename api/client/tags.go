@@ -0,0 +1,32 @@
+package client
+
+import (
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// GetTags gets all tag names
+func (client *Client) GetTags() (*dto.TagsData, error) {
+	result := &dto.TagsData{}
+	if err := client.doJSON("GET", "/tag", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTagsStatistics gets triggers and subscriptions of every tag
+func (client *Client) GetTagsStatistics() (*dto.TagsStatistics, error) {
+	result := &dto.TagsStatistics{}
+	if err := client.doJSON("GET", "/tag/stats", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveTag removes tag which is not used by triggers
+func (client *Client) RemoveTag(tag string) (*dto.MessageResponse, error) {
+	result := &dto.MessageResponse{}
+	if err := client.doJSON("DELETE", "/tag/"+escape(tag), nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
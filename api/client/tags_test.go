@@ -0,0 +1,41 @@
+package client
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/api/dto"
+)
+
+func TestTags(t *testing.T) {
+	Convey("Get tags", t, func() {
+		server, received := newTestServer(200, `{"list":["a","b"]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetTags()
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/tag")
+		So(result, ShouldResemble, &dto.TagsData{TagNames: []string{"a", "b"}})
+	})
+
+	Convey("Get tags statistics", t, func() {
+		server, received := newTestServer(200, `{"list":[{"name":"a","triggers":["trigger-id"],"subscriptions":[]}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetTagsStatistics()
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/tag/stats")
+		So(result.List, ShouldHaveLength, 1)
+		So(result.List[0].TagName, ShouldEqual, "a")
+		So(result.List[0].Triggers, ShouldResemble, []string{"trigger-id"})
+	})
+
+	Convey("Remove tag", t, func() {
+		server, received := newTestServer(200, `{"message":"tag deleted"}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).RemoveTag("a/b")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "DELETE")
+		So(received.path, ShouldEqual, "/tag/a%2Fb")
+		So(result, ShouldResemble, &dto.MessageResponse{Message: "tag deleted"})
+	})
+}
@@ -0,0 +1,166 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// TriggerPageFilter filters triggers of page, search query syntax is the same as in web interface
+type TriggerPageFilter struct {
+	OnlyProblems bool
+	Tags         []string
+	Query        string
+	Sort         string
+}
+
+// GetTriggers gets all triggers with last check data
+func (client *Client) GetTriggers() (*dto.TriggersList, error) {
+	result := &dto.TriggersList{}
+	if err := client.doJSON("GET", "/trigger", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTriggerPage gets page of triggers matched by filter
+func (client *Client) GetTriggerPage(page int64, size int64, filter TriggerPageFilter) (*dto.TriggersList, error) {
+	query := pagination(page, size)
+	query.Set("onlyProblems", strconv.FormatBool(filter.OnlyProblems))
+	for i, tag := range filter.Tags {
+		query.Set(fmt.Sprintf("tags[%d]", i), tag)
+	}
+	if filter.Query != "" {
+		query.Set("q", filter.Query)
+	}
+	if filter.Sort != "" {
+		query.Set("sort", filter.Sort)
+	}
+	result := &dto.TriggersList{}
+	if err := client.doJSON("GET", "/trigger/page", query, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTrigger gets trigger by id
+func (client *Client) GetTrigger(triggerID string) (*dto.Trigger, error) {
+	result := &dto.Trigger{}
+	if err := client.doJSON("GET", "/trigger/"+escape(triggerID), nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// CreateTrigger creates trigger, comment is saved in trigger history
+func (client *Client) CreateTrigger(trigger *dto.TriggerModel, comment string) (*dto.SaveTriggerResponse, error) {
+	result := &dto.SaveTriggerResponse{}
+	if err := client.doJSON("PUT", "/trigger", commentQuery(comment), trigger, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// UpdateTrigger updates trigger, comment is saved in trigger history
+func (client *Client) UpdateTrigger(triggerID string, trigger *dto.TriggerModel, comment string) (*dto.SaveTriggerResponse, error) {
+	result := &dto.SaveTriggerResponse{}
+	if err := client.doJSON("PUT", "/trigger/"+escape(triggerID), commentQuery(comment), trigger, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveTrigger removes trigger, comment is saved in trigger history
+func (client *Client) RemoveTrigger(triggerID string, comment string) error {
+	return client.doJSON("DELETE", "/trigger/"+escape(triggerID), commentQuery(comment), nil, nil)
+}
+
+// GetTriggerState gets trigger last check data
+func (client *Client) GetTriggerState(triggerID string) (*dto.TriggerCheck, error) {
+	result := &dto.TriggerCheck{}
+	if err := client.doJSON("GET", "/trigger/"+escape(triggerID)+"/state", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTriggerThrottling gets timestamp until which trigger notifications are throttled
+func (client *Client) GetTriggerThrottling(triggerID string) (*dto.ThrottlingResponse, error) {
+	result := &dto.ThrottlingResponse{}
+	if err := client.doJSON("GET", "/trigger/"+escape(triggerID)+"/throttling", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveTriggerThrottling resets trigger throttling
+func (client *Client) RemoveTriggerThrottling(triggerID string) error {
+	return client.doJSON("DELETE", "/trigger/"+escape(triggerID)+"/throttling", nil, nil, nil)
+}
+
+// GetTriggerMetrics gets values of trigger metrics, from and to are graphite-like time, e.g. -1hour and now
+func (client *Client) GetTriggerMetrics(triggerID string, from string, to string) (dto.TriggerMetrics, error) {
+	query := url.Values{}
+	query.Set("from", from)
+	query.Set("to", to)
+	result := make(dto.TriggerMetrics)
+	if err := client.doJSON("GET", "/trigger/"+escape(triggerID)+"/metrics", query, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RemoveTriggerMetric removes metric from trigger last check
+func (client *Client) RemoveTriggerMetric(triggerID string, metric string) error {
+	query := url.Values{}
+	query.Set("name", metric)
+	return client.doJSON("DELETE", "/trigger/"+escape(triggerID)+"/metrics", query, nil, nil)
+}
+
+// SetMetricsMaintenance sets maintenance end timestamps of trigger metrics
+func (client *Client) SetMetricsMaintenance(triggerID string, maintenance dto.MetricsMaintenance) error {
+	return client.doJSON("PUT", "/trigger/"+escape(triggerID)+"/maintenance", nil, maintenance, nil)
+}
+
+// GetTriggerHistory gets stored trigger versions
+func (client *Client) GetTriggerHistory(triggerID string) (*dto.TriggerHistory, error) {
+	result := &dto.TriggerHistory{}
+	if err := client.doJSON("GET", "/trigger/"+escape(triggerID)+"/history", nil, nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RestoreTriggerVersion restores trigger to given version
+func (client *Client) RestoreTriggerVersion(triggerID string, version int64, comment string) (*dto.SaveTriggerResponse, error) {
+	path := fmt.Sprintf("/trigger/%s/history/%d/restore", escape(triggerID), version)
+	result := &dto.SaveTriggerResponse{}
+	if err := client.doJSON("POST", path, commentQuery(comment), nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ApplyTriggersBulkOperation applies operation to selected triggers, in dry run mode changes are only returned
+func (client *Client) ApplyTriggersBulkOperation(operation *dto.TriggersBulkOperation, dryRun bool, comment string) (*dto.TriggersBulkResult, error) {
+	query := commentQuery(comment)
+	if dryRun {
+		query.Set("dry_run", "true")
+	}
+	result := &dto.TriggersBulkResult{}
+	if err := client.doJSON("POST", "/trigger/bulk", query, operation, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// GetTriggerDeliveries gets page of notification deliveries of trigger
+func (client *Client) GetTriggerDeliveries(triggerID string, page int64, size int64) (*dto.DeliveriesList, error) {
+	result := &dto.DeliveriesList{}
+	if err := client.doJSON("GET", "/trigger/"+escape(triggerID)+"/deliveries", pagination(page, size), nil, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
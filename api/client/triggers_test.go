@@ -0,0 +1,156 @@
+package client
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+func TestTriggers(t *testing.T) {
+	warnValue, errorValue := 10.0, 20.0
+	trigger := dto.TriggerModel{
+		ID:         "trigger-id",
+		Name:       "Trigger",
+		Targets:    []string{"my.metric"},
+		WarnValue:  &warnValue,
+		ErrorValue: &errorValue,
+		Tags:       []string{"tag"},
+		Patterns:   []string{"my.metric"},
+	}
+
+	Convey("Get triggers", t, func() {
+		server, received := newTestServer(200, `{"list":[{"id":"trigger-id","name":"Trigger","last_check":{"state":"OK","score":0}}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetTriggers()
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "GET")
+		So(received.path, ShouldEqual, "/trigger")
+		So(result.List, ShouldHaveLength, 1)
+		So(result.List[0].ID, ShouldEqual, "trigger-id")
+		So(result.List[0].LastCheck.State, ShouldEqual, "OK")
+	})
+
+	Convey("Get trigger page", t, func() {
+		server, received := newTestServer(200, `{"page":1,"size":20,"total":21,"list":[]}`)
+		defer server.Close()
+		filter := TriggerPageFilter{OnlyProblems: true, Tags: []string{"a", "b"}, Query: "state:ERROR", Sort: "-score"}
+		result, err := NewClient(server.URL, nil).GetTriggerPage(1, 20, filter)
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/trigger/page")
+		So(received.query, ShouldResemble, map[string][]string{
+			"p":            {"1"},
+			"size":         {"20"},
+			"onlyProblems": {"true"},
+			"tags[0]":      {"a"},
+			"tags[1]":      {"b"},
+			"q":            {"state:ERROR"},
+			"sort":         {"-score"},
+		})
+		So(*result.Total, ShouldEqual, 21)
+	})
+
+	Convey("Get trigger", t, func() {
+		server, received := newTestServer(200, `{"id":"trigger-id","name":"Trigger","targets":["my.metric"],"warn_value":10,"error_value":20,"tags":["tag"],"expression":"","patterns":["my.metric"],"throttling":100}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetTrigger("trigger-id")
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/trigger/trigger-id")
+		So(result, ShouldResemble, &dto.Trigger{TriggerModel: trigger, Throttling: 100})
+	})
+
+	Convey("Create trigger", t, func() {
+		server, received := newTestServer(200, `{"id":"trigger-id","message":"trigger created"}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).CreateTrigger(&trigger, "first version")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/trigger")
+		So(received.query["comment"], ShouldResemble, []string{"first version"})
+		So(received.contentType, ShouldEqual, "application/json")
+		So(received.body, ShouldContainSubstring, `"targets":["my.metric"]`)
+		So(result, ShouldResemble, &dto.SaveTriggerResponse{ID: "trigger-id", Message: "trigger created"})
+	})
+
+	Convey("Update trigger without comment", t, func() {
+		server, received := newTestServer(200, `{"id":"trigger-id","message":"trigger updated"}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).UpdateTrigger("trigger-id", &trigger, "")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/trigger/trigger-id")
+		So(received.query, ShouldBeEmpty)
+		So(result.Message, ShouldEqual, "trigger updated")
+	})
+
+	Convey("Remove trigger", t, func() {
+		server, received := newTestServer(200, ``)
+		defer server.Close()
+		err := NewClient(server.URL, nil).RemoveTrigger("trigger-id", "not needed")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "DELETE")
+		So(received.path, ShouldEqual, "/trigger/trigger-id")
+		So(received.query["comment"], ShouldResemble, []string{"not needed"})
+	})
+
+	Convey("Get trigger state", t, func() {
+		server, received := newTestServer(200, `{"trigger_id":"trigger-id","state":"ERROR","score":1000,"metrics":{"my.metric":{"state":"ERROR","timestamp":100,"event_timestamp":100,"suppressed":false}}}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetTriggerState("trigger-id")
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/trigger/trigger-id/state")
+		So(result.TriggerID, ShouldEqual, "trigger-id")
+		So(result.State, ShouldEqual, "ERROR")
+		So(result.Metrics["my.metric"], ShouldResemble, moira.MetricState{State: "ERROR", Timestamp: 100, EventTimestamp: 100})
+	})
+
+	Convey("Get trigger metrics", t, func() {
+		server, received := newTestServer(200, `{"my.metric":[{"ts":60,"value":1}]}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).GetTriggerMetrics("trigger-id", "-1hour", "now")
+		So(err, ShouldBeNil)
+		So(received.path, ShouldEqual, "/trigger/trigger-id/metrics")
+		So(received.query, ShouldResemble, map[string][]string{"from": {"-1hour"}, "to": {"now"}})
+		So(result, ShouldResemble, dto.TriggerMetrics{"my.metric": {{Timestamp: 60, Value: 1}}})
+	})
+
+	Convey("Set metrics maintenance", t, func() {
+		server, received := newTestServer(200, ``)
+		defer server.Close()
+		err := NewClient(server.URL, nil).SetMetricsMaintenance("trigger-id", dto.MetricsMaintenance{"my.metric": 1000})
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "PUT")
+		So(received.path, ShouldEqual, "/trigger/trigger-id/maintenance")
+		So(received.body, ShouldEqual, `{"my.metric":1000}`)
+	})
+
+	Convey("Restore trigger version", t, func() {
+		server, received := newTestServer(200, `{"id":"trigger-id","message":"trigger restored"}`)
+		defer server.Close()
+		result, err := NewClient(server.URL, nil).RestoreTriggerVersion("trigger-id", 3, "")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "POST")
+		So(received.path, ShouldEqual, "/trigger/trigger-id/history/3/restore")
+		So(result.ID, ShouldEqual, "trigger-id")
+	})
+
+	Convey("Apply bulk operation in dry run mode", t, func() {
+		server, received := newTestServer(200, `{"dry_run":true,"list":[{"trigger_id":"trigger-id","name":"Trigger","changes":[{"field":"tags","old_value":["tag"],"new_value":["tag","new"]}]}]}`)
+		defer server.Close()
+		operation := &dto.TriggersBulkOperation{
+			Selection: dto.TriggersBulkSelection{IDs: []string{"trigger-id"}},
+			Operation: dto.TriggersBulkAddTags,
+			Tags:      []string{"new"},
+		}
+		result, err := NewClient(server.URL, nil).ApplyTriggersBulkOperation(operation, true, "")
+		So(err, ShouldBeNil)
+		So(received.method, ShouldEqual, "POST")
+		So(received.path, ShouldEqual, "/trigger/bulk")
+		So(received.query, ShouldResemble, map[string][]string{"dry_run": {"true"}})
+		So(result.DryRun, ShouldBeTrue)
+		So(result.List, ShouldHaveLength, 1)
+		So(result.List[0].Changes[0].Field, ShouldEqual, "tags")
+	})
+}
@@ -1,7 +1,30 @@
 package api
 
+// Authentication methods of api requests
+const (
+	// AuthHeader trusts user login from x-webauth-user header set by reverse proxy, requests without header are anonymous
+	// It can't be combined with other methods
+	AuthHeader = "header"
+	// AuthToken checks personal api tokens from Authorization header
+	AuthToken = "token"
+	// AuthJWT validates JWT bearer tokens from Authorization header with keys from local JWKS file
+	AuthJWT = "jwt"
+)
+
 // Config config is api configuration variables
+// Admins are logins of users allowed to manage global settings like global maintenance windows
 type Config struct {
-	Enabled bool
-	Listen  string
+	Enabled     bool
+	Listen      string
+	AuthMethods []string
+	JWT         JWTConfig
+	Admins      []string
+}
+
+// JWTConfig is JWT bearer tokens validation settings
+type JWTConfig struct {
+	Issuer     string
+	Audience   string
+	KeysFile   string
+	LoginClaim string
 }
@@ -0,0 +1,82 @@
+package controller
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/satori/go.uuid"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+)
+
+// GetUserAPITokens gets user api tokens without secrets
+func GetUserAPITokens(database moira.Database, userLogin string) (*dto.APITokenList, *api.ErrorResponse) {
+	if userLogin == "" {
+		return nil, api.ErrorUnauthorized("Authentication required")
+	}
+	tokenIDs, err := database.GetUserAPITokenIDs(userLogin)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	tokens, err := database.GetAPITokens(tokenIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	tokenList := &dto.APITokenList{List: make([]*dto.APIToken, 0)}
+	for _, token := range tokens {
+		if token != nil {
+			tokenList.List = append(tokenList.List, &dto.APIToken{ID: token.ID, Name: token.Name, CreatedAt: token.CreatedAt})
+		}
+	}
+	return tokenList, nil
+}
+
+// CreateAPIToken creates new api token for user, token secret is returned only once
+func CreateAPIToken(database moira.Database, token *dto.APIToken, userLogin string) *api.ErrorResponse {
+	if userLogin == "" {
+		return api.ErrorUnauthorized("Authentication required")
+	}
+	secret, hash, err := auth.GenerateAPIToken()
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	apiToken := &moira.APIToken{
+		ID:        uuid.NewV4().String(),
+		Login:     userLogin,
+		Name:      token.Name,
+		Hash:      hash,
+		CreatedAt: time.Now().Unix(),
+	}
+	if err := database.SaveAPIToken(apiToken); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	token.ID = apiToken.ID
+	token.CreatedAt = apiToken.CreatedAt
+	token.Token = secret
+	return nil
+}
+
+// RemoveAPIToken deletes user api token
+func RemoveAPIToken(dataBase moira.Database, tokenID string, userLogin string) *api.ErrorResponse {
+	if userLogin == "" {
+		return api.ErrorUnauthorized("Authentication required")
+	}
+	token, err := dataBase.GetAPIToken(tokenID)
+	if err != nil {
+		if err == database.ErrNil {
+			return api.ErrorNotFound(fmt.Sprintf("API token with ID '%s' does not exists", tokenID))
+		}
+		return api.ErrorInternalServer(err)
+	}
+	if token.Login != userLogin {
+		return api.ErrorForbidden("You have not permissions")
+	}
+	if err := dataBase.RemoveAPIToken(tokenID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestGetUserAPITokens(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	login := "user"
+
+	Convey("Anonymous user, should return unauthorized", t, func() {
+		tokens, err := GetUserAPITokens(dataBase, "")
+		So(err, ShouldResemble, api.ErrorUnauthorized("Authentication required"))
+		So(tokens, ShouldBeNil)
+	})
+
+	Convey("Success get tokens without hashes", t, func() {
+		dataBase.EXPECT().GetUserAPITokenIDs(login).Return([]string{"1", "2"}, nil)
+		dataBase.EXPECT().GetAPITokens([]string{"1", "2"}).Return([]*moira.APIToken{
+			{ID: "1", Login: login, Name: "ci", Hash: "hash", CreatedAt: 100},
+			nil,
+		}, nil)
+		tokens, err := GetUserAPITokens(dataBase, login)
+		So(err, ShouldBeNil)
+		So(tokens, ShouldResemble, &dto.APITokenList{List: []*dto.APIToken{{ID: "1", Name: "ci", CreatedAt: 100}}})
+	})
+
+	Convey("Error get token ids", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get token ids")
+		dataBase.EXPECT().GetUserAPITokenIDs(login).Return(nil, expected)
+		tokens, err := GetUserAPITokens(dataBase, login)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(tokens, ShouldBeNil)
+	})
+}
+
+func TestCreateAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	login := "user"
+
+	Convey("Success create, should store only hash", t, func() {
+		var saved *moira.APIToken
+		dataBase.EXPECT().SaveAPIToken(gomock.Any()).Do(func(token *moira.APIToken) { saved = token }).Return(nil)
+		token := &dto.APIToken{Name: "ci"}
+		err := CreateAPIToken(dataBase, token, login)
+		So(err, ShouldBeNil)
+		So(token.Token, ShouldNotBeEmpty)
+		So(token.ID, ShouldEqual, saved.ID)
+		So(saved.Login, ShouldEqual, login)
+		So(saved.Name, ShouldEqual, "ci")
+		So(saved.Hash, ShouldEqual, auth.HashAPIToken(token.Token))
+	})
+
+	Convey("Anonymous user, should return unauthorized", t, func() {
+		err := CreateAPIToken(dataBase, &dto.APIToken{Name: "ci"}, "")
+		So(err, ShouldResemble, api.ErrorUnauthorized("Authentication required"))
+	})
+
+	Convey("Error save token", t, func() {
+		expected := fmt.Errorf("Oooops! Can not save token")
+		dataBase.EXPECT().SaveAPIToken(gomock.Any()).Return(expected)
+		err := CreateAPIToken(dataBase, &dto.APIToken{Name: "ci"}, login)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+	})
+}
+
+func TestRemoveAPIToken(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	login := "user"
+
+	Convey("Success remove", t, func() {
+		dataBase.EXPECT().GetAPIToken("1").Return(moira.APIToken{ID: "1", Login: login}, nil)
+		dataBase.EXPECT().RemoveAPIToken("1").Return(nil)
+		So(RemoveAPIToken(dataBase, "1", login), ShouldBeNil)
+	})
+
+	Convey("Token of other user, should return forbidden", t, func() {
+		dataBase.EXPECT().GetAPIToken("1").Return(moira.APIToken{ID: "1", Login: "other"}, nil)
+		So(RemoveAPIToken(dataBase, "1", login), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Token does not exist, should return not found", t, func() {
+		dataBase.EXPECT().GetAPIToken("1").Return(moira.APIToken{}, database.ErrNil)
+		So(RemoveAPIToken(dataBase, "1", login), ShouldResemble, api.ErrorNotFound("API token with ID '1' does not exists"))
+	})
+}
@@ -28,9 +28,17 @@ func GetAllContacts(database moira.Database) (*dto.ContactList, *api.ErrorRespon
 // CreateContact creates new notification contact for current user
 func CreateContact(dataBase moira.Database, contact *dto.Contact, userLogin string) *api.ErrorResponse {
 	contactData := moira.ContactData{
-		User:  userLogin,
-		Type:  contact.Type,
-		Value: contact.Value,
+		User:     userLogin,
+		Type:     contact.Type,
+		Value:    contact.Value,
+		TeamID:   contact.TeamID,
+		Fallback: contact.Fallback,
+	}
+	if err := CheckUserPermissionsForTeam(dataBase, contact.TeamID, userLogin, moira.TeamRoleEditor); err != nil {
+		return err
+	}
+	if err := checkFallbackContact(dataBase, contact.ID, contact.Fallback, userLogin, contact.TeamID); err != nil {
+		return err
 	}
 	if contact.ID == "" {
 		contactData.ID = uuid.NewV4().String()
@@ -53,6 +61,7 @@ func CreateContact(dataBase moira.Database, contact *dto.Contact, userLogin stri
 }
 
 // UpdateContact updates notification contact for current user
+// Team contact can be updated by team editors, but only contact owner can move it to other team
 func UpdateContact(dataBase moira.Database, contact *dto.Contact, contactID string, userLogin string) *api.ErrorResponse {
 	contactData, err := dataBase.GetContact(contactID)
 	if err != nil {
@@ -61,12 +70,27 @@ func UpdateContact(dataBase moira.Database, contact *dto.Contact, contactID stri
 		}
 		return api.ErrorInternalServer(err)
 	}
+	if contact.TeamID != contactData.TeamID {
+		if contactData.User != userLogin {
+			return api.ErrorForbidden("You have not permissions")
+		}
+		if err := CheckUserPermissionsForTeam(dataBase, contact.TeamID, userLogin, moira.TeamRoleEditor); err != nil {
+			return err
+		}
+	}
+	if err := checkFallbackContact(dataBase, contactID, contact.Fallback, userLogin, contact.TeamID); err != nil {
+		return err
+	}
 	contactData.Type = contact.Type
 	contactData.Value = contact.Value
+	contactData.TeamID = contact.TeamID
+	contactData.Fallback = contact.Fallback
 
 	if err := dataBase.SaveContact(&contactData); err != nil {
 		return api.ErrorInternalServer(err)
 	}
+	contact.User = contactData.User
+	contact.ID = contactData.ID
 	return nil
 }
 
@@ -126,6 +150,7 @@ func SendTestContactNotification(dataBase moira.Database, contactID string, user
 }
 
 // CheckUserPermissionsForContact checks contact for existence and permissions for given user
+// Contact shared with team can be modified by team editors
 func CheckUserPermissionsForContact(dataBase moira.Database, contactID string, userLogin string) *api.ErrorResponse {
 	contactData, err := dataBase.GetContact(contactID)
 	if err != nil {
@@ -134,9 +159,38 @@ func CheckUserPermissionsForContact(dataBase moira.Database, contactID string, u
 		}
 		return api.ErrorInternalServer(err)
 	}
-	if contactData.User != userLogin {
+	if contactData.User == userLogin {
+		return nil
+	}
+	if contactData.TeamID == "" {
 		return api.ErrorForbidden("You have not permissions")
 	}
+	return CheckUserPermissionsForTeam(dataBase, contactData.TeamID, userLogin, moira.TeamRoleEditor)
+}
+
+// checkFallbackContact checks that fallback contact exists, belongs to given user or team and differs from contact itself
+func checkFallbackContact(dataBase moira.Database, contactID string, fallbackID string, userLogin string, teamID string) *api.ErrorResponse {
+	if fallbackID == "" {
+		return nil
+	}
+	if fallbackID == contactID {
+		return api.ErrorInvalidRequest(fmt.Errorf("Contact can not be fallback for itself"))
+	}
+	return checkContactOwner(dataBase, "Fallback contact", fallbackID, userLogin, teamID)
+}
+
+// checkContactOwner checks that contact referred by name exists and belongs to given user or team
+func checkContactOwner(dataBase moira.Database, name string, contactID string, userLogin string, teamID string) *api.ErrorResponse {
+	contact, err := dataBase.GetContact(contactID)
+	if err != nil {
+		if err == database.ErrNil {
+			return api.ErrorInvalidRequest(fmt.Errorf("%s with ID '%s' does not exists", name, contactID))
+		}
+		return api.ErrorInternalServer(err)
+	}
+	if contact.User != userLogin && (teamID == "" || contact.TeamID != teamID) {
+		return api.ErrorInvalidRequest(fmt.Errorf("%s with ID '%s' does not exists", name, contactID))
+	}
 	return nil
 }
 
@@ -108,6 +108,56 @@ func TestCreateContact(t *testing.T) {
 		So(expected, ShouldResemble, api.ErrorInternalServer(err))
 	})
 
+	Convey("Success create contact with fallback", t, func() {
+		fallbackID := uuid.NewV4().String()
+		contact := &dto.Contact{
+			Value:    "some@mail.com",
+			Type:     "telegram",
+			Fallback: fallbackID,
+		}
+		dataBase.EXPECT().GetContact(fallbackID).Return(moira.ContactData{ID: fallbackID, User: userLogin}, nil)
+		dataBase.EXPECT().SaveContact(gomock.Any()).Return(nil)
+		err := CreateContact(dataBase, contact, userLogin)
+		So(err, ShouldBeNil)
+		So(contact.Fallback, ShouldEqual, fallbackID)
+	})
+
+	Convey("Fallback contact does not exists", t, func() {
+		fallbackID := uuid.NewV4().String()
+		contact := &dto.Contact{
+			Value:    "some@mail.com",
+			Type:     "telegram",
+			Fallback: fallbackID,
+		}
+		dataBase.EXPECT().GetContact(fallbackID).Return(moira.ContactData{}, database.ErrNil)
+		err := CreateContact(dataBase, contact, userLogin)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Fallback contact with ID '%s' does not exists", fallbackID)))
+	})
+
+	Convey("Fallback contact belongs to other user", t, func() {
+		fallbackID := uuid.NewV4().String()
+		contact := &dto.Contact{
+			Value:    "some@mail.com",
+			Type:     "telegram",
+			Fallback: fallbackID,
+		}
+		dataBase.EXPECT().GetContact(fallbackID).Return(moira.ContactData{ID: fallbackID, User: "other"}, nil)
+		err := CreateContact(dataBase, contact, userLogin)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Fallback contact with ID '%s' does not exists", fallbackID)))
+	})
+
+	Convey("Contact can not be fallback for itself", t, func() {
+		contactID := uuid.NewV4().String()
+		contact := &dto.Contact{
+			ID:       contactID,
+			Value:    "some@mail.com",
+			Type:     "telegram",
+			Fallback: contactID,
+		}
+		err := CreateContact(dataBase, contact, userLogin)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Contact can not be fallback for itself")))
+	})
+
 	Convey("Error save contact", t, func() {
 		contact := &dto.Contact{
 			Value: "some@mail.com",
@@ -125,6 +175,36 @@ func TestCreateContact(t *testing.T) {
 	})
 }
 
+func TestUpdateContact(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	id := uuid.NewV4().String()
+	teamContact := moira.ContactData{ID: id, Type: "mail", Value: "ops@example.com", User: "owner", TeamID: testTeam.ID}
+
+	Convey("Team editor updates contact of other user", t, func() {
+		contact := &dto.Contact{Type: "mail", Value: "dev@example.com", TeamID: testTeam.ID}
+		dataBase.EXPECT().GetContact(id).Return(teamContact, nil)
+		dataBase.EXPECT().SaveContact(&moira.ContactData{ID: id, Type: "mail", Value: "dev@example.com", User: "owner", TeamID: testTeam.ID}).Return(nil)
+		So(UpdateContact(dataBase, contact, id, "editor"), ShouldBeNil)
+		So(contact.User, ShouldEqual, "owner")
+		So(contact.ID, ShouldEqual, id)
+	})
+
+	Convey("Team editor can not move contact of other user to other team", t, func() {
+		contact := &dto.Contact{Type: "mail", Value: "ops@example.com"}
+		dataBase.EXPECT().GetContact(id).Return(teamContact, nil)
+		So(UpdateContact(dataBase, contact, id, "editor"), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Owner moves contact to other team", t, func() {
+		contact := &dto.Contact{Type: "mail", Value: "ops@example.com"}
+		dataBase.EXPECT().GetContact(id).Return(teamContact, nil)
+		dataBase.EXPECT().SaveContact(&moira.ContactData{ID: id, Type: "mail", Value: "ops@example.com", User: "owner"}).Return(nil)
+		So(UpdateContact(dataBase, contact, id, "owner"), ShouldBeNil)
+	})
+}
+
 func TestRemoveContact(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
@@ -256,4 +336,15 @@ func TestCheckUserPermissionsForContact(t *testing.T) {
 		expected := CheckUserPermissionsForContact(dataBase, id, userLogin)
 		So(expected, ShouldResemble, api.ErrorInternalServer(err))
 	})
+
+	Convey("Team contact", t, func() {
+		team := moira.Team{ID: "team", Members: map[string]string{userLogin: moira.TeamRoleEditor, "viewer": moira.TeamRoleViewer}}
+		dataBase.EXPECT().GetContact(id).Return(moira.ContactData{User: "diffUser", TeamID: team.ID}, nil)
+		dataBase.EXPECT().GetTeam(team.ID).Return(team, nil)
+		So(CheckUserPermissionsForContact(dataBase, id, userLogin), ShouldBeNil)
+
+		dataBase.EXPECT().GetContact(id).Return(moira.ContactData{User: "diffUser", TeamID: team.ID}, nil)
+		dataBase.EXPECT().GetTeam(team.ID).Return(team, nil)
+		So(CheckUserPermissionsForContact(dataBase, id, "viewer"), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
 }
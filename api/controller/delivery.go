@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// GetContactDeliveries gets notification delivery attempts to contact from current page and all stored attempts count
+func GetContactDeliveries(database moira.Database, contactID string, page int64, size int64) (*dto.DeliveriesList, *api.ErrorResponse) {
+	deliveries, total, err := database.GetContactDeliveries(contactID, page*size, size)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return createDeliveriesList(deliveries, total, page, size), nil
+}
+
+// GetTriggerDeliveries gets trigger notification delivery attempts from current page and all stored attempts count
+func GetTriggerDeliveries(database moira.Database, triggerID string, page int64, size int64) (*dto.DeliveriesList, *api.ErrorResponse) {
+	deliveries, total, err := database.GetTriggerDeliveries(triggerID, page*size, size)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return createDeliveriesList(deliveries, total, page, size), nil
+}
+
+func createDeliveriesList(deliveries []*moira.DeliveryData, total int64, page int64, size int64) *dto.DeliveriesList {
+	deliveriesList := &dto.DeliveriesList{
+		Page:  page,
+		Size:  size,
+		Total: total,
+		List:  make([]moira.DeliveryData, 0),
+	}
+	for _, delivery := range deliveries {
+		if delivery != nil {
+			deliveriesList.List = append(deliveriesList.List, *delivery)
+		}
+	}
+	return deliveriesList
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/mock/moira-alert"
+	"github.com/satori/go.uuid"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestGetContactDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	defer mockCtrl.Finish()
+	contactID := uuid.NewV4().String()
+	var page int64 = 1
+	var size int64 = 100
+
+	Convey("Test has deliveries", t, func() {
+		var total int64 = 102
+		deliveries := []*moira.DeliveryData{{Result: moira.DeliveryOK, Attempt: 2}, nil, {Result: moira.DeliveryFailed, Error: "Timeout", Attempt: 1}}
+		dataBase.EXPECT().GetContactDeliveries(contactID, page*size, size).Return(deliveries, total, nil)
+		list, err := GetContactDeliveries(dataBase, contactID, page, size)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.DeliveriesList{
+			List:  []moira.DeliveryData{*deliveries[0], *deliveries[2]},
+			Total: total,
+			Size:  size,
+			Page:  page,
+		})
+	})
+
+	Convey("Test no deliveries", t, func() {
+		dataBase.EXPECT().GetContactDeliveries(contactID, page*size, size).Return(make([]*moira.DeliveryData, 0), int64(0), nil)
+		list, err := GetContactDeliveries(dataBase, contactID, page, size)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.DeliveriesList{
+			List: make([]moira.DeliveryData, 0),
+			Size: size,
+			Page: page,
+		})
+	})
+
+	Convey("Test error", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get deliveries")
+		dataBase.EXPECT().GetContactDeliveries(contactID, page*size, size).Return(nil, int64(0), expected)
+		list, err := GetContactDeliveries(dataBase, contactID, page, size)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+}
+
+func TestGetTriggerDeliveries(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	defer mockCtrl.Finish()
+	triggerID := uuid.NewV4().String()
+	var page int64
+	var size int64 = 10
+
+	Convey("Test has deliveries", t, func() {
+		deliveries := []*moira.DeliveryData{{TriggerID: triggerID, Result: moira.DeliveryOK, Attempt: 1}}
+		dataBase.EXPECT().GetTriggerDeliveries(triggerID, page*size, size).Return(deliveries, int64(1), nil)
+		list, err := GetTriggerDeliveries(dataBase, triggerID, page, size)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.DeliveriesList{
+			List:  []moira.DeliveryData{*deliveries[0]},
+			Total: 1,
+			Size:  size,
+			Page:  page,
+		})
+	})
+
+	Convey("Test error", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get deliveries")
+		dataBase.EXPECT().GetTriggerDeliveries(triggerID, page*size, size).Return(nil, int64(0), expected)
+		list, err := GetTriggerDeliveries(dataBase, triggerID, page, size)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+}
@@ -1,11 +1,34 @@
 package controller
 
 import (
+	"fmt"
+	"sort"
+
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/filter"
 )
 
+// EventsFilter selects events of all triggers in time range, empty fields select events with any value
+// Tags selects events of triggers which have all given tags, Metric is graphite glob of event metric
+type EventsFilter struct {
+	From     int64
+	To       int64
+	Tags     []string
+	State    string
+	OldState string
+	Metric   string
+}
+
+// maxFilteredEventsRange is max time range in seconds of events which are filtered or counted in api process
+const maxFilteredEventsRange = 7 * 24 * 3600
+
+// isPagedByDatabase checks that filter selects events only by time range, so page of events can be got from database directly
+func (eventsFilter EventsFilter) isPagedByDatabase() bool {
+	return len(eventsFilter.Tags) == 0 && eventsFilter.State == "" && eventsFilter.OldState == "" && eventsFilter.Metric == ""
+}
+
 // GetTriggerEvents gets trigger event from current page and all trigger event count
 func GetTriggerEvents(database moira.Database, triggerID string, page int64, size int64) (*dto.EventsList, *api.ErrorResponse) {
 	events, err := database.GetNotificationEvents(triggerID, page*size, size-1)
@@ -27,3 +50,174 @@ func GetTriggerEvents(database moira.Database, triggerID string, page int64, siz
 	}
 	return eventsList, nil
 }
+
+// GetEvents gets events of all triggers matched by filter from current page and count of all matched events, newest events go first
+// Events matched by time range only are paged by database, other filters are applied to limited time range
+func GetEvents(database moira.Database, eventsFilter EventsFilter, page int64, size int64) (*dto.EventsList, *api.ErrorResponse) {
+	if eventsFilter.isPagedByDatabase() {
+		return getEventsPage(database, eventsFilter, page, size)
+	}
+	events, errorResponse := getFilteredEvents(database, eventsFilter)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	eventsList := &dto.EventsList{
+		Size:  size,
+		Page:  page,
+		Total: int64(len(events)),
+		List:  make([]moira.NotificationEvent, 0),
+	}
+	if page < 0 || size < 0 {
+		return eventsList, nil
+	}
+	for i := page * size; i < (page+1)*size && i < int64(len(events)); i++ {
+		eventsList.List = append(eventsList.List, *events[i])
+	}
+	return eventsList, nil
+}
+
+// getEventsPage gets page of events in filter time range and count of all events in range from database
+func getEventsPage(database moira.Database, eventsFilter EventsFilter, page int64, size int64) (*dto.EventsList, *api.ErrorResponse) {
+	total, err := database.GetNotificationEventCountByTime(eventsFilter.From, eventsFilter.To)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	eventsList := &dto.EventsList{
+		Size:  size,
+		Page:  page,
+		Total: total,
+		List:  make([]moira.NotificationEvent, 0),
+	}
+	if page < 0 || size <= 0 {
+		return eventsList, nil
+	}
+	events, err := database.GetNotificationEventsByTime(eventsFilter.From, eventsFilter.To, page*size, size)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	for _, event := range events {
+		if event != nil {
+			eventsList.List = append(eventsList.List, *event)
+		}
+	}
+	return eventsList, nil
+}
+
+// GetEventCounts counts events of all triggers matched by filter per trigger and time interval
+func GetEventCounts(database moira.Database, eventsFilter EventsFilter, interval int64) (*dto.EventCounts, *api.ErrorResponse) {
+	if interval <= 0 {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("Interval must be positive"))
+	}
+	events, errorResponse := getFilteredEvents(database, eventsFilter)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	eventCounts := &dto.EventCounts{
+		From:     eventsFilter.From,
+		To:       eventsFilter.To,
+		Interval: interval,
+		Total:    int64(len(events)),
+		List:     make([]dto.EventCount, 0),
+	}
+	counts := make(map[dto.EventCount]int64)
+	for _, event := range events {
+		key := dto.EventCount{TriggerID: event.TriggerID, Timestamp: event.Timestamp - event.Timestamp%interval}
+		counts[key]++
+	}
+	for key, count := range counts {
+		key.Count = count
+		eventCounts.List = append(eventCounts.List, key)
+	}
+	sort.Slice(eventCounts.List, func(i, j int) bool {
+		if eventCounts.List[i].Timestamp != eventCounts.List[j].Timestamp {
+			return eventCounts.List[i].Timestamp < eventCounts.List[j].Timestamp
+		}
+		return eventCounts.List[i].TriggerID < eventCounts.List[j].TriggerID
+	})
+	return eventCounts, nil
+}
+
+// getFilteredEvents gets events of all triggers in filter time range and drops events not matched by other filter fields
+// Time range is limited by maxFilteredEventsRange, because all events of range are decoded
+func getFilteredEvents(database moira.Database, eventsFilter EventsFilter) ([]*moira.NotificationEvent, *api.ErrorResponse) {
+	if eventsFilter.To-eventsFilter.From > maxFilteredEventsRange {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("Time range of filtered events must not be longer than %d seconds", maxFilteredEventsRange))
+	}
+	var metricGlob filter.MetricGlob
+	if eventsFilter.Metric != "" {
+		var err error
+		if metricGlob, err = filter.CompileMetricGlob(eventsFilter.Metric); err != nil {
+			return nil, api.ErrorInvalidRequest(fmt.Errorf("Invalid metric glob: %s", err.Error()))
+		}
+	}
+	events, err := database.GetNotificationEventsByTime(eventsFilter.From, eventsFilter.To, 0, -1)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	triggersTags, err := getEventsTriggersTags(database, events, eventsFilter.Tags)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	filtered := make([]*moira.NotificationEvent, 0, len(events))
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		if eventsFilter.State != "" && event.State != eventsFilter.State {
+			continue
+		}
+		if eventsFilter.OldState != "" && event.OldState != eventsFilter.OldState {
+			continue
+		}
+		if metricGlob != nil && !metricGlob.Match(event.Metric) {
+			continue
+		}
+		if triggersTags != nil && !hasAllTags(triggersTags[event.TriggerID], eventsFilter.Tags) {
+			continue
+		}
+		filtered = append(filtered, event)
+	}
+	return filtered, nil
+}
+
+// getEventsTriggersTags gets tags of triggers of given events, returns nil if there are no tags to filter by
+func getEventsTriggersTags(database moira.Database, events []*moira.NotificationEvent, filterTags []string) (map[string]map[string]bool, error) {
+	if len(filterTags) == 0 {
+		return nil, nil
+	}
+	triggerIDs := make([]string, 0)
+	triggersTags := make(map[string]map[string]bool)
+	for _, event := range events {
+		if event == nil {
+			continue
+		}
+		if _, ok := triggersTags[event.TriggerID]; !ok {
+			triggersTags[event.TriggerID] = nil
+			triggerIDs = append(triggerIDs, event.TriggerID)
+		}
+	}
+	triggers, err := database.GetTriggers(triggerIDs)
+	if err != nil {
+		return nil, err
+	}
+	for i, trigger := range triggers {
+		if trigger == nil {
+			continue
+		}
+		tags := make(map[string]bool, len(trigger.Tags))
+		for _, tag := range trigger.Tags {
+			tags[tag] = true
+		}
+		triggersTags[triggerIDs[i]] = tags
+	}
+	return triggersTags, nil
+}
+
+func hasAllTags(tags map[string]bool, filterTags []string) bool {
+	for _, tag := range filterTags {
+		if !tags[tag] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/database"
+)
+
+// streamTriggerTagsTTL is time after which cached trigger tags are reloaded from database
+const streamTriggerTagsTTL = time.Minute
+
+// StreamEventsFilter selects events of given triggers which have all given tags, empty filter selects all events
+type StreamEventsFilter struct {
+	TriggerIDs []string
+	Tags       []string
+}
+
+// streamClientBufferSize is number of events buffered for every stream client, events of slower clients are dropped
+const streamClientBufferSize = 100
+
+// StreamEventsHub shares one database subscription between all stream clients
+// Subscription is created with first client and closed after last client leaves
+type StreamEventsHub struct {
+	dataBase     moira.Database
+	logger       moira.Logger
+	mutex        sync.Mutex
+	subscription *streamSubscription
+}
+
+type streamSubscription struct {
+	tomb    tomb.Tomb
+	clients map[chan *moira.StreamEvent]bool
+}
+
+// NewStreamEventsHub creates hub of stream events subscriptions
+func NewStreamEventsHub(dataBase moira.Database, logger moira.Logger) *StreamEventsHub {
+	return &StreamEventsHub{dataBase: dataBase, logger: logger}
+}
+
+// subscribe adds client channel to shared subscription, subscription is created if hub has no clients
+func (hub *StreamEventsHub) subscribe() (chan *moira.StreamEvent, *streamSubscription, error) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	if hub.subscription == nil {
+		subscription := &streamSubscription{clients: make(map[chan *moira.StreamEvent]bool)}
+		source, err := hub.dataBase.SubscribeStreamEvents(&subscription.tomb)
+		if err != nil {
+			return nil, nil, err
+		}
+		hub.subscription = subscription
+		go hub.broadcast(subscription, source)
+	}
+	client := make(chan *moira.StreamEvent, streamClientBufferSize)
+	hub.subscription.clients[client] = true
+	return client, hub.subscription, nil
+}
+
+// unsubscribe removes client channel, subscription without clients is closed
+func (hub *StreamEventsHub) unsubscribe(subscription *streamSubscription, client chan *moira.StreamEvent) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	delete(subscription.clients, client)
+	if len(subscription.clients) == 0 && hub.subscription == subscription {
+		hub.subscription = nil
+		subscription.tomb.Kill(nil)
+	}
+}
+
+// broadcast sends source events to clients without waiting for them, client channels are closed after source is closed
+func (hub *StreamEventsHub) broadcast(subscription *streamSubscription, source <-chan *moira.StreamEvent) {
+	for event := range source {
+		hub.mutex.Lock()
+		for client := range subscription.clients {
+			select {
+			case client <- event:
+			default:
+				hub.logger.Warningf("Stream client is too slow, event of trigger %s is dropped", event.TriggerID)
+			}
+		}
+		hub.mutex.Unlock()
+	}
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	if hub.subscription == subscription {
+		hub.subscription = nil
+	}
+	for client := range subscription.clients {
+		close(client)
+		delete(subscription.clients, client)
+	}
+}
+
+// SubscribeStreamEvents subscribes to trigger check results and new notification events matched by filter
+// Returned channel is closed after tomb dies
+func SubscribeStreamEvents(hub *StreamEventsHub, logger moira.Logger, tomb *tomb.Tomb, filter StreamEventsFilter) (<-chan *moira.StreamEvent, *api.ErrorResponse) {
+	client, subscription, err := hub.subscribe()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	matcher := newStreamEventsMatcher(hub.dataBase, filter)
+	events := make(chan *moira.StreamEvent)
+	go func() {
+		defer close(events)
+		defer hub.unsubscribe(subscription, client)
+		for {
+			var event *moira.StreamEvent
+			var ok bool
+			select {
+			case event, ok = <-client:
+			case <-tomb.Dying():
+				return
+			}
+			if !ok {
+				return
+			}
+			matched, err := matcher.match(event)
+			if err != nil {
+				logger.Warningf("Failed to match stream event of trigger %s: %s", event.TriggerID, err.Error())
+				continue
+			}
+			if !matched {
+				continue
+			}
+			select {
+			case events <- event:
+			case <-tomb.Dying():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+type streamEventsMatcher struct {
+	dataBase    moira.Database
+	triggerIDs  map[string]bool
+	tags        []string
+	triggerTags map[string]*streamTriggerTags
+}
+
+type streamTriggerTags struct {
+	tags     map[string]bool
+	loadedAt time.Time
+}
+
+func newStreamEventsMatcher(dataBase moira.Database, filter StreamEventsFilter) *streamEventsMatcher {
+	matcher := &streamEventsMatcher{
+		dataBase:    dataBase,
+		triggerIDs:  make(map[string]bool, len(filter.TriggerIDs)),
+		tags:        filter.Tags,
+		triggerTags: make(map[string]*streamTriggerTags),
+	}
+	for _, triggerID := range filter.TriggerIDs {
+		matcher.triggerIDs[triggerID] = true
+	}
+	return matcher
+}
+
+func (matcher *streamEventsMatcher) match(event *moira.StreamEvent) (bool, error) {
+	if len(matcher.triggerIDs) > 0 && !matcher.triggerIDs[event.TriggerID] {
+		return false, nil
+	}
+	if len(matcher.tags) == 0 {
+		return true, nil
+	}
+	if event.TriggerID == "" {
+		return false, nil
+	}
+	triggerTags, err := matcher.getTriggerTags(event.TriggerID)
+	if err != nil {
+		return false, err
+	}
+	for _, tag := range matcher.tags {
+		if !triggerTags[tag] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// getTriggerTags returns cached trigger tags, tags of removed trigger are empty
+func (matcher *streamEventsMatcher) getTriggerTags(triggerID string) (map[string]bool, error) {
+	cached, ok := matcher.triggerTags[triggerID]
+	if ok && time.Since(cached.loadedAt) < streamTriggerTagsTTL {
+		return cached.tags, nil
+	}
+	trigger, err := matcher.dataBase.GetTrigger(triggerID)
+	if err != nil && err != database.ErrNil {
+		return nil, err
+	}
+	cached = &streamTriggerTags{tags: make(map[string]bool, len(trigger.Tags)), loadedAt: time.Now()}
+	for _, tag := range trigger.Tags {
+		cached.tags[tag] = true
+	}
+	matcher.triggerTags[triggerID] = cached
+	return cached.tags, nil
+}
@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestSubscribeStreamEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("api")
+
+	stateEvent := func(triggerID string) *moira.StreamEvent {
+		return &moira.StreamEvent{Type: moira.StreamEventTriggerState, TriggerID: triggerID, State: &moira.CheckData{State: "OK"}}
+	}
+
+	// subscribe returns source channel of database subscription, closed channel is sent to returned channel after subscription tomb dies
+	subscribe := func() (chan *moira.StreamEvent, chan bool) {
+		source := make(chan *moira.StreamEvent, 10)
+		closed := make(chan bool, 1)
+		dataBase.EXPECT().SubscribeStreamEvents(gomock.Any()).Do(func(subscriptionTomb *tomb.Tomb) {
+			go func() {
+				<-subscriptionTomb.Dying()
+				close(source)
+				closed <- true
+			}()
+		}).Return((<-chan *moira.StreamEvent)(source), nil)
+		return source, closed
+	}
+
+	Convey("Subscribe error", t, func() {
+		var streamTomb tomb.Tomb
+		hub := NewStreamEventsHub(dataBase, logger)
+		expected := fmt.Errorf("Oooops! Can not subscribe")
+		dataBase.EXPECT().SubscribeStreamEvents(gomock.Any()).Return(nil, expected)
+		events, err := SubscribeStreamEvents(hub, logger, &streamTomb, StreamEventsFilter{})
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(events, ShouldBeNil)
+	})
+
+	Convey("Empty filter selects all events", t, func() {
+		var streamTomb tomb.Tomb
+		hub := NewStreamEventsHub(dataBase, logger)
+		source, _ := subscribe()
+		events, err := SubscribeStreamEvents(hub, logger, &streamTomb, StreamEventsFilter{})
+		So(err, ShouldBeNil)
+
+		notificationEvent := &moira.StreamEvent{Type: moira.StreamEventNotification, Event: &moira.NotificationEvent{State: "OK"}}
+		source <- stateEvent("trigger1")
+		source <- notificationEvent
+		So(<-events, ShouldResemble, stateEvent("trigger1"))
+		So(<-events, ShouldResemble, notificationEvent)
+
+		streamTomb.Kill(nil)
+		_, ok := <-events
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Filter by trigger ids", t, func() {
+		var streamTomb tomb.Tomb
+		hub := NewStreamEventsHub(dataBase, logger)
+		source, _ := subscribe()
+		events, err := SubscribeStreamEvents(hub, logger, &streamTomb, StreamEventsFilter{TriggerIDs: []string{"trigger2"}})
+		So(err, ShouldBeNil)
+
+		source <- stateEvent("trigger1")
+		source <- stateEvent("trigger2")
+		So(<-events, ShouldResemble, stateEvent("trigger2"))
+		streamTomb.Kill(nil)
+		_, ok := <-events
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Filter by tags", t, func() {
+		var streamTomb tomb.Tomb
+		hub := NewStreamEventsHub(dataBase, logger)
+		source, _ := subscribe()
+		events, err := SubscribeStreamEvents(hub, logger, &streamTomb, StreamEventsFilter{Tags: []string{"a", "b"}})
+		So(err, ShouldBeNil)
+
+		dataBase.EXPECT().GetTrigger("trigger1").Return(moira.Trigger{Tags: []string{"a"}}, nil)
+		dataBase.EXPECT().GetTrigger("trigger2").Return(moira.Trigger{Tags: []string{"b", "c", "a"}}, nil)
+		dataBase.EXPECT().GetTrigger("trigger3").Return(moira.Trigger{}, database.ErrNil)
+		dataBase.EXPECT().GetTrigger("trigger4").Return(moira.Trigger{}, fmt.Errorf("Oooops! Can not get trigger"))
+
+		source <- stateEvent("trigger1")
+		source <- stateEvent("trigger3")
+		source <- stateEvent("trigger4")
+		source <- &moira.StreamEvent{Type: moira.StreamEventNotification, Event: &moira.NotificationEvent{State: "OK"}}
+		source <- stateEvent("trigger2")
+		source <- stateEvent("trigger2")
+		So(<-events, ShouldResemble, stateEvent("trigger2"))
+		So(<-events, ShouldResemble, stateEvent("trigger2"))
+
+		streamTomb.Kill(nil)
+		_, ok := <-events
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Events channel is closed after tomb dies with pending events", t, func() {
+		var streamTomb tomb.Tomb
+		hub := NewStreamEventsHub(dataBase, logger)
+		source, _ := subscribe()
+		events, err := SubscribeStreamEvents(hub, logger, &streamTomb, StreamEventsFilter{})
+		So(err, ShouldBeNil)
+
+		source <- stateEvent("trigger1")
+		source <- stateEvent("trigger2")
+		streamTomb.Kill(nil)
+		count := 0
+		for range events {
+			count++
+		}
+		So(count, ShouldBeLessThanOrEqualTo, 2)
+	})
+	Convey("Clients share one subscription which is closed after last client leaves", t, func() {
+		var streamTomb1, streamTomb2 tomb.Tomb
+		hub := NewStreamEventsHub(dataBase, logger)
+		source, closed := subscribe()
+		events1, err := SubscribeStreamEvents(hub, logger, &streamTomb1, StreamEventsFilter{})
+		So(err, ShouldBeNil)
+		events2, err := SubscribeStreamEvents(hub, logger, &streamTomb2, StreamEventsFilter{TriggerIDs: []string{"trigger2"}})
+		So(err, ShouldBeNil)
+
+		source <- stateEvent("trigger1")
+		source <- stateEvent("trigger2")
+		So(<-events1, ShouldResemble, stateEvent("trigger1"))
+		So(<-events1, ShouldResemble, stateEvent("trigger2"))
+		So(<-events2, ShouldResemble, stateEvent("trigger2"))
+
+		streamTomb1.Kill(nil)
+		for range events1 {
+		}
+		source <- stateEvent("trigger2")
+		So(<-events2, ShouldResemble, stateEvent("trigger2"))
+		So(closed, ShouldBeEmpty)
+
+		streamTomb2.Kill(nil)
+		for range events2 {
+		}
+		So(<-closed, ShouldBeTrue)
+	})
+
+	Convey("Slow client does not block other clients", t, func() {
+		var slowTomb, streamTomb tomb.Tomb
+		hub := NewStreamEventsHub(dataBase, logger)
+		source, closed := subscribe()
+		_, err := SubscribeStreamEvents(hub, logger, &slowTomb, StreamEventsFilter{})
+		So(err, ShouldBeNil)
+		events, err := SubscribeStreamEvents(hub, logger, &streamTomb, StreamEventsFilter{})
+		So(err, ShouldBeNil)
+
+		for i := 0; i < streamClientBufferSize*3; i++ {
+			source <- stateEvent("trigger1")
+			So(<-events, ShouldResemble, stateEvent("trigger1"))
+		}
+
+		slowTomb.Kill(nil)
+		streamTomb.Kill(nil)
+		So(<-closed, ShouldBeTrue)
+	})
+}
@@ -56,3 +56,106 @@ func TestGetEvents(t *testing.T) {
 		So(list, ShouldBeNil)
 	})
 }
+
+func TestGetAllTriggersEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	defer mockCtrl.Finish()
+
+	events := []*moira.NotificationEvent{
+		{Timestamp: 7300, TriggerID: "trigger1", Metric: "servers.web1.cpu", State: "ERROR", OldState: "OK"},
+		{Timestamp: 7200, TriggerID: "trigger2", Metric: "servers.db1.cpu", State: "ERROR", OldState: "WARN"},
+		{Timestamp: 3700, TriggerID: "trigger1", Metric: "servers.web2.cpu", State: "OK", OldState: "ERROR"},
+		{Timestamp: 3600, TriggerID: "trigger1", Metric: "servers.web1.cpu", State: "ERROR", OldState: "OK"},
+	}
+
+	Convey("Events are filtered by state, old state and metric glob", t, func() {
+		filter := EventsFilter{From: 0, To: 10000, State: "ERROR", OldState: "OK", Metric: "servers.web*.cpu"}
+		dataBase.EXPECT().GetNotificationEventsByTime(int64(0), int64(10000), int64(0), int64(-1)).Return(events, nil)
+		list, err := GetEvents(dataBase, filter, 0, 10)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.EventsList{Page: 0, Size: 10, Total: 2, List: []moira.NotificationEvent{*events[0], *events[3]}})
+	})
+
+	Convey("Events are filtered by trigger tags", t, func() {
+		filter := EventsFilter{From: 0, To: 10000, Tags: []string{"dc-eu"}}
+		dataBase.EXPECT().GetNotificationEventsByTime(int64(0), int64(10000), int64(0), int64(-1)).Return(events, nil)
+		dataBase.EXPECT().GetTriggers([]string{"trigger1", "trigger2"}).Return([]*moira.Trigger{{Tags: []string{"web"}}, {Tags: []string{"db", "dc-eu"}}}, nil)
+		list, err := GetEvents(dataBase, filter, 0, 10)
+		So(err, ShouldBeNil)
+		So(list.List, ShouldResemble, []moira.NotificationEvent{*events[1]})
+	})
+
+	Convey("Events matched by time range only are paginated by database", t, func() {
+		dataBase.EXPECT().GetNotificationEventCountByTime(int64(0), int64(10000)).Return(int64(4), nil)
+		dataBase.EXPECT().GetNotificationEventsByTime(int64(0), int64(10000), int64(3), int64(3)).Return(events[3:], nil)
+		list, err := GetEvents(dataBase, EventsFilter{From: 0, To: 10000}, 1, 3)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.EventsList{Page: 1, Size: 3, Total: 4, List: []moira.NotificationEvent{*events[3]}})
+	})
+
+	Convey("Filtered events are paginated", t, func() {
+		filter := EventsFilter{From: 0, To: 10000, State: "ERROR"}
+		dataBase.EXPECT().GetNotificationEventsByTime(int64(0), int64(10000), int64(0), int64(-1)).Return(events, nil)
+		list, err := GetEvents(dataBase, filter, 1, 2)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.EventsList{Page: 1, Size: 2, Total: 3, List: []moira.NotificationEvent{*events[3]}})
+	})
+
+	Convey("Time range of filtered events is limited", t, func() {
+		filter := EventsFilter{From: 0, To: maxFilteredEventsRange + 1, State: "ERROR"}
+		expected := api.ErrorInvalidRequest(fmt.Errorf("Time range of filtered events must not be longer than %d seconds", maxFilteredEventsRange))
+		list, err := GetEvents(dataBase, filter, 0, 10)
+		So(err, ShouldResemble, expected)
+		So(list, ShouldBeNil)
+		counts, err := GetEventCounts(dataBase, EventsFilter{From: 0, To: maxFilteredEventsRange + 1}, 3600)
+		So(err, ShouldResemble, expected)
+		So(counts, ShouldBeNil)
+	})
+
+	Convey("Invalid metric glob", t, func() {
+		list, err := GetEvents(dataBase, EventsFilter{Metric: "servers.{web"}, 0, 10)
+		So(err, ShouldNotBeNil)
+		So(err.HTTPStatusCode, ShouldEqual, 400)
+		So(list, ShouldBeNil)
+	})
+
+	Convey("Error get events", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get events")
+		dataBase.EXPECT().GetNotificationEventCountByTime(int64(0), int64(10000)).Return(int64(4), nil)
+		dataBase.EXPECT().GetNotificationEventsByTime(int64(0), int64(10000), int64(0), int64(10)).Return(nil, expected)
+		list, err := GetEvents(dataBase, EventsFilter{From: 0, To: 10000}, 0, 10)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+
+		dataBase.EXPECT().GetNotificationEventsByTime(int64(0), int64(10000), int64(0), int64(-1)).Return(nil, expected)
+		list, err = GetEvents(dataBase, EventsFilter{From: 0, To: 10000, State: "ERROR"}, 0, 10)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+
+	Convey("Error count events", t, func() {
+		expected := fmt.Errorf("Oooops! Can not count events")
+		dataBase.EXPECT().GetNotificationEventCountByTime(int64(0), int64(10000)).Return(int64(0), expected)
+		list, err := GetEvents(dataBase, EventsFilter{From: 0, To: 10000}, 0, 10)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+
+	Convey("Events are counted per trigger and interval", t, func() {
+		dataBase.EXPECT().GetNotificationEventsByTime(int64(0), int64(10000), int64(0), int64(-1)).Return(events, nil)
+		counts, err := GetEventCounts(dataBase, EventsFilter{From: 0, To: 10000}, 3600)
+		So(err, ShouldBeNil)
+		So(counts, ShouldResemble, &dto.EventCounts{From: 0, To: 10000, Interval: 3600, Total: 4, List: []dto.EventCount{
+			{TriggerID: "trigger1", Timestamp: 3600, Count: 2},
+			{TriggerID: "trigger1", Timestamp: 7200, Count: 1},
+			{TriggerID: "trigger2", Timestamp: 7200, Count: 1},
+		}})
+	})
+
+	Convey("Interval must be positive", t, func() {
+		counts, err := GetEventCounts(dataBase, EventsFilter{From: 0, To: 10000}, 0)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Interval must be positive")))
+		So(counts, ShouldBeNil)
+	})
+}
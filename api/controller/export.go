@@ -0,0 +1,40 @@
+package controller
+
+import (
+	"sort"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// ExportTriggers gets definitions of all triggers sorted by id, contacts and subscriptions of given user are added if login is not empty
+func ExportTriggers(dataBase moira.Database, userLogin string) (*dto.Export, *api.ErrorResponse) {
+	triggerIDs, err := dataBase.GetTriggerIDs()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	triggers, err := dataBase.GetTriggers(triggerIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	export := &dto.Export{Triggers: make([]dto.TriggerModel, 0, len(triggers))}
+	for _, trigger := range triggers {
+		if trigger != nil {
+			export.Triggers = append(export.Triggers, dto.CreateTriggerModel(trigger))
+		}
+	}
+	sort.Slice(export.Triggers, func(i, j int) bool {
+		return export.Triggers[i].ID < export.Triggers[j].ID
+	})
+	if userLogin == "" {
+		return export, nil
+	}
+	userSettings, errorResponse := GetUserSettings(dataBase, userLogin)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	export.Contacts = userSettings.Contacts
+	export.Subscriptions = userSettings.Subscriptions
+	return export, nil
+}
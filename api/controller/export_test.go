@@ -0,0 +1,66 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestExportTriggers(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	warnValue, errorValue := float64(10), float64(20)
+	trigger1 := moira.Trigger{ID: "b", Name: "cpu", Targets: []string{"cpu"}, WarnValue: &warnValue, ErrorValue: &errorValue, Tags: []string{"prod"}, Patterns: []string{"cpu"}, TTL: 600}
+	trigger2 := moira.Trigger{ID: "a", Name: "disk", Targets: []string{"disk"}, WarnValue: &warnValue, ErrorValue: &errorValue, Tags: []string{"prod"}, Patterns: []string{"disk"}}
+	contact := moira.ContactData{ID: "contact", Type: "mail", Value: "user@example.com", User: "user"}
+
+	Convey("Export without user should contain only triggers sorted by id", t, func() {
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{"b", "a", "removed"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"b", "a", "removed"}).Return([]*moira.Trigger{&trigger1, &trigger2, nil}, nil)
+		exported, err := ExportTriggers(dataBase, "")
+		So(err, ShouldBeNil)
+		So(exported, ShouldResemble, &dto.Export{Triggers: []dto.TriggerModel{dto.CreateTriggerModel(&trigger2), dto.CreateTriggerModel(&trigger1)}})
+
+		Convey("Export should be decoded back from yaml and json", func() {
+			for _, format := range []string{dto.ExportYAML, dto.ExportJSON} {
+				data, err := exported.Encode(format)
+				So(err, ShouldBeNil)
+				decoded, err := dto.DecodeExport(data, format)
+				So(err, ShouldBeNil)
+				So(decoded, ShouldResemble, exported)
+			}
+			_, err := exported.Encode("xml")
+			So(err, ShouldResemble, fmt.Errorf("Unknown export format 'xml'"))
+		})
+	})
+
+	Convey("Export with user should contain user contacts and subscriptions", t, func() {
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{}, nil)
+		dataBase.EXPECT().GetTriggers([]string{}).Return([]*moira.Trigger{}, nil)
+		dataBase.EXPECT().GetUserSubscriptionIDs("user").Return([]string{}, nil)
+		dataBase.EXPECT().GetSubscriptions([]string{}).Return([]*moira.SubscriptionData{}, nil)
+		dataBase.EXPECT().GetUserContactIDs("user").Return([]string{contact.ID}, nil)
+		dataBase.EXPECT().GetContacts([]string{contact.ID}).Return([]*moira.ContactData{&contact}, nil)
+		exported, err := ExportTriggers(dataBase, "user")
+		So(err, ShouldBeNil)
+		So(exported.Triggers, ShouldBeEmpty)
+		So(exported.Contacts, ShouldResemble, []moira.ContactData{contact})
+		So(exported.Subscriptions, ShouldBeEmpty)
+	})
+
+	Convey("Error get triggers", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get triggers")
+		dataBase.EXPECT().GetTriggerIDs().Return(nil, expected)
+		exported, err := ExportTriggers(dataBase, "")
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(exported, ShouldBeNil)
+	})
+}
@@ -0,0 +1,140 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/satori/go.uuid"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+)
+
+// GetMaintenanceWindows gets all maintenance windows ordered by start
+func GetMaintenanceWindows(database moira.Database) (*dto.MaintenanceWindowList, *api.ErrorResponse) {
+	windows, err := database.GetMaintenanceWindows()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	windowList := &dto.MaintenanceWindowList{List: make([]moira.MaintenanceWindow, 0, len(windows))}
+	for _, window := range windows {
+		windowList.List = append(windowList.List, *window)
+	}
+	sort.Slice(windowList.List, func(i, j int) bool {
+		if windowList.List[i].Start != windowList.List[j].Start {
+			return windowList.List[i].Start < windowList.List[j].Start
+		}
+		return windowList.List[i].ID < windowList.List[j].ID
+	})
+	return windowList, nil
+}
+
+// GetMaintenanceWindow gets maintenance window by id
+func GetMaintenanceWindow(dataBase moira.Database, windowID string) (*dto.MaintenanceWindow, *api.ErrorResponse) {
+	window, err := dataBase.GetMaintenanceWindow(windowID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil, api.ErrorNotFound(fmt.Sprintf("Maintenance window with ID '%s' does not exists", windowID))
+		}
+		return nil, api.ErrorInternalServer(err)
+	}
+	response := dto.MaintenanceWindow(window)
+	return &response, nil
+}
+
+// CreateMaintenanceWindow creates maintenance window owned by user, user must be able to modify all selected triggers
+// Only api administrators can create global windows
+func CreateMaintenanceWindow(dataBase moira.Database, window *dto.MaintenanceWindow, userLogin string, isAdmin bool) *api.ErrorResponse {
+	if err := checkMaintenanceWindowSelection(dataBase, window, userLogin, isAdmin); err != nil {
+		return err
+	}
+	window.ID = uuid.NewV4().String()
+	window.User = userLogin
+	windowData := moira.MaintenanceWindow(*window)
+	if err := dataBase.SaveMaintenanceWindow(&windowData); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// UpdateMaintenanceWindow updates maintenance window keeping its owner
+func UpdateMaintenanceWindow(dataBase moira.Database, window *dto.MaintenanceWindow, windowID string, userLogin string, isAdmin bool) *api.ErrorResponse {
+	existing, errorResponse := getMaintenanceWindowForUser(dataBase, windowID, userLogin, isAdmin)
+	if errorResponse != nil {
+		return errorResponse
+	}
+	if err := checkMaintenanceWindowSelection(dataBase, window, userLogin, isAdmin); err != nil {
+		return err
+	}
+	window.ID = windowID
+	window.User = existing.User
+	windowData := moira.MaintenanceWindow(*window)
+	if err := dataBase.SaveMaintenanceWindow(&windowData); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// RemoveMaintenanceWindow deletes maintenance window
+func RemoveMaintenanceWindow(dataBase moira.Database, windowID string, userLogin string, isAdmin bool) *api.ErrorResponse {
+	if _, errorResponse := getMaintenanceWindowForUser(dataBase, windowID, userLogin, isAdmin); errorResponse != nil {
+		return errorResponse
+	}
+	if err := dataBase.RemoveMaintenanceWindow(windowID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// CheckUserPermissionsForMaintenanceWindow checks that user is owner of maintenance window or editor of its team,
+// global window can be modified only by api administrators
+func CheckUserPermissionsForMaintenanceWindow(dataBase moira.Database, windowID string, userLogin string, isAdmin bool) *api.ErrorResponse {
+	_, errorResponse := getMaintenanceWindowForUser(dataBase, windowID, userLogin, isAdmin)
+	return errorResponse
+}
+
+func getMaintenanceWindowForUser(dataBase moira.Database, windowID string, userLogin string, isAdmin bool) (moira.MaintenanceWindow, *api.ErrorResponse) {
+	window, err := dataBase.GetMaintenanceWindow(windowID)
+	if err != nil {
+		if err == database.ErrNil {
+			return window, api.ErrorNotFound(fmt.Sprintf("Maintenance window with ID '%s' does not exists", windowID))
+		}
+		return window, api.ErrorInternalServer(err)
+	}
+	if window.Global {
+		if !isAdmin {
+			return window, api.ErrorForbidden("Only administrators can modify global maintenance window")
+		}
+		return window, nil
+	}
+	if window.User == userLogin {
+		return window, nil
+	}
+	if window.TeamID == "" {
+		return window, api.ErrorForbidden("You have not permissions")
+	}
+	return window, CheckUserPermissionsForTeam(dataBase, window.TeamID, userLogin, moira.TeamRoleEditor)
+}
+
+// checkMaintenanceWindowSelection checks that user can modify triggers selected by window and edit window team
+func checkMaintenanceWindowSelection(dataBase moira.Database, window *dto.MaintenanceWindow, userLogin string, isAdmin bool) *api.ErrorResponse {
+	if window.Global && !isAdmin {
+		return api.ErrorForbidden("Only administrators can modify global maintenance window")
+	}
+	if err := CheckUserPermissionsForTeam(dataBase, window.TeamID, userLogin, moira.TeamRoleEditor); err != nil {
+		return err
+	}
+	if len(window.TriggerIDs) != 0 {
+		if err := CheckUserPermissionsForTriggers(dataBase, window.TriggerIDs, userLogin); err != nil {
+			return err
+		}
+	}
+	for _, tag := range window.Tags {
+		if err := CheckUserPermissionsForTag(dataBase, tag, userLogin); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,193 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+var testMaintenanceWindow = moira.MaintenanceWindow{
+	ID:         "window-1",
+	Name:       "DC works",
+	Tags:       []string{"dc-eu"},
+	Start:      7200,
+	End:        14400,
+	Recurrence: moira.MaintenanceRecurrenceWeekly,
+	User:       "owner",
+}
+
+func TestGetMaintenanceWindows(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Windows are ordered by start", t, func() {
+		later := moira.MaintenanceWindow{ID: "window-2", Tags: []string{"db"}, Start: 10000, End: 20000}
+		dataBase.EXPECT().GetMaintenanceWindows().Return([]*moira.MaintenanceWindow{&later, &testMaintenanceWindow}, nil)
+		list, err := GetMaintenanceWindows(dataBase)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.MaintenanceWindowList{List: []moira.MaintenanceWindow{testMaintenanceWindow, later}})
+	})
+
+	Convey("Empty list", t, func() {
+		dataBase.EXPECT().GetMaintenanceWindows().Return(nil, nil)
+		list, err := GetMaintenanceWindows(dataBase)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.MaintenanceWindowList{List: make([]moira.MaintenanceWindow, 0)})
+	})
+
+	Convey("Error get windows", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get maintenance windows")
+		dataBase.EXPECT().GetMaintenanceWindows().Return(nil, expected)
+		list, err := GetMaintenanceWindows(dataBase)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+}
+
+func TestGetMaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Window exists", t, func() {
+		dataBase.EXPECT().GetMaintenanceWindow(testMaintenanceWindow.ID).Return(testMaintenanceWindow, nil)
+		window, err := GetMaintenanceWindow(dataBase, testMaintenanceWindow.ID)
+		So(err, ShouldBeNil)
+		So(*window, ShouldResemble, dto.MaintenanceWindow(testMaintenanceWindow))
+	})
+
+	Convey("Window does not exist", t, func() {
+		dataBase.EXPECT().GetMaintenanceWindow("unknown").Return(moira.MaintenanceWindow{}, database.ErrNil)
+		window, err := GetMaintenanceWindow(dataBase, "unknown")
+		So(err, ShouldResemble, api.ErrorNotFound("Maintenance window with ID 'unknown' does not exists"))
+		So(window, ShouldBeNil)
+	})
+}
+
+func TestCreateMaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("User can silence triggers he can modify", t, func() {
+		window := &dto.MaintenanceWindow{TriggerIDs: []string{"trigger-1"}, Tags: []string{"dc-eu"}, Start: 7200, End: 14400}
+		dataBase.EXPECT().GetTriggers([]string{"trigger-1"}).Return([]*moira.Trigger{{ID: "trigger-1"}}, nil)
+		dataBase.EXPECT().GetTagTriggerIDs("dc-eu").Return([]string{"trigger-2"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"trigger-2"}).Return([]*moira.Trigger{{ID: "trigger-2"}}, nil)
+		dataBase.EXPECT().SaveMaintenanceWindow(gomock.Any()).Return(nil)
+		err := CreateMaintenanceWindow(dataBase, window, "user", false)
+		So(err, ShouldBeNil)
+		So(window.ID, ShouldNotBeEmpty)
+		So(window.User, ShouldEqual, "user")
+	})
+
+	Convey("User can not silence triggers of team where he is viewer", t, func() {
+		window := &dto.MaintenanceWindow{Tags: []string{"dc-eu"}, Start: 7200, End: 14400}
+		dataBase.EXPECT().GetTagTriggerIDs("dc-eu").Return([]string{"trigger-1"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"trigger-1"}).Return([]*moira.Trigger{{ID: "trigger-1", TeamID: testTeam.ID}}, nil)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		err := CreateMaintenanceWindow(dataBase, window, "viewer", false)
+		So(err, ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Only administrator can create global window", t, func() {
+		window := &dto.MaintenanceWindow{Global: true, Start: 7200, End: 14400}
+		err := CreateMaintenanceWindow(dataBase, window, "user", false)
+		So(err, ShouldResemble, api.ErrorForbidden("Only administrators can modify global maintenance window"))
+
+		dataBase.EXPECT().SaveMaintenanceWindow(gomock.Any()).Return(nil)
+		err = CreateMaintenanceWindow(dataBase, window, "admin", true)
+		So(err, ShouldBeNil)
+		So(window.User, ShouldEqual, "admin")
+	})
+
+	Convey("Error save window", t, func() {
+		window := &dto.MaintenanceWindow{TriggerIDs: []string{"trigger-1"}, Start: 7200, End: 14400}
+		expected := fmt.Errorf("Oooops! Can not save maintenance window")
+		dataBase.EXPECT().GetTriggers([]string{"trigger-1"}).Return([]*moira.Trigger{nil}, nil)
+		dataBase.EXPECT().SaveMaintenanceWindow(gomock.Any()).Return(expected)
+		err := CreateMaintenanceWindow(dataBase, window, "user", false)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+	})
+}
+
+func TestUpdateMaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Team editor updates window keeping its owner", t, func() {
+		existing := testMaintenanceWindow
+		existing.TeamID = testTeam.ID
+		window := &dto.MaintenanceWindow{TriggerIDs: []string{"trigger-1"}, Start: 7200, End: 10800, TeamID: testTeam.ID}
+		dataBase.EXPECT().GetMaintenanceWindow(existing.ID).Return(existing, nil)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil).Times(2)
+		dataBase.EXPECT().GetTriggers([]string{"trigger-1"}).Return([]*moira.Trigger{{ID: "trigger-1"}}, nil)
+		expected := moira.MaintenanceWindow{ID: existing.ID, TriggerIDs: []string{"trigger-1"}, Start: 7200, End: 10800, User: "owner", TeamID: testTeam.ID}
+		dataBase.EXPECT().SaveMaintenanceWindow(&expected).Return(nil)
+		err := UpdateMaintenanceWindow(dataBase, window, existing.ID, "editor", false)
+		So(err, ShouldBeNil)
+		So(*window, ShouldResemble, dto.MaintenanceWindow(expected))
+	})
+
+	Convey("Other user can not update window", t, func() {
+		dataBase.EXPECT().GetMaintenanceWindow(testMaintenanceWindow.ID).Return(testMaintenanceWindow, nil)
+		err := UpdateMaintenanceWindow(dataBase, &dto.MaintenanceWindow{}, testMaintenanceWindow.ID, "other", false)
+		So(err, ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Only administrator can update global window", t, func() {
+		existing := moira.MaintenanceWindow{ID: "global", Global: true, Start: 7200, End: 14400, User: "admin"}
+		dataBase.EXPECT().GetMaintenanceWindow(existing.ID).Return(existing, nil)
+		err := UpdateMaintenanceWindow(dataBase, &dto.MaintenanceWindow{Tags: []string{"dc-eu"}}, existing.ID, "admin", false)
+		So(err, ShouldResemble, api.ErrorForbidden("Only administrators can modify global maintenance window"))
+
+		window := &dto.MaintenanceWindow{Global: true, Start: 7200, End: 10800}
+		dataBase.EXPECT().GetMaintenanceWindow(existing.ID).Return(existing, nil)
+		dataBase.EXPECT().SaveMaintenanceWindow(&moira.MaintenanceWindow{ID: "global", Global: true, Start: 7200, End: 10800, User: "admin"}).Return(nil)
+		err = UpdateMaintenanceWindow(dataBase, window, existing.ID, "other-admin", true)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Window does not exist", t, func() {
+		dataBase.EXPECT().GetMaintenanceWindow("unknown").Return(moira.MaintenanceWindow{}, database.ErrNil)
+		err := UpdateMaintenanceWindow(dataBase, &dto.MaintenanceWindow{}, "unknown", "owner", false)
+		So(err, ShouldResemble, api.ErrorNotFound("Maintenance window with ID 'unknown' does not exists"))
+	})
+}
+
+func TestRemoveMaintenanceWindow(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Owner removes window", t, func() {
+		dataBase.EXPECT().GetMaintenanceWindow(testMaintenanceWindow.ID).Return(testMaintenanceWindow, nil)
+		dataBase.EXPECT().RemoveMaintenanceWindow(testMaintenanceWindow.ID).Return(nil)
+		err := RemoveMaintenanceWindow(dataBase, testMaintenanceWindow.ID, "owner", false)
+		So(err, ShouldBeNil)
+	})
+
+	Convey("Other user can not remove window", t, func() {
+		dataBase.EXPECT().GetMaintenanceWindow(testMaintenanceWindow.ID).Return(testMaintenanceWindow, nil)
+		err := RemoveMaintenanceWindow(dataBase, testMaintenanceWindow.ID, "other", false)
+		So(err, ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Error remove window", t, func() {
+		expected := fmt.Errorf("Oooops! Can not remove maintenance window")
+		dataBase.EXPECT().GetMaintenanceWindow(testMaintenanceWindow.ID).Return(testMaintenanceWindow, nil)
+		dataBase.EXPECT().RemoveMaintenanceWindow(testMaintenanceWindow.ID).Return(expected)
+		err := RemoveMaintenanceWindow(dataBase, testMaintenanceWindow.ID, "owner", false)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+	})
+}
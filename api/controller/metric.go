@@ -0,0 +1,57 @@
+package controller
+
+import (
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/filter"
+)
+
+// MatchMetric runs metric name through pattern tree built from current patterns
+// and returns matched patterns with their triggers, metric retention and values saved in given period
+func MatchMetric(database moira.Database, logger moira.Logger, metric string, from, to int64) (*dto.MetricMatch, *api.ErrorResponse) {
+	patternStorage, err := filter.NewPatternStorage(database, nil, logger)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	name, patterns, err := patternStorage.MatchMetric(metric)
+	if err != nil {
+		return nil, api.ErrorInvalidRequest(err)
+	}
+	metricMatch := dto.MetricMatch{
+		Metric:   name,
+		Patterns: make([]dto.MatchedPattern, 0, len(patterns)),
+		Values:   make([]*moira.MetricValue, 0),
+	}
+	for _, pattern := range patterns {
+		triggerIDs, err := database.GetPatternTriggerIDs(pattern)
+		if err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		triggers, err := database.GetTriggers(triggerIDs)
+		if err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		matchedPattern := dto.MatchedPattern{
+			Pattern:  pattern,
+			Triggers: make([]dto.TriggerModel, 0, len(triggers)),
+		}
+		for _, trigger := range triggers {
+			if trigger != nil {
+				matchedPattern.Triggers = append(matchedPattern.Triggers, dto.CreateTriggerModel(trigger))
+			}
+		}
+		metricMatch.Patterns = append(metricMatch.Patterns, matchedPattern)
+	}
+	if metricMatch.Retention, err = database.GetMetricRetention(name); err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	values, err := database.GetMetricsValues([]string{name}, from, to)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	if metricValues, ok := values[name]; ok {
+		metricMatch.Values = metricValues
+	}
+	return &metricMatch, nil
+}
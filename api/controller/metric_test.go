@@ -0,0 +1,70 @@
+package controller
+
+import (
+	"fmt"
+	"github.com/golang/mock/gomock"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/mock/moira-alert"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestMatchMetric(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Test")
+	defer mockCtrl.Finish()
+	patterns := []string{"my.*.metric", "my.first.*", "other.pattern", "seriesByTag('name=my.first.metric')"}
+	trigger := &moira.Trigger{ID: "trigger1", Name: "My trigger"}
+	values := []*moira.MetricValue{{RetentionTimestamp: 60, Timestamp: 61, Value: 1}}
+
+	Convey("Metric matches patterns, should return patterns with triggers, retention and values", t, func() {
+		dataBase.EXPECT().GetPatterns().Return(patterns, nil)
+		dataBase.EXPECT().GetPatternTriggerIDs("my.*.metric").Return([]string{trigger.ID}, nil)
+		dataBase.EXPECT().GetTriggers([]string{trigger.ID}).Return([]*moira.Trigger{trigger, nil}, nil)
+		dataBase.EXPECT().GetPatternTriggerIDs("my.first.*").Return([]string{}, nil)
+		dataBase.EXPECT().GetTriggers([]string{}).Return([]*moira.Trigger{}, nil)
+		dataBase.EXPECT().GetMetricRetention("my.first.metric").Return(int64(60), nil)
+		dataBase.EXPECT().GetMetricsValues([]string{"my.first.metric"}, int64(0), int64(120)).Return(map[string][]*moira.MetricValue{"my.first.metric": values}, nil)
+		metricMatch, err := MatchMetric(dataBase, logger, "my.first.metric", 0, 120)
+		So(err, ShouldBeNil)
+		So(metricMatch, ShouldResemble, &dto.MetricMatch{
+			Metric: "my.first.metric",
+			Patterns: []dto.MatchedPattern{
+				{Pattern: "my.first.*", Triggers: []dto.TriggerModel{}},
+				{Pattern: "my.*.metric", Triggers: []dto.TriggerModel{dto.CreateTriggerModel(trigger)}},
+			},
+			Retention: 60,
+			Values:    values,
+		})
+	})
+
+	Convey("Tagged metric, should normalize name and match seriesByTag patterns", t, func() {
+		dataBase.EXPECT().GetPatterns().Return(patterns, nil)
+		dataBase.EXPECT().GetPatternTriggerIDs("seriesByTag('name=my.first.metric')").Return([]string{}, nil)
+		dataBase.EXPECT().GetTriggers([]string{}).Return([]*moira.Trigger{}, nil)
+		dataBase.EXPECT().GetMetricRetention("my.first.metric;a=1;b=2").Return(int64(60), nil)
+		dataBase.EXPECT().GetMetricsValues([]string{"my.first.metric;a=1;b=2"}, int64(0), int64(120)).Return(map[string][]*moira.MetricValue{}, nil)
+		metricMatch, err := MatchMetric(dataBase, logger, "my.first.metric;b=2;a=1", 0, 120)
+		So(err, ShouldBeNil)
+		So(metricMatch.Metric, ShouldEqual, "my.first.metric;a=1;b=2")
+		So(metricMatch.Patterns, ShouldHaveLength, 1)
+		So(metricMatch.Values, ShouldBeEmpty)
+	})
+
+	Convey("Invalid metric name, should return invalid request", t, func() {
+		dataBase.EXPECT().GetPatterns().Return(patterns, nil)
+		_, err := MatchMetric(dataBase, logger, "my first metric", 0, 120)
+		So(err.HTTPStatusCode, ShouldEqual, 400)
+	})
+
+	Convey("GetPatterns error, should return internal server error", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get patterns")
+		dataBase.EXPECT().GetPatterns().Return(nil, expected)
+		_, err := MatchMetric(dataBase, logger, "my.first.metric", 0, 120)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+	})
+}
@@ -65,3 +65,12 @@ func DeletePattern(database moira.Database, pattern string) *api.ErrorResponse {
 	}
 	return nil
 }
+
+// CheckUserPermissionsForPattern checks that user can modify all triggers with given pattern
+func CheckUserPermissionsForPattern(database moira.Database, pattern string, userLogin string) *api.ErrorResponse {
+	triggerIDs, err := database.GetPatternTriggerIDs(pattern)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return CheckUserPermissionsForTriggers(database, triggerIDs, userLogin)
+}
@@ -1,6 +1,7 @@
 package controller
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/go-graphite/carbonapi/date"
@@ -9,6 +10,7 @@ import (
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
 )
 
 // GetUserSubscriptions get all user subscriptions
@@ -33,18 +35,63 @@ func GetUserSubscriptions(database moira.Database, userLogin string) (*dto.Subsc
 }
 
 // WriteSubscription create or update subscription
-func WriteSubscription(database moira.Database, userLogin string, subscription *dto.Subscription) *api.ErrorResponse {
+// Updated subscription keeps its owner, only owner can move subscription to other team
+// Holidays reroute contact must belong to subscription owner or team
+func WriteSubscription(dataBase moira.Database, userLogin string, subscription *dto.Subscription) *api.ErrorResponse {
+	if err := CheckUserPermissionsForTeam(dataBase, subscription.TeamID, userLogin, moira.TeamRoleEditor); err != nil {
+		return err
+	}
 	subscription.User = userLogin
 	if subscription.ID == "" {
 		subscription.ID = uuid.NewV4().String()
+	} else {
+		existing, err := dataBase.GetSubscription(subscription.ID)
+		if err != nil && err != database.ErrNil {
+			return api.ErrorInternalServer(err)
+		}
+		if err == nil {
+			if existing.TeamID != subscription.TeamID && existing.User != userLogin {
+				return api.ErrorForbidden("You have not permissions")
+			}
+			subscription.User = existing.User
+		}
+	}
+	if subscription.Holidays.IsReroute() {
+		if err := checkContactOwner(dataBase, "Holidays contact", subscription.Holidays.Contact, subscription.User, subscription.TeamID); err != nil {
+			return err
+		}
 	}
 	data := moira.SubscriptionData(*subscription)
-	if err := database.SaveSubscription(&data); err != nil {
+	if err := dataBase.SaveSubscription(&data); err != nil {
 		return api.ErrorInternalServer(err)
 	}
 	return nil
 }
 
+// ImportSubscriptionHolidays parses iCal data and adds its dates to subscription holiday calendar
+func ImportSubscriptionHolidays(dataBase moira.Database, subscriptionID string, iCalData string) (*dto.Subscription, *api.ErrorResponse) {
+	dates, err := moira.ParseICalHolidays(iCalData)
+	if err != nil {
+		return nil, api.ErrorInvalidRequest(err)
+	}
+	subscription, err := dataBase.GetSubscription(subscriptionID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil, api.ErrorNotFound(fmt.Sprintf("Subscription with ID '%s' does not exists", subscriptionID))
+		}
+		return nil, api.ErrorInternalServer(err)
+	}
+	if subscription.Holidays == nil {
+		subscription.Holidays = &moira.HolidaysData{Action: moira.HolidayActionSuppress}
+	}
+	subscription.Holidays.AddDates(dates)
+	if err := dataBase.SaveSubscription(&subscription); err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	response := dto.Subscription(subscription)
+	return &response, nil
+}
+
 // RemoveSubscription deletes subscription
 func RemoveSubscription(database moira.Database, subscriptionID string, userLogin string) *api.ErrorResponse {
 	if err := database.RemoveSubscription(subscriptionID); err != nil {
@@ -71,3 +118,22 @@ func SendTestNotification(database moira.Database, subscriptionID string) *api.E
 
 	return nil
 }
+
+// CheckUserPermissionsForSubscription checks that user can modify subscription, subscription shared with team can be modified by team editors
+// Not existing subscription can be created by any user
+func CheckUserPermissionsForSubscription(dataBase moira.Database, subscriptionID string, userLogin string) *api.ErrorResponse {
+	subscription, err := dataBase.GetSubscription(subscriptionID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil
+		}
+		return api.ErrorInternalServer(err)
+	}
+	if subscription.User == userLogin {
+		return nil
+	}
+	if subscription.TeamID == "" {
+		return api.ErrorForbidden("You have not permissions")
+	}
+	return CheckUserPermissionsForTeam(dataBase, subscription.TeamID, userLogin, moira.TeamRoleEditor)
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
 	"github.com/moira-alert/moira/mock/moira-alert"
 	"github.com/satori/go.uuid"
 	. "github.com/smartystreets/goconvey/convey"
@@ -103,20 +104,132 @@ func TestSendTestNotification(t *testing.T) {
 func TestWriteSubscription(t *testing.T) {
 	mockCtrl := gomock.NewController(t)
 	defer mockCtrl.Finish()
-	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
 	login := "user"
 
-	subscription := dto.Subscription{ID: ""}
 	Convey("Success", t, func() {
-		database.EXPECT().SaveSubscription(gomock.Any()).Return(nil)
-		err := WriteSubscription(database, login, &subscription)
+		subscription := dto.Subscription{ID: ""}
+		dataBase.EXPECT().SaveSubscription(gomock.Any()).Return(nil)
+		err := WriteSubscription(dataBase, login, &subscription)
 		So(err, ShouldBeNil)
+		So(subscription.ID, ShouldNotBeEmpty)
+		So(subscription.User, ShouldEqual, login)
 	})
 
 	Convey("Error", t, func() {
+		subscription := dto.Subscription{ID: ""}
 		expected := fmt.Errorf("Oooops! Can not create subscription")
-		database.EXPECT().SaveSubscription(gomock.Any()).Return(expected)
-		err := WriteSubscription(database, login, &subscription)
+		dataBase.EXPECT().SaveSubscription(gomock.Any()).Return(expected)
+		err := WriteSubscription(dataBase, login, &subscription)
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 	})
+
+	Convey("Team editor updates subscription of other user", t, func() {
+		subscription := dto.Subscription{ID: "subscription", TeamID: testTeam.ID, Tags: []string{"new"}}
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		dataBase.EXPECT().GetSubscription("subscription").Return(moira.SubscriptionData{ID: "subscription", User: "owner", TeamID: testTeam.ID}, nil)
+		dataBase.EXPECT().SaveSubscription(&moira.SubscriptionData{ID: "subscription", User: "owner", TeamID: testTeam.ID, Tags: []string{"new"}}).Return(nil)
+		err := WriteSubscription(dataBase, "editor", &subscription)
+		So(err, ShouldBeNil)
+		So(subscription.User, ShouldEqual, "owner")
+	})
+
+	Convey("Team editor can not move subscription of other user to other team", t, func() {
+		subscription := dto.Subscription{ID: "subscription"}
+		dataBase.EXPECT().GetSubscription("subscription").Return(moira.SubscriptionData{ID: "subscription", User: "owner", TeamID: testTeam.ID}, nil)
+		err := WriteSubscription(dataBase, "editor", &subscription)
+		So(err, ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Holidays reroute contact must belong to subscription owner or team", t, func() {
+		reroute := func(contactID string) dto.Subscription {
+			return dto.Subscription{TeamID: testTeam.ID, Holidays: &moira.HolidaysData{Action: moira.HolidayActionReroute, Contact: contactID}}
+		}
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil).Times(4)
+
+		subscription := reroute("own")
+		dataBase.EXPECT().GetContact("own").Return(moira.ContactData{ID: "own", User: "editor"}, nil)
+		dataBase.EXPECT().SaveSubscription(gomock.Any()).Return(nil)
+		So(WriteSubscription(dataBase, "editor", &subscription), ShouldBeNil)
+
+		subscription = reroute("team")
+		dataBase.EXPECT().GetContact("team").Return(moira.ContactData{ID: "team", User: "other", TeamID: testTeam.ID}, nil)
+		dataBase.EXPECT().SaveSubscription(gomock.Any()).Return(nil)
+		So(WriteSubscription(dataBase, "editor", &subscription), ShouldBeNil)
+
+		subscription = reroute("foreign")
+		dataBase.EXPECT().GetContact("foreign").Return(moira.ContactData{ID: "foreign", User: "other"}, nil)
+		So(WriteSubscription(dataBase, "editor", &subscription), ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Holidays contact with ID 'foreign' does not exists")))
+
+		subscription = reroute("missing")
+		dataBase.EXPECT().GetContact("missing").Return(moira.ContactData{}, database.ErrNil)
+		So(WriteSubscription(dataBase, "editor", &subscription), ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Holidays contact with ID 'missing' does not exists")))
+	})
+
+	Convey("Subscription with new ID is created for current user", t, func() {
+		subscription := dto.Subscription{ID: "subscription"}
+		dataBase.EXPECT().GetSubscription("subscription").Return(moira.SubscriptionData{}, database.ErrNil)
+		dataBase.EXPECT().SaveSubscription(&moira.SubscriptionData{ID: "subscription", User: login}).Return(nil)
+		err := WriteSubscription(dataBase, login, &subscription)
+		So(err, ShouldBeNil)
+	})
+}
+
+func TestImportSubscriptionHolidays(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	id := uuid.NewV4().String()
+	iCalData := "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20180101\r\nDTEND;VALUE=DATE:20180103\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+
+	Convey("Success", t, func() {
+		subscription := moira.SubscriptionData{ID: id, Holidays: &moira.HolidaysData{Dates: []string{"2018-01-02", "2018-05-09"}}}
+		expected := subscription
+		expected.Holidays = &moira.HolidaysData{Dates: []string{"2018-01-01", "2018-01-02", "2018-05-09"}}
+		dataBase.EXPECT().GetSubscription(id).Return(subscription, nil)
+		dataBase.EXPECT().SaveSubscription(&expected).Return(nil)
+		actual, err := ImportSubscriptionHolidays(dataBase, id, iCalData)
+		So(err, ShouldBeNil)
+		So(actual.Holidays.Dates, ShouldResemble, expected.Holidays.Dates)
+	})
+
+	Convey("Subscription without holidays, should use suppress action", t, func() {
+		dataBase.EXPECT().GetSubscription(id).Return(moira.SubscriptionData{ID: id}, nil)
+		dataBase.EXPECT().SaveSubscription(gomock.Any()).Return(nil)
+		actual, err := ImportSubscriptionHolidays(dataBase, id, iCalData)
+		So(err, ShouldBeNil)
+		So(actual.Holidays, ShouldResemble, &moira.HolidaysData{Dates: []string{"2018-01-01", "2018-01-02"}, Action: moira.HolidayActionSuppress})
+	})
+
+	Convey("Invalid iCal data", t, func() {
+		actual, err := ImportSubscriptionHolidays(dataBase, id, "BEGIN:VEVENT\r\nDTSTART:2018\r\nEND:VEVENT")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Invalid iCal date '2018'")))
+		So(actual, ShouldBeNil)
+	})
+
+	Convey("Unknown subscription", t, func() {
+		dataBase.EXPECT().GetSubscription(id).Return(moira.SubscriptionData{}, database.ErrNil)
+		actual, err := ImportSubscriptionHolidays(dataBase, id, iCalData)
+		So(err, ShouldResemble, api.ErrorNotFound(fmt.Sprintf("Subscription with ID '%s' does not exists", id)))
+		So(actual, ShouldBeNil)
+	})
+
+	Convey("Errors", t, func() {
+		Convey("GetSubscription", func() {
+			expected := fmt.Errorf("Oooops! Can not get subscription")
+			dataBase.EXPECT().GetSubscription(id).Return(moira.SubscriptionData{}, expected)
+			actual, err := ImportSubscriptionHolidays(dataBase, id, iCalData)
+			So(err, ShouldResemble, api.ErrorInternalServer(expected))
+			So(actual, ShouldBeNil)
+		})
+
+		Convey("SaveSubscription", func() {
+			expected := fmt.Errorf("Oooops! Can not save subscription")
+			dataBase.EXPECT().GetSubscription(id).Return(moira.SubscriptionData{ID: id}, nil)
+			dataBase.EXPECT().SaveSubscription(gomock.Any()).Return(expected)
+			actual, err := ImportSubscriptionHolidays(dataBase, id, iCalData)
+			So(err, ShouldResemble, api.ErrorInternalServer(expected))
+			So(actual, ShouldBeNil)
+		})
+	})
 }
@@ -81,3 +81,12 @@ func RemoveTag(database moira.Database, tagName string) (*dto.MessageResponse, *
 	}
 	return &dto.MessageResponse{Message: "tag deleted"}, nil
 }
+
+// CheckUserPermissionsForTag checks that user can modify all triggers with given tag
+func CheckUserPermissionsForTag(database moira.Database, tagName string, userLogin string) *api.ErrorResponse {
+	triggerIDs, err := database.GetTagTriggerIDs(tagName)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return CheckUserPermissionsForTriggers(database, triggerIDs, userLogin)
+}
@@ -0,0 +1,164 @@
+package controller
+
+import (
+	"fmt"
+
+	"github.com/satori/go.uuid"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+)
+
+// GetUserTeams gets teams where user is member
+func GetUserTeams(database moira.Database, userLogin string) (*dto.TeamList, *api.ErrorResponse) {
+	teamIDs, err := database.GetUserTeamIDs(userLogin)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	teams, err := database.GetTeams(teamIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	teamList := &dto.TeamList{List: make([]moira.Team, 0)}
+	for _, team := range teams {
+		if team != nil {
+			teamList.List = append(teamList.List, *team)
+		}
+	}
+	return teamList, nil
+}
+
+// CreateTeam creates new team, user who creates team becomes its admin
+func CreateTeam(database moira.Database, team *dto.Team, userLogin string) *api.ErrorResponse {
+	if userLogin == "" {
+		return api.ErrorUnauthorized("Authentication required")
+	}
+	team.ID = uuid.NewV4().String()
+	if team.Members == nil {
+		team.Members = make(map[string]string)
+	}
+	team.Members[userLogin] = moira.TeamRoleAdmin
+	teamData := moira.Team(*team)
+	if err := database.SaveTeam(&teamData); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// GetTeam gets team available to its members
+func GetTeam(database moira.Database, teamID string, userLogin string) (*dto.Team, *api.ErrorResponse) {
+	team, errorResponse := getTeamWithRole(database, teamID, userLogin, moira.TeamRoleViewer)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	response := dto.Team(team)
+	return &response, nil
+}
+
+// GetTeamSettings gets team with contacts and subscriptions shared with it
+func GetTeamSettings(database moira.Database, teamID string, userLogin string) (*dto.TeamSettings, *api.ErrorResponse) {
+	team, errorResponse := getTeamWithRole(database, teamID, userLogin, moira.TeamRoleViewer)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	teamSettings := &dto.TeamSettings{
+		Team:          dto.Team(team),
+		Contacts:      make([]moira.ContactData, 0),
+		Subscriptions: make([]moira.SubscriptionData, 0),
+	}
+	contactIDs, err := database.GetTeamContactIDs(teamID)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	contacts, err := database.GetContacts(contactIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	for _, contact := range contacts {
+		if contact != nil {
+			teamSettings.Contacts = append(teamSettings.Contacts, *contact)
+		}
+	}
+	subscriptionIDs, err := database.GetTeamSubscriptionIDs(teamID)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	subscriptions, err := database.GetSubscriptions(subscriptionIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	for _, subscription := range subscriptions {
+		if subscription != nil {
+			teamSettings.Subscriptions = append(teamSettings.Subscriptions, *subscription)
+		}
+	}
+	return teamSettings, nil
+}
+
+// UpdateTeam updates team name, description and members, only team admins can do it
+func UpdateTeam(database moira.Database, team *dto.Team, teamID string, userLogin string) *api.ErrorResponse {
+	if _, errorResponse := getTeamWithRole(database, teamID, userLogin, moira.TeamRoleAdmin); errorResponse != nil {
+		return errorResponse
+	}
+	team.ID = teamID
+	teamData := moira.Team(*team)
+	if !teamData.HasRole(userLogin, moira.TeamRoleAdmin) {
+		return api.ErrorInvalidRequest(fmt.Errorf("You can not remove admin role from yourself"))
+	}
+	if err := database.SaveTeam(&teamData); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// RemoveTeam deletes team which has no triggers, contacts and subscriptions, only team admins can do it
+func RemoveTeam(database moira.Database, teamID string, userLogin string) *api.ErrorResponse {
+	if _, errorResponse := getTeamWithRole(database, teamID, userLogin, moira.TeamRoleAdmin); errorResponse != nil {
+		return errorResponse
+	}
+	triggerIDs, err := database.GetTeamTriggerIDs(teamID)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	contactIDs, err := database.GetTeamContactIDs(teamID)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	subscriptionIDs, err := database.GetTeamSubscriptionIDs(teamID)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	if len(triggerIDs) > 0 || len(contactIDs) > 0 || len(subscriptionIDs) > 0 {
+		return api.ErrorInvalidRequest(fmt.Errorf("Team owns %v triggers, %v contacts and %v subscriptions. Move or remove them first",
+			len(triggerIDs), len(contactIDs), len(subscriptionIDs)))
+	}
+	if err := database.RemoveTeam(teamID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// CheckUserPermissionsForTeam checks that user has given role in team, empty teamID means no team and is always permitted
+func CheckUserPermissionsForTeam(dataBase moira.Database, teamID string, userLogin string, role string) *api.ErrorResponse {
+	if teamID == "" {
+		return nil
+	}
+	_, errorResponse := getTeamWithRole(dataBase, teamID, userLogin, role)
+	return errorResponse
+}
+
+func getTeamWithRole(dataBase moira.Database, teamID string, userLogin string, role string) (moira.Team, *api.ErrorResponse) {
+	team, err := dataBase.GetTeam(teamID)
+	if err != nil {
+		if err == database.ErrNil {
+			return team, api.ErrorInvalidRequest(fmt.Errorf("Team with ID '%s' does not exists", teamID))
+		}
+		return team, api.ErrorInternalServer(err)
+	}
+	if !team.HasRole(userLogin, role) {
+		return team, api.ErrorForbidden("You have not permissions")
+	}
+	return team, nil
+}
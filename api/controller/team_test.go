@@ -0,0 +1,207 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+var testTeam = moira.Team{
+	ID:   "team-1",
+	Name: "Ops",
+	Members: map[string]string{
+		"admin":  moira.TeamRoleAdmin,
+		"editor": moira.TeamRoleEditor,
+		"viewer": moira.TeamRoleViewer,
+	},
+}
+
+func TestCreateTeam(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Creator becomes team admin", t, func() {
+		team := &dto.Team{Name: "Ops", Members: map[string]string{"user": moira.TeamRoleViewer}}
+		dataBase.EXPECT().SaveTeam(gomock.Any()).Return(nil)
+		err := CreateTeam(dataBase, team, "creator")
+		So(err, ShouldBeNil)
+		So(team.ID, ShouldNotBeEmpty)
+		So(team.Members, ShouldResemble, map[string]string{"user": moira.TeamRoleViewer, "creator": moira.TeamRoleAdmin})
+	})
+
+	Convey("Anonymous user can not create team", t, func() {
+		err := CreateTeam(dataBase, &dto.Team{Name: "Ops"}, "")
+		So(err, ShouldResemble, api.ErrorUnauthorized("Authentication required"))
+	})
+}
+
+func TestGetTeam(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Team member can get team", t, func() {
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		team, err := GetTeam(dataBase, testTeam.ID, "viewer")
+		So(err, ShouldBeNil)
+		So(*team, ShouldResemble, dto.Team(testTeam))
+	})
+
+	Convey("Not member can not get team", t, func() {
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		team, err := GetTeam(dataBase, testTeam.ID, "other")
+		So(err, ShouldResemble, api.ErrorForbidden("You have not permissions"))
+		So(team, ShouldBeNil)
+	})
+
+	Convey("Team does not exist", t, func() {
+		dataBase.EXPECT().GetTeam("unknown").Return(moira.Team{}, database.ErrNil)
+		_, err := GetTeam(dataBase, "unknown", "viewer")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Team with ID 'unknown' does not exists")))
+	})
+}
+
+func TestUpdateTeam(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Admin can update team", t, func() {
+		team := &dto.Team{Name: "Ops", Members: map[string]string{"admin": moira.TeamRoleAdmin}}
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		dataBase.EXPECT().SaveTeam(gomock.Any()).Return(nil)
+		So(UpdateTeam(dataBase, team, testTeam.ID, "admin"), ShouldBeNil)
+		So(team.ID, ShouldEqual, testTeam.ID)
+	})
+
+	Convey("Editor can not update team", t, func() {
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		err := UpdateTeam(dataBase, &dto.Team{Name: "Ops"}, testTeam.ID, "editor")
+		So(err, ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Admin can not remove own admin role", t, func() {
+		team := &dto.Team{Name: "Ops", Members: map[string]string{"admin": moira.TeamRoleEditor}}
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		err := UpdateTeam(dataBase, team, testTeam.ID, "admin")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("You can not remove admin role from yourself")))
+	})
+}
+
+func TestRemoveTeam(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Empty team, should remove", t, func() {
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		dataBase.EXPECT().GetTeamTriggerIDs(testTeam.ID).Return([]string{}, nil)
+		dataBase.EXPECT().GetTeamContactIDs(testTeam.ID).Return([]string{}, nil)
+		dataBase.EXPECT().GetTeamSubscriptionIDs(testTeam.ID).Return([]string{}, nil)
+		dataBase.EXPECT().RemoveTeam(testTeam.ID).Return(nil)
+		So(RemoveTeam(dataBase, testTeam.ID, "admin"), ShouldBeNil)
+	})
+
+	Convey("Team owns triggers, should not remove", t, func() {
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		dataBase.EXPECT().GetTeamTriggerIDs(testTeam.ID).Return([]string{"trigger"}, nil)
+		dataBase.EXPECT().GetTeamContactIDs(testTeam.ID).Return([]string{}, nil)
+		dataBase.EXPECT().GetTeamSubscriptionIDs(testTeam.ID).Return([]string{}, nil)
+		err := RemoveTeam(dataBase, testTeam.ID, "admin")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Team owns 1 triggers, 0 contacts and 0 subscriptions. Move or remove them first")))
+	})
+}
+
+func TestGetTeamSettings(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Should return team contacts and subscriptions", t, func() {
+		contact := moira.ContactData{ID: "contact", User: "editor", TeamID: testTeam.ID}
+		subscription := moira.SubscriptionData{ID: "subscription", User: "editor", TeamID: testTeam.ID}
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		dataBase.EXPECT().GetTeamContactIDs(testTeam.ID).Return([]string{contact.ID}, nil)
+		dataBase.EXPECT().GetContacts([]string{contact.ID}).Return([]*moira.ContactData{&contact}, nil)
+		dataBase.EXPECT().GetTeamSubscriptionIDs(testTeam.ID).Return([]string{subscription.ID, "removed"}, nil)
+		dataBase.EXPECT().GetSubscriptions([]string{subscription.ID, "removed"}).Return([]*moira.SubscriptionData{&subscription, nil}, nil)
+		settings, err := GetTeamSettings(dataBase, testTeam.ID, "viewer")
+		So(err, ShouldBeNil)
+		So(settings, ShouldResemble, &dto.TeamSettings{
+			Team:          dto.Team(testTeam),
+			Contacts:      []moira.ContactData{contact},
+			Subscriptions: []moira.SubscriptionData{subscription},
+		})
+	})
+}
+
+func TestCheckUserPermissionsForTrigger(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Trigger without team can be modified by anyone", t, func() {
+		dataBase.EXPECT().GetTrigger("trigger").Return(moira.Trigger{ID: "trigger"}, nil)
+		So(CheckUserPermissionsForTrigger(dataBase, "trigger", "other"), ShouldBeNil)
+	})
+
+	Convey("Team trigger can be modified by team editors", t, func() {
+		dataBase.EXPECT().GetTrigger("trigger").Return(moira.Trigger{ID: "trigger", TeamID: testTeam.ID}, nil)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		So(CheckUserPermissionsForTrigger(dataBase, "trigger", "editor"), ShouldBeNil)
+	})
+
+	Convey("Team trigger can not be modified by team viewers", t, func() {
+		dataBase.EXPECT().GetTrigger("trigger").Return(moira.Trigger{ID: "trigger", TeamID: testTeam.ID}, nil)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		So(CheckUserPermissionsForTrigger(dataBase, "trigger", "viewer"), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Team trigger can be viewed by team viewers only", t, func() {
+		dataBase.EXPECT().GetTrigger("trigger").Return(moira.Trigger{ID: "trigger", TeamID: testTeam.ID}, nil).Times(2)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil).Times(2)
+		So(CheckUserViewPermissionsForTrigger(dataBase, "trigger", "viewer"), ShouldBeNil)
+		So(CheckUserViewPermissionsForTrigger(dataBase, "trigger", "other"), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Pattern of team trigger can not be removed by other users", t, func() {
+		dataBase.EXPECT().GetPatternTriggerIDs("pattern").Return([]string{"trigger1", "trigger2"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"trigger1", "trigger2"}).Return([]*moira.Trigger{
+			{ID: "trigger1"},
+			{ID: "trigger2", TeamID: testTeam.ID},
+		}, nil)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		So(CheckUserPermissionsForPattern(dataBase, "pattern", "other"), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+}
+
+func TestCheckUserPermissionsForSubscription(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("New subscription can be created by anyone", t, func() {
+		dataBase.EXPECT().GetSubscription("subscription").Return(moira.SubscriptionData{}, database.ErrNil)
+		So(CheckUserPermissionsForSubscription(dataBase, "subscription", "user"), ShouldBeNil)
+	})
+
+	Convey("Subscription of other user can not be modified", t, func() {
+		dataBase.EXPECT().GetSubscription("subscription").Return(moira.SubscriptionData{User: "other"}, nil)
+		So(CheckUserPermissionsForSubscription(dataBase, "subscription", "user"), ShouldResemble, api.ErrorForbidden("You have not permissions"))
+	})
+
+	Convey("Team subscription can be modified by team editors", t, func() {
+		dataBase.EXPECT().GetSubscription("subscription").Return(moira.SubscriptionData{User: "other", TeamID: testTeam.ID}, nil)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		So(CheckUserPermissionsForSubscription(dataBase, "subscription", "editor"), ShouldBeNil)
+	})
+}
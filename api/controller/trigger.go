@@ -13,19 +13,31 @@ import (
 	"github.com/moira-alert/moira/target"
 )
 
-// UpdateTrigger update trigger data and trigger metrics in last state
-func UpdateTrigger(dataBase moira.Database, trigger *dto.TriggerModel, triggerID string, timeSeriesNames map[string]bool) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
-	_, err := dataBase.GetTrigger(triggerID)
+// UpdateTrigger update trigger data and trigger metrics in last state, new trigger version is stored in trigger history
+// Sync mark of managed trigger is kept, so manual changes can be detected by sync
+func UpdateTrigger(dataBase moira.Database, trigger *dto.TriggerModel, triggerID string, timeSeriesNames map[string]bool, userLogin string, comment string) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
+	existing, err := dataBase.GetTrigger(triggerID)
 	if err != nil {
 		if err == database.ErrNil {
 			return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger with ID = '%s' does not exists", triggerID))
 		}
 		return nil, api.ErrorInternalServer(err)
 	}
-	return saveTrigger(dataBase, trigger.ToMoiraTrigger(), triggerID, timeSeriesNames)
+	moiraTrigger := trigger.ToMoiraTrigger()
+	moiraTrigger.ID = triggerID
+	moiraTrigger.Managed = existing.Managed
+	response, errorResponse := saveTrigger(dataBase, moiraTrigger, triggerID, timeSeriesNames)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	if errorResponse := addTriggerVersion(dataBase, moiraTrigger, moira.TriggerActionUpdate, userLogin, comment); errorResponse != nil {
+		return nil, errorResponse
+	}
+	return response, nil
 }
 
 // saveTrigger create or update trigger data and update trigger metrics in last state
+// If timeSeriesNames is nil metrics in last state are kept as is
 func saveTrigger(dataBase moira.Database, trigger *moira.Trigger, triggerID string, timeSeriesNames map[string]bool) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
 	if err := dataBase.AcquireTriggerCheckLock(triggerID, 10); err != nil {
 		return nil, api.ErrorInternalServer(err)
@@ -37,9 +49,11 @@ func saveTrigger(dataBase moira.Database, trigger *moira.Trigger, triggerID stri
 	}
 
 	if err != database.ErrNil {
-		for metric := range lastCheck.Metrics {
-			if _, ok := timeSeriesNames[metric]; !ok {
-				delete(lastCheck.Metrics, metric)
+		if timeSeriesNames != nil {
+			for metric := range lastCheck.Metrics {
+				if _, ok := timeSeriesNames[metric]; !ok {
+					delete(lastCheck.Metrics, metric)
+				}
 			}
 		}
 	} else {
@@ -89,14 +103,23 @@ func GetTrigger(dataBase moira.Database, triggerID string) (*dto.Trigger, *api.E
 	return &triggerResponse, nil
 }
 
-// RemoveTrigger deletes trigger by given triggerID
-func RemoveTrigger(database moira.Database, triggerID string) *api.ErrorResponse {
-	if err := database.RemoveTrigger(triggerID); err != nil {
+// RemoveTrigger deletes trigger by given triggerID, last trigger state is stored in trigger history
+func RemoveTrigger(dataBase moira.Database, triggerID string, userLogin string, comment string) *api.ErrorResponse {
+	trigger, err := dataBase.GetTrigger(triggerID)
+	if err != nil && err != database.ErrNil {
 		return api.ErrorInternalServer(err)
 	}
-	if err := database.RemoveTriggerLastCheck(triggerID); err != nil {
+	exists := err == nil
+	if err := dataBase.RemoveTrigger(triggerID); err != nil {
 		return api.ErrorInternalServer(err)
 	}
+	if err := dataBase.RemoveTriggerLastCheck(triggerID); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	if exists {
+		trigger.ID = triggerID
+		return addTriggerVersion(dataBase, &trigger, moira.TriggerActionDelete, userLogin, comment)
+	}
 	return nil
 }
 
@@ -154,7 +177,7 @@ func DeleteTriggerThrottling(database moira.Database, triggerID string) *api.Err
 	return nil
 }
 
-// DeleteTriggerMetric deletes metric from last check and all trigger patterns metrics
+// DeleteTriggerMetric deletes metric from last check and all trigger patterns metrics, tagged series is removed from series tags index
 func DeleteTriggerMetric(dataBase moira.Database, metricName string, triggerID string) *api.ErrorResponse {
 	trigger, err := dataBase.GetTrigger(triggerID)
 	if err != nil {
@@ -183,6 +206,11 @@ func DeleteTriggerMetric(dataBase moira.Database, metricName string, triggerID s
 	if err = dataBase.RemovePatternsMetrics(trigger.Patterns); err != nil {
 		return api.ErrorInternalServer(err)
 	}
+	if moira.IsTaggedMetric(metricName) {
+		if err = dataBase.RemoveSeriesTags(metricName); err != nil {
+			return api.ErrorInternalServer(err)
+		}
+	}
 	if err = dataBase.SetTriggerLastCheck(triggerID, &lastCheck); err != nil {
 		return api.ErrorInternalServer(err)
 	}
@@ -227,3 +255,46 @@ func GetTriggerMetrics(dataBase moira.Database, from, to int64, triggerID string
 	}
 	return triggerMetrics, nil
 }
+
+// CheckUserPermissionsForTrigger checks that user can modify trigger, triggers without team can be modified by any user
+func CheckUserPermissionsForTrigger(dataBase moira.Database, triggerID string, userLogin string) *api.ErrorResponse {
+	trigger, err := dataBase.GetTrigger(triggerID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil
+		}
+		return api.ErrorInternalServer(err)
+	}
+	return CheckUserPermissionsForTeam(dataBase, trigger.TeamID, userLogin, moira.TeamRoleEditor)
+}
+
+// CheckUserViewPermissionsForTrigger checks that user can view trigger notification details, triggers without team can be viewed by any user
+func CheckUserViewPermissionsForTrigger(dataBase moira.Database, triggerID string, userLogin string) *api.ErrorResponse {
+	trigger, err := dataBase.GetTrigger(triggerID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil
+		}
+		return api.ErrorInternalServer(err)
+	}
+	return CheckUserPermissionsForTeam(dataBase, trigger.TeamID, userLogin, moira.TeamRoleViewer)
+}
+
+// CheckUserPermissionsForTriggers checks that user can modify all given triggers
+func CheckUserPermissionsForTriggers(dataBase moira.Database, triggerIDs []string, userLogin string) *api.ErrorResponse {
+	triggers, err := dataBase.GetTriggers(triggerIDs)
+	if err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	checkedTeams := make(map[string]bool)
+	for _, trigger := range triggers {
+		if trigger == nil || checkedTeams[trigger.TeamID] {
+			continue
+		}
+		if err := CheckUserPermissionsForTeam(dataBase, trigger.TeamID, userLogin, moira.TeamRoleEditor); err != nil {
+			return err
+		}
+		checkedTeams[trigger.TeamID] = true
+	}
+	return nil
+}
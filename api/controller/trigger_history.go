@@ -0,0 +1,136 @@
+package controller
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+)
+
+// GetTriggerHistory gets trigger versions with changes relative to previous versions, the newest version goes first
+func GetTriggerHistory(dataBase moira.Database, triggerID string) (*dto.TriggerHistory, *api.ErrorResponse) {
+	versions, err := dataBase.GetTriggerVersions(triggerID)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	history := &dto.TriggerHistory{List: make([]dto.TriggerVersion, 0, len(versions))}
+	var previous *dto.TriggerModel
+	for _, version := range versions {
+		model := dto.CreateTriggerModel(&version.Trigger)
+		changes, err := getTriggerChanges(previous, &model)
+		if err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		history.List = append(history.List, dto.TriggerVersion{
+			Version:   version.Version,
+			Action:    version.Action,
+			Author:    version.Author,
+			Timestamp: version.Timestamp,
+			Comment:   version.Comment,
+			Trigger:   model,
+			Changes:   changes,
+		})
+		previous = &model
+	}
+	for i, j := 0, len(history.List)-1; i < j; i, j = i+1, j-1 {
+		history.List[i], history.List[j] = history.List[j], history.List[i]
+	}
+	return history, nil
+}
+
+// RestoreTriggerVersion saves trigger snapshot of given version as current trigger state
+// Trigger metrics in last check are kept as is, checker will update them on next check
+func RestoreTriggerVersion(dataBase moira.Database, triggerID string, version int64, userLogin string, comment string) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
+	triggerVersion, err := dataBase.GetTriggerVersion(triggerID, version)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil, api.ErrorNotFound(fmt.Sprintf("Version %d of trigger '%s' not found", version, triggerID))
+		}
+		return nil, api.ErrorInternalServer(err)
+	}
+	trigger := triggerVersion.Trigger
+	trigger.ID = triggerID
+	if errorResponse := CheckUserPermissionsForTeam(dataBase, trigger.TeamID, userLogin, moira.TeamRoleEditor); errorResponse != nil {
+		return nil, errorResponse
+	}
+	if comment == "" {
+		comment = fmt.Sprintf("Restored version %d", version)
+	}
+	response, errorResponse := saveTrigger(dataBase, &trigger, triggerID, nil)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	if errorResponse := addTriggerVersion(dataBase, &trigger, moira.TriggerActionRestore, userLogin, comment); errorResponse != nil {
+		return nil, errorResponse
+	}
+	response.Message = "trigger restored"
+	return response, nil
+}
+
+// addTriggerVersion stores trigger snapshot in trigger history
+func addTriggerVersion(dataBase moira.Database, trigger *moira.Trigger, action string, userLogin string, comment string) *api.ErrorResponse {
+	version := &moira.TriggerVersion{
+		Action:    action,
+		Author:    userLogin,
+		Timestamp: time.Now().Unix(),
+		Comment:   comment,
+		Trigger:   *trigger,
+	}
+	if err := dataBase.AddTriggerVersion(trigger.ID, version); err != nil {
+		return api.ErrorInternalServer(err)
+	}
+	return nil
+}
+
+// getTriggerChanges compares api representations of trigger versions field by field
+func getTriggerChanges(previous *dto.TriggerModel, current *dto.TriggerModel) ([]dto.TriggerFieldChange, error) {
+	previousFields, err := getTriggerFields(previous)
+	if err != nil {
+		return nil, err
+	}
+	currentFields, err := getTriggerFields(current)
+	if err != nil {
+		return nil, err
+	}
+	fieldNames := make([]string, 0, len(currentFields))
+	for field := range currentFields {
+		fieldNames = append(fieldNames, field)
+	}
+	for field := range previousFields {
+		if _, ok := currentFields[field]; !ok {
+			fieldNames = append(fieldNames, field)
+		}
+	}
+	sort.Strings(fieldNames)
+
+	changes := make([]dto.TriggerFieldChange, 0)
+	for _, field := range fieldNames {
+		if !reflect.DeepEqual(previousFields[field], currentFields[field]) {
+			changes = append(changes, dto.TriggerFieldChange{
+				Field:    field,
+				OldValue: previousFields[field],
+				NewValue: currentFields[field],
+			})
+		}
+	}
+	return changes, nil
+}
+
+func getTriggerFields(trigger *dto.TriggerModel) (map[string]interface{}, error) {
+	fields := make(map[string]interface{})
+	if trigger == nil {
+		return fields, nil
+	}
+	bytes, err := json.Marshal(trigger)
+	if err != nil {
+		return nil, err
+	}
+	err = json.Unmarshal(bytes, &fields)
+	return fields, err
+}
@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestGetTriggerHistory(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	warnValue, changedWarnValue, errorValue := float64(10), float64(20), float64(30)
+	trigger := moira.Trigger{ID: "trigger", Name: "cpu", Targets: []string{"my.metric"}, WarnValue: &warnValue, ErrorValue: &errorValue, Tags: []string{"tag"}, Patterns: []string{"my.metric"}}
+	changed := trigger
+	changed.WarnValue = &changedWarnValue
+
+	Convey("Should return versions from the newest with changes", t, func() {
+		dataBase.EXPECT().GetTriggerVersions(trigger.ID).Return([]*moira.TriggerVersion{
+			{Version: 1, Action: moira.TriggerActionCreate, Author: "user", Timestamp: 100, Trigger: trigger},
+			{Version: 2, Action: moira.TriggerActionUpdate, Author: "other", Timestamp: 200, Comment: "too noisy", Trigger: changed},
+		}, nil)
+		history, err := GetTriggerHistory(dataBase, trigger.ID)
+		So(err, ShouldBeNil)
+		So(history.List, ShouldHaveLength, 2)
+		So(history.List[0], ShouldResemble, dto.TriggerVersion{
+			Version:   2,
+			Action:    moira.TriggerActionUpdate,
+			Author:    "other",
+			Timestamp: 200,
+			Comment:   "too noisy",
+			Trigger:   dto.CreateTriggerModel(&changed),
+			Changes:   []dto.TriggerFieldChange{{Field: "warn_value", OldValue: float64(10), NewValue: float64(20)}},
+		})
+		So(history.List[1].Version, ShouldEqual, 1)
+		So(history.List[1].Changes, ShouldContain, dto.TriggerFieldChange{Field: "name", OldValue: nil, NewValue: "cpu"})
+	})
+
+	Convey("Error get versions", t, func() {
+		expected := fmt.Errorf("Oooops! Can not get versions")
+		dataBase.EXPECT().GetTriggerVersions(trigger.ID).Return(nil, expected)
+		history, err := GetTriggerHistory(dataBase, trigger.ID)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(history, ShouldBeNil)
+	})
+}
+
+func TestRestoreTriggerVersion(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	trigger := moira.Trigger{ID: "trigger", Name: "cpu", Targets: []string{"my.metric"}}
+	lastCheck := moira.CheckData{Metrics: map[string]moira.MetricState{"my.metric": {}}}
+
+	Convey("Should save snapshot, keep last check metrics and add restore version", t, func() {
+		var restoreVersion *moira.TriggerVersion
+		dataBase.EXPECT().GetTriggerVersion(trigger.ID, int64(3)).Return(moira.TriggerVersion{Version: 3, Trigger: trigger}, nil)
+		dataBase.EXPECT().AcquireTriggerCheckLock(trigger.ID, 10)
+		dataBase.EXPECT().DeleteTriggerCheckLock(trigger.ID)
+		dataBase.EXPECT().GetTriggerLastCheck(trigger.ID).Return(lastCheck, nil)
+		dataBase.EXPECT().SetTriggerLastCheck(trigger.ID, &lastCheck).Return(nil)
+		dataBase.EXPECT().SaveTrigger(trigger.ID, &trigger).Return(nil)
+		dataBase.EXPECT().AddTriggerVersion(trigger.ID, gomock.Any()).Do(func(triggerID string, version *moira.TriggerVersion) {
+			restoreVersion = version
+		}).Return(nil)
+		response, err := RestoreTriggerVersion(dataBase, trigger.ID, 3, "user", "")
+		So(err, ShouldBeNil)
+		So(response, ShouldResemble, &dto.SaveTriggerResponse{ID: trigger.ID, Message: "trigger restored"})
+		So(lastCheck.Metrics, ShouldContainKey, "my.metric")
+		So(restoreVersion.Action, ShouldEqual, moira.TriggerActionRestore)
+		So(restoreVersion.Author, ShouldEqual, "user")
+		So(restoreVersion.Comment, ShouldEqual, "Restored version 3")
+		So(restoreVersion.Trigger, ShouldResemble, trigger)
+	})
+
+	Convey("Version does not exist", t, func() {
+		dataBase.EXPECT().GetTriggerVersion(trigger.ID, int64(5)).Return(moira.TriggerVersion{}, database.ErrNil)
+		response, err := RestoreTriggerVersion(dataBase, trigger.ID, 5, "user", "")
+		So(err, ShouldResemble, api.ErrorNotFound("Version 5 of trigger 'trigger' not found"))
+		So(response, ShouldBeNil)
+	})
+
+	Convey("Snapshot owned by other team", t, func() {
+		teamTrigger := trigger
+		teamTrigger.TeamID = testTeam.ID
+		dataBase.EXPECT().GetTriggerVersion(trigger.ID, int64(1)).Return(moira.TriggerVersion{Version: 1, Trigger: teamTrigger}, nil)
+		dataBase.EXPECT().GetTeam(testTeam.ID).Return(testTeam, nil)
+		response, err := RestoreTriggerVersion(dataBase, trigger.ID, 1, "other", "")
+		So(err, ShouldResemble, api.ErrorForbidden("You have not permissions"))
+		So(response, ShouldBeNil)
+	})
+}
@@ -0,0 +1,125 @@
+package controller
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+)
+
+var triggerSearchStates = []string{"OK", "WARN", "ERROR", "NODATA", "EXCEPTION"}
+
+// ParseTriggerSearchQuery parses trigger search query and sort order
+// Query consists of space separated words matched against trigger name or description and field filters:
+// name:<text>, target:<text>, tag:<tag>, state:<state>[,<state>], owner:<team id>[,<team id>], maintenance:<true|false>.
+// Values with spaces must be quoted, for example name:"disk usage".
+// Sort order is one of name, score or event with optional minus prefix for descending order
+func ParseTriggerSearchQuery(searchText string, sortOrder string) (moira.TriggerSearchQuery, *api.ErrorResponse) {
+	query := moira.TriggerSearchQuery{}
+	terms, err := splitTriggerSearchText(searchText)
+	if err != nil {
+		return query, api.ErrorInvalidRequest(err)
+	}
+	for _, term := range terms {
+		if term.field == "" {
+			query.Text = append(query.Text, term.value)
+			continue
+		}
+		if term.value == "" {
+			return query, api.ErrorInvalidRequest(fmt.Errorf("Search field '%s' has empty value", term.field))
+		}
+		switch strings.ToLower(term.field) {
+		case "name":
+			query.Names = append(query.Names, term.value)
+		case "target", "pattern":
+			query.Targets = append(query.Targets, term.value)
+		case "tag":
+			query.Tags = append(query.Tags, term.value)
+		case "state":
+			for _, state := range strings.Split(term.value, ",") {
+				state = strings.ToUpper(state)
+				if !isTriggerSearchState(state) {
+					return query, api.ErrorInvalidRequest(fmt.Errorf("Unknown trigger state '%s'", state))
+				}
+				query.States = append(query.States, state)
+			}
+		case "owner", "team":
+			query.Owners = append(query.Owners, strings.Split(term.value, ",")...)
+		case "maintenance":
+			maintenance, err := strconv.ParseBool(term.value)
+			if err != nil {
+				return query, api.ErrorInvalidRequest(fmt.Errorf("Invalid maintenance value '%s'", term.value))
+			}
+			query.Maintenance = &maintenance
+		default:
+			return query, api.ErrorInvalidRequest(fmt.Errorf("Unknown search field '%s'", term.field))
+		}
+	}
+
+	if sortOrder != "" {
+		query.SortDesc = strings.HasPrefix(sortOrder, "-")
+		query.Sort = strings.TrimPrefix(sortOrder, "-")
+		switch query.Sort {
+		case moira.TriggerSortName, moira.TriggerSortScore, moira.TriggerSortEventTime:
+		default:
+			return query, api.ErrorInvalidRequest(fmt.Errorf("Unknown sort field '%s'", query.Sort))
+		}
+	}
+	return query, nil
+}
+
+type triggerSearchTerm struct {
+	field string
+	value string
+}
+
+// splitTriggerSearchText splits search text to terms by spaces outside of quotes
+func splitTriggerSearchText(searchText string) ([]triggerSearchTerm, error) {
+	terms := make([]triggerSearchTerm, 0)
+	var term triggerSearchTerm
+	var value []rune
+	inQuotes, inTerm, quoted := false, false, false
+	for _, char := range searchText {
+		switch {
+		case char == '"':
+			inQuotes = !inQuotes
+			inTerm, quoted = true, true
+		case inQuotes:
+			value = append(value, char)
+		case unicode.IsSpace(char):
+			if inTerm {
+				term.value = string(value)
+				terms = append(terms, term)
+			}
+			term, value = triggerSearchTerm{}, nil
+			inTerm, quoted = false, false
+		case char == ':' && term.field == "" && !quoted:
+			term.field = string(value)
+			value = nil
+			inTerm = true
+		default:
+			value = append(value, char)
+			inTerm = true
+		}
+	}
+	if inQuotes {
+		return nil, fmt.Errorf("Search query has unclosed quote")
+	}
+	if inTerm {
+		term.value = string(value)
+		terms = append(terms, term)
+	}
+	return terms, nil
+}
+
+func isTriggerSearchState(state string) bool {
+	for _, searchState := range triggerSearchStates {
+		if state == searchState {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,95 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestParseTriggerSearchQuery(t *testing.T) {
+	Convey("Empty query", t, func() {
+		query, err := ParseTriggerSearchQuery("", "")
+		So(err, ShouldBeNil)
+		So(query, ShouldResemble, moira.TriggerSearchQuery{})
+	})
+
+	Convey("Query with text and fields", t, func() {
+		maintenance := true
+		query, err := ParseTriggerSearchQuery(`cpu  "high load" name:"disk usage" target:Servers.* tag:prod state:error,warn owner:team1 maintenance:true`, "-event")
+		So(err, ShouldBeNil)
+		So(query, ShouldResemble, moira.TriggerSearchQuery{
+			Text:        []string{"cpu", "high load"},
+			Names:       []string{"disk usage"},
+			Targets:     []string{"Servers.*"},
+			Tags:        []string{"prod"},
+			States:      []string{"ERROR", "WARN"},
+			Owners:      []string{"team1"},
+			Maintenance: &maintenance,
+			Sort:        moira.TriggerSortEventTime,
+			SortDesc:    true,
+		})
+	})
+
+	Convey("Quoted colon is not a field separator", t, func() {
+		query, err := ParseTriggerSearchQuery(`"host:port" tag:"a:b"`, "name")
+		So(err, ShouldBeNil)
+		So(query, ShouldResemble, moira.TriggerSearchQuery{
+			Text: []string{"host:port"},
+			Tags: []string{"a:b"},
+			Sort: moira.TriggerSortName,
+		})
+	})
+
+	Convey("Invalid queries", t, func() {
+		_, err := ParseTriggerSearchQuery(`name:"disk`, "")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Search query has unclosed quote")))
+		_, err = ParseTriggerSearchQuery("author:user", "")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Unknown search field 'author'")))
+		_, err = ParseTriggerSearchQuery("name:", "")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Search field 'name' has empty value")))
+		_, err = ParseTriggerSearchQuery("state:BAD", "")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Unknown trigger state 'BAD'")))
+		_, err = ParseTriggerSearchQuery("maintenance:sometimes", "")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Invalid maintenance value 'sometimes'")))
+		_, err = ParseTriggerSearchQuery("", "-created")
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Unknown sort field 'created'")))
+	})
+}
+
+func TestSearchTriggerPage(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	query := moira.TriggerSearchQuery{Text: []string{"cpu"}, Sort: moira.TriggerSortName}
+	var page int64 = 1
+	var size int64 = 2
+	var total int64 = 3
+
+	Convey("Found triggers page", t, func() {
+		database.EXPECT().SearchTriggerIDs(query).Return([]string{"trigger1", "trigger2", "trigger3"}, nil)
+		database.EXPECT().GetTriggerChecks([]string{"trigger3"}).Return([]*moira.TriggerCheck{{Trigger: moira.Trigger{ID: "trigger3"}}}, nil)
+		list, err := SearchTriggerPage(database, page, size, query)
+		So(err, ShouldBeNil)
+		So(list, ShouldResemble, &dto.TriggersList{
+			List:  []moira.TriggerCheck{{Trigger: moira.Trigger{ID: "trigger3"}}},
+			Total: &total,
+			Page:  &page,
+			Size:  &size,
+		})
+	})
+
+	Convey("Error SearchTriggerIDs", t, func() {
+		expected := fmt.Errorf("SearchTriggerIDs error")
+		database.EXPECT().SearchTriggerIDs(query).Return(nil, expected)
+		list, err := SearchTriggerPage(database, page, size, query)
+		So(err, ShouldResemble, api.ErrorInternalServer(expected))
+		So(list, ShouldBeNil)
+	})
+}
@@ -28,7 +28,8 @@ func TestUpdateTrigger(t *testing.T) {
 		dataBase.EXPECT().GetTriggerLastCheck(gomock.Any()).Return(moira.CheckData{}, database.ErrNil)
 		dataBase.EXPECT().SetTriggerLastCheck(gomock.Any(), gomock.Any()).Return(nil)
 		dataBase.EXPECT().SaveTrigger(gomock.Any(), trigger).Return(nil)
-		resp, err := UpdateTrigger(dataBase, &triggerModel, triggerModel.ID, make(map[string]bool))
+		dataBase.EXPECT().AddTriggerVersion(triggerModel.ID, gomock.Any()).Return(nil)
+		resp, err := UpdateTrigger(dataBase, &triggerModel, triggerModel.ID, make(map[string]bool), "user", "")
 		So(err, ShouldBeNil)
 		So(resp.Message, ShouldResemble, "trigger updated")
 	})
@@ -36,7 +37,7 @@ func TestUpdateTrigger(t *testing.T) {
 	Convey("Trigger does not exists", t, func() {
 		trigger := dto.TriggerModel{ID: uuid.NewV4().String()}
 		dataBase.EXPECT().GetTrigger(trigger.ID).Return(moira.Trigger{}, database.ErrNil)
-		resp, err := UpdateTrigger(dataBase, &trigger, trigger.ID, make(map[string]bool))
+		resp, err := UpdateTrigger(dataBase, &trigger, trigger.ID, make(map[string]bool), "user", "")
 		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger with ID = '%s' does not exists", trigger.ID)))
 		So(resp, ShouldBeNil)
 	})
@@ -45,7 +46,7 @@ func TestUpdateTrigger(t *testing.T) {
 		trigger := dto.TriggerModel{ID: uuid.NewV4().String()}
 		expected := fmt.Errorf("Soo bad trigger")
 		dataBase.EXPECT().GetTrigger(trigger.ID).Return(moira.Trigger{}, expected)
-		resp, err := UpdateTrigger(dataBase, &trigger, trigger.ID, make(map[string]bool))
+		resp, err := UpdateTrigger(dataBase, &trigger, trigger.ID, make(map[string]bool), "user", "")
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 		So(resp, ShouldBeNil)
 	})
@@ -208,24 +209,28 @@ func TestRemoveTrigger(t *testing.T) {
 	triggerID := uuid.NewV4().String()
 
 	Convey("Success", t, func() {
+		dataBase.EXPECT().GetTrigger(triggerID).Return(moira.Trigger{ID: triggerID}, nil)
 		dataBase.EXPECT().RemoveTrigger(triggerID).Return(nil)
 		dataBase.EXPECT().RemoveTriggerLastCheck(triggerID).Return(nil)
-		err := RemoveTrigger(dataBase, triggerID)
+		dataBase.EXPECT().AddTriggerVersion(triggerID, gomock.Any()).Return(nil)
+		err := RemoveTrigger(dataBase, triggerID, "user", "")
 		So(err, ShouldBeNil)
 	})
 
 	Convey("Error remove trigger", t, func() {
 		expected := fmt.Errorf("Oooops! Error delete")
+		dataBase.EXPECT().GetTrigger(triggerID).Return(moira.Trigger{ID: triggerID}, nil)
 		dataBase.EXPECT().RemoveTrigger(triggerID).Return(expected)
-		err := RemoveTrigger(dataBase, triggerID)
+		err := RemoveTrigger(dataBase, triggerID, "user", "")
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 	})
 
 	Convey("Error remove last check", t, func() {
 		expected := fmt.Errorf("Oooops! Error delete")
+		dataBase.EXPECT().GetTrigger(triggerID).Return(moira.Trigger{ID: triggerID}, nil)
 		dataBase.EXPECT().RemoveTrigger(triggerID).Return(nil)
 		dataBase.EXPECT().RemoveTriggerLastCheck(triggerID).Return(expected)
-		err := RemoveTrigger(dataBase, triggerID)
+		err := RemoveTrigger(dataBase, triggerID, "user", "")
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 	})
 }
@@ -340,6 +345,21 @@ func TestDeleteTriggerMetric(t *testing.T) {
 		So(expectedLastCheck, ShouldResemble, emptyLastCheck)
 	})
 
+	Convey("Success delete tagged series from last check and series tags index", t, func() {
+		metric := "cpu;dc=eu1"
+		expectedLastCheck := moira.CheckData{Metrics: map[string]moira.MetricState{metric: {}}}
+		dataBase.EXPECT().GetTrigger(triggerID).Return(trigger, nil)
+		dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10).Return(nil)
+		dataBase.EXPECT().DeleteTriggerCheckLock(triggerID)
+		dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(expectedLastCheck, nil)
+		dataBase.EXPECT().RemovePatternsMetrics(trigger.Patterns).Return(nil)
+		dataBase.EXPECT().RemoveSeriesTags(metric).Return(nil)
+		dataBase.EXPECT().SetTriggerLastCheck(triggerID, &expectedLastCheck)
+		err := DeleteTriggerMetric(dataBase, metric, triggerID)
+		So(err, ShouldBeNil)
+		So(expectedLastCheck, ShouldResemble, emptyLastCheck)
+	})
+
 	Convey("Success delete nothing to delete", t, func() {
 		expectedLastCheck := emptyLastCheck
 		dataBase.EXPECT().GetTrigger(triggerID).Return(trigger, nil)
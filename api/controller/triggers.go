@@ -11,8 +11,8 @@ import (
 	"github.com/moira-alert/moira/database"
 )
 
-// CreateTrigger creates new trigger
-func CreateTrigger(dataBase moira.Database, trigger *dto.TriggerModel, timeSeriesNames map[string]bool) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
+// CreateTrigger creates new trigger and stores its first version in trigger history
+func CreateTrigger(dataBase moira.Database, trigger *dto.TriggerModel, timeSeriesNames map[string]bool, userLogin string, comment string) (*dto.SaveTriggerResponse, *api.ErrorResponse) {
 	if trigger.ID == "" {
 		trigger.ID = uuid.NewV4().String()
 	} else {
@@ -24,11 +24,16 @@ func CreateTrigger(dataBase moira.Database, trigger *dto.TriggerModel, timeSerie
 			return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger with this ID already exists"))
 		}
 	}
-	resp, err := saveTrigger(dataBase, trigger.ToMoiraTrigger(), trigger.ID, timeSeriesNames)
-	if resp != nil {
-		resp.Message = "trigger created"
+	moiraTrigger := trigger.ToMoiraTrigger()
+	resp, err := saveTrigger(dataBase, moiraTrigger, trigger.ID, timeSeriesNames)
+	if err != nil {
+		return nil, err
+	}
+	if err := addTriggerVersion(dataBase, moiraTrigger, moira.TriggerActionCreate, userLogin, comment); err != nil {
+		return nil, err
 	}
-	return resp, err
+	resp.Message = "trigger created"
+	return resp, nil
 }
 
 func isTriggerExists(dataBase moira.Database, triggerID string) (bool, error) {
@@ -69,6 +74,19 @@ func GetTriggerPage(database moira.Database, page int64, size int64, onlyErrors
 	if err != nil {
 		return nil, api.ErrorInternalServer(err)
 	}
+	return getTriggerPage(database, triggerIDs, page, size)
+}
+
+// SearchTriggerPage gets page of triggers found by search query
+func SearchTriggerPage(database moira.Database, page int64, size int64, query moira.TriggerSearchQuery) (*dto.TriggersList, *api.ErrorResponse) {
+	triggerIDs, err := database.SearchTriggerIDs(query)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	return getTriggerPage(database, triggerIDs, page, size)
+}
+
+func getTriggerPage(database moira.Database, triggerIDs []string, page int64, size int64) (*dto.TriggersList, *api.ErrorResponse) {
 	total := int64(len(triggerIDs))
 	triggerIDs = getTriggerIdsRange(triggerIDs, total, page, size)
 	triggerChecks, err := database.GetTriggerChecks(triggerIDs)
@@ -0,0 +1,225 @@
+package controller
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+)
+
+// triggerBulkChange is prepared change of single trigger, model is nil if trigger is deleted
+type triggerBulkChange struct {
+	trigger            *moira.Trigger
+	model              *dto.TriggerModel
+	metricsMaintenance map[string]int64
+}
+
+// ApplyTriggersBulkOperation applies operation to selected triggers, in dry run mode changes are only returned
+// Every changed trigger is checked by validate func, nothing is changed if any trigger is invalid
+func ApplyTriggersBulkOperation(dataBase moira.Database, operation *dto.TriggersBulkOperation, validate func(trigger *dto.Trigger) error, userLogin string, comment string, dryRun bool) (*dto.TriggersBulkResult, *api.ErrorResponse) {
+	triggers, errorResponse := getTriggersBulkSelection(dataBase, operation.Selection)
+	if errorResponse != nil {
+		return nil, errorResponse
+	}
+	triggerIDs := make([]string, 0, len(triggers))
+	for _, trigger := range triggers {
+		triggerIDs = append(triggerIDs, trigger.ID)
+	}
+	if errorResponse := CheckUserPermissionsForTriggers(dataBase, triggerIDs, userLogin); errorResponse != nil {
+		return nil, errorResponse
+	}
+
+	result := &dto.TriggersBulkResult{DryRun: dryRun, List: make([]dto.TriggerBulkChange, 0, len(triggers))}
+	changes := make([]*triggerBulkChange, 0, len(triggers))
+	invalid := -1
+	for _, trigger := range triggers {
+		change, err := prepareTriggerBulkChange(dataBase, trigger, operation)
+		if err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		resultChange, err := getTriggerBulkResultChange(change, operation, validate)
+		if err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		result.List = append(result.List, resultChange)
+		if resultChange.Error != "" && invalid < 0 {
+			invalid = len(result.List) - 1
+		}
+		changes = append(changes, change)
+	}
+	if dryRun {
+		return result, nil
+	}
+	if invalid >= 0 {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("Trigger '%s' is invalid: %s", result.List[invalid].TriggerID, result.List[invalid].Error))
+	}
+
+	if comment == "" {
+		comment = fmt.Sprintf("Bulk operation '%s'", operation.Operation)
+	}
+	for i, change := range changes {
+		newTriggerID, errorResponse := applyTriggerBulkChange(dataBase, change, operation.Operation, userLogin, comment)
+		if errorResponse != nil {
+			return nil, errorResponse
+		}
+		result.List[i].NewTriggerID = newTriggerID
+	}
+	return result, nil
+}
+
+// getTriggersBulkSelection gets triggers selected by ids or by tags and search query
+func getTriggersBulkSelection(dataBase moira.Database, selection dto.TriggersBulkSelection) ([]*moira.Trigger, *api.ErrorResponse) {
+	triggerIDs := selection.IDs
+	if len(triggerIDs) == 0 {
+		query, errorResponse := ParseTriggerSearchQuery(selection.Query, "")
+		if errorResponse != nil {
+			return nil, errorResponse
+		}
+		query.Tags = append(query.Tags, selection.Tags...)
+		query.Sort = moira.TriggerSortName
+		var err error
+		if triggerIDs, err = dataBase.SearchTriggerIDs(query); err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+	}
+	triggers, err := dataBase.GetTriggers(triggerIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	for i, trigger := range triggers {
+		if trigger == nil {
+			return nil, api.ErrorNotFound(fmt.Sprintf("Trigger '%s' not found", triggerIDs[i]))
+		}
+		trigger.ID = triggerIDs[i]
+	}
+	return triggers, nil
+}
+
+func prepareTriggerBulkChange(dataBase moira.Database, trigger *moira.Trigger, operation *dto.TriggersBulkOperation) (*triggerBulkChange, error) {
+	change := &triggerBulkChange{trigger: trigger}
+	if operation.Operation == dto.TriggersBulkDelete {
+		return change, nil
+	}
+	if operation.Operation == dto.TriggersBulkSetMaintenance {
+		lastCheck, err := dataBase.GetTriggerLastCheck(trigger.ID)
+		if err != nil && err != database.ErrNil {
+			return nil, err
+		}
+		change.metricsMaintenance = make(map[string]int64)
+		for metric := range lastCheck.Metrics {
+			change.metricsMaintenance[metric] = operation.Maintenance
+		}
+		return change, nil
+	}
+
+	model := dto.CreateTriggerModel(trigger)
+	model.Tags = append(make([]string, 0, len(trigger.Tags)), trigger.Tags...)
+	switch operation.Operation {
+	case dto.TriggersBulkAddTags:
+		for _, tag := range operation.Tags {
+			if !containsString(model.Tags, tag) {
+				model.Tags = append(model.Tags, tag)
+			}
+		}
+	case dto.TriggersBulkRemoveTags:
+		tags := make([]string, 0, len(model.Tags))
+		for _, tag := range model.Tags {
+			if !containsString(operation.Tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+		model.Tags = tags
+	case dto.TriggersBulkSetThresholds:
+		if operation.WarnValue != nil {
+			model.WarnValue = operation.WarnValue
+		}
+		if operation.ErrorValue != nil {
+			model.ErrorValue = operation.ErrorValue
+		}
+	case dto.TriggersBulkSetTTL:
+		if operation.TTL != nil {
+			model.TTL = *operation.TTL
+		}
+		if operation.TTLState != nil {
+			model.TTLState = operation.TTLState
+		}
+	case dto.TriggersBulkClone:
+		model.ID = ""
+		model.Name = fmt.Sprintf("%s (copy)", trigger.Name)
+	}
+	change.model = &model
+	return change, nil
+}
+
+// getTriggerBulkResultChange validates changed trigger and describes its changes
+func getTriggerBulkResultChange(change *triggerBulkChange, operation *dto.TriggersBulkOperation, validate func(trigger *dto.Trigger) error) (dto.TriggerBulkChange, error) {
+	resultChange := dto.TriggerBulkChange{
+		TriggerID: change.trigger.ID,
+		Name:      change.trigger.Name,
+		Changes:   make([]dto.TriggerFieldChange, 0),
+	}
+	if change.metricsMaintenance != nil {
+		metrics := make([]string, 0, len(change.metricsMaintenance))
+		for metric := range change.metricsMaintenance {
+			metrics = append(metrics, metric)
+		}
+		sort.Strings(metrics)
+		for _, metric := range metrics {
+			resultChange.Changes = append(resultChange.Changes, dto.TriggerFieldChange{
+				Field:    fmt.Sprintf("maintenance.%s", metric),
+				NewValue: change.metricsMaintenance[metric],
+			})
+		}
+		return resultChange, nil
+	}
+
+	original := dto.CreateTriggerModel(change.trigger)
+	var previous *dto.TriggerModel
+	if operation.Operation != dto.TriggersBulkClone {
+		previous = &original
+	}
+	if change.model != nil {
+		trigger := &dto.Trigger{TriggerModel: *change.model}
+		if err := validate(trigger); err != nil {
+			resultChange.Error = err.Error()
+		}
+		*change.model = trigger.TriggerModel
+	}
+	changes, err := getTriggerChanges(previous, change.model)
+	if err != nil {
+		return resultChange, err
+	}
+	resultChange.Changes = changes
+	return resultChange, nil
+}
+
+// applyTriggerBulkChange saves prepared change and returns new trigger id if trigger was cloned
+func applyTriggerBulkChange(dataBase moira.Database, change *triggerBulkChange, operation string, userLogin string, comment string) (string, *api.ErrorResponse) {
+	switch operation {
+	case dto.TriggersBulkDelete:
+		return "", RemoveTrigger(dataBase, change.trigger.ID, userLogin, comment)
+	case dto.TriggersBulkSetMaintenance:
+		return "", SetMetricsMaintenance(dataBase, change.trigger.ID, dto.MetricsMaintenance(change.metricsMaintenance))
+	case dto.TriggersBulkClone:
+		response, errorResponse := CreateTrigger(dataBase, change.model, nil, userLogin, comment)
+		if errorResponse != nil {
+			return "", errorResponse
+		}
+		return response.ID, nil
+	default:
+		_, errorResponse := UpdateTrigger(dataBase, change.model, change.trigger.ID, nil, userLogin, comment)
+		return "", errorResponse
+	}
+}
+
+func containsString(values []string, expected string) bool {
+	for _, value := range values {
+		if value == expected {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,129 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestApplyTriggersBulkOperation(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	warnValue, errorValue, newWarnValue := float64(10), float64(20), float64(15)
+	trigger1 := moira.Trigger{ID: "trigger1", Name: "cpu", Targets: []string{"cpu"}, WarnValue: &warnValue, ErrorValue: &errorValue, Tags: []string{"old", "prod"}, Patterns: []string{"cpu"}}
+	trigger2 := moira.Trigger{ID: "trigger2", Name: "disk", Targets: []string{"disk"}, WarnValue: &warnValue, ErrorValue: &errorValue, Tags: []string{"prod"}, Patterns: []string{"disk"}}
+	triggerIDs := []string{trigger1.ID, trigger2.ID}
+	validate := func(trigger *dto.Trigger) error {
+		return nil
+	}
+	expectSelection := func() {
+		dataBase.EXPECT().GetTriggers(triggerIDs).Return([]*moira.Trigger{&trigger1, &trigger2}, nil).Times(2)
+	}
+
+	Convey("Dry run should return changes without saving", t, func() {
+		expectSelection()
+		operation := &dto.TriggersBulkOperation{Selection: dto.TriggersBulkSelection{IDs: triggerIDs}, Operation: dto.TriggersBulkRemoveTags, Tags: []string{"old"}}
+		result, err := ApplyTriggersBulkOperation(dataBase, operation, validate, "user", "", true)
+		So(err, ShouldBeNil)
+		So(result, ShouldResemble, &dto.TriggersBulkResult{
+			DryRun: true,
+			List: []dto.TriggerBulkChange{
+				{TriggerID: "trigger1", Name: "cpu", Changes: []dto.TriggerFieldChange{{Field: "tags", OldValue: []interface{}{"old", "prod"}, NewValue: []interface{}{"prod"}}}},
+				{TriggerID: "trigger2", Name: "disk", Changes: []dto.TriggerFieldChange{}},
+			},
+		})
+	})
+
+	Convey("Invalid trigger should be reported in dry run and should stop operation", t, func() {
+		invalidate := func(trigger *dto.Trigger) error {
+			if trigger.ID == "trigger2" {
+				return fmt.Errorf("invalid expression")
+			}
+			return nil
+		}
+		operation := &dto.TriggersBulkOperation{Selection: dto.TriggersBulkSelection{IDs: triggerIDs}, Operation: dto.TriggersBulkSetThresholds, WarnValue: &newWarnValue}
+
+		expectSelection()
+		result, err := ApplyTriggersBulkOperation(dataBase, operation, invalidate, "user", "", true)
+		So(err, ShouldBeNil)
+		So(result.List[0].Error, ShouldBeEmpty)
+		So(result.List[1].Error, ShouldEqual, "invalid expression")
+
+		expectSelection()
+		result, err = ApplyTriggersBulkOperation(dataBase, operation, invalidate, "user", "", false)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger 'trigger2' is invalid: invalid expression")))
+		So(result, ShouldBeNil)
+	})
+
+	Convey("Set thresholds should update every trigger with history comment", t, func() {
+		expectSelection()
+		operation := &dto.TriggersBulkOperation{Selection: dto.TriggersBulkSelection{IDs: triggerIDs}, Operation: dto.TriggersBulkSetThresholds, WarnValue: &newWarnValue}
+		for _, triggerID := range triggerIDs {
+			dataBase.EXPECT().GetTrigger(triggerID).Return(moira.Trigger{}, nil)
+			dataBase.EXPECT().AcquireTriggerCheckLock(triggerID, 10).Return(nil)
+			dataBase.EXPECT().DeleteTriggerCheckLock(triggerID).Return(nil)
+			dataBase.EXPECT().GetTriggerLastCheck(triggerID).Return(moira.CheckData{}, database.ErrNil)
+			dataBase.EXPECT().SetTriggerLastCheck(triggerID, gomock.Any()).Return(nil)
+			dataBase.EXPECT().SaveTrigger(triggerID, gomock.Any()).Do(func(triggerID string, trigger *moira.Trigger) {
+				So(*trigger.WarnValue, ShouldEqual, newWarnValue)
+				So(*trigger.ErrorValue, ShouldEqual, errorValue)
+			}).Return(nil)
+			dataBase.EXPECT().AddTriggerVersion(triggerID, gomock.Any()).Do(func(triggerID string, version *moira.TriggerVersion) {
+				So(version.Action, ShouldEqual, moira.TriggerActionUpdate)
+				So(version.Comment, ShouldEqual, "Bulk operation 'set_thresholds'")
+			}).Return(nil)
+		}
+		result, err := ApplyTriggersBulkOperation(dataBase, operation, validate, "user", "", false)
+		So(err, ShouldBeNil)
+		So(result.DryRun, ShouldBeFalse)
+		So(result.List, ShouldHaveLength, 2)
+	})
+
+	Convey("Set maintenance should set it for all trigger metrics", t, func() {
+		dataBase.EXPECT().SearchTriggerIDs(moira.TriggerSearchQuery{Tags: []string{"prod"}, States: []string{"ERROR"}, Sort: moira.TriggerSortName}).Return([]string{trigger1.ID}, nil)
+		dataBase.EXPECT().GetTriggers([]string{trigger1.ID}).Return([]*moira.Trigger{&trigger1}, nil).Times(2)
+		dataBase.EXPECT().GetTriggerLastCheck(trigger1.ID).Return(moira.CheckData{Metrics: map[string]moira.MetricState{"cpu": {}}}, nil)
+		dataBase.EXPECT().SetTriggerCheckMetricsMaintenance(trigger1.ID, map[string]int64{"cpu": 1000}).Return(nil)
+		operation := &dto.TriggersBulkOperation{Selection: dto.TriggersBulkSelection{Tags: []string{"prod"}, Query: "state:error"}, Operation: dto.TriggersBulkSetMaintenance, Maintenance: 1000}
+		result, err := ApplyTriggersBulkOperation(dataBase, operation, validate, "user", "", false)
+		So(err, ShouldBeNil)
+		So(result.List, ShouldResemble, []dto.TriggerBulkChange{
+			{TriggerID: "trigger1", Name: "cpu", Changes: []dto.TriggerFieldChange{{Field: "maintenance.cpu", NewValue: int64(1000)}}},
+		})
+	})
+
+	Convey("Clone should create triggers with new ids", t, func() {
+		dataBase.EXPECT().GetTriggers([]string{trigger1.ID}).Return([]*moira.Trigger{&trigger1}, nil).Times(2)
+		dataBase.EXPECT().AcquireTriggerCheckLock(gomock.Any(), 10).Return(nil)
+		dataBase.EXPECT().DeleteTriggerCheckLock(gomock.Any()).Return(nil)
+		dataBase.EXPECT().GetTriggerLastCheck(gomock.Any()).Return(moira.CheckData{}, database.ErrNil)
+		dataBase.EXPECT().SetTriggerLastCheck(gomock.Any(), gomock.Any()).Return(nil)
+		dataBase.EXPECT().SaveTrigger(gomock.Any(), gomock.Any()).Do(func(triggerID string, trigger *moira.Trigger) {
+			So(triggerID, ShouldNotEqual, trigger1.ID)
+			So(trigger.Name, ShouldEqual, "cpu (copy)")
+		}).Return(nil)
+		dataBase.EXPECT().AddTriggerVersion(gomock.Any(), gomock.Any()).Return(nil)
+		operation := &dto.TriggersBulkOperation{Selection: dto.TriggersBulkSelection{IDs: []string{trigger1.ID}}, Operation: dto.TriggersBulkClone}
+		result, err := ApplyTriggersBulkOperation(dataBase, operation, validate, "user", "copy", false)
+		So(err, ShouldBeNil)
+		So(result.List[0].NewTriggerID, ShouldNotBeEmpty)
+		So(result.List[0].NewTriggerID, ShouldNotEqual, trigger1.ID)
+	})
+
+	Convey("Unknown trigger should return not found", t, func() {
+		dataBase.EXPECT().GetTriggers([]string{"unknown"}).Return([]*moira.Trigger{nil}, nil)
+		operation := &dto.TriggersBulkOperation{Selection: dto.TriggersBulkSelection{IDs: []string{"unknown"}}, Operation: dto.TriggersBulkDelete}
+		result, err := ApplyTriggersBulkOperation(dataBase, operation, validate, "user", "", false)
+		So(err, ShouldResemble, api.ErrorNotFound("Trigger 'unknown' not found"))
+		So(result, ShouldBeNil)
+	})
+}
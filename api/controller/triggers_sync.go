@@ -0,0 +1,234 @@
+package controller
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+)
+
+// PlanTriggersSync compares trigger definitions from given sources of namespace with stored triggers
+// Sources are stored in sync mark prefixed with namespace, so syncs of different namespaces do not affect each other triggers
+// Every definition is checked by validate func. Managed triggers of namespace missing in definitions are deleted,
+// triggers of other namespaces and unmanaged triggers are never deleted
+// If previousNamespace is not empty, its triggers are moved to namespace as if they were synced from it
+func PlanTriggersSync(dataBase moira.Database, namespace string, previousNamespace string, sources map[string]*dto.Export, validate func(trigger *dto.Trigger) (map[string]bool, error)) (*dto.TriggersSyncPlan, *api.ErrorResponse) {
+	if namespace == "" {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("Sync namespace is required"))
+	}
+	namespaces := []string{namespace}
+	if previousNamespace != "" {
+		namespaces = append(namespaces, previousNamespace)
+	}
+	sourceNames := make([]string, 0, len(sources))
+	for name := range sources {
+		sourceNames = append(sourceNames, name)
+	}
+	sort.Strings(sourceNames)
+
+	plan := &dto.TriggersSyncPlan{List: make([]dto.TriggerSyncAction, 0)}
+	definedSources := make(map[string]string)
+	for _, name := range sourceNames {
+		source := getSyncSource(namespace, name)
+		for _, model := range sources[name].Triggers {
+			if model.ID == "" {
+				return nil, api.ErrorInvalidRequest(fmt.Errorf("%s: trigger '%s' has no id", source, model.Name))
+			}
+			if definedSource, ok := definedSources[model.ID]; ok {
+				return nil, api.ErrorInvalidRequest(fmt.Errorf("%s: trigger '%s' is already defined in %s", source, model.ID, definedSource))
+			}
+			definedSources[model.ID] = source
+
+			action, errorResponse := planTriggerSync(dataBase, namespaces, source, model, validate)
+			if errorResponse != nil {
+				return nil, errorResponse
+			}
+			if action != nil {
+				plan.List = append(plan.List, *action)
+			}
+		}
+	}
+
+	triggerIDs, err := dataBase.GetTriggerIDs()
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	sort.Strings(triggerIDs)
+	triggers, err := dataBase.GetTriggers(triggerIDs)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	for i, trigger := range triggers {
+		if trigger == nil || trigger.Managed == nil || !isSyncSourceInNamespaces(trigger.Managed.Source, namespaces) {
+			continue
+		}
+		if _, ok := definedSources[triggerIDs[i]]; ok {
+			continue
+		}
+		trigger.ID = triggerIDs[i]
+		current := dto.CreateTriggerModel(trigger)
+		action, err := newTriggerSyncAction(dto.TriggerSyncDelete, trigger.Managed.Source, &current, nil)
+		if err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		if action.ManuallyEdited, err = isTriggerManuallyEdited(&current); err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		plan.List = append(plan.List, *action)
+	}
+	return plan, nil
+}
+
+// planTriggerSync validates trigger definition and compares it with stored trigger, nil is returned if trigger is up to date
+// Trigger managed from namespace not listed in given namespaces is not taken over
+func planTriggerSync(dataBase moira.Database, namespaces []string, source string, model dto.TriggerModel, validate func(trigger *dto.Trigger) (map[string]bool, error)) (*dto.TriggerSyncAction, *api.ErrorResponse) {
+	trigger := &dto.Trigger{TriggerModel: model}
+	trigger.Managed = nil
+	timeSeriesNames, err := validate(trigger)
+	if err != nil {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("%s: trigger '%s' is invalid: %s", source, model.ID, err.Error()))
+	}
+	desired := trigger.TriggerModel
+	checksum, err := getTriggerChecksum(&desired)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	desired.Managed = &moira.TriggerManagement{Source: source, Checksum: checksum}
+
+	stored, err := dataBase.GetTrigger(model.ID)
+	if err != nil && err != database.ErrNil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	if err == database.ErrNil {
+		action, err := newTriggerSyncAction(dto.TriggerSyncCreate, source, nil, &desired)
+		if err != nil {
+			return nil, api.ErrorInternalServer(err)
+		}
+		action.TimeSeriesNames = timeSeriesNames
+		return action, nil
+	}
+
+	if stored.Managed != nil && isNamespacedSyncSource(stored.Managed.Source) && !isSyncSourceInNamespaces(stored.Managed.Source, namespaces) {
+		return nil, api.ErrorInvalidRequest(fmt.Errorf("%s: trigger '%s' is managed from %s", source, model.ID, stored.Managed.Source))
+	}
+	stored.ID = model.ID
+	current := dto.CreateTriggerModel(&stored)
+	currentChecksum, err := getTriggerChecksum(&current)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	if stored.Managed != nil && stored.Managed.Source == source && currentChecksum == checksum {
+		return nil, nil
+	}
+	action, err := newTriggerSyncAction(dto.TriggerSyncUpdate, source, &current, &desired)
+	if err != nil {
+		return nil, api.ErrorInternalServer(err)
+	}
+	action.ManuallyEdited = stored.Managed != nil && currentChecksum != stored.Managed.Checksum
+	action.TimeSeriesNames = timeSeriesNames
+	return action, nil
+}
+
+// ApplyTriggersSyncPlan saves planned changes, trigger history comments contain definition source
+func ApplyTriggersSyncPlan(dataBase moira.Database, plan *dto.TriggersSyncPlan, userLogin string) *api.ErrorResponse {
+	for _, action := range plan.List {
+		if action.Action == dto.TriggerSyncDelete {
+			comment := fmt.Sprintf("Removed from %s", action.Source)
+			if errorResponse := RemoveTrigger(dataBase, action.TriggerID, userLogin, comment); errorResponse != nil {
+				return errorResponse
+			}
+			continue
+		}
+		trigger := action.Trigger.ToMoiraTrigger()
+		trigger.Managed = action.Trigger.Managed
+		if _, errorResponse := saveTrigger(dataBase, trigger, action.TriggerID, action.TimeSeriesNames); errorResponse != nil {
+			return errorResponse
+		}
+		historyAction := moira.TriggerActionUpdate
+		if action.Action == dto.TriggerSyncCreate {
+			historyAction = moira.TriggerActionCreate
+		}
+		comment := fmt.Sprintf("Synced from %s", action.Source)
+		if errorResponse := addTriggerVersion(dataBase, trigger, historyAction, userLogin, comment); errorResponse != nil {
+			return errorResponse
+		}
+	}
+	return nil
+}
+
+// getSyncSource returns source of definitions file with given path relative to namespace root
+func getSyncSource(namespace string, name string) string {
+	return namespace + syncNamespaceSeparator + name
+}
+
+func isSyncSourceInNamespaces(source string, namespaces []string) bool {
+	for _, namespace := range namespaces {
+		if strings.HasPrefix(source, namespace+syncNamespaceSeparator) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNamespacedSyncSource checks that source is not bare file name saved by sync before namespaces were introduced
+func isNamespacedSyncSource(source string) bool {
+	return strings.Contains(source, syncNamespaceSeparator)
+}
+
+// syncNamespaceSeparator is not path separator, so nested directories synced as separate namespaces do not overlap
+const syncNamespaceSeparator = ":"
+
+func newTriggerSyncAction(actionType string, source string, current *dto.TriggerModel, desired *dto.TriggerModel) (*dto.TriggerSyncAction, error) {
+	action := &dto.TriggerSyncAction{Action: actionType, Source: source, Trigger: desired}
+	if desired != nil {
+		action.TriggerID, action.Name = desired.ID, desired.Name
+	} else {
+		action.TriggerID, action.Name = current.ID, current.Name
+	}
+	changes, err := getTriggerChanges(getTriggerSyncModel(current), getTriggerSyncModel(desired))
+	if err != nil {
+		return nil, err
+	}
+	action.Changes = changes
+	return action, nil
+}
+
+// isTriggerManuallyEdited checks that managed trigger differs from synced definition
+func isTriggerManuallyEdited(trigger *dto.TriggerModel) (bool, error) {
+	checksum, err := getTriggerChecksum(trigger)
+	if err != nil {
+		return false, err
+	}
+	return checksum != trigger.Managed.Checksum, nil
+}
+
+// getTriggerChecksum calculates checksum of trigger definition, resolved patterns and sync mark are not included
+func getTriggerChecksum(trigger *dto.TriggerModel) (string, error) {
+	definition := getTriggerSyncModel(trigger)
+	definition.Patterns = nil
+	bytes, err := json.Marshal(definition)
+	if err != nil {
+		return "", err
+	}
+	hash := sha256.Sum256(bytes)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// getTriggerSyncModel returns copy of trigger without sync mark and with sorted tags, stored tags order is not kept
+func getTriggerSyncModel(trigger *dto.TriggerModel) *dto.TriggerModel {
+	if trigger == nil {
+		return nil
+	}
+	model := *trigger
+	model.Managed = nil
+	model.Tags = append(make([]string, 0, len(trigger.Tags)), trigger.Tags...)
+	sort.Strings(model.Tags)
+	return &model
+}
@@ -0,0 +1,248 @@
+package controller
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestPlanTriggersSync(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	warnValue, errorValue, changedWarnValue := float64(10), float64(20), float64(15)
+	timeSeriesNames := map[string]bool{"cpu": true}
+	validate := func(trigger *dto.Trigger) (map[string]bool, error) {
+		trigger.Patterns = trigger.Targets
+		return timeSeriesNames, nil
+	}
+	definition := dto.TriggerModel{ID: "cpu", Name: "cpu", Targets: []string{"cpu"}, WarnValue: &warnValue, ErrorValue: &errorValue, Tags: []string{"b", "a"}}
+	synced := definition
+	synced.Patterns = []string{"cpu"}
+	checksum, err := getTriggerChecksum(&synced)
+	if err != nil {
+		t.Fatal(err)
+	}
+	management := &moira.TriggerManagement{Source: "team-a:cpu.yml", Checksum: checksum}
+	stored := synced.ToMoiraTrigger()
+	stored.Tags = []string{"a", "b"}
+	stored.Managed = management
+	sources := map[string]*dto.Export{"cpu.yml": {Triggers: []dto.TriggerModel{definition}}}
+
+	Convey("New trigger should be created with sync mark", t, func() {
+		dataBase.EXPECT().GetTrigger("cpu").Return(moira.Trigger{}, database.ErrNil)
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{}, nil)
+		dataBase.EXPECT().GetTriggers([]string{}).Return([]*moira.Trigger{}, nil)
+		plan, err := PlanTriggersSync(dataBase, "team-a", "", sources, validate)
+		So(err, ShouldBeNil)
+		So(plan.List, ShouldHaveLength, 1)
+		action := plan.List[0]
+		So(action.Action, ShouldEqual, dto.TriggerSyncCreate)
+		So(action.TriggerID, ShouldEqual, "cpu")
+		So(action.Source, ShouldEqual, "team-a:cpu.yml")
+		So(action.Changes, ShouldContain, dto.TriggerFieldChange{Field: "name", OldValue: nil, NewValue: "cpu"})
+		So(action.Trigger.Managed, ShouldResemble, management)
+		So(action.TimeSeriesNames, ShouldResemble, timeSeriesNames)
+
+		Convey("Applied plan should save trigger with sync mark and history comment", func() {
+			dataBase.EXPECT().AcquireTriggerCheckLock("cpu", 10).Return(nil)
+			dataBase.EXPECT().DeleteTriggerCheckLock("cpu").Return(nil)
+			dataBase.EXPECT().GetTriggerLastCheck("cpu").Return(moira.CheckData{}, database.ErrNil)
+			dataBase.EXPECT().SetTriggerLastCheck("cpu", gomock.Any()).Return(nil)
+			dataBase.EXPECT().SaveTrigger("cpu", gomock.Any()).Do(func(triggerID string, trigger *moira.Trigger) {
+				So(trigger.Managed, ShouldResemble, management)
+			}).Return(nil)
+			dataBase.EXPECT().AddTriggerVersion("cpu", gomock.Any()).Do(func(triggerID string, version *moira.TriggerVersion) {
+				So(version.Action, ShouldEqual, moira.TriggerActionCreate)
+				So(version.Author, ShouldEqual, "sync")
+				So(version.Comment, ShouldEqual, "Synced from team-a:cpu.yml")
+			}).Return(nil)
+			So(ApplyTriggersSyncPlan(dataBase, plan, "sync"), ShouldBeNil)
+		})
+	})
+
+	Convey("Synced trigger should not be changed", t, func() {
+		dataBase.EXPECT().GetTrigger("cpu").Return(*stored, nil)
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{"cpu"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"cpu"}).Return([]*moira.Trigger{stored}, nil)
+		plan, err := PlanTriggersSync(dataBase, "team-a", "", sources, validate)
+		So(err, ShouldBeNil)
+		So(plan.List, ShouldBeEmpty)
+	})
+
+	Convey("Manually edited trigger should be updated", t, func() {
+		edited := *stored
+		edited.WarnValue = &changedWarnValue
+		dataBase.EXPECT().GetTrigger("cpu").Return(edited, nil)
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{"cpu"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"cpu"}).Return([]*moira.Trigger{&edited}, nil)
+		plan, err := PlanTriggersSync(dataBase, "team-a", "", sources, validate)
+		So(err, ShouldBeNil)
+		So(plan.List, ShouldResemble, []dto.TriggerSyncAction{{
+			Action:          dto.TriggerSyncUpdate,
+			TriggerID:       "cpu",
+			Name:            "cpu",
+			Source:          "team-a:cpu.yml",
+			ManuallyEdited:  true,
+			Changes:         []dto.TriggerFieldChange{{Field: "warn_value", OldValue: float64(15), NewValue: float64(10)}},
+			Trigger:         plan.List[0].Trigger,
+			TimeSeriesNames: timeSeriesNames,
+		}})
+	})
+
+	Convey("Only managed triggers missing in definitions should be deleted", t, func() {
+		unmanaged := moira.Trigger{ID: "unmanaged", Name: "unmanaged"}
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{"unmanaged", "cpu"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"cpu", "unmanaged"}).Return([]*moira.Trigger{stored, &unmanaged}, nil)
+		plan, err := PlanTriggersSync(dataBase, "team-a", "", map[string]*dto.Export{}, validate)
+		So(err, ShouldBeNil)
+		So(plan.List, ShouldHaveLength, 1)
+		So(plan.List[0].Action, ShouldEqual, dto.TriggerSyncDelete)
+		So(plan.List[0].TriggerID, ShouldEqual, "cpu")
+		So(plan.List[0].Source, ShouldEqual, "team-a:cpu.yml")
+		So(plan.List[0].ManuallyEdited, ShouldBeFalse)
+
+		Convey("Applied plan should remove trigger", func() {
+			dataBase.EXPECT().GetTrigger("cpu").Return(*stored, nil)
+			dataBase.EXPECT().RemoveTrigger("cpu").Return(nil)
+			dataBase.EXPECT().RemoveTriggerLastCheck("cpu").Return(nil)
+			dataBase.EXPECT().AddTriggerVersion("cpu", gomock.Any()).Do(func(triggerID string, version *moira.TriggerVersion) {
+				So(version.Action, ShouldEqual, moira.TriggerActionDelete)
+				So(version.Comment, ShouldEqual, "Removed from team-a:cpu.yml")
+			}).Return(nil)
+			So(ApplyTriggersSyncPlan(dataBase, plan, "sync"), ShouldBeNil)
+		})
+	})
+
+	Convey("Invalid definitions", t, func() {
+		withoutID := definition
+		withoutID.ID = ""
+		_, err := PlanTriggersSync(dataBase, "team-a", "", map[string]*dto.Export{"cpu.yml": {Triggers: []dto.TriggerModel{withoutID}}}, validate)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("team-a:cpu.yml: trigger 'cpu' has no id")))
+
+		dataBase.EXPECT().GetTrigger("cpu").Return(*stored, nil)
+		_, err = PlanTriggersSync(dataBase, "team-a", "", map[string]*dto.Export{"a.yml": {Triggers: []dto.TriggerModel{definition}}, "b.yml": {Triggers: []dto.TriggerModel{definition}}}, validate)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("team-a:b.yml: trigger 'cpu' is already defined in team-a:a.yml")))
+
+		invalidate := func(trigger *dto.Trigger) (map[string]bool, error) {
+			return nil, fmt.Errorf("targets is required")
+		}
+		_, err = PlanTriggersSync(dataBase, "team-a", "", sources, invalidate)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("team-a:cpu.yml: trigger 'cpu' is invalid: targets is required")))
+
+		_, err = PlanTriggersSync(dataBase, "", "", sources, validate)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Sync namespace is required")))
+	})
+
+	Convey("Trigger managed from other namespace should not be taken over", t, func() {
+		dataBase.EXPECT().GetTrigger("cpu").Return(*stored, nil)
+		_, err := PlanTriggersSync(dataBase, "team-b", "", sources, validate)
+		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("team-b:cpu.yml: trigger 'cpu' is managed from team-a:cpu.yml")))
+	})
+
+	Convey("Trigger of previous namespace should be moved to namespace", t, func() {
+		removed := *stored
+		removed.Managed = &moira.TriggerManagement{Source: "team-a:memory.yml", Checksum: checksum}
+		dataBase.EXPECT().GetTrigger("cpu").Return(*stored, nil)
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{"cpu", "memory"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"cpu", "memory"}).Return([]*moira.Trigger{stored, &removed}, nil)
+		plan, err := PlanTriggersSync(dataBase, "team-b", "team-a", sources, validate)
+		So(err, ShouldBeNil)
+		So(plan.List, ShouldHaveLength, 2)
+		So(plan.List[0].Action, ShouldEqual, dto.TriggerSyncUpdate)
+		So(plan.List[0].Trigger.Managed, ShouldResemble, &moira.TriggerManagement{Source: "team-b:cpu.yml", Checksum: checksum})
+		So(plan.List[1].Action, ShouldEqual, dto.TriggerSyncDelete)
+		So(plan.List[1].TriggerID, ShouldEqual, "memory")
+	})
+
+	Convey("Trigger synced before namespaces should be moved to namespace", t, func() {
+		legacy := *stored
+		legacy.Managed = &moira.TriggerManagement{Source: "cpu.yml", Checksum: checksum}
+		dataBase.EXPECT().GetTrigger("cpu").Return(legacy, nil)
+		dataBase.EXPECT().GetTriggerIDs().Return([]string{"cpu"}, nil)
+		dataBase.EXPECT().GetTriggers([]string{"cpu"}).Return([]*moira.Trigger{&legacy}, nil)
+		plan, err := PlanTriggersSync(dataBase, "team-a", "", sources, validate)
+		So(err, ShouldBeNil)
+		So(plan.List, ShouldHaveLength, 1)
+		So(plan.List[0].Action, ShouldEqual, dto.TriggerSyncUpdate)
+		So(plan.List[0].Trigger.Managed, ShouldResemble, management)
+	})
+}
+
+func TestPlanTriggersSyncOfTwoDirectories(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	validate := func(trigger *dto.Trigger) (map[string]bool, error) {
+		trigger.Patterns = trigger.Targets
+		return map[string]bool{}, nil
+	}
+	value := float64(10)
+	cpu := dto.TriggerModel{ID: "cpu", Name: "cpu", Targets: []string{"cpu"}, WarnValue: &value, ErrorValue: &value, Tags: []string{"a"}}
+	memory := dto.TriggerModel{ID: "memory", Name: "memory", Targets: []string{"memory"}, WarnValue: &value, ErrorValue: &value, Tags: []string{"a"}}
+	firstDirectory := map[string]*dto.Export{"triggers.yml": {Triggers: []dto.TriggerModel{cpu}}}
+	secondDirectory := map[string]*dto.Export{"triggers.yml": {Triggers: []dto.TriggerModel{memory}}}
+
+	// stored keeps triggers saved by applied plans
+	stored := make(map[string]*moira.Trigger)
+	sync := func(namespace string, sources map[string]*dto.Export) *dto.TriggersSyncPlan {
+		for _, export := range sources {
+			for _, model := range export.Triggers {
+				if trigger, ok := stored[model.ID]; ok {
+					dataBase.EXPECT().GetTrigger(model.ID).Return(*trigger, nil)
+				} else {
+					dataBase.EXPECT().GetTrigger(model.ID).Return(moira.Trigger{}, database.ErrNil)
+				}
+			}
+		}
+		triggerIDs := make([]string, 0)
+		triggers := make([]*moira.Trigger, 0)
+		for _, triggerID := range []string{"cpu", "memory"} {
+			if trigger, ok := stored[triggerID]; ok {
+				triggerIDs = append(triggerIDs, triggerID)
+				triggers = append(triggers, trigger)
+			}
+		}
+		dataBase.EXPECT().GetTriggerIDs().Return(triggerIDs, nil)
+		dataBase.EXPECT().GetTriggers(triggerIDs).Return(triggers, nil)
+		plan, err := PlanTriggersSync(dataBase, namespace, "", sources, validate)
+		So(err, ShouldBeNil)
+		for _, action := range plan.List {
+			if action.Action == dto.TriggerSyncDelete {
+				delete(stored, action.TriggerID)
+				continue
+			}
+			trigger := action.Trigger.ToMoiraTrigger()
+			trigger.Managed = action.Trigger.Managed
+			stored[action.TriggerID] = trigger
+		}
+		return plan
+	}
+
+	Convey("Directories synced one after another should not delete each other triggers", t, func() {
+		So(sync("/srv/first", firstDirectory).List, ShouldHaveLength, 1)
+		// memory trigger is created and cpu trigger of the first directory is not deleted
+		So(sync("/srv/second", secondDirectory).List, ShouldHaveLength, 1)
+		So(stored, ShouldContainKey, "cpu")
+		So(sync("/srv/first", firstDirectory).List, ShouldBeEmpty)
+		So(sync("/srv/second", secondDirectory).List, ShouldBeEmpty)
+		So(stored["cpu"].Managed.Source, ShouldEqual, "/srv/first:triggers.yml")
+		So(stored["memory"].Managed.Source, ShouldEqual, "/srv/second:triggers.yml")
+
+		Convey("Trigger removed from directory should be deleted only by its directory sync", func() {
+			plan := sync("/srv/second", map[string]*dto.Export{})
+			So(plan.List, ShouldHaveLength, 1)
+			So(plan.List[0].Action, ShouldEqual, dto.TriggerSyncDelete)
+			So(plan.List[0].TriggerID, ShouldEqual, "memory")
+			So(sync("/srv/first", firstDirectory).List, ShouldBeEmpty)
+		})
+	})
+}
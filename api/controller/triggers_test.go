@@ -25,7 +25,8 @@ func TestCreateTrigger(t *testing.T) {
 		dataBase.EXPECT().GetTriggerLastCheck(gomock.Any()).Return(moira.CheckData{}, database.ErrNil)
 		dataBase.EXPECT().SetTriggerLastCheck(gomock.Any(), gomock.Any()).Return(nil)
 		dataBase.EXPECT().SaveTrigger(gomock.Any(), gomock.Any()).Return(nil)
-		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool))
+		dataBase.EXPECT().AddTriggerVersion(gomock.Any(), gomock.Any()).Return(nil)
+		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool), "user", "")
 		So(err, ShouldBeNil)
 		So(resp.Message, ShouldResemble, "trigger created")
 	})
@@ -38,7 +39,8 @@ func TestCreateTrigger(t *testing.T) {
 		dataBase.EXPECT().GetTriggerLastCheck(gomock.Any()).Return(moira.CheckData{}, database.ErrNil)
 		dataBase.EXPECT().SetTriggerLastCheck(gomock.Any(), gomock.Any()).Return(nil)
 		dataBase.EXPECT().SaveTrigger(gomock.Any(), triggerModel.ToMoiraTrigger()).Return(nil)
-		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool))
+		dataBase.EXPECT().AddTriggerVersion(triggerModel.ID, gomock.Any()).Return(nil)
+		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool), "user", "")
 		So(err, ShouldBeNil)
 		So(resp.Message, ShouldResemble, "trigger created")
 	})
@@ -47,7 +49,7 @@ func TestCreateTrigger(t *testing.T) {
 		triggerModel := dto.TriggerModel{ID: uuid.NewV4().String()}
 		trigger := triggerModel.ToMoiraTrigger()
 		dataBase.EXPECT().GetTrigger(triggerModel.ID).Return(*trigger, nil)
-		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool))
+		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool), "user", "")
 		So(err, ShouldResemble, api.ErrorInvalidRequest(fmt.Errorf("Trigger with this ID already exists")))
 		So(resp, ShouldBeNil)
 	})
@@ -56,7 +58,7 @@ func TestCreateTrigger(t *testing.T) {
 		trigger := dto.TriggerModel{ID: uuid.NewV4().String()}
 		expected := fmt.Errorf("Soo bad trigger")
 		dataBase.EXPECT().GetTrigger(trigger.ID).Return(moira.Trigger{}, expected)
-		resp, err := CreateTrigger(dataBase, &trigger, make(map[string]bool))
+		resp, err := CreateTrigger(dataBase, &trigger, make(map[string]bool), "user", "")
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 		So(resp, ShouldBeNil)
 	})
@@ -70,7 +72,7 @@ func TestCreateTrigger(t *testing.T) {
 		dataBase.EXPECT().GetTriggerLastCheck(gomock.Any()).Return(moira.CheckData{}, database.ErrNil)
 		dataBase.EXPECT().SetTriggerLastCheck(gomock.Any(), gomock.Any()).Return(nil)
 		dataBase.EXPECT().SaveTrigger(gomock.Any(), triggerModel.ToMoiraTrigger()).Return(expected)
-		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool))
+		resp, err := CreateTrigger(dataBase, &triggerModel, make(map[string]bool), "user", "")
 		So(err, ShouldResemble, api.ErrorInternalServer(expected))
 		So(resp, ShouldBeNil)
 	})
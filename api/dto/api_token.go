@@ -0,0 +1,33 @@
+// nolint
+package dto
+
+import (
+	"fmt"
+	"net/http"
+)
+
+type APIToken struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	CreatedAt int64  `json:"created_at"`
+	Token     string `json:"token,omitempty"`
+}
+
+func (*APIToken) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+func (token *APIToken) Bind(r *http.Request) error {
+	if token.Name == "" {
+		return fmt.Errorf("Token name can not be empty")
+	}
+	return nil
+}
+
+type APITokenList struct {
+	List []*APIToken `json:"list"`
+}
+
+func (*APITokenList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
@@ -16,10 +16,12 @@ func (*ContactList) Render(w http.ResponseWriter, r *http.Request) error {
 }
 
 type Contact struct {
-	Type  string `json:"type"`
-	Value string `json:"value"`
-	ID    string `json:"id,omitempty"`
-	User  string `json:"user,omitempty"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	ID       string `json:"id,omitempty"`
+	User     string `json:"user,omitempty"`
+	TeamID   string `json:"team_id,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
 }
 
 func (*Contact) Render(w http.ResponseWriter, r *http.Request) error {
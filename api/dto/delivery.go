@@ -0,0 +1,18 @@
+// nolint
+package dto
+
+import (
+	"github.com/moira-alert/moira"
+	"net/http"
+)
+
+type DeliveriesList struct {
+	Page  int64                `json:"page"`
+	Size  int64                `json:"size"`
+	Total int64                `json:"total"`
+	List  []moira.DeliveryData `json:"list"`
+}
+
+func (*DeliveriesList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
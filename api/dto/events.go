@@ -16,3 +16,21 @@ type EventsList struct {
 func (*EventsList) Render(w http.ResponseWriter, r *http.Request) error {
 	return nil
 }
+
+type EventCounts struct {
+	From     int64        `json:"from"`
+	To       int64        `json:"to"`
+	Interval int64        `json:"interval"`
+	Total    int64        `json:"total"`
+	List     []EventCount `json:"list"`
+}
+
+func (*EventCounts) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+type EventCount struct {
+	TriggerID string `json:"trigger_id"`
+	Timestamp int64  `json:"timestamp"`
+	Count     int64  `json:"count"`
+}
@@ -0,0 +1,116 @@
+// nolint
+package dto
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"gopkg.in/yaml.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+// Export formats
+const (
+	ExportJSON = "json"
+	ExportYAML = "yaml"
+)
+
+// Export is set of trigger definitions with user contacts and subscriptions, it is also format of sync definition files
+type Export struct {
+	Triggers      []TriggerModel           `json:"triggers"`
+	Contacts      []moira.ContactData      `json:"contacts,omitempty"`
+	Subscriptions []moira.SubscriptionData `json:"subscriptions,omitempty"`
+}
+
+func (*Export) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// Encode marshals export to given format, yaml keys are the same as json keys
+func (export *Export) Encode(format string) ([]byte, error) {
+	data, err := json.MarshalIndent(export, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	switch format {
+	case ExportJSON:
+		return data, nil
+	case ExportYAML:
+		var value interface{}
+		if err := json.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		return yaml.Marshal(value)
+	default:
+		return nil, fmt.Errorf("Unknown export format '%s'", format)
+	}
+}
+
+// DecodeExport unmarshals export of given format
+func DecodeExport(data []byte, format string) (*Export, error) {
+	export := &Export{}
+	switch format {
+	case ExportJSON:
+	case ExportYAML:
+		var value interface{}
+		if err := yaml.Unmarshal(data, &value); err != nil {
+			return nil, err
+		}
+		var err error
+		if data, err = json.Marshal(convertYAMLValue(value)); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("Unknown export format '%s'", format)
+	}
+	if err := json.Unmarshal(data, export); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// convertYAMLValue converts yaml maps with interface keys to json compatible maps
+func convertYAMLValue(value interface{}) interface{} {
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(typed))
+		for key, item := range typed {
+			result[fmt.Sprint(key)] = convertYAMLValue(item)
+		}
+		return result
+	case []interface{}:
+		for i, item := range typed {
+			typed[i] = convertYAMLValue(item)
+		}
+	}
+	return value
+}
+
+// Triggers sync actions
+const (
+	TriggerSyncCreate = "create"
+	TriggerSyncUpdate = "update"
+	TriggerSyncDelete = "delete"
+)
+
+type TriggersSyncPlan struct {
+	List []TriggerSyncAction `json:"list"`
+}
+
+func (*TriggersSyncPlan) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// TriggerSyncAction is planned change of single trigger, manually edited is set if managed trigger was changed not by sync
+type TriggerSyncAction struct {
+	Action          string               `json:"action"`
+	TriggerID       string               `json:"trigger_id"`
+	Name            string               `json:"name"`
+	Source          string               `json:"source,omitempty"`
+	ManuallyEdited  bool                 `json:"manually_edited,omitempty"`
+	Changes         []TriggerFieldChange `json:"changes"`
+	Trigger         *TriggerModel        `json:"-"`
+	TimeSeriesNames map[string]bool      `json:"-"`
+}
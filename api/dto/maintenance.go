@@ -0,0 +1,27 @@
+// nolint
+package dto
+
+import (
+	"net/http"
+
+	"github.com/moira-alert/moira"
+)
+
+type MaintenanceWindowList struct {
+	List []moira.MaintenanceWindow `json:"list"`
+}
+
+func (*MaintenanceWindowList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+type MaintenanceWindow moira.MaintenanceWindow
+
+func (*MaintenanceWindow) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+func (window *MaintenanceWindow) Bind(r *http.Request) error {
+	windowData := moira.MaintenanceWindow(*window)
+	return windowData.Validate()
+}
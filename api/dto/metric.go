@@ -0,0 +1,23 @@
+// nolint
+package dto
+
+import (
+	"github.com/moira-alert/moira"
+	"net/http"
+)
+
+type MetricMatch struct {
+	Metric    string               `json:"metric"`
+	Patterns  []MatchedPattern     `json:"patterns"`
+	Retention int64                `json:"retention"`
+	Values    []*moira.MetricValue `json:"values"`
+}
+
+func (*MetricMatch) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+type MatchedPattern struct {
+	Pattern  string         `json:"pattern"`
+	Triggers []TriggerModel `json:"triggers"`
+}
@@ -28,5 +28,8 @@ func (subscription *Subscription) Bind(r *http.Request) error {
 	if len(subscription.Contacts) == 0 {
 		return fmt.Errorf("Subscription must have contacts")
 	}
-	return nil
+	if subscription.ThrottlingWindow < 0 {
+		return fmt.Errorf("Subscription throttling window can not be negative")
+	}
+	return subscription.Holidays.Validate()
 }
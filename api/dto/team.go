@@ -0,0 +1,48 @@
+// nolint
+package dto
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/moira-alert/moira"
+)
+
+type TeamList struct {
+	List []moira.Team `json:"list"`
+}
+
+func (*TeamList) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+type Team moira.Team
+
+func (*Team) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+func (team *Team) Bind(r *http.Request) error {
+	if team.Name == "" {
+		return fmt.Errorf("Team name can not be empty")
+	}
+	for login, role := range team.Members {
+		if login == "" {
+			return fmt.Errorf("Team member login can not be empty")
+		}
+		if !moira.IsValidTeamRole(role) {
+			return fmt.Errorf("Unknown role '%s' of team member %s", role, login)
+		}
+	}
+	return nil
+}
+
+type TeamSettings struct {
+	Team
+	Contacts      []moira.ContactData      `json:"contacts"`
+	Subscriptions []moira.SubscriptionData `json:"subscriptions"`
+}
+
+func (*TeamSettings) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
@@ -0,0 +1,29 @@
+// nolint
+package dto
+
+import "net/http"
+
+type TriggerHistory struct {
+	List []TriggerVersion `json:"list"`
+}
+
+func (*TriggerHistory) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+type TriggerVersion struct {
+	Version   int64                `json:"version"`
+	Action    string               `json:"action"`
+	Author    string               `json:"author"`
+	Timestamp int64                `json:"timestamp"`
+	Comment   string               `json:"comment,omitempty"`
+	Trigger   TriggerModel         `json:"trigger"`
+	Changes   []TriggerFieldChange `json:"changes"`
+}
+
+// TriggerFieldChange is difference of trigger field between version and previous one
+type TriggerFieldChange struct {
+	Field    string      `json:"field"`
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value"`
+}
@@ -29,19 +29,22 @@ type Trigger struct {
 }
 
 // TriggerModel is moira.Trigger api representation
+// Managed is set only by triggers sync and is ignored on trigger save
 type TriggerModel struct {
-	ID         string              `json:"id"`
-	Name       string              `json:"name"`
-	Desc       *string             `json:"desc,omitempty"`
-	Targets    []string            `json:"targets"`
-	WarnValue  *float64            `json:"warn_value"`
-	ErrorValue *float64            `json:"error_value"`
-	Tags       []string            `json:"tags"`
-	TTLState   *string             `json:"ttl_state,omitempty"`
-	TTL        int64               `json:"ttl,omitempty"`
-	Schedule   *moira.ScheduleData `json:"sched,omitempty"`
-	Expression string              `json:"expression"`
-	Patterns   []string            `json:"patterns"`
+	ID         string                   `json:"id"`
+	Name       string                   `json:"name"`
+	Desc       *string                  `json:"desc,omitempty"`
+	Targets    []string                 `json:"targets"`
+	WarnValue  *float64                 `json:"warn_value"`
+	ErrorValue *float64                 `json:"error_value"`
+	Tags       []string                 `json:"tags"`
+	TTLState   *string                  `json:"ttl_state,omitempty"`
+	TTL        int64                    `json:"ttl,omitempty"`
+	Schedule   *moira.ScheduleData      `json:"sched,omitempty"`
+	Expression string                   `json:"expression"`
+	Patterns   []string                 `json:"patterns"`
+	TeamID     string                   `json:"team_id,omitempty"`
+	Managed    *moira.TriggerManagement `json:"managed,omitempty"`
 }
 
 // ToMoiraTrigger transforms TriggerModel to moira.Trigger
@@ -59,6 +62,7 @@ func (model *TriggerModel) ToMoiraTrigger() *moira.Trigger {
 		Schedule:   model.Schedule,
 		Expression: &model.Expression,
 		Patterns:   model.Patterns,
+		TeamID:     model.TeamID,
 	}
 }
 
@@ -77,18 +81,30 @@ func CreateTriggerModel(trigger *moira.Trigger) TriggerModel {
 		Schedule:   trigger.Schedule,
 		Expression: moira.UseString(trigger.Expression),
 		Patterns:   trigger.Patterns,
+		TeamID:     trigger.TeamID,
+		Managed:    trigger.Managed,
 	}
 }
 
 func (trigger *Trigger) Bind(request *http.Request) error {
+	timeSeriesNames, err := trigger.Validate(middleware.GetDatabase(request), middleware.GetLoggerEntry(request))
+	if err != nil {
+		return err
+	}
+	middleware.SetTimeSeriesNames(request, timeSeriesNames)
+	return nil
+}
+
+// Validate checks trigger targets and expression and resolves trigger patterns, names of found time series are returned
+func (trigger *Trigger) Validate(database moira.Database, logger moira.Logger) (map[string]bool, error) {
 	if len(trigger.Targets) == 0 {
-		return fmt.Errorf("targets is required")
+		return nil, fmt.Errorf("targets is required")
 	}
 	if trigger.WarnValue == nil && trigger.Expression == "" {
-		return fmt.Errorf("warn_value is required")
+		return nil, fmt.Errorf("warn_value is required")
 	}
 	if trigger.ErrorValue == nil && trigger.Expression == "" {
-		return fmt.Errorf("error_value is required")
+		return nil, fmt.Errorf("error_value is required")
 	}
 
 	triggerExpression := expression.TriggerExpression{
@@ -99,30 +115,28 @@ func (trigger *Trigger) Bind(request *http.Request) error {
 		Expression:              &trigger.Expression,
 	}
 
-	logger := middleware.GetLoggerEntry(request)
-
-	if err := resolvePatterns(request, trigger, &triggerExpression); err != nil {
+	timeSeriesNames, err := resolvePatterns(database, trigger, &triggerExpression)
+	if err != nil {
 		logger.Infof("Invalid graphite targets %s: %s\n", trigger.Targets, err.Error())
-		return fmt.Errorf("Invalid graphite targets: %s", err.Error())
+		return nil, fmt.Errorf("Invalid graphite targets: %s", err.Error())
 	}
 	if _, err := triggerExpression.Evaluate(); err != nil {
 		logger.Infof("Invalid expression %s: %s\n", trigger.Expression, err.Error())
-		return err
+		return nil, err
 	}
-	return nil
+	return timeSeriesNames, nil
 }
 
-func resolvePatterns(request *http.Request, trigger *Trigger, expressionValues *expression.TriggerExpression) error {
+func resolvePatterns(database moira.Database, trigger *Trigger, expressionValues *expression.TriggerExpression) (map[string]bool, error) {
 	now := time.Now().Unix()
 	targetNum := 1
 	trigger.Patterns = make([]string, 0)
 	timeSeriesNames := make(map[string]bool)
 
 	for _, tar := range trigger.Targets {
-		database := middleware.GetDatabase(request)
 		result, err := target.EvaluateTarget(database, tar, now-600, now, true)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		trigger.Patterns = append(trigger.Patterns, result.Patterns...)
 		for _, timeSeries := range result.TimeSeries {
@@ -136,8 +150,7 @@ func resolvePatterns(request *http.Request, trigger *Trigger, expressionValues *
 		}
 		targetNum++
 	}
-	middleware.SetTimeSeriesNames(request, timeSeriesNames)
-	return nil
+	return timeSeriesNames, nil
 }
 
 func (*Trigger) Render(w http.ResponseWriter, r *http.Request) error {
@@ -0,0 +1,104 @@
+// nolint
+package dto
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/moira-alert/moira/checker"
+)
+
+// Bulk trigger operations
+const (
+	TriggersBulkAddTags        = "add_tags"
+	TriggersBulkRemoveTags     = "remove_tags"
+	TriggersBulkSetThresholds  = "set_thresholds"
+	TriggersBulkSetTTL         = "set_ttl"
+	TriggersBulkSetMaintenance = "set_maintenance"
+	TriggersBulkDelete         = "delete"
+	TriggersBulkClone          = "clone"
+)
+
+// TriggersBulkSelection selects triggers by ids or by tags and search query, ids take precedence
+type TriggersBulkSelection struct {
+	IDs   []string `json:"ids,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+	Query string   `json:"query,omitempty"`
+}
+
+type TriggersBulkOperation struct {
+	Selection   TriggersBulkSelection `json:"selection"`
+	Operation   string                `json:"operation"`
+	Tags        []string              `json:"tags,omitempty"`
+	WarnValue   *float64              `json:"warn_value,omitempty"`
+	ErrorValue  *float64              `json:"error_value,omitempty"`
+	TTL         *int64                `json:"ttl,omitempty"`
+	TTLState    *string               `json:"ttl_state,omitempty"`
+	Maintenance int64                 `json:"maintenance,omitempty"`
+}
+
+func (operation *TriggersBulkOperation) Bind(r *http.Request) error {
+	selection := operation.Selection
+	if len(selection.IDs) == 0 && len(selection.Tags) == 0 && selection.Query == "" {
+		return fmt.Errorf("selection must contain trigger ids, tags or query")
+	}
+	switch operation.Operation {
+	case TriggersBulkAddTags, TriggersBulkRemoveTags:
+		if len(operation.Tags) == 0 {
+			return fmt.Errorf("tags is required")
+		}
+		for _, tag := range operation.Tags {
+			if tag == "" {
+				return fmt.Errorf("tag can not be empty")
+			}
+		}
+	case TriggersBulkSetThresholds:
+		if operation.WarnValue == nil && operation.ErrorValue == nil {
+			return fmt.Errorf("warn_value or error_value is required")
+		}
+	case TriggersBulkSetTTL:
+		if operation.TTL == nil && operation.TTLState == nil {
+			return fmt.Errorf("ttl or ttl_state is required")
+		}
+		if operation.TTL != nil && *operation.TTL < 0 {
+			return fmt.Errorf("ttl can not be negative")
+		}
+		if operation.TTLState != nil && !isTTLState(*operation.TTLState) {
+			return fmt.Errorf("Unknown ttl_state '%s'", *operation.TTLState)
+		}
+	case TriggersBulkSetMaintenance:
+		if operation.Maintenance <= 0 {
+			return fmt.Errorf("maintenance is required")
+		}
+	case TriggersBulkDelete, TriggersBulkClone:
+	default:
+		return fmt.Errorf("Unknown operation '%s'", operation.Operation)
+	}
+	return nil
+}
+
+func isTTLState(state string) bool {
+	switch state {
+	case checker.OK, checker.WARN, checker.ERROR, checker.NODATA, checker.DEL:
+		return true
+	}
+	return false
+}
+
+type TriggersBulkResult struct {
+	DryRun bool                `json:"dry_run"`
+	List   []TriggerBulkChange `json:"list"`
+}
+
+func (*TriggersBulkResult) Render(w http.ResponseWriter, r *http.Request) error {
+	return nil
+}
+
+// TriggerBulkChange is result of bulk operation for single trigger, error is set if trigger is invalid after change
+type TriggerBulkChange struct {
+	TriggerID    string               `json:"trigger_id"`
+	Name         string               `json:"name"`
+	NewTriggerID string               `json:"new_trigger_id,omitempty"`
+	Changes      []TriggerFieldChange `json:"changes"`
+	Error        string               `json:"error,omitempty"`
+}
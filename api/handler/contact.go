@@ -20,6 +20,7 @@ func contact(router chi.Router) {
 		router.Put("/", updateContact)
 		router.Delete("/", removeContact)
 		router.Post("/test", testContact)
+		router.With(middleware.Paginate(0, 100)).Get("/deliveries", getContactDeliveries)
 	})
 }
 
@@ -67,6 +68,7 @@ func updateContact(writer http.ResponseWriter, request *http.Request) {
 	err := controller.CheckUserPermissionsForContact(database, contactID, userLogin)
 	if err != nil {
 		render.Render(writer, request, err)
+		return
 	}
 
 	if err := controller.UpdateContact(database, contact, contactID, userLogin); err != nil {
@@ -86,6 +88,7 @@ func removeContact(writer http.ResponseWriter, request *http.Request) {
 	err := controller.CheckUserPermissionsForContact(database, contactID, userLogin)
 	if err != nil {
 		render.Render(writer, request, err)
+		return
 	}
 
 	err = controller.RemoveContact(database, contactID, userLogin)
@@ -100,6 +103,7 @@ func testContact(writer http.ResponseWriter, request *http.Request) {
 	err := controller.CheckUserPermissionsForContact(database, contactID, userLogin)
 	if err != nil {
 		render.Render(writer, request, err)
+		return
 	}
 
 	err = controller.SendTestContactNotification(database, contactID, userLogin)
@@ -107,3 +111,23 @@ func testContact(writer http.ResponseWriter, request *http.Request) {
 		render.Render(writer, request, err)
 	}
 }
+
+func getContactDeliveries(writer http.ResponseWriter, request *http.Request) {
+	contactID := middleware.GetContactID(request)
+	userLogin := middleware.GetLogin(request)
+	if err := controller.CheckUserPermissionsForContact(database, contactID, userLogin); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	size := middleware.GetSize(request)
+	page := middleware.GetPage(request)
+	deliveriesList, err := controller.GetContactDeliveries(database, contactID, page, size)
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, deliveriesList); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/go-graphite/carbonapi/date"
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/middleware"
+)
+
+// streamPingInterval is interval of comments sent to keep idle event stream connection open
+var streamPingInterval = 30 * time.Second
+
+// defaultEventCountsInterval is interval in seconds of event counts if request has no interval
+const defaultEventCountsInterval = 3600
+
+func events(router chi.Router) {
+	router.With(middleware.Paginate(0, 100), middleware.DateRange("-1day", "now")).Get("/", getEvents)
+	router.With(middleware.DateRange("-1day", "now")).Get("/counts", getEventCounts)
+	router.Get("/stream", streamEvents)
+}
+
+func getEvents(writer http.ResponseWriter, request *http.Request) {
+	filter, errorResponse := getEventsFilter(request)
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+	eventsList, errorResponse := controller.GetEvents(database, filter, middleware.GetPage(request), middleware.GetSize(request))
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+	if err := render.Render(writer, request, eventsList); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func getEventCounts(writer http.ResponseWriter, request *http.Request) {
+	filter, errorResponse := getEventsFilter(request)
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+	interval := int64(defaultEventCountsInterval)
+	if intervalStr := request.URL.Query().Get("interval"); intervalStr != "" {
+		var err error
+		if interval, err = strconv.ParseInt(intervalStr, 10, 64); err != nil {
+			render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Can not parse interval: %s", intervalStr)))
+			return
+		}
+	}
+	eventCounts, errorResponse := controller.GetEventCounts(database, filter, interval)
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+	if err := render.Render(writer, request, eventCounts); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func getEventsFilter(request *http.Request) (controller.EventsFilter, *api.ErrorResponse) {
+	fromStr := middleware.GetFromStr(request)
+	toStr := middleware.GetToStr(request)
+	from := date.DateParamToEpoch(fromStr, "UTC", 0, time.UTC)
+	if from == 0 {
+		return controller.EventsFilter{}, api.ErrorInvalidRequest(fmt.Errorf("Can not parse from: %s", fromStr))
+	}
+	to := date.DateParamToEpoch(toStr, "UTC", 0, time.UTC)
+	if to == 0 {
+		return controller.EventsFilter{}, api.ErrorInvalidRequest(fmt.Errorf("Can not parse to: %s", toStr))
+	}
+	return controller.EventsFilter{
+		From:     int64(from),
+		To:       int64(to),
+		Tags:     getIndexedFormValues(request, "tags"),
+		State:    request.URL.Query().Get("state"),
+		OldState: request.URL.Query().Get("oldState"),
+		Metric:   request.URL.Query().Get("metric"),
+	}, nil
+}
+
+// streamEvents sends trigger check results and new notification events as server-sent events until client disconnects
+func streamEvents(writer http.ResponseWriter, request *http.Request) {
+	flusher, ok := writer.(http.Flusher)
+	if !ok {
+		render.Render(writer, request, api.ErrorInternalServer(fmt.Errorf("Streaming is not supported")))
+		return
+	}
+	filter := controller.StreamEventsFilter{
+		TriggerIDs: getIndexedFormValues(request, "triggers"),
+		Tags:       getIndexedFormValues(request, "tags"),
+	}
+
+	var streamTomb tomb.Tomb
+	defer streamTomb.Kill(nil)
+	streamEvents, errorResponse := controller.SubscribeStreamEvents(streamEventsHub, middleware.GetLoggerEntry(request), &streamTomb, filter)
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/event-stream")
+	writer.Header().Set("Connection", "keep-alive")
+	writer.Header().Set("X-Accel-Buffering", "no")
+	writer.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ping := time.NewTicker(streamPingInterval)
+	defer ping.Stop()
+	for {
+		select {
+		case <-request.Context().Done():
+			return
+		case <-ping.C:
+			fmt.Fprint(writer, ": ping\n\n")
+		case event, ok := <-streamEvents:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				middleware.GetLoggerEntry(request).Errorf("Failed to marshal stream event: %s", err.Error())
+				continue
+			}
+			fmt.Fprintf(writer, "event: %s\ndata: %s\n\n", event.Type, data)
+		}
+		flusher.Flush()
+	}
+}
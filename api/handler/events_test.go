@@ -0,0 +1,67 @@
+package handler
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestStreamEvents(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("api")
+	authentication, err := auth.NewAuthentication(api.Config{AuthMethods: []string{api.AuthHeader}}, dataBase)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewHandler(dataBase, logger, authentication))
+	defer server.Close()
+
+	Convey("Matched events are sent as server-sent events until client disconnects", t, func() {
+		source := make(chan *moira.StreamEvent, 10)
+		closed := make(chan bool)
+		dataBase.EXPECT().SubscribeStreamEvents(gomock.Any()).Do(func(streamTomb *tomb.Tomb) {
+			go func() {
+				<-streamTomb.Dying()
+				close(source)
+				close(closed)
+			}()
+		}).Return((<-chan *moira.StreamEvent)(source), nil)
+
+		response, err := http.Get(server.URL + "/api/events/stream?triggers[0]=trigger2")
+		So(err, ShouldBeNil)
+		So(response.StatusCode, ShouldEqual, 200)
+		So(response.Header.Get("Content-Type"), ShouldEqual, "text/event-stream")
+
+		source <- &moira.StreamEvent{Type: moira.StreamEventTriggerState, TriggerID: "trigger1", State: &moira.CheckData{State: "OK"}}
+		source <- &moira.StreamEvent{Type: moira.StreamEventTriggerState, TriggerID: "trigger2", State: &moira.CheckData{State: "ERROR", Score: 100}}
+		reader := bufio.NewReader(response.Body)
+		lines := make([]string, 0)
+		for len(lines) < 3 {
+			line, err := reader.ReadString('\n')
+			So(err, ShouldBeNil)
+			lines = append(lines, strings.TrimSuffix(line, "\n"))
+		}
+		So(lines, ShouldResemble, []string{
+			"event: trigger_state",
+			`data: {"type":"trigger_state","trigger_id":"trigger2","state":{"metrics":null,"score":100,"state":"ERROR"}}`,
+			"",
+		})
+
+		response.Body.Close()
+		<-closed
+	})
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/api/middleware"
+)
+
+func export(router chi.Router) {
+	router.Get("/", exportTriggers)
+}
+
+func exportTriggers(writer http.ResponseWriter, request *http.Request) {
+	format := request.URL.Query().Get("format")
+	if format == "" {
+		format = dto.ExportJSON
+	}
+	if format != dto.ExportJSON && format != dto.ExportYAML {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Unknown export format '%s'", format)))
+		return
+	}
+	exported, errorResponse := controller.ExportTriggers(database, middleware.GetLogin(request))
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+	data, err := exported.Encode(format)
+	if err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+	if format == dto.ExportYAML {
+		writer.Header().Set("Content-Type", "application/x-yaml")
+	} else {
+		writer.Header().Set("Content-Type", "application/json")
+	}
+	writer.Write(data)
+}
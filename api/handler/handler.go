@@ -6,16 +6,25 @@ import (
 	"github.com/go-chi/render"
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
+	"github.com/moira-alert/moira/api/controller"
 	moira_middle "github.com/moira-alert/moira/api/middleware"
 	"github.com/rs/cors"
 	"net/http"
 )
 
 var database moira.Database
+var streamEventsHub *controller.StreamEventsHub
 
 // NewHandler creates new api handler request uris based on github.com/go-chi/chi
-func NewHandler(db moira.Database, log moira.Logger) http.Handler {
+func NewHandler(db moira.Database, log moira.Logger, authentication *auth.Authentication) http.Handler {
 	database = db
+	streamEventsHub = controller.NewStreamEventsHub(db, log)
+	return cors.AllowAll().Handler(newRouter(log, authentication))
+}
+
+// newRouter creates router of all api routes, every route must be described in api/openapi.yml
+func newRouter(log moira.Logger, authentication *auth.Authentication) chi.Router {
 	router := chi.NewRouter()
 	router.Use(render.SetContentType(render.ContentTypeJSON))
 	router.Use(moira_middle.RequestLogger(log))
@@ -26,17 +35,22 @@ func NewHandler(db moira.Database, log moira.Logger) http.Handler {
 
 	router.Route("/api", func(router chi.Router) {
 		router.Use(moira_middle.DatabaseContext(database))
-		router.Use(moira_middle.UserContext)
+		router.Use(moira_middle.UserContext(authentication))
 		router.Route("/user", user)
 		router.Route("/trigger", triggers)
 		router.Route("/tag", tag)
 		router.Route("/pattern", pattern)
+		router.Route("/metric", metric)
 		router.Route("/event", event)
+		router.Route("/events", events)
 		router.Route("/contact", contact)
 		router.Route("/subscription", subscription)
+		router.Route("/team", team)
+		router.Route("/maintenance", maintenance)
 		router.Route("/notification", notification)
+		router.Route("/export", export)
 	})
-	return cors.AllowAll().Handler(router)
+	return router
 }
 
 func notFoundHandler(writer http.ResponseWriter, request *http.Request) {
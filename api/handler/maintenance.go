@@ -0,0 +1,94 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/api/middleware"
+)
+
+func maintenance(router chi.Router) {
+	router.Get("/", getMaintenanceWindows)
+	router.Put("/", createMaintenanceWindow)
+	router.Route("/{maintenanceId}", func(router chi.Router) {
+		router.Get("/", getMaintenanceWindow)
+		router.Put("/", updateMaintenanceWindow)
+		router.Delete("/", removeMaintenanceWindow)
+	})
+}
+
+func getMaintenanceWindows(writer http.ResponseWriter, request *http.Request) {
+	windows, err := controller.GetMaintenanceWindows(database)
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, windows); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func createMaintenanceWindow(writer http.ResponseWriter, request *http.Request) {
+	window := &dto.MaintenanceWindow{}
+	if err := render.Bind(request, window); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	if err := controller.CreateMaintenanceWindow(database, window, middleware.GetLogin(request), middleware.IsAdmin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, window); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func getMaintenanceWindow(writer http.ResponseWriter, request *http.Request) {
+	windowID := chi.URLParam(request, "maintenanceId")
+	window, err := controller.GetMaintenanceWindow(database, windowID)
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, window); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func updateMaintenanceWindow(writer http.ResponseWriter, request *http.Request) {
+	window := &dto.MaintenanceWindow{}
+	if err := render.Bind(request, window); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	windowID := chi.URLParam(request, "maintenanceId")
+
+	if err := controller.UpdateMaintenanceWindow(database, window, windowID, middleware.GetLogin(request), middleware.IsAdmin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, window); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func removeMaintenanceWindow(writer http.ResponseWriter, request *http.Request) {
+	windowID := chi.URLParam(request, "maintenanceId")
+	if err := controller.RemoveMaintenanceWindow(database, windowID, middleware.GetLogin(request), middleware.IsAdmin(request)); err != nil {
+		render.Render(writer, request, err)
+	}
+}
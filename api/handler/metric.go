@@ -0,0 +1,46 @@
+package handler
+
+import (
+	"fmt"
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+	"github.com/go-graphite/carbonapi/date"
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/middleware"
+	"net/http"
+	"time"
+)
+
+func metric(router chi.Router) {
+	router.With(middleware.DateRange("-10minutes", "now")).Get("/match", matchMetric)
+}
+
+func matchMetric(writer http.ResponseWriter, request *http.Request) {
+	metricName := request.URL.Query().Get("name")
+	if metricName == "" {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Metric name can not be empty")))
+		return
+	}
+	fromStr := middleware.GetFromStr(request)
+	toStr := middleware.GetToStr(request)
+	from := date.DateParamToEpoch(fromStr, "UTC", 0, time.UTC)
+	if from == 0 {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Can not parse from: %s", fromStr)))
+		return
+	}
+	to := date.DateParamToEpoch(toStr, "UTC", 0, time.UTC)
+	if to == 0 {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Can not parse to: %s", toStr)))
+		return
+	}
+	logger := middleware.GetLoggerEntry(request)
+	metricMatch, err := controller.MatchMetric(database, logger, metricName, int64(from), int64(to))
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, metricMatch); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"io/ioutil"
+	"net/http"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/yaml.v2"
+
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
+)
+
+type openAPIDocument struct {
+	OpenAPI string `yaml:"openapi"`
+	Servers []struct {
+		URL string `yaml:"url"`
+	} `yaml:"servers"`
+	Paths map[string]map[string]interface{} `yaml:"paths"`
+}
+
+var openAPIMethods = map[string]bool{"get": true, "put": true, "post": true, "delete": true, "patch": true, "head": true, "options": true}
+
+func TestOpenAPIDocument(t *testing.T) {
+	data, err := ioutil.ReadFile("../openapi.yml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	document := openAPIDocument{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		t.Fatal(err)
+	}
+
+	logger, _ := logging.GetLogger("api")
+	authentication, err := auth.NewAuthentication(api.Config{AuthMethods: []string{api.AuthHeader}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := newRouter(logger, authentication)
+
+	Convey("Document is OpenAPI 3 with api server", t, func() {
+		So(document.OpenAPI, ShouldStartWith, "3.")
+		So(document.Servers, ShouldHaveLength, 1)
+		So(document.Servers[0].URL, ShouldEqual, "/api")
+	})
+
+	Convey("Document describes all router routes and nothing else", t, func() {
+		documentRoutes := make([]string, 0)
+		for path, item := range document.Paths {
+			for method := range item {
+				if openAPIMethods[method] {
+					documentRoutes = append(documentRoutes, strings.ToUpper(method)+" "+document.Servers[0].URL+path)
+				}
+			}
+		}
+		sort.Strings(documentRoutes)
+
+		routerRoutes := make([]string, 0)
+		err := chi.Walk(router, func(method string, route string, handler http.Handler, middlewares ...func(http.Handler) http.Handler) error {
+			routerRoutes = append(routerRoutes, method+" "+normalizeRoute(route))
+			return nil
+		})
+		So(err, ShouldBeNil)
+		sort.Strings(routerRoutes)
+
+		So(documentRoutes, ShouldResemble, routerRoutes)
+	})
+
+	Convey("All document references are defined", t, func() {
+		var value interface{}
+		So(yaml.Unmarshal(data, &value), ShouldBeNil)
+		for _, ref := range getOpenAPIRefs(value) {
+			var node interface{} = value
+			for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+				object, ok := node.(map[interface{}]interface{})
+				So(ok, ShouldBeTrue)
+				node = object[key]
+			}
+			So(node, ShouldNotBeNil)
+		}
+	})
+}
+
+// getOpenAPIRefs returns all $ref values of yaml document
+func getOpenAPIRefs(value interface{}) []string {
+	refs := make([]string, 0)
+	switch typed := value.(type) {
+	case map[interface{}]interface{}:
+		for key, item := range typed {
+			if ref, ok := item.(string); ok && key == "$ref" {
+				refs = append(refs, ref)
+				continue
+			}
+			refs = append(refs, getOpenAPIRefs(item)...)
+		}
+	case []interface{}:
+		for _, item := range typed {
+			refs = append(refs, getOpenAPIRefs(item)...)
+		}
+	}
+	return refs
+}
+
+// normalizeRoute removes subrouter wildcards and trailing slash added by chi.Walk to mounted routes
+func normalizeRoute(route string) string {
+	route = strings.Replace(route, "/*", "", -1)
+	if route != "/" {
+		route = strings.TrimSuffix(route, "/")
+	}
+	return route
+}
@@ -33,6 +33,10 @@ func deletePattern(writer http.ResponseWriter, request *http.Request) {
 		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Pattern must be set")))
 		return
 	}
+	if err := controller.CheckUserPermissionsForPattern(database, pattern, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
 	err := controller.DeletePattern(database, pattern)
 	if err != nil {
 		render.Render(writer, request, err)
@@ -7,6 +7,7 @@ import (
 	"github.com/moira-alert/moira/api/controller"
 	"github.com/moira-alert/moira/api/dto"
 	"github.com/moira-alert/moira/api/middleware"
+	"io/ioutil"
 	"net/http"
 )
 
@@ -15,8 +16,10 @@ func subscription(router chi.Router) {
 	router.Put("/", createSubscription)
 	router.Route("/{subscriptionId}", func(router chi.Router) {
 		router.Use(middleware.SubscriptionContext)
+		router.Use(subscriptionPermissions)
 		router.Delete("/", deleteSubscription)
 		router.Put("/test", sendTestNotification)
+		router.Put("/holidays", importSubscriptionHolidays)
 	})
 }
 
@@ -41,6 +44,12 @@ func createSubscription(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 	userLogin := middleware.GetLogin(request)
+	if subscription.ID != "" {
+		if err := controller.CheckUserPermissionsForSubscription(database, subscription.ID, userLogin); err != nil {
+			render.Render(writer, request, err)
+			return
+		}
+	}
 
 	if err := controller.WriteSubscription(database, userLogin, subscription); err != nil {
 		render.Render(writer, request, err)
@@ -67,3 +76,35 @@ func sendTestNotification(writer http.ResponseWriter, request *http.Request) {
 		render.Render(writer, request, err)
 	}
 }
+
+func importSubscriptionHolidays(writer http.ResponseWriter, request *http.Request) {
+	iCalData, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	subscriptionID := middleware.GetSubscriptionID(request)
+	subscription, errorResponse := controller.ImportSubscriptionHolidays(database, subscriptionID, string(iCalData))
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+
+	if err := render.Render(writer, request, subscription); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+// subscriptionPermissions checks that user can modify subscription from request context
+func subscriptionPermissions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		subscriptionID := middleware.GetSubscriptionID(request)
+		userLogin := middleware.GetLogin(request)
+		if err := controller.CheckUserPermissionsForSubscription(database, subscriptionID, userLogin); err != nil {
+			render.Render(writer, request, err)
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
+}
@@ -46,6 +46,10 @@ func getAllTagsAndSubscriptions(writer http.ResponseWriter, request *http.Reques
 
 func removeTag(writer http.ResponseWriter, request *http.Request) {
 	tagName := middleware.GetTag(request)
+	if err := controller.CheckUserPermissionsForTag(database, tagName, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
 	response, err := controller.RemoveTag(database, tagName)
 	if err != nil {
 		render.Render(writer, request, err)
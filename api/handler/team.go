@@ -0,0 +1,109 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/render"
+
+	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/dto"
+	"github.com/moira-alert/moira/api/middleware"
+)
+
+func team(router chi.Router) {
+	router.Get("/", getUserTeams)
+	router.Put("/", createTeam)
+	router.Route("/{teamId}", func(router chi.Router) {
+		router.Get("/", getTeam)
+		router.Put("/", updateTeam)
+		router.Delete("/", removeTeam)
+		router.Get("/settings", getTeamSettings)
+	})
+}
+
+func getUserTeams(writer http.ResponseWriter, request *http.Request) {
+	teams, err := controller.GetUserTeams(database, middleware.GetLogin(request))
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, teams); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func createTeam(writer http.ResponseWriter, request *http.Request) {
+	team := &dto.Team{}
+	if err := render.Bind(request, team); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+
+	if err := controller.CreateTeam(database, team, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, team); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func getTeam(writer http.ResponseWriter, request *http.Request) {
+	teamID := chi.URLParam(request, "teamId")
+	team, err := controller.GetTeam(database, teamID, middleware.GetLogin(request))
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, team); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func getTeamSettings(writer http.ResponseWriter, request *http.Request) {
+	teamID := chi.URLParam(request, "teamId")
+	teamSettings, err := controller.GetTeamSettings(database, teamID, middleware.GetLogin(request))
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, teamSettings); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func updateTeam(writer http.ResponseWriter, request *http.Request) {
+	team := &dto.Team{}
+	if err := render.Bind(request, team); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	teamID := chi.URLParam(request, "teamId")
+
+	if err := controller.UpdateTeam(database, team, teamID, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, team); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func removeTeam(writer http.ResponseWriter, request *http.Request) {
+	teamID := chi.URLParam(request, "teamId")
+	if err := controller.RemoveTeam(database, teamID, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
 	"github.com/go-graphite/carbonapi/date"
+	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/controller"
 	"github.com/moira-alert/moira/api/dto"
@@ -12,6 +13,7 @@ import (
 	"github.com/moira-alert/moira/expression"
 	"github.com/moira-alert/moira/target"
 	"net/http"
+	"strconv"
 	"time"
 )
 
@@ -30,6 +32,23 @@ func trigger(router chi.Router) {
 		router.Delete("/", deleteTriggerMetric)
 	})
 	router.Put("/maintenance", setMetricsMaintenance)
+	router.Route("/history", func(router chi.Router) {
+		router.Get("/", getTriggerHistory)
+		router.Post("/{version}/restore", restoreTriggerVersion)
+	})
+	router.With(triggerViewPermissions, middleware.Paginate(0, 100)).Get("/deliveries", getTriggerDeliveries)
+}
+
+// triggerViewPermissions checks that user can view notification details of trigger from request context
+func triggerViewPermissions(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		triggerID := middleware.GetTriggerID(request)
+		if err := controller.CheckUserViewPermissionsForTrigger(database, triggerID, middleware.GetLogin(request)); err != nil {
+			render.Render(writer, request, err)
+			return
+		}
+		next.ServeHTTP(writer, request)
+	})
 }
 
 func updateTrigger(writer http.ResponseWriter, request *http.Request) {
@@ -44,8 +63,19 @@ func updateTrigger(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 
+	userLogin := middleware.GetLogin(request)
+	if err := controller.CheckUserPermissionsForTrigger(database, triggerID, userLogin); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+	if err := controller.CheckUserPermissionsForTeam(database, trigger.TeamID, userLogin, moira.TeamRoleEditor); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
 	timeSeriesNames := middleware.GetTimeSeriesNames(request)
-	response, err := controller.UpdateTrigger(database, &trigger.TriggerModel, triggerID, timeSeriesNames)
+	comment := request.URL.Query().Get("comment")
+	response, err := controller.UpdateTrigger(database, &trigger.TriggerModel, triggerID, timeSeriesNames, userLogin, comment)
 	if err != nil {
 		render.Render(writer, request, err)
 		return
@@ -59,7 +89,12 @@ func updateTrigger(writer http.ResponseWriter, request *http.Request) {
 
 func removeTrigger(writer http.ResponseWriter, request *http.Request) {
 	triggerID := middleware.GetTriggerID(request)
-	err := controller.RemoveTrigger(database, triggerID)
+	userLogin := middleware.GetLogin(request)
+	if err := controller.CheckUserPermissionsForTrigger(database, triggerID, userLogin); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+	err := controller.RemoveTrigger(database, triggerID, userLogin, request.URL.Query().Get("comment"))
 	if err != nil {
 		render.Render(writer, request, err)
 	}
@@ -106,6 +141,10 @@ func getTriggerThrottling(writer http.ResponseWriter, request *http.Request) {
 
 func deleteThrottling(writer http.ResponseWriter, request *http.Request) {
 	triggerID := middleware.GetTriggerID(request)
+	if err := controller.CheckUserPermissionsForTrigger(database, triggerID, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
 	err := controller.DeleteTriggerThrottling(database, triggerID)
 	if err != nil {
 		render.Render(writer, request, err)
@@ -143,6 +182,10 @@ func deleteTriggerMetric(writer http.ResponseWriter, request *http.Request) {
 		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Metric name can not be empty")))
 		return
 	}
+	if err := controller.CheckUserPermissionsForTrigger(database, triggerID, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
 	if err := controller.DeleteTriggerMetric(database, metricName, triggerID); err != nil {
 		render.Render(writer, request, err)
 	}
@@ -155,8 +198,63 @@ func setMetricsMaintenance(writer http.ResponseWriter, request *http.Request) {
 		render.Render(writer, request, api.ErrorInvalidRequest(err))
 		return
 	}
+	if err := controller.CheckUserPermissionsForTrigger(database, triggerID, middleware.GetLogin(request)); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
 	err := controller.SetMetricsMaintenance(database, triggerID, metricsMaintenance)
 	if err != nil {
 		render.Render(writer, request, err)
 	}
 }
+
+func getTriggerDeliveries(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	size := middleware.GetSize(request)
+	page := middleware.GetPage(request)
+	deliveriesList, err := controller.GetTriggerDeliveries(database, triggerID, page, size)
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+	if err := render.Render(writer, request, deliveriesList); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func getTriggerHistory(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	history, err := controller.GetTriggerHistory(database, triggerID)
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, history); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
+
+func restoreTriggerVersion(writer http.ResponseWriter, request *http.Request) {
+	triggerID := middleware.GetTriggerID(request)
+	version, err := strconv.ParseInt(chi.URLParam(request, "version"), 10, 64)
+	if err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(fmt.Errorf("Version must be a number")))
+		return
+	}
+	userLogin := middleware.GetLogin(request)
+	if err := controller.CheckUserPermissionsForTrigger(database, triggerID, userLogin); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	response, errorResponse := controller.RestoreTriggerVersion(database, triggerID, version, userLogin, request.URL.Query().Get("comment"))
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+
+	if err := render.Render(writer, request, response); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"github.com/go-chi/chi"
 	"github.com/go-chi/render"
+	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/api/controller"
 	"github.com/moira-alert/moira/api/dto"
@@ -19,6 +20,7 @@ func triggers(router chi.Router) {
 	router.Get("/", getAllTriggers)
 	router.Put("/", createTrigger)
 	router.With(middleware.Paginate(0, 10)).Get("/page", getTriggersPage)
+	router.Post("/bulk", applyTriggersBulkOperation)
 	router.Route("/{triggerId}", trigger)
 }
 
@@ -45,8 +47,15 @@ func createTrigger(writer http.ResponseWriter, request *http.Request) {
 		}
 		return
 	}
+	userLogin := middleware.GetLogin(request)
+	if err := controller.CheckUserPermissionsForTeam(database, trigger.TeamID, userLogin, moira.TeamRoleEditor); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
 	timeSeriesNames := middleware.GetTimeSeriesNames(request)
-	response, err := controller.CreateTrigger(database, &trigger.TriggerModel, timeSeriesNames)
+	comment := request.URL.Query().Get("comment")
+	response, err := controller.CreateTrigger(database, &trigger.TriggerModel, timeSeriesNames, userLogin, comment)
 	if err != nil {
 		render.Render(writer, request, err)
 		return
@@ -66,7 +75,22 @@ func getTriggersPage(writer http.ResponseWriter, request *http.Request) {
 	page := middleware.GetPage(request)
 	size := middleware.GetSize(request)
 
-	triggersList, errorResponse := controller.GetTriggerPage(database, page, size, onlyErrors, filterTags)
+	searchText := request.FormValue("q")
+	sortOrder := request.FormValue("sort")
+
+	var triggersList *dto.TriggersList
+	var errorResponse *api.ErrorResponse
+	if searchText == "" && sortOrder == "" {
+		triggersList, errorResponse = controller.GetTriggerPage(database, page, size, onlyErrors, filterTags)
+	} else {
+		var query moira.TriggerSearchQuery
+		query, errorResponse = controller.ParseTriggerSearchQuery(searchText, sortOrder)
+		if errorResponse == nil {
+			query.Tags = append(query.Tags, filterTags...)
+			query.OnlyProblems = onlyErrors
+			triggersList, errorResponse = controller.SearchTriggerPage(database, page, size, query)
+		}
+	}
 	if errorResponse != nil {
 		render.Render(writer, request, errorResponse)
 		return
@@ -78,18 +102,33 @@ func getTriggersPage(writer http.ResponseWriter, request *http.Request) {
 	}
 }
 
-func getRequestTags(request *http.Request) []string {
-	var filterTags []string
-	i := 0
-	for {
-		tag := request.FormValue(fmt.Sprintf("tags[%v]", i))
-		if tag == "" {
-			break
-		}
-		filterTags = append(filterTags, tag)
-		i++
+func applyTriggersBulkOperation(writer http.ResponseWriter, request *http.Request) {
+	operation := &dto.TriggersBulkOperation{}
+	if err := render.Bind(request, operation); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	dryRun, _ := strconv.ParseBool(request.URL.Query().Get("dry_run"))
+	validate := func(trigger *dto.Trigger) error {
+		return trigger.Bind(request)
 	}
 
+	userLogin := middleware.GetLogin(request)
+	comment := request.URL.Query().Get("comment")
+	result, errorResponse := controller.ApplyTriggersBulkOperation(database, operation, validate, userLogin, comment, dryRun)
+	if errorResponse != nil {
+		render.Render(writer, request, errorResponse)
+		return
+	}
+
+	if err := render.Render(writer, request, result); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func getRequestTags(request *http.Request) []string {
+	filterTags := getIndexedFormValues(request, "tags")
 	if len(filterTags) != 0 {
 		return filterTags
 	}
@@ -108,6 +147,18 @@ func getRequestTags(request *http.Request) []string {
 	return filterTags
 }
 
+// getIndexedFormValues returns values of form fields name[0], name[1] and so on up to the first empty value
+func getIndexedFormValues(request *http.Request, name string) []string {
+	var values []string
+	for i := 0; ; i++ {
+		value := request.FormValue(fmt.Sprintf("%s[%v]", name, i))
+		if value == "" {
+			return values
+		}
+		values = append(values, value)
+	}
+}
+
 func getOnlyProblemsFlag(request *http.Request) bool {
 	onlyProblemsStr := request.FormValue("onlyProblems")
 	if onlyProblemsStr != "" {
@@ -13,6 +13,11 @@ import (
 func user(router chi.Router) {
 	router.Get("/", getUserName)
 	router.Get("/settings", getUserSettings)
+	router.Route("/tokens", func(router chi.Router) {
+		router.Get("/", getUserAPITokens)
+		router.Post("/", createAPIToken)
+		router.Delete("/{tokenId}", removeAPIToken)
+	})
 }
 
 func getUserName(writer http.ResponseWriter, request *http.Request) {
@@ -38,3 +43,45 @@ func getUserSettings(writer http.ResponseWriter, request *http.Request) {
 		return
 	}
 }
+
+func getUserAPITokens(writer http.ResponseWriter, request *http.Request) {
+	userLogin := middleware.GetLogin(request)
+	tokens, err := controller.GetUserAPITokens(database, userLogin)
+	if err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, tokens); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func createAPIToken(writer http.ResponseWriter, request *http.Request) {
+	token := &dto.APIToken{}
+	if err := render.Bind(request, token); err != nil {
+		render.Render(writer, request, api.ErrorInvalidRequest(err))
+		return
+	}
+	userLogin := middleware.GetLogin(request)
+
+	if err := controller.CreateAPIToken(database, token, userLogin); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+
+	if err := render.Render(writer, request, token); err != nil {
+		render.Render(writer, request, api.ErrorRender(err))
+		return
+	}
+}
+
+func removeAPIToken(writer http.ResponseWriter, request *http.Request) {
+	tokenID := chi.URLParam(request, "tokenId")
+	userLogin := middleware.GetLogin(request)
+	if err := controller.RemoveAPIToken(database, tokenID, userLogin); err != nil {
+		render.Render(writer, request, err)
+		return
+	}
+}
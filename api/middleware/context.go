@@ -7,6 +7,7 @@ import (
 	"github.com/go-chi/render"
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
 	"net/http"
 	"strconv"
 )
@@ -21,13 +22,20 @@ func DatabaseContext(database moira.Database) func(next http.Handler) http.Handl
 	}
 }
 
-// UserContext get x-webauth-user header and sets it in request context, if header is empty sets empty string
-func UserContext(next http.Handler) http.Handler {
-	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
-		userLogin := request.Header.Get("x-webauth-user")
-		ctx := context.WithValue(request.Context(), loginKey, userLogin)
-		next.ServeHTTP(writer, request.WithContext(ctx))
-	})
+// UserContext authenticates request with configured methods and sets user login in request context, anonymous requests get empty login
+func UserContext(authentication *auth.Authentication) func(next http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+			userLogin, err := authentication.Authenticate(request)
+			if err != nil {
+				render.Render(writer, request, err)
+				return
+			}
+			ctx := context.WithValue(request.Context(), loginKey, userLogin)
+			ctx = context.WithValue(ctx, adminKey, authentication.IsAdmin(userLogin))
+			next.ServeHTTP(writer, request.WithContext(ctx))
+		})
+	}
 }
 
 // TriggerContext gets triggerId from parsed URI corresponding to trigger routes and set it to request context
@@ -109,16 +109,33 @@ func (entry *apiLoggerEntry) writePanic(status, bytes int, elapsed time.Duration
 	entry.logger.Error(entry.buf.String())
 }
 
+// responseWriterWithBody keeps body of server error responses to log error text
 type responseWriterWithBody struct {
 	http.ResponseWriter
-	body bytes.Buffer
+	status int
+	body   bytes.Buffer
+}
+
+func (w *responseWriterWithBody) WriteHeader(status int) {
+	w.status = status
+	w.ResponseWriter.WriteHeader(status)
 }
 
 func (w *responseWriterWithBody) Write(buf []byte) (int, error) {
 	n, err := w.ResponseWriter.Write(buf)
+	if w.status < 500 {
+		return n, err
+	}
 	_, err2 := w.body.Write(buf[:n])
 	if err == nil {
 		err = err2
 	}
 	return n, err
 }
+
+// Flush sends buffered data to client, it is required by streaming responses
+func (w *responseWriterWithBody) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
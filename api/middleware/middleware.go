@@ -23,6 +23,7 @@ var (
 	fromKey            contextKey = "from"
 	toKey              contextKey = "to"
 	loginKey           contextKey = "login"
+	adminKey           contextKey = "admin"
 	timeSeriesNamesKey contextKey = "timeSeriesNames"
 )
 
@@ -36,6 +37,11 @@ func GetLogin(request *http.Request) string {
 	return request.Context().Value(loginKey).(string)
 }
 
+// IsAdmin checks that user is api administrator, flag is set in UserContext middleware
+func IsAdmin(request *http.Request) bool {
+	return request.Context().Value(adminKey).(bool)
+}
+
 // GetTriggerID gets TriggerID string from request context, which was sets in TriggerContext middleware
 func GetTriggerID(request *http.Request) string {
 	return request.Context().Value(triggerIDKey).(string)
@@ -195,9 +195,19 @@ func (triggerChecker *TriggerChecker) getTimeSeriesState(triggerTimeSeries *trig
 	}, nil
 }
 
+// cleanupMetricsValues removes values older than metrics TTL or, if metric is downsampled, than retention of its last storage schema archive
 func (triggerChecker *TriggerChecker) cleanupMetricsValues(metrics []string, until int64) {
 	for _, metric := range metrics {
-		if err := triggerChecker.Database.RemoveMetricValues(metric, until-triggerChecker.Config.MetricsTTL); err != nil {
+		ttl := triggerChecker.Config.MetricsTTL
+		storageRetention, err := triggerChecker.Database.GetMetricStorageRetention(metric)
+		if err != nil {
+			triggerChecker.Logger.Error(err.Error())
+			continue
+		}
+		if storageRetention > ttl {
+			ttl = storageRetention
+		}
+		if err := triggerChecker.Database.RemoveMetricValues(metric, until-ttl); err != nil {
 			triggerChecker.Logger.Error(err.Error())
 		}
 	}
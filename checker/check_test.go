@@ -548,7 +548,9 @@ func TestHandleTrigger(t *testing.T) {
 		dataBase.EXPECT().GetMetricsValues([]string{metric}, triggerChecker.From, triggerChecker.Until).Return(dataList, nil)
 		var val float64
 		var val1 float64 = 4
+		dataBase.EXPECT().GetMetricStorageRetention(metric).Return(int64(0), nil)
 		dataBase.EXPECT().RemoveMetricValues(metric, triggerChecker.Until-triggerChecker.Config.MetricsTTL)
+		dataBase.EXPECT().GetMaintenanceWindows().Return(nil, nil)
 		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
 			TriggerID: triggerChecker.TriggerID,
 			Timestamp: 3617,
@@ -593,6 +595,7 @@ func TestHandleTrigger(t *testing.T) {
 		dataBase.EXPECT().GetPatternMetrics(pattern).Return([]string{metric}, nil)
 		dataBase.EXPECT().GetMetricRetention(metric).Return(retention, nil)
 		dataBase.EXPECT().GetMetricsValues([]string{metric}, triggerChecker.From, triggerChecker.Until).Return(dataList, nil)
+		dataBase.EXPECT().GetMetricStorageRetention(metric).Return(int64(0), nil)
 		dataBase.EXPECT().RemoveMetricValues(metric, triggerChecker.Until-triggerChecker.Config.MetricsTTL)
 		checkData, err := triggerChecker.handleTrigger()
 		So(err, ShouldBeNil)
@@ -620,6 +623,7 @@ func TestHandleTrigger(t *testing.T) {
 		dataBase.EXPECT().GetPatternMetrics(pattern).Return([]string{metric}, nil)
 		dataBase.EXPECT().GetMetricRetention(metric).Return(retention, nil)
 		dataBase.EXPECT().GetMetricsValues([]string{metric}, triggerChecker.From, triggerChecker.Until).Return(dataList, nil)
+		dataBase.EXPECT().GetMetricStorageRetention(metric).Return(int64(0), nil)
 		dataBase.EXPECT().RemoveMetricValues(metric, triggerChecker.Until-triggerChecker.Config.MetricsTTL)
 		dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
 			TriggerID: triggerChecker.TriggerID,
@@ -656,6 +660,7 @@ func TestHandleTrigger(t *testing.T) {
 		dataBase.EXPECT().GetPatternMetrics(pattern).Return([]string{metric}, nil)
 		dataBase.EXPECT().GetMetricRetention(metric).Return(retention, nil)
 		dataBase.EXPECT().GetMetricsValues([]string{metric}, triggerChecker.From, triggerChecker.Until).Return(dataList, nil)
+		dataBase.EXPECT().GetMetricStorageRetention(metric).Return(int64(0), nil)
 		dataBase.EXPECT().RemoveMetricValues(metric, triggerChecker.Until-triggerChecker.Config.MetricsTTL)
 		dataBase.EXPECT().RemovePatternsMetrics(triggerChecker.trigger.Patterns).Return(nil)
 		checkData, err := triggerChecker.handleTrigger()
@@ -669,3 +674,35 @@ func TestHandleTrigger(t *testing.T) {
 		mockCtrl.Finish()
 	})
 }
+
+func TestCleanupMetricsValues(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Test")
+	triggerChecker := TriggerChecker{
+		Database: dataBase,
+		Logger:   logger,
+		Config: &Config{
+			MetricsTTL: 3600,
+		},
+	}
+
+	Convey("Values are removed after metrics TTL", t, func() {
+		dataBase.EXPECT().GetMetricStorageRetention("metric").Return(int64(0), nil)
+		dataBase.EXPECT().RemoveMetricValues("metric", int64(100000-3600))
+		triggerChecker.cleanupMetricsValues([]string{"metric"}, 100000)
+	})
+
+	Convey("Downsampled values are removed after longest archive retention", t, func() {
+		dataBase.EXPECT().GetMetricStorageRetention("metric").Return(int64(86400), nil)
+		dataBase.EXPECT().RemoveMetricValues("metric", int64(100000-86400))
+		triggerChecker.cleanupMetricsValues([]string{"metric"}, 100000)
+	})
+
+	Convey("Storage retention shorter than metrics TTL is ignored", t, func() {
+		dataBase.EXPECT().GetMetricStorageRetention("metric").Return(int64(600), nil)
+		dataBase.EXPECT().RemoveMetricValues("metric", int64(100000-3600))
+		triggerChecker.cleanupMetricsValues([]string{"metric"}, 100000)
+	})
+}
@@ -3,6 +3,8 @@ package checker
 import "time"
 
 // Config represent checker config
+// Metric values are kept for MetricsTTL seconds, values of metrics downsampled by filter storage schema
+// are kept for retention of the last schema archive if it is longer
 type Config struct {
 	Enabled              bool
 	NoDataCheckInterval  time.Duration
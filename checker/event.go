@@ -23,6 +23,9 @@ func (triggerChecker *TriggerChecker) compareChecks(currentCheck moira.CheckData
 	if !needSend {
 		return currentCheck, nil
 	}
+	if message != nil && triggerChecker.isReminderAcknowledged(triggerChecker.lastCheck.GetEventTimestamp()) {
+		return currentCheck, nil
+	}
 	if message == nil {
 		message = &currentCheck.Message
 	}
@@ -58,6 +61,9 @@ func (triggerChecker *TriggerChecker) compareStates(metric string, currentState
 	if !needSend {
 		return currentState, nil
 	}
+	if message != nil && triggerChecker.isReminderAcknowledged(lastState.GetEventTimestamp()) {
+		return currentState, nil
+	}
 
 	event := moira.NotificationEvent{
 		TriggerID: triggerChecker.TriggerID,
@@ -90,9 +96,55 @@ func (triggerChecker *TriggerChecker) isTriggerSuppressed(event *moira.Notificat
 		triggerChecker.Logger.Infof("Event %v suppressed due to metric %s maintenance until %v.", event, metric, time.Unix(stateMaintenance, 0))
 		return true
 	}
+	if window := triggerChecker.getActiveMaintenanceWindow(timestamp); window != nil {
+		triggerChecker.Logger.Infof("Event %v suppressed due to maintenance window %s (%s)", event, window.ID, window.Name)
+		return true
+	}
 	return false
 }
 
+// getActiveMaintenanceWindow returns maintenance window selecting trigger and active at timestamp or nil if there is no such
+// maintenance windows are read once per trigger check
+func (triggerChecker *TriggerChecker) getActiveMaintenanceWindow(timestamp int64) *moira.MaintenanceWindow {
+	if triggerChecker.maintenanceWindows == nil {
+		windows, err := triggerChecker.Database.GetMaintenanceWindows()
+		if err != nil {
+			triggerChecker.Logger.Warningf("Trigger %s: %s", triggerChecker.TriggerID, err.Error())
+			return nil
+		}
+		triggerChecker.maintenanceWindows = make([]*moira.MaintenanceWindow, 0, len(windows))
+		for _, window := range windows {
+			if window.Matches(triggerChecker.TriggerID, triggerChecker.trigger.Tags) {
+				triggerChecker.maintenanceWindows = append(triggerChecker.maintenanceWindows, window)
+			}
+		}
+	}
+	for _, window := range triggerChecker.maintenanceWindows {
+		if window.IsActive(timestamp) {
+			return window
+		}
+	}
+	return nil
+}
+
+// isReminderAcknowledged returns true if trigger notification was acknowledged after the last event, e.g. by twilio voice call
+// acknowledgement is read once per trigger check
+func (triggerChecker *TriggerChecker) isReminderAcknowledged(lastEventTimestamp int64) bool {
+	if triggerChecker.ack == nil {
+		ack, err := triggerChecker.Database.GetTriggerAck(triggerChecker.TriggerID)
+		if err != nil {
+			triggerChecker.Logger.Warningf("Trigger %s: %s", triggerChecker.TriggerID, err.Error())
+			return false
+		}
+		triggerChecker.ack = &ack
+	}
+	if *triggerChecker.ack < lastEventTimestamp {
+		return false
+	}
+	triggerChecker.Logger.Infof("Trigger %s reminder suppressed due to acknowledgement at %v", triggerChecker.TriggerID, time.Unix(*triggerChecker.ack, 0))
+	return true
+}
+
 func needSendEvent(currentStateValue string, lastStateValue string, currentStateTimestamp int64, lastStateEventTimestamp int64, isLastStateSuppressed bool) (bool, *string) {
 	if currentStateValue != lastStateValue {
 		return true, nil
@@ -34,6 +34,11 @@ func TestCompareStates(t *testing.T) {
 		State:      NODATA,
 	}
 
+	// acknowledgement is read once by the first reminder and then cached in triggerChecker
+	dataBase.EXPECT().GetTriggerAck(triggerChecker.TriggerID).Return(int64(0), nil)
+	// maintenance windows are read once by the first event and then cached in triggerChecker
+	dataBase.EXPECT().GetMaintenanceWindows().Return(nil, nil)
+
 	Convey("Same state values", t, func() {
 		Convey("Status OK, no need to send", func() {
 			lastState := lastStateExample
@@ -160,6 +165,69 @@ func TestCompareStates(t *testing.T) {
 		})
 	})
 
+	Convey("Acknowledged trigger", t, func() {
+		lastState := lastStateExample
+		currentState := currentStateExample
+		lastState.State = NODATA
+		currentState.State = NODATA
+		currentState.Timestamp = 1502809200
+
+		Convey("Acknowledged after last event, no need to send reminder", func() {
+			ackedTriggerChecker := triggerChecker
+			ackedTriggerChecker.ack = nil
+			dataBase.EXPECT().GetTriggerAck(triggerChecker.TriggerID).Return(lastState.EventTimestamp+60, nil)
+
+			actual, err := ackedTriggerChecker.compareStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			currentState.EventTimestamp = lastState.EventTimestamp
+			So(actual, ShouldResemble, currentState)
+
+			actual, err = ackedTriggerChecker.compareStates("m2", currentState, lastState)
+			So(err, ShouldBeNil)
+			So(actual, ShouldResemble, currentState)
+		})
+
+		Convey("Acknowledged before last event, need to send reminder", func() {
+			ackedTriggerChecker := triggerChecker
+			ackedTriggerChecker.ack = nil
+			dataBase.EXPECT().GetTriggerAck(triggerChecker.TriggerID).Return(lastState.EventTimestamp-60, nil)
+
+			message := fmt.Sprintf("This metric has been in bad state for more than 24 hours - please, fix.")
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID: triggerChecker.TriggerID,
+				Timestamp: currentState.Timestamp,
+				State:     NODATA,
+				OldState:  NODATA,
+				Metric:    "m1",
+				Value:     currentState.Value,
+				Message:   &message,
+			}, true).Return(nil)
+			actual, err := ackedTriggerChecker.compareStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			currentState.EventTimestamp = currentState.Timestamp
+			So(actual, ShouldResemble, currentState)
+		})
+
+		Convey("State changed after acknowledgement, need to send", func() {
+			ackedTriggerChecker := triggerChecker
+			ackedTriggerChecker.ack = nil
+			currentState.State = OK
+
+			dataBase.EXPECT().PushNotificationEvent(&moira.NotificationEvent{
+				TriggerID: triggerChecker.TriggerID,
+				Timestamp: currentState.Timestamp,
+				State:     OK,
+				OldState:  NODATA,
+				Metric:    "m1",
+				Value:     currentState.Value,
+			}, true).Return(nil)
+			actual, err := ackedTriggerChecker.compareStates("m1", currentState, lastState)
+			So(err, ShouldBeNil)
+			currentState.EventTimestamp = currentState.Timestamp
+			So(actual, ShouldResemble, currentState)
+		})
+	})
+
 	Convey("Test different states", t, func() {
 		Convey("Trigger maintenance", func() {
 			lastState := lastStateExample
@@ -199,6 +267,8 @@ func TestCompareChecks(t *testing.T) {
 		Timestamp:  1502719200,
 	}
 
+	dataBase.EXPECT().GetMaintenanceWindows().Return(nil, nil)
+
 	Convey("Same states", t, func() {
 		Convey("No need send", func() {
 			lastCheck := lastCheckExample
@@ -290,3 +360,55 @@ func TestCompareChecks(t *testing.T) {
 		})
 	})
 }
+
+func TestMaintenanceWindowSuppression(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	logger, _ := logging.GetLogger("Test")
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	// weekly window from 02:00 to 04:00 starting on 2017-08-14
+	window := &moira.MaintenanceWindow{ID: "window", Tags: []string{"dc-eu"}, Start: 1502676000, End: 1502683200, Recurrence: moira.MaintenanceRecurrenceWeekly}
+	lastState := moira.MetricState{State: OK, Timestamp: 1503280800, EventTimestamp: 1502708400}
+	currentState := moira.MetricState{State: NODATA, Timestamp: 1503284400}
+	event := &moira.NotificationEvent{TriggerID: "SuperId", State: NODATA, OldState: OK, Timestamp: currentState.Timestamp, Metric: "m1"}
+
+	Convey("Event of trigger selected by active window is suppressed", t, func() {
+		triggerChecker := TriggerChecker{TriggerID: "SuperId", Database: dataBase, Logger: logger, trigger: &moira.Trigger{Tags: []string{"dc-eu", "db"}}}
+		dataBase.EXPECT().GetMaintenanceWindows().Return([]*moira.MaintenanceWindow{window}, nil)
+		actual, err := triggerChecker.compareStates("m1", currentState, lastState)
+		So(err, ShouldBeNil)
+		So(actual.Suppressed, ShouldBeTrue)
+	})
+
+	Convey("Event of trigger not selected by window is sent", t, func() {
+		triggerChecker := TriggerChecker{TriggerID: "SuperId", Database: dataBase, Logger: logger, trigger: &moira.Trigger{Tags: []string{"dc-us"}}}
+		dataBase.EXPECT().GetMaintenanceWindows().Return([]*moira.MaintenanceWindow{window}, nil)
+		dataBase.EXPECT().PushNotificationEvent(event, true).Return(nil)
+		actual, err := triggerChecker.compareStates("m1", currentState, lastState)
+		So(err, ShouldBeNil)
+		So(actual.Suppressed, ShouldBeFalse)
+	})
+
+	Convey("Event out of window is sent", t, func() {
+		triggerChecker := TriggerChecker{TriggerID: "SuperId", Database: dataBase, Logger: logger, trigger: &moira.Trigger{Tags: []string{"dc-eu"}}}
+		dataBase.EXPECT().GetMaintenanceWindows().Return([]*moira.MaintenanceWindow{window}, nil)
+		state := currentState
+		state.Timestamp += 3600
+		outEvent := *event
+		outEvent.Timestamp = state.Timestamp
+		dataBase.EXPECT().PushNotificationEvent(&outEvent, true).Return(nil)
+		actual, err := triggerChecker.compareStates("m1", state, lastState)
+		So(err, ShouldBeNil)
+		So(actual.Suppressed, ShouldBeFalse)
+	})
+
+	Convey("Event is sent if maintenance windows can not be read", t, func() {
+		triggerChecker := TriggerChecker{TriggerID: "SuperId", Database: dataBase, Logger: logger, trigger: &moira.Trigger{Tags: []string{"dc-eu"}}}
+		dataBase.EXPECT().GetMaintenanceWindows().Return(nil, fmt.Errorf("Oooops! Can not read maintenance windows"))
+		dataBase.EXPECT().PushNotificationEvent(event, true).Return(nil)
+		actual, err := triggerChecker.compareStates("m1", currentState, lastState)
+		So(err, ShouldBeNil)
+		So(actual.Suppressed, ShouldBeFalse)
+	})
+}
@@ -24,6 +24,10 @@ type TriggerChecker struct {
 
 	ttl      int64
 	ttlState string
+
+	ack *int64
+
+	maintenanceWindows []*moira.MaintenanceWindow
 }
 
 // ErrTriggerNotExists used if trigger to check does not exists
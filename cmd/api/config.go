@@ -1,6 +1,7 @@
 package main
 
 import (
+	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/cmd"
 )
 
@@ -11,21 +12,36 @@ type config struct {
 }
 
 type apiConfig struct {
-	Listen string `yaml:"listen"`
+	Listen      string        `yaml:"listen"`
+	AuthMethods []string      `yaml:"auth_methods"`
+	JWT         cmd.JWTConfig `yaml:"jwt"`
+	Admins      []string      `yaml:"admins"`
+}
+
+func (config *apiConfig) getSettings() api.Config {
+	return api.Config{
+		Enabled:     true,
+		Listen:      config.Listen,
+		AuthMethods: config.AuthMethods,
+		JWT:         config.JWT.GetSettings(),
+		Admins:      config.Admins,
+	}
 }
 
 func getDefault() config {
 	return config{
 		Redis: cmd.RedisConfig{
-			Host: "localhost",
-			Port: "6379",
+			Host:                "localhost",
+			Port:                "6379",
+			TriggerHistoryLimit: 100,
 		},
 		Logger: cmd.LoggerConfig{
 			LogFile:  "stdout",
 			LogLevel: "debug",
 		},
-		API: apiConfig {
-			Listen: ":8081",
+		API: apiConfig{
+			Listen:      ":8081",
+			AuthMethods: []string{api.AuthHeader},
 		},
 	}
 }
@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api/auth"
 	"github.com/moira-alert/moira/api/handler"
 	"github.com/moira-alert/moira/cmd"
 	"github.com/moira-alert/moira/database/redis"
@@ -71,7 +72,12 @@ func main() {
 
 	logger.Infof("Start listening by address: [%s]", config.API.Listen)
 
-	httpHandler := handler.NewHandler(database, logger)
+	authentication, err := auth.NewAuthentication(config.API.getSettings(), database)
+	if err != nil {
+		logger.Fatalf("Can not configure api authentication: %s", err.Error())
+	}
+
+	httpHandler := handler.NewHandler(database, logger, authentication)
 	server := &http.Server{
 		Handler: httpHandler,
 	}
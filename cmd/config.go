@@ -8,23 +8,26 @@ import (
 	"gopkg.in/yaml.v2"
 	"menteslibres.net/gosexy/to"
 
+	"github.com/moira-alert/moira/api"
 	"github.com/moira-alert/moira/database/redis"
 	"github.com/moira-alert/moira/metrics/graphite"
 )
 
 // RedisConfig is redis config structure, which are taken on the start of moira
 type RedisConfig struct {
-	Host string `yaml:"host"`
-	Port string `yaml:"port"`
-	DBID int    `yaml:"dbid"`
+	Host                string `yaml:"host"`
+	Port                string `yaml:"port"`
+	DBID                int    `yaml:"dbid"`
+	TriggerHistoryLimit int    `yaml:"trigger_history_limit"`
 }
 
 // GetSettings return redis config parsed from moira config files
 func (config *RedisConfig) GetSettings() redis.Config {
 	return redis.Config{
-		Host: config.Host,
-		Port: config.Port,
-		DBID: config.DBID,
+		Host:                config.Host,
+		Port:                config.Port,
+		DBID:                config.DBID,
+		TriggerHistoryLimit: config.TriggerHistoryLimit,
 	}
 }
 
@@ -46,6 +49,24 @@ func (graphiteConfig *GraphiteConfig) GetSettings() graphite.Config {
 	}
 }
 
+// JWTConfig is api JWT bearer tokens validation settings, which are taken on the start of moira
+type JWTConfig struct {
+	Issuer     string `yaml:"issuer"`
+	Audience   string `yaml:"audience"`
+	KeysFile   string `yaml:"keys_file"`
+	LoginClaim string `yaml:"login_claim"`
+}
+
+// GetSettings return api JWT config parsed from moira config files
+func (config *JWTConfig) GetSettings() api.JWTConfig {
+	return api.JWTConfig{
+		Issuer:     config.Issuer,
+		Audience:   config.Audience,
+		KeysFile:   config.KeysFile,
+		LoginClaim: config.LoginClaim,
+	}
+}
+
 // LoggerConfig is logger settings, which are taken on the start of moira
 type LoggerConfig struct {
 	LogFile  string `yaml:"log_file"`
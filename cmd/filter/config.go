@@ -12,8 +12,25 @@ type config struct {
 }
 
 type filterConfig struct {
-	Listen          string `yaml:"listen"`
-	RetentionConfig string `yaml:"retention-config"`
+	Mode                    string   `yaml:"mode"`
+	Listen                  string   `yaml:"listen"`
+	ListenPickle            string   `yaml:"listen_pickle"`
+	ListenUDP               string   `yaml:"listen_udp"`
+	ListenPrometheus        string   `yaml:"listen_prometheus"`
+	PrometheusPathLabels    []string `yaml:"prometheus_path_labels"`
+	RetentionConfig         string   `yaml:"retention-config"`
+	AggregationConfig       string   `yaml:"aggregation-config"`
+	RewriteConfig           string   `yaml:"rewrite-config"`
+	PatternsRefreshInterval string   `yaml:"patterns_refresh_interval"`
+	QueueSize               int      `yaml:"queue_size"`
+	QueueOverflowPolicy     string   `yaml:"queue_overflow_policy"`
+	BatchSize               int      `yaml:"batch_size"`
+	FlushInterval           string   `yaml:"flush_interval"`
+	SaveRetries             int      `yaml:"save_retries"`
+	SaveRetryInterval       string   `yaml:"save_retry_interval"`
+	ShardID                 string   `yaml:"shard_id"`
+	ShardAddress            string   `yaml:"shard_address"`
+	RelayRefreshInterval    string   `yaml:"relay_refresh_interval"`
 }
 
 func getDefault() config {
@@ -28,8 +45,18 @@ func getDefault() config {
 			LogLevel: "debug",
 		},
 		Filter: filterConfig{
-			Listen:          ":2003",
-			RetentionConfig: "storage-schemas.conf",
+			Mode:                    "filter",
+			Listen:                  ":2003",
+			PrometheusPathLabels:    []string{"job", "instance", "__name__"},
+			RetentionConfig:         "storage-schemas.conf",
+			PatternsRefreshInterval: "60s0ms",
+			QueueSize:               10000,
+			QueueOverflowPolicy:     "block",
+			BatchSize:               10,
+			FlushInterval:           "1s0ms",
+			SaveRetries:             3,
+			SaveRetryInterval:       "1s0ms",
+			RelayRefreshInterval:    "10s0ms",
 		},
 		Graphite: cmd.GraphiteConfig{
 			URI:      "localhost:2003",
@@ -8,6 +8,8 @@ import (
 	"sync"
 	"syscall"
 
+	"menteslibres.net/gosexy/to"
+
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/cmd"
 	"github.com/moira-alert/moira/database/redis"
@@ -16,7 +18,10 @@ import (
 	"github.com/moira-alert/moira/filter/heartbeat"
 	"github.com/moira-alert/moira/filter/matched_metrics"
 	"github.com/moira-alert/moira/filter/patterns"
+	"github.com/moira-alert/moira/filter/relay"
+	"github.com/moira-alert/moira/filter/sharding"
 	"github.com/moira-alert/moira/logging/go-logging"
+	"github.com/moira-alert/moira/metrics/graphite"
 	"github.com/moira-alert/moira/metrics/graphite/go-metrics"
 )
 
@@ -72,6 +77,15 @@ func main() {
 
 	database := redis.NewDatabase(logger, config.Redis.GetSettings())
 
+	switch config.Filter.Mode {
+	case filter.ModeFilter:
+	case filter.ModeRelay:
+		runRelay(database, cacheMetrics, config.Filter)
+		return
+	default:
+		logger.Fatalf("Unknown filter mode '%s'", config.Filter.Mode)
+	}
+
 	retentionConfigFile, err := os.Open(config.Filter.RetentionConfig)
 	if err != nil {
 		logger.Fatalf("Error open retentions file [%s]: %s", config.Filter.RetentionConfig, err.Error())
@@ -82,13 +96,33 @@ func main() {
 		logger.Fatalf("Failed to initialize cache storage with config [%s]: %s", config.Filter.RetentionConfig, err.Error())
 	}
 
+	if config.Filter.AggregationConfig != "" {
+		aggregationConfigFile, err := os.Open(config.Filter.AggregationConfig)
+		if err != nil {
+			logger.Fatalf("Error open aggregations file [%s]: %s", config.Filter.AggregationConfig, err.Error())
+		}
+		if err = cacheStorage.LoadAggregations(aggregationConfigFile); err != nil {
+			logger.Fatalf("Failed to load aggregations config [%s]: %s", config.Filter.AggregationConfig, err.Error())
+		}
+	}
+
 	patternStorage, err := filter.NewPatternStorage(database, cacheMetrics, logger)
 	if err != nil {
 		logger.Fatalf("Failed to refresh pattern storage: %s", err.Error())
 	}
 
+	if config.Filter.RewriteConfig != "" {
+		rewriteConfigFile, err := os.Open(config.Filter.RewriteConfig)
+		if err != nil {
+			logger.Fatalf("Error open rewrite rules file [%s]: %s", config.Filter.RewriteConfig, err.Error())
+		}
+		if err = patternStorage.LoadRewriteRules(rewriteConfigFile); err != nil {
+			logger.Fatalf("Failed to load rewrite rules config [%s]: %s", config.Filter.RewriteConfig, err.Error())
+		}
+	}
+
 	// Refresh Patterns on first init
-	refreshPatternWorker := patterns.NewRefreshPatternWorker(database, cacheMetrics, logger, patternStorage)
+	refreshPatternWorker := patterns.NewRefreshPatternWorker(database, cacheMetrics, logger, patternStorage, to.Duration(config.Filter.PatternsRefreshInterval))
 
 	// Start patterns refresher
 	err = refreshPatternWorker.Start()
@@ -103,19 +137,55 @@ func main() {
 	defer stopHeartbeatWorker(heartbeatWorker)
 
 	// Start metrics listener
-	listener, err := connection.NewListener(config.Filter.Listen, logger, patternStorage)
+	listener, err := connection.NewListener(config.Filter.Listen, logger, cacheMetrics, patternStorage)
 	if err != nil {
 		logger.Fatalf("Failed to start listen: %s", err.Error())
 	}
+	if err = listener.SetQueue(config.Filter.QueueSize, config.Filter.QueueOverflowPolicy); err != nil {
+		logger.Fatalf("Failed to configure metrics queue: %s", err.Error())
+	}
+	if config.Filter.ListenPickle != "" {
+		if err = listener.ListenPickle(config.Filter.ListenPickle); err != nil {
+			logger.Fatalf("Failed to start listen: %s", err.Error())
+		}
+	}
+	if config.Filter.ListenUDP != "" {
+		if err = listener.ListenUDP(config.Filter.ListenUDP); err != nil {
+			logger.Fatalf("Failed to start listen: %s", err.Error())
+		}
+	}
+	if config.Filter.ListenPrometheus != "" {
+		if err = listener.ListenPrometheus(config.Filter.ListenPrometheus, config.Filter.PrometheusPathLabels); err != nil {
+			logger.Fatalf("Failed to start listen: %s", err.Error())
+		}
+	}
 	metricsChan := listener.Listen()
 
 	// Start metrics matcher
 	var matcherWG sync.WaitGroup
-	metricsMatcher := matchedmetrics.NewMetricsMatcher(cacheMetrics, logger, database, cacheStorage)
+	metricsMatcher := matchedmetrics.NewMetricsMatcher(cacheMetrics, logger, database, cacheStorage, matchedmetrics.Config{
+		QueueSize:         config.Filter.QueueSize,
+		BatchSize:         config.Filter.BatchSize,
+		FlushInterval:     to.Duration(config.Filter.FlushInterval),
+		SaveRetries:       config.Filter.SaveRetries,
+		SaveRetryInterval: to.Duration(config.Filter.SaveRetryInterval),
+	})
 	metricsMatcher.Start(metricsChan, &matcherWG)
 	defer matcherWG.Wait()       // First stop listener
 	defer stopListener(listener) // Then waiting for metrics matcher handle all received events
 
+	// Register filter shard to receive metrics from relays
+	if config.Filter.ShardID != "" {
+		registrationWorker := sharding.NewRegistrationWorker(database, logger, moira.FilterShard{
+			ID:      config.Filter.ShardID,
+			Address: config.Filter.ShardAddress,
+		})
+		if err = registrationWorker.Start(); err != nil {
+			logger.Fatalf("Failed to register filter shard: %s", err.Error())
+		}
+		defer stopRegistrationWorker(registrationWorker) // Stop receiving metrics from relays before listener is stopped
+	}
+
 	logger.Infof("Moira Filter started. Version: %s", MoiraVersion)
 	ch := make(chan os.Signal, 1)
 	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
@@ -123,6 +193,36 @@ func main() {
 	logger.Infof("Moira Filter shutting down.")
 }
 
+// runRelay routes received metrics to registered filter shards until process is stopped
+func runRelay(database moira.Database, cacheMetrics *graphite.FilterMetrics, config filterConfig) {
+	metricsRelay, err := relay.NewRelay(config.Listen, database, cacheMetrics, logger, to.Duration(config.RelayRefreshInterval), config.QueueSize)
+	if err != nil {
+		logger.Fatalf("Failed to start listen: %s", err.Error())
+	}
+	if err = metricsRelay.Start(); err != nil {
+		logger.Fatalf("Failed to start relay: %s", err.Error())
+	}
+	defer stopRelay(metricsRelay)
+
+	logger.Infof("Moira Filter Relay started. Version: %s", MoiraVersion)
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGINT, syscall.SIGTERM)
+	logger.Info(fmt.Sprint(<-ch))
+	logger.Infof("Moira Filter Relay shutting down.")
+}
+
+func stopRelay(metricsRelay *relay.Relay) {
+	if err := metricsRelay.Stop(); err != nil {
+		logger.Errorf("Failed to stop relay: %v", err)
+	}
+}
+
+func stopRegistrationWorker(registrationWorker *sharding.RegistrationWorker) {
+	if err := registrationWorker.Stop(); err != nil {
+		logger.Errorf("Failed to stop filter shard registration: %v", err)
+	}
+}
+
 func stopListener(listener *connection.MetricsListener) {
 	if err := listener.Stop(); err != nil {
 		logger.Errorf("Failed to stop listener: %v", err)
@@ -15,9 +15,10 @@ func getDefault() config {
 		LogFile:  "stdout",
 		LogLevel: "debug",
 		Redis: cmd.RedisConfig{
-			Host: "redis",
-			Port: "6379",
-			DBID: 0,
+			Host:                "redis",
+			Port:                "6379",
+			DBID:                0,
+			TriggerHistoryLimit: 100,
 		},
 	}
 }
@@ -5,8 +5,10 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api/controller"
 	"github.com/moira-alert/moira/cmd"
 	"github.com/moira-alert/moira/database/redis"
 	"github.com/moira-alert/moira/logging/go-logging"
@@ -20,6 +22,9 @@ var (
 	convertPythonExpression         = flag.String("convert-expression", "", "Convert python expression used in moira 1.x to govaluate expressions in moira 2.x for concrete trigger")
 	getTriggerWithPythonExpressions = flag.Bool("python-expressions-triggers", false, "Get count of triggers with python expression and count of triggers, that has python expression and has not govaluate expression")
 	removeBotInstanceLock           = flag.String("delete-bot-host-lock", "", "Delete bot host lock for launching bots with new distributed lock strategy. Must use for upgrade from Moira 1.x to 2.x")
+	matchMetric                     = flag.String("match-metric", "", "Show patterns and triggers matched by metric name, metric retention and values saved for last hour")
+	fillEventsTimeline              = flag.Bool("fill-events-timeline", false, "Add stored events of all triggers to events timeline used by api events search. Must use for upgrade to find events pushed by previous versions")
+	rebuildTriggerSearchIndex       = flag.Bool("rebuild-trigger-search-index", false, "Rebuild trigger search index used by api trigger search. Index is also rebuilt by first search after upgrade")
 )
 
 // Moira version
@@ -81,12 +86,50 @@ func main() {
 		}
 	}
 
+	if *matchMetric != "" {
+		if err := MatchMetric(dataBase, log, *matchMetric); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to match metric: %v", err)
+			os.Exit(1)
+		}
+	}
+
+	if *rebuildTriggerSearchIndex {
+		fmt.Println("Rebuilding trigger search index started")
+		if err := dataBase.RebuildTriggerSearchIndex(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to rebuild trigger search index: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println("Trigger search index successfully rebuilt")
+	}
+
+	if *fillEventsTimeline {
+		fmt.Println("Filling events timeline started")
+		if err := dataBase.FillNotificationEventsTimeline(); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to fill events timeline: %v", err)
+			os.Exit(1)
+		}
+		fmt.Println("Events timeline successfully filled")
+	}
+
 	if *convertPythonExpression != "" {
 		if err := ConvertPythonExpression(dataBase, *convertPythonExpression); err != nil {
 			fmt.Fprintf(os.Stderr, "Failed to convert: %v", err)
 			os.Exit(1)
 		}
 	}
+
+	switch flag.Arg(0) {
+	case "export":
+		if err := Export(dataBase, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to export: %v\n", err)
+			os.Exit(1)
+		}
+	case "sync":
+		if err := Sync(dataBase, log, flag.Args()[1:]); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to sync: %v\n", err)
+			os.Exit(1)
+		}
+	}
 }
 
 // RemoveBotInstanceLock - in Moira 2.0 we switch from host-based single instance telegram-bot run lock
@@ -101,6 +144,32 @@ func RemoveBotInstanceLock(dataBase moira.Database, botName string) error {
 	return nil
 }
 
+// MatchMetric runs metric name through pattern tree built from current patterns like filter does
+// and prints matched patterns with their triggers, metric retention and values saved for last hour
+func MatchMetric(dataBase moira.Database, logger moira.Logger, metric string) error {
+	until := time.Now().Unix()
+	metricMatch, errResponse := controller.MatchMetric(dataBase, logger, metric, until-3600, until)
+	if errResponse != nil {
+		return errResponse.Err
+	}
+	fmt.Println(fmt.Sprintf("Metric: %s", metricMatch.Metric))
+	if len(metricMatch.Patterns) == 0 {
+		fmt.Println("No patterns matched, metric is not saved by filter")
+	}
+	for _, pattern := range metricMatch.Patterns {
+		fmt.Println(fmt.Sprintf("Pattern: %s", pattern.Pattern))
+		for _, trigger := range pattern.Triggers {
+			fmt.Println(fmt.Sprintf("    Trigger %s: %s", trigger.ID, trigger.Name))
+		}
+	}
+	fmt.Println(fmt.Sprintf("Retention: %d", metricMatch.Retention))
+	fmt.Println(fmt.Sprintf("Values for last hour: %d", len(metricMatch.Values)))
+	for _, value := range metricMatch.Values {
+		fmt.Println(fmt.Sprintf("    %s %v", time.Unix(value.Timestamp, 0).Format(time.RFC3339), value.Value))
+	}
+	return nil
+}
+
 // GetTriggerWithPythonExpressions iterate by all triggers in system and print triggers
 // count with python expressions and triggers count with govaluate expressions, used in Moira 2.0
 func GetTriggerWithPythonExpressions(dataBase moira.Database) error {
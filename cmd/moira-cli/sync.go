@@ -0,0 +1,159 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/api/controller"
+	"github.com/moira-alert/moira/api/dto"
+)
+
+// syncAuthor is trigger history author of changes made by sync
+const syncAuthor = "moira-cli"
+
+// Export writes definitions of all triggers and contacts and subscriptions of given user to file or stdout
+func Export(dataBase moira.Database, args []string) error {
+	flags := flag.NewFlagSet("export", flag.ExitOnError)
+	output := flags.String("o", "", "Path to output file, stdout is used if empty")
+	format := flags.String("format", dto.ExportYAML, "Output format: yaml or json")
+	userLogin := flags.String("user", "", "Login of user whose contacts and subscriptions are exported")
+	flags.Parse(args)
+
+	exported, errorResponse := controller.ExportTriggers(dataBase, *userLogin)
+	if errorResponse != nil {
+		return errorResponse.Err
+	}
+	data, err := exported.Encode(*format)
+	if err != nil {
+		return err
+	}
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return ioutil.WriteFile(*output, data, 0644)
+}
+
+// Sync reads trigger definitions from yaml and json files of directory, prints plan of changes and applies it on confirmation
+// Only triggers synced before from the same namespace are deleted, namespace is required to not depend on directory location,
+// triggers of renamed namespace are moved to new one by sync with -previous-namespace
+func Sync(dataBase moira.Database, logger moira.Logger, args []string) error {
+	flags := flag.NewFlagSet("sync", flag.ExitOnError)
+	directory := flags.String("f", "", "Path to directory with trigger definition files")
+	namespace := flags.String("namespace", "", "Name of triggers set defined by directory, e.g. repository name")
+	previousNamespace := flags.String("previous-namespace", "", "Former namespace of directory, its triggers are moved to namespace")
+	autoApprove := flags.Bool("yes", false, "Apply plan without confirmation")
+	flags.Parse(args)
+	if *directory == "" {
+		return fmt.Errorf("directory with trigger definitions is not specified")
+	}
+	if *namespace == "" {
+		return fmt.Errorf("namespace of trigger definitions is not specified")
+	}
+
+	sources, err := readTriggerDefinitions(*directory)
+	if err != nil {
+		return err
+	}
+	validate := func(trigger *dto.Trigger) (map[string]bool, error) {
+		return trigger.Validate(dataBase, logger)
+	}
+	plan, errorResponse := controller.PlanTriggersSync(dataBase, *namespace, *previousNamespace, sources, validate)
+	if errorResponse != nil {
+		return errorResponse.Err
+	}
+	printTriggersSyncPlan(plan)
+	if len(plan.List) == 0 {
+		return nil
+	}
+
+	if !*autoApprove {
+		fmt.Print("Apply changes? [y/N]: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.ToLower(strings.TrimSpace(answer)) != "y" {
+			fmt.Println("Sync cancelled")
+			return nil
+		}
+	}
+	if errorResponse := controller.ApplyTriggersSyncPlan(dataBase, plan, syncAuthor); errorResponse != nil {
+		return errorResponse.Err
+	}
+	fmt.Println("Sync successfully applied")
+	return nil
+}
+
+// readTriggerDefinitions decodes yaml and json files of directory and its subdirectories,
+// file paths relative to directory are used as definition sources
+func readTriggerDefinitions(directory string) (map[string]*dto.Export, error) {
+	sources := make(map[string]*dto.Export)
+	err := filepath.Walk(directory, func(path string, file os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		var format string
+		switch filepath.Ext(file.Name()) {
+		case ".yml", ".yaml":
+			format = dto.ExportYAML
+		case ".json":
+			format = dto.ExportJSON
+		default:
+			return nil
+		}
+		if file.IsDir() {
+			return nil
+		}
+		name, err := filepath.Rel(directory, path)
+		if err != nil {
+			return err
+		}
+		name = filepath.ToSlash(name)
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		definitions, err := dto.DecodeExport(data, format)
+		if err != nil {
+			return fmt.Errorf("Failed to parse %s: %s", name, err.Error())
+		}
+		if len(definitions.Contacts) > 0 || len(definitions.Subscriptions) > 0 {
+			fmt.Println(fmt.Sprintf("Contacts and subscriptions of %s are not synced", name))
+		}
+		sources[name] = definitions
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return sources, nil
+}
+
+func printTriggersSyncPlan(plan *dto.TriggersSyncPlan) {
+	if len(plan.List) == 0 {
+		fmt.Println("Triggers are up to date")
+		return
+	}
+	counts := make(map[string]int)
+	signs := map[string]string{dto.TriggerSyncCreate: "+", dto.TriggerSyncUpdate: "~", dto.TriggerSyncDelete: "-"}
+	for _, action := range plan.List {
+		counts[action.Action]++
+		line := fmt.Sprintf("%s %s trigger %s (%s) from %s", signs[action.Action], action.Action, action.TriggerID, action.Name, action.Source)
+		if action.ManuallyEdited {
+			line += ", manual changes will be lost"
+		}
+		fmt.Println(line)
+		for _, change := range action.Changes {
+			oldValue, _ := json.Marshal(change.OldValue)
+			newValue, _ := json.Marshal(change.NewValue)
+			fmt.Println(fmt.Sprintf("    %s: %s -> %s", change.Field, oldValue, newValue))
+		}
+	}
+	fmt.Println(fmt.Sprintf("Plan: %d to create, %d to update, %d to delete",
+		counts[dto.TriggerSyncCreate], counts[dto.TriggerSyncUpdate], counts[dto.TriggerSyncDelete]))
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/moira-alert/moira/api"
+	"github.com/moira-alert/moira/api/auth"
 	"github.com/moira-alert/moira/api/handler"
 	"github.com/moira-alert/moira/database/redis"
 	"github.com/moira-alert/moira/logging/go-logging"
@@ -42,7 +43,12 @@ func (apiService *APIService) Start() error {
 		return err
 	}
 
-	httpHandler := handler.NewHandler(dataBase, logger)
+	authentication, err := auth.NewAuthentication(*apiService.Config, dataBase)
+	if err != nil {
+		return fmt.Errorf("Can't configure Api authentication: %v", err)
+	}
+
+	httpHandler := handler.NewHandler(dataBase, logger, authentication)
 	apiService.http = &http.Server{
 		Handler: httpHandler,
 	}
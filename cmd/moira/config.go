@@ -28,34 +28,74 @@ type config struct {
 // API Config
 
 type apiConfig struct {
-	Enabled  string `yaml:"enabled"`
-	Listen   string `yaml:"listen"`
-	LogFile  string `yaml:"log_file"`
-	LogLevel string `yaml:"log_level"`
+	Enabled     string        `yaml:"enabled"`
+	Listen      string        `yaml:"listen"`
+	AuthMethods []string      `yaml:"auth_methods"`
+	JWT         cmd.JWTConfig `yaml:"jwt"`
+	Admins      []string      `yaml:"admins"`
+	LogFile     string        `yaml:"log_file"`
+	LogLevel    string        `yaml:"log_level"`
 }
 
 func (config *apiConfig) getSettings() *api.Config {
 	return &api.Config{
-		Enabled: cmd.ToBool(config.Enabled),
-		Listen:  config.Listen,
+		Enabled:     cmd.ToBool(config.Enabled),
+		Listen:      config.Listen,
+		AuthMethods: config.AuthMethods,
+		JWT:         config.JWT.GetSettings(),
+		Admins:      config.Admins,
 	}
 }
 
 // Filter Config
 
 type filterConfig struct {
-	Enabled         string `yaml:"enabled"`
-	Listen          string `yaml:"listen"`
-	RetentionConfig string `yaml:"retention-config"`
-	LogFile         string `yaml:"log_file"`
-	LogLevel        string `yaml:"log_level"`
+	Enabled                 string   `yaml:"enabled"`
+	Mode                    string   `yaml:"mode"`
+	Listen                  string   `yaml:"listen"`
+	ListenPickle            string   `yaml:"listen_pickle"`
+	ListenUDP               string   `yaml:"listen_udp"`
+	ListenPrometheus        string   `yaml:"listen_prometheus"`
+	PrometheusPathLabels    []string `yaml:"prometheus_path_labels"`
+	RetentionConfig         string   `yaml:"retention-config"`
+	AggregationConfig       string   `yaml:"aggregation-config"`
+	RewriteConfig           string   `yaml:"rewrite-config"`
+	PatternsRefreshInterval string   `yaml:"patterns_refresh_interval"`
+	QueueSize               int      `yaml:"queue_size"`
+	QueueOverflowPolicy     string   `yaml:"queue_overflow_policy"`
+	BatchSize               int      `yaml:"batch_size"`
+	FlushInterval           string   `yaml:"flush_interval"`
+	SaveRetries             int      `yaml:"save_retries"`
+	SaveRetryInterval       string   `yaml:"save_retry_interval"`
+	ShardID                 string   `yaml:"shard_id"`
+	ShardAddress            string   `yaml:"shard_address"`
+	RelayRefreshInterval    string   `yaml:"relay_refresh_interval"`
+	LogFile                 string   `yaml:"log_file"`
+	LogLevel                string   `yaml:"log_level"`
 }
 
 func (config *filterConfig) getSettings() *filter.Config {
 	return &filter.Config{
-		Enabled:         cmd.ToBool(config.Enabled),
-		Listen:          config.Listen,
-		RetentionConfig: config.RetentionConfig,
+		Enabled:                 cmd.ToBool(config.Enabled),
+		Mode:                    config.Mode,
+		Listen:                  config.Listen,
+		ListenPickle:            config.ListenPickle,
+		ListenUDP:               config.ListenUDP,
+		ListenPrometheus:        config.ListenPrometheus,
+		PrometheusPathLabels:    config.PrometheusPathLabels,
+		RetentionConfig:         config.RetentionConfig,
+		AggregationConfig:       config.AggregationConfig,
+		RewriteConfig:           config.RewriteConfig,
+		PatternsRefreshInterval: to.Duration(config.PatternsRefreshInterval),
+		QueueSize:               config.QueueSize,
+		QueueOverflowPolicy:     config.QueueOverflowPolicy,
+		BatchSize:               config.BatchSize,
+		FlushInterval:           to.Duration(config.FlushInterval),
+		SaveRetries:             config.SaveRetries,
+		SaveRetryInterval:       to.Duration(config.SaveRetryInterval),
+		ShardID:                 config.ShardID,
+		ShardAddress:            config.ShardAddress,
+		RelayRefreshInterval:    to.Duration(config.RelayRefreshInterval),
 	}
 }
 
@@ -86,15 +126,18 @@ func (config *checkerConfig) getSettings() *checker.Config {
 //  Notifier Config
 
 type notifierConfig struct {
-	Enabled          string              `yaml:"enabled"`
-	SenderTimeout    string              `yaml:"sender_timeout"`
-	ResendingTimeout string              `yaml:"resending_timeout"`
-	Senders          []map[string]string `yaml:"senders"`
-	SelfState        selfStateConfig     `yaml:"moira_selfstate"`
-	LogFile          string              `yaml:"log_file"`
-	LogLevel         string              `yaml:"log_level"`
-	FrontURL         string              `yaml:"front_uri"`
-	Timezone         string              `yaml:"timezone"`
+	Enabled              string              `yaml:"enabled"`
+	SenderTimeout        string              `yaml:"sender_timeout"`
+	ResendingTimeout     string              `yaml:"resending_timeout"`
+	SenderFailureRate    float64             `yaml:"sender_failure_rate"`
+	SenderFailuresWindow string              `yaml:"sender_failures_window"`
+	SenderMinAttempts    int                 `yaml:"sender_min_attempts"`
+	Senders              []map[string]string `yaml:"senders"`
+	SelfState            selfStateConfig     `yaml:"moira_selfstate"`
+	LogFile              string              `yaml:"log_file"`
+	LogLevel             string              `yaml:"log_level"`
+	FrontURL             string              `yaml:"front_uri"`
+	Timezone             string              `yaml:"timezone"`
 }
 
 func (config *notifierConfig) getSettings(logger moira.Logger) *notifier.Config {
@@ -107,14 +150,17 @@ func (config *notifierConfig) getSettings(logger moira.Logger) *notifier.Config
 	}
 
 	return &notifier.Config{
-		Enabled:          cmd.ToBool(config.Enabled),
-		SendingTimeout:   to.Duration(config.SenderTimeout),
-		ResendingTimeout: to.Duration(config.ResendingTimeout),
-		Senders:          config.Senders,
-		LogFile:          config.LogFile,
-		LogLevel:         config.LogLevel,
-		FrontURL:         config.FrontURL,
-		Location:         location,
+		Enabled:              cmd.ToBool(config.Enabled),
+		SendingTimeout:       to.Duration(config.SenderTimeout),
+		ResendingTimeout:     to.Duration(config.ResendingTimeout),
+		SenderFailureRate:    config.SenderFailureRate,
+		SenderFailuresWindow: to.Duration(config.SenderFailuresWindow),
+		SenderMinAttempts:    config.SenderMinAttempts,
+		Senders:              config.Senders,
+		LogFile:              config.LogFile,
+		LogLevel:             config.LogLevel,
+		FrontURL:             config.FrontURL,
+		Location:             location,
 	}
 }
 
@@ -143,22 +189,34 @@ func getDefault() config {
 		LogFile:  "stdout",
 		LogLevel: "debug",
 		Redis: cmd.RedisConfig{
-			Host: "redis",
-			Port: "6379",
-			DBID: 0,
+			Host:                "redis",
+			Port:                "6379",
+			DBID:                0,
+			TriggerHistoryLimit: 100,
 		},
 		API: apiConfig{
-			Enabled:  "true",
-			Listen:   ":8081",
-			LogFile:  "stdout",
-			LogLevel: "debug",
+			Enabled:     "true",
+			Listen:      ":8081",
+			AuthMethods: []string{api.AuthHeader},
+			LogFile:     "stdout",
+			LogLevel:    "debug",
 		},
 		Filter: filterConfig{
-			Enabled:         "true",
-			Listen:          ":2003",
-			RetentionConfig: "storage-schemas.conf",
-			LogFile:         "stdout",
-			LogLevel:        "debug",
+			Enabled:                 "true",
+			Mode:                    "filter",
+			Listen:                  ":2003",
+			PrometheusPathLabels:    []string{"job", "instance", "__name__"},
+			RetentionConfig:         "storage-schemas.conf",
+			PatternsRefreshInterval: "60s0ms",
+			QueueSize:               10000,
+			QueueOverflowPolicy:     "block",
+			BatchSize:               10,
+			FlushInterval:           "1s0ms",
+			SaveRetries:             3,
+			SaveRetryInterval:       "1s0ms",
+			RelayRefreshInterval:    "10s0ms",
+			LogFile:                 "stdout",
+			LogLevel:                "debug",
 		},
 		Checker: checkerConfig{
 			Enabled:              "true",
@@ -170,9 +228,12 @@ func getDefault() config {
 			LogLevel:             "debug",
 		},
 		Notifier: notifierConfig{
-			Enabled:          "true",
-			SenderTimeout:    "10s0ms",
-			ResendingTimeout: "24:00",
+			Enabled:              "true",
+			SenderTimeout:        "10s0ms",
+			ResendingTimeout:     "24:00",
+			SenderFailureRate:    0.5,
+			SenderFailuresWindow: "300s0ms",
+			SenderMinAttempts:    10,
 			SelfState: selfStateConfig{
 				Enabled:                 "false",
 				RedisDisconnectDelay:    30,
@@ -5,12 +5,15 @@ import (
 	"os"
 	"sync"
 
+	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/database/redis"
 	"github.com/moira-alert/moira/filter"
 	"github.com/moira-alert/moira/filter/connection"
 	"github.com/moira-alert/moira/filter/heartbeat"
 	"github.com/moira-alert/moira/filter/matched_metrics"
 	"github.com/moira-alert/moira/filter/patterns"
+	"github.com/moira-alert/moira/filter/relay"
+	"github.com/moira-alert/moira/filter/sharding"
 	"github.com/moira-alert/moira/logging/go-logging"
 	"github.com/moira-alert/moira/metrics/graphite/go-metrics"
 )
@@ -27,6 +30,8 @@ type FilterService struct {
 	matcherWG            *sync.WaitGroup
 	refreshPatternWorker *patterns.RefreshPatternWorker
 	heartbeatWorker      *heartbeat.Worker
+	registrationWorker   *sharding.RegistrationWorker
+	relay                *relay.Relay
 }
 
 // Start Moira Filter
@@ -44,6 +49,20 @@ func (filterService *FilterService) Start() error {
 	dataBase := redis.NewDatabase(logger, *filterService.DatabaseConfig)
 	cacheMetrics := metrics.ConfigureFilterMetrics("filter")
 
+	switch filterService.Config.Mode {
+	case filter.ModeFilter:
+	case filter.ModeRelay:
+		if filterService.relay, err = relay.NewRelay(filterService.Config.Listen, dataBase, cacheMetrics, logger, filterService.Config.RelayRefreshInterval, filterService.Config.QueueSize); err != nil {
+			return fmt.Errorf("Failed to start listen: %s", err.Error())
+		}
+		if err = filterService.relay.Start(); err != nil {
+			return fmt.Errorf("Failed to start relay: %s", err.Error())
+		}
+		return nil
+	default:
+		return fmt.Errorf("Unknown filter mode '%s'", filterService.Config.Mode)
+	}
+
 	retentionConfigFile, err := os.Open(filterService.Config.RetentionConfig)
 	if err != nil {
 		return err
@@ -54,12 +73,32 @@ func (filterService *FilterService) Start() error {
 		return fmt.Errorf("Failed to initialize cache storage with config [%s]: %v", filterService.Config.RetentionConfig, err)
 	}
 
+	if filterService.Config.AggregationConfig != "" {
+		aggregationConfigFile, err := os.Open(filterService.Config.AggregationConfig)
+		if err != nil {
+			return err
+		}
+		if err = cacheStorage.LoadAggregations(aggregationConfigFile); err != nil {
+			return fmt.Errorf("Failed to load aggregations config [%s]: %v", filterService.Config.AggregationConfig, err)
+		}
+	}
+
 	patternStorage, err := filter.NewPatternStorage(dataBase, cacheMetrics, logger)
 	if err != nil {
 		return fmt.Errorf("Failed to refresh pattern storage: %s", err.Error())
 	}
 
-	filterService.refreshPatternWorker = patterns.NewRefreshPatternWorker(dataBase, cacheMetrics, logger, patternStorage)
+	if filterService.Config.RewriteConfig != "" {
+		rewriteConfigFile, err := os.Open(filterService.Config.RewriteConfig)
+		if err != nil {
+			return err
+		}
+		if err = patternStorage.LoadRewriteRules(rewriteConfigFile); err != nil {
+			return fmt.Errorf("Failed to load rewrite rules config [%s]: %v", filterService.Config.RewriteConfig, err)
+		}
+	}
+
+	filterService.refreshPatternWorker = patterns.NewRefreshPatternWorker(dataBase, cacheMetrics, logger, patternStorage, filterService.Config.PatternsRefreshInterval)
 	filterService.heartbeatWorker = heartbeat.NewHeartbeatWorker(dataBase, cacheMetrics, logger)
 
 	if err = filterService.refreshPatternWorker.Start(); err != nil {
@@ -67,19 +106,62 @@ func (filterService *FilterService) Start() error {
 	}
 	filterService.heartbeatWorker.Start()
 
-	if filterService.listener, err = connection.NewListener(filterService.Config.Listen, logger, patternStorage); err != nil {
+	if filterService.listener, err = connection.NewListener(filterService.Config.Listen, logger, cacheMetrics, patternStorage); err != nil {
 		return fmt.Errorf("Failed to start listen: %s", err.Error())
 	}
+	if err = filterService.listener.SetQueue(filterService.Config.QueueSize, filterService.Config.QueueOverflowPolicy); err != nil {
+		return fmt.Errorf("Failed to configure metrics queue: %s", err.Error())
+	}
+	if filterService.Config.ListenPickle != "" {
+		if err = filterService.listener.ListenPickle(filterService.Config.ListenPickle); err != nil {
+			return fmt.Errorf("Failed to start listen: %s", err.Error())
+		}
+	}
+	if filterService.Config.ListenUDP != "" {
+		if err = filterService.listener.ListenUDP(filterService.Config.ListenUDP); err != nil {
+			return fmt.Errorf("Failed to start listen: %s", err.Error())
+		}
+	}
+	if filterService.Config.ListenPrometheus != "" {
+		if err = filterService.listener.ListenPrometheus(filterService.Config.ListenPrometheus, filterService.Config.PrometheusPathLabels); err != nil {
+			return fmt.Errorf("Failed to start listen: %s", err.Error())
+		}
+	}
 
-	metricsMatcher := matchedmetrics.NewMetricsMatcher(cacheMetrics, logger, dataBase, cacheStorage)
+	metricsMatcher := matchedmetrics.NewMetricsMatcher(cacheMetrics, logger, dataBase, cacheStorage, matchedmetrics.Config{
+		QueueSize:         filterService.Config.QueueSize,
+		BatchSize:         filterService.Config.BatchSize,
+		FlushInterval:     filterService.Config.FlushInterval,
+		SaveRetries:       filterService.Config.SaveRetries,
+		SaveRetryInterval: filterService.Config.SaveRetryInterval,
+	})
 
 	metricsChan := filterService.listener.Listen()
+	filterService.matcherWG = &sync.WaitGroup{}
 	metricsMatcher.Start(metricsChan, filterService.matcherWG)
+
+	if filterService.Config.ShardID != "" {
+		filterService.registrationWorker = sharding.NewRegistrationWorker(dataBase, logger, moira.FilterShard{
+			ID:      filterService.Config.ShardID,
+			Address: filterService.Config.ShardAddress,
+		})
+		if err = filterService.registrationWorker.Start(); err != nil {
+			return fmt.Errorf("Failed to register filter shard: %s", err.Error())
+		}
+	}
 	return nil
 }
 
 // Stop Moira Filter
 func (filterService *FilterService) Stop() error {
+	if filterService.relay != nil {
+		return filterService.relay.Stop()
+	}
+	if filterService.registrationWorker != nil {
+		if err := filterService.registrationWorker.Stop(); err != nil {
+			return err
+		}
+	}
 	if err := filterService.listener.Stop(); err != nil {
 		return err
 	}
@@ -19,12 +19,15 @@ type config struct {
 }
 
 type notifierConfig struct {
-	SenderTimeout    string              `yaml:"sender_timeout"`
-	ResendingTimeout string              `yaml:"resending_timeout"`
-	Senders          []map[string]string `yaml:"senders"`
-	SelfState        selfStateConfig     `yaml:"moira_selfstate"`
-	FrontURI         string              `yaml:"front_uri"`
-	Timezone         string              `yaml:"timezone"`
+	SenderTimeout        string              `yaml:"sender_timeout"`
+	ResendingTimeout     string              `yaml:"resending_timeout"`
+	SenderFailureRate    float64             `yaml:"sender_failure_rate"`
+	SenderFailuresWindow string              `yaml:"sender_failures_window"`
+	SenderMinAttempts    int                 `yaml:"sender_min_attempts"`
+	Senders              []map[string]string `yaml:"senders"`
+	SelfState            selfStateConfig     `yaml:"moira_selfstate"`
+	FrontURI             string              `yaml:"front_uri"`
+	Timezone             string              `yaml:"timezone"`
 }
 
 type selfStateConfig struct {
@@ -53,8 +56,11 @@ func getDefault() config {
 			LogLevel: "debug",
 		},
 		Notifier: notifierConfig{
-			SenderTimeout:    "10s0ms",
-			ResendingTimeout: "24:00",
+			SenderTimeout:        "10s0ms",
+			ResendingTimeout:     "24:00",
+			SenderFailureRate:    0.5,
+			SenderFailuresWindow: "300s0ms",
+			SenderMinAttempts:    10,
 			SelfState: selfStateConfig{
 				Enabled:                 "false",
 				RedisDisconnectDelay:    30,
@@ -78,11 +84,14 @@ func (config *notifierConfig) getSettings(logger moira.Logger) notifier.Config {
 	}
 
 	return notifier.Config{
-		SendingTimeout:   to.Duration(config.SenderTimeout),
-		ResendingTimeout: to.Duration(config.ResendingTimeout),
-		Senders:          config.Senders,
-		FrontURL:         config.FrontURI,
-		Location:         location,
+		SendingTimeout:       to.Duration(config.SenderTimeout),
+		ResendingTimeout:     to.Duration(config.ResendingTimeout),
+		SenderFailureRate:    config.SenderFailureRate,
+		SenderFailuresWindow: to.Duration(config.SenderFailuresWindow),
+		SenderMinAttempts:    config.SenderMinAttempts,
+		Senders:              config.Senders,
+		FrontURL:             config.FrontURI,
+		Location:             location,
 	}
 }
 
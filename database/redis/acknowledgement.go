@@ -0,0 +1,35 @@
+package redis
+
+import (
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+)
+
+var triggerAckTTL = 3600 * 24 * 7
+
+// SetTriggerAck stores the time when somebody acknowledged trigger notification, acknowledgement expires in 7 days
+func (connector *DbConnector) SetTriggerAck(triggerID string, timestamp int64) error {
+	c := connector.pool.Get()
+	defer c.Close()
+	_, err := c.Do("SET", triggerAckKey(triggerID), timestamp, "EX", triggerAckTTL)
+	return err
+}
+
+// GetTriggerAck returns the time of the last trigger notification acknowledgement or zero if trigger is not acknowledged
+func (connector *DbConnector) GetTriggerAck(triggerID string) (int64, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	timestamp, err := redis.Int64(c.Do("GET", triggerAckKey(triggerID)))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("Failed to get trigger acknowledgement: %s", err.Error())
+	}
+	return timestamp, nil
+}
+
+func triggerAckKey(triggerID string) string {
+	return fmt.Sprintf("moira-trigger-ack:%s", triggerID)
+}
@@ -0,0 +1,47 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestTriggerAck(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Trigger acknowledgement manipulation", t, func() {
+		Convey("Not acknowledged trigger", func() {
+			ack, err := dataBase.GetTriggerAck("trigger-id")
+			So(err, ShouldBeNil)
+			So(ack, ShouldEqual, 0)
+		})
+
+		Convey("Acknowledge trigger", func() {
+			err := dataBase.SetTriggerAck("trigger-id", 1502712000)
+			So(err, ShouldBeNil)
+
+			ack, err := dataBase.GetTriggerAck("trigger-id")
+			So(err, ShouldBeNil)
+			So(ack, ShouldEqual, 1502712000)
+
+			ack, err = dataBase.GetTriggerAck("other-trigger-id")
+			So(err, ShouldBeNil)
+			So(ack, ShouldEqual, 0)
+		})
+	})
+
+	Convey("Test on bad connection", t, func() {
+		dataBase = NewDatabase(logger, emptyConfig)
+		dataBase.flush()
+
+		err := dataBase.SetTriggerAck("trigger-id", 1502712000)
+		So(err, ShouldNotBeNil)
+
+		_, err = dataBase.GetTriggerAck("trigger-id")
+		So(err, ShouldNotBeNil)
+	})
+}
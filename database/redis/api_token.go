@@ -0,0 +1,106 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/database/redis/reply"
+)
+
+// GetAPIToken returns api token by given id, if no value, return database.ErrNil error
+func (connector *DbConnector) GetAPIToken(tokenID string) (moira.APIToken, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	return reply.APIToken(c.Do("GET", apiTokenKey(tokenID)))
+}
+
+// GetAPITokens returns api tokens by given ids, len of tokenIDs is equal to len of returned values array.
+// If there is no object by current ID, then nil is returned
+func (connector *DbConnector) GetAPITokens(tokenIDs []string) ([]*moira.APIToken, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	for _, id := range tokenIDs {
+		c.Send("GET", apiTokenKey(id))
+	}
+	return reply.APITokens(c.Do("EXEC"))
+}
+
+// GetAPITokenByHash returns api token by hash of its secret, if no value, return database.ErrNil error
+func (connector *DbConnector) GetAPITokenByHash(hash string) (moira.APIToken, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	tokenID, err := redis.String(c.Do("GET", apiTokenHashKey(hash)))
+	if err != nil {
+		if err == redis.ErrNil {
+			return moira.APIToken{}, database.ErrNil
+		}
+		return moira.APIToken{}, fmt.Errorf("Failed to get api token by hash: %s", err.Error())
+	}
+	return reply.APIToken(c.Do("GET", apiTokenKey(tokenID)))
+}
+
+// GetUserAPITokenIDs returns api tokens ids by given login
+func (connector *DbConnector) GetUserAPITokenIDs(login string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	tokenIDs, err := redis.Strings(c.Do("SMEMBERS", userAPITokensKey(login)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get api tokens for user login %s: %s", login, err.Error())
+	}
+	return tokenIDs, nil
+}
+
+// SaveAPIToken writes api token, its hash index and updates user api tokens
+func (connector *DbConnector) SaveAPIToken(token *moira.APIToken) error {
+	tokenString, err := json.Marshal(token)
+	if err != nil {
+		return err
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	c.Send("SET", apiTokenKey(token.ID), tokenString)
+	c.Send("SET", apiTokenHashKey(token.Hash), token.ID)
+	c.Send("SADD", userAPITokensKey(token.Login), token.ID)
+	if _, err = c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// RemoveAPIToken deletes api token, its hash index and tokenID from user api tokens
+func (connector *DbConnector) RemoveAPIToken(tokenID string) error {
+	existing, err := connector.GetAPIToken(tokenID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil
+		}
+		return err
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	c.Send("DEL", apiTokenKey(tokenID))
+	c.Send("DEL", apiTokenHashKey(existing.Hash))
+	c.Send("SREM", userAPITokensKey(existing.Login), tokenID)
+	if _, err = c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+func apiTokenKey(id string) string {
+	return fmt.Sprintf("moira-api-token:%s", id)
+}
+
+func apiTokenHashKey(hash string) string {
+	return fmt.Sprintf("moira-api-token-hash:%s", hash)
+}
+
+func userAPITokensKey(login string) string {
+	return fmt.Sprintf("moira-user-api-tokens:%s", login)
+}
@@ -0,0 +1,54 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+func TestAPITokenStoring(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	token := moira.APIToken{ID: "token-1", Login: "user", Name: "ci", Hash: "hash-1", CreatedAt: 100}
+
+	Convey("API tokens manipulation", t, func() {
+		_, err := dataBase.GetAPIToken(token.ID)
+		So(err, ShouldResemble, database.ErrNil)
+		_, err = dataBase.GetAPITokenByHash(token.Hash)
+		So(err, ShouldResemble, database.ErrNil)
+
+		So(dataBase.SaveAPIToken(&token), ShouldBeNil)
+
+		actual, err := dataBase.GetAPIToken(token.ID)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, token)
+
+		actual, err = dataBase.GetAPITokenByHash(token.Hash)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, token)
+
+		ids, err := dataBase.GetUserAPITokenIDs(token.Login)
+		So(err, ShouldBeNil)
+		So(ids, ShouldResemble, []string{token.ID})
+
+		tokens, err := dataBase.GetAPITokens([]string{token.ID, "unknown"})
+		So(err, ShouldBeNil)
+		So(tokens, ShouldResemble, []*moira.APIToken{&token, nil})
+
+		So(dataBase.RemoveAPIToken(token.ID), ShouldBeNil)
+		So(dataBase.RemoveAPIToken(token.ID), ShouldBeNil)
+
+		_, err = dataBase.GetAPITokenByHash(token.Hash)
+		So(err, ShouldResemble, database.ErrNil)
+		ids, err = dataBase.GetUserAPITokenIDs(token.Login)
+		So(err, ShouldBeNil)
+		So(ids, ShouldBeEmpty)
+	})
+}
@@ -1,8 +1,10 @@
 package redis
 
 // Config - Redis database connection config
+// TriggerHistoryLimit is count of the latest trigger versions kept in trigger history, zero means unlimited history
 type Config struct {
-	Host string
-	Port string
-	DBID int
+	Host                string
+	Port                string
+	DBID                int
+	TriggerHistoryLimit int
 }
@@ -84,7 +84,13 @@ func (connector *DbConnector) SaveContact(contact *moira.ContactData) error {
 	if getContactErr != database.ErrNil && contact.User != existing.User {
 		c.Send("SREM", userContactsKey(existing.User), contact.ID)
 	}
+	if getContactErr != database.ErrNil && existing.TeamID != "" && contact.TeamID != existing.TeamID {
+		c.Send("SREM", teamContactsKey(existing.TeamID), contact.ID)
+	}
 	c.Send("SADD", userContactsKey(contact.User), contact.ID)
+	if contact.TeamID != "" {
+		c.Send("SADD", teamContactsKey(contact.TeamID), contact.ID)
+	}
 	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -104,6 +110,9 @@ func (connector *DbConnector) RemoveContact(contactID string) error {
 	c.Send("MULTI")
 	c.Send("DEL", contactKey(contactID))
 	c.Send("SREM", userContactsKey(existing.User), contactID)
+	if existing.TeamID != "" {
+		c.Send("SREM", teamContactsKey(existing.TeamID), contactID)
+	}
 	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -15,24 +15,32 @@ import (
 
 // DbConnector contains redis pool
 type DbConnector struct {
-	pool            *redis.Pool
-	logger          moira.Logger
-	retentionCache  *cache.Cache
-	metricsCache    *cache.Cache
-	messengersCache *cache.Cache
-	sync            *redsync.Redsync
+	pool                  *redis.Pool
+	logger                moira.Logger
+	retentionCache        *cache.Cache
+	metricsCache          *cache.Cache
+	messengersCache       *cache.Cache
+	seriesTagsCache       *cache.Cache
+	downsamplingCache     *cache.Cache
+	storageRetentionCache *cache.Cache
+	sync                  *redsync.Redsync
+	historyLimit          int
 }
 
 // NewDatabase creates Redis pool based on config
 func NewDatabase(logger moira.Logger, config Config) *DbConnector {
 	pool := newRedisPool(fmt.Sprintf("%s:%s", config.Host, config.Port), config.DBID)
 	db := DbConnector{
-		pool:            pool,
-		logger:          logger,
-		retentionCache:  cache.New(time.Minute, time.Minute*60),
-		metricsCache:    cache.New(time.Minute, time.Minute*60),
-		messengersCache: cache.New(cache.NoExpiration, cache.DefaultExpiration),
-		sync:            redsync.New([]redsync.Pool{pool}),
+		pool:                  pool,
+		logger:                logger,
+		retentionCache:        cache.New(time.Minute, time.Minute*60),
+		metricsCache:          cache.New(time.Minute, time.Minute*60),
+		messengersCache:       cache.New(cache.NoExpiration, cache.DefaultExpiration),
+		seriesTagsCache:       cache.New(time.Minute, time.Minute*60),
+		downsamplingCache:     cache.New(time.Minute, time.Minute*60),
+		storageRetentionCache: cache.New(time.Minute, time.Minute*60),
+		sync:                  redsync.New([]redsync.Pool{pool}),
+		historyLimit:          config.TriggerHistoryLimit,
 	}
 	return &db
 }
@@ -99,7 +107,7 @@ func (connector *DbConnector) manageSubscriptions(tomb *tomb.Tomb, channel strin
 				}
 			case *net.OpError:
 				connector.logger.Info("psc.Receive() returned *net.OpError, reconnecting")
-				newPsc, err := connector.makePubSubConnection(metricEventKey)
+				newPsc, err := connector.makePubSubConnection(channel)
 				if err != nil {
 					connector.logger.Errorf("Failed to reconnect to subscription: %v", err)
 					<-time.After(5 * time.Second)
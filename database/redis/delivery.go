@@ -0,0 +1,82 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database/redis/reply"
+)
+
+var deliveriesTTL int64 = 3600 * 24 * 7
+
+// AddDelivery stores delivery attempt to contact and trigger delivery logs and deletes records who are older than 7 days
+func (connector *DbConnector) AddDelivery(delivery *moira.DeliveryData) error {
+	deliveryBytes, err := json.Marshal(delivery)
+	if err != nil {
+		return err
+	}
+	keys := []string{contactDeliveriesKey(delivery.Contact.ID)}
+	if delivery.TriggerID != "" {
+		keys = append(keys, triggerDeliveriesKey(delivery.TriggerID))
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	for _, key := range keys {
+		c.Send("ZADD", key, delivery.Timestamp, deliveryBytes)
+		c.Send("ZREMRANGEBYSCORE", key, "-inf", time.Now().Unix()-deliveriesTTL)
+		c.Send("EXPIRE", key, deliveriesTTL)
+	}
+	_, err = c.Do("EXEC")
+	if err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// GetContactDeliveries gets latest delivery attempts to given contact in given range and total count of stored attempts
+func (connector *DbConnector) GetContactDeliveries(contactID string, start, size int64) ([]*moira.DeliveryData, int64, error) {
+	return connector.getDeliveries(contactDeliveriesKey(contactID), start, size)
+}
+
+// GetTriggerDeliveries gets latest delivery attempts of given trigger notifications in given range and total count of stored attempts
+func (connector *DbConnector) GetTriggerDeliveries(triggerID string, start, size int64) ([]*moira.DeliveryData, int64, error) {
+	return connector.getDeliveries(triggerDeliveriesKey(triggerID), start, size)
+}
+
+func (connector *DbConnector) getDeliveries(key string, start, size int64) ([]*moira.DeliveryData, int64, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	c.Send("ZREVRANGE", key, start, start+size-1)
+	c.Send("ZCARD", key)
+	rawResponse, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return nil, 0, fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	if len(rawResponse) == 0 {
+		return make([]*moira.DeliveryData, 0), 0, nil
+	}
+	total, err := redis.Int64(rawResponse[1], nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	deliveries, err := reply.Deliveries(rawResponse[0], nil)
+	if err != nil {
+		return nil, 0, err
+	}
+	return deliveries, total, nil
+}
+
+func contactDeliveriesKey(contactID string) string {
+	return fmt.Sprintf("moira-contact-deliveries:%s", contactID)
+}
+
+func triggerDeliveriesKey(triggerID string) string {
+	return fmt.Sprintf("moira-trigger-deliveries:%s", triggerID)
+}
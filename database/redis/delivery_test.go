@@ -0,0 +1,104 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestDeliveries(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Delivery log manipulation", t, func() {
+		now := time.Now().Unix()
+		contact := moira.ContactData{ID: "contact-id", Type: "mail", Value: "mail@example.com"}
+		deliveryOld := moira.DeliveryData{
+			Timestamp:   now - 3600,
+			Contact:     contact,
+			TriggerID:   "trigger-id",
+			EventsCount: 2,
+			Result:      moira.DeliveryFailed,
+			Error:       "Timeout",
+			Attempt:     1,
+		}
+		delivery := moira.DeliveryData{
+			Timestamp:   now,
+			Contact:     contact,
+			TriggerID:   "trigger-id",
+			EventsCount: 2,
+			Result:      moira.DeliveryOK,
+			Attempt:     2,
+		}
+		deliveryExpired := moira.DeliveryData{
+			Timestamp: now - deliveriesTTL - 1,
+			Contact:   contact,
+			TriggerID: "trigger-id",
+			Result:    moira.DeliveryOK,
+			Attempt:   1,
+		}
+		deliveryTest := moira.DeliveryData{
+			Timestamp: now + 1,
+			Contact:   contact,
+			Result:    moira.DeliveryOK,
+			Attempt:   1,
+		}
+
+		Convey("Test add and get by pages", func() {
+			So(dataBase.AddDelivery(&deliveryExpired), ShouldBeNil)
+			So(dataBase.AddDelivery(&deliveryOld), ShouldBeNil)
+			So(dataBase.AddDelivery(&delivery), ShouldBeNil)
+			So(dataBase.AddDelivery(&deliveryTest), ShouldBeNil)
+
+			actual, total, err := dataBase.GetContactDeliveries(contact.ID, 0, 100)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 3)
+			So(actual, ShouldResemble, []*moira.DeliveryData{&deliveryTest, &delivery, &deliveryOld})
+
+			actual, total, err = dataBase.GetContactDeliveries(contact.ID, 1, 1)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 3)
+			So(actual, ShouldResemble, []*moira.DeliveryData{&delivery})
+
+			actual, total, err = dataBase.GetTriggerDeliveries("trigger-id", 0, 100)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 2)
+			So(actual, ShouldResemble, []*moira.DeliveryData{&delivery, &deliveryOld})
+		})
+
+		Convey("Test get unknown contact deliveries", func() {
+			actual, total, err := dataBase.GetContactDeliveries("unknown-contact", 0, 100)
+			So(err, ShouldBeNil)
+			So(total, ShouldEqual, 0)
+			So(actual, ShouldResemble, make([]*moira.DeliveryData, 0))
+		})
+	})
+}
+
+func TestDeliveriesErrorConnection(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, emptyConfig)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Should throw error when no connection", t, func() {
+		err := dataBase.AddDelivery(&moira.DeliveryData{})
+		So(err, ShouldNotBeNil)
+
+		actual, total, err := dataBase.GetContactDeliveries("123", 0, 1)
+		So(actual, ShouldBeNil)
+		So(total, ShouldEqual, 0)
+		So(err, ShouldNotBeNil)
+
+		actual, total, err = dataBase.GetTriggerDeliveries("123", 0, 1)
+		So(actual, ShouldBeNil)
+		So(total, ShouldEqual, 0)
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,137 @@
+package redis
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database/redis/reply"
+)
+
+// DownsampleMetricValues aggregates metric values older than retention of every schema archive with precision of the next archive
+// and removes values older than retention of the last archive
+// Every metric is downsampled not more often than once per precision of the second archive
+// Downsampling state keeps time each archive is downsampled until with its precision, so values are unpacked with their own step,
+// and retention of the last archive, so checker does not remove downsampled values
+func (connector *DbConnector) DownsampleMetricValues(metric string, schema *moira.MetricSchema, now int64) error {
+	if schema == nil || len(schema.Archives) < 2 {
+		return nil
+	}
+	if err := connector.downsamplingCache.Add(metric, true, time.Duration(schema.Archives[1].Precision)*time.Second); err != nil {
+		return nil
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	downsampledUntil, err := redis.Int64Map(c.Do("HGETALL", metricDownsamplingKey(metric)))
+	if err != nil {
+		return fmt.Errorf("Failed to get metric %s downsampling state: %s", metric, err.Error())
+	}
+
+	for i := 1; i < len(schema.Archives); i++ {
+		precision := schema.Archives[i].Precision
+		from := downsampledUntil[strconv.Itoa(i)]
+		until := (now - schema.Archives[i-1].Retention) / precision * precision
+		if until <= from {
+			continue
+		}
+		result, err := c.Do("ZRANGEBYSCORE", metricDataKey(metric), from, fmt.Sprintf("(%d", until), "WITHSCORES")
+		if err != nil {
+			return fmt.Errorf("Failed to get metric %s values to downsample: %s", metric, err.Error())
+		}
+		values, err := reply.MetricValues(result)
+		if err != nil {
+			return fmt.Errorf("Failed to get metric %s values to downsample: %s", metric, err.Error())
+		}
+		expected := int(precision / schema.Archives[i-1].Precision)
+
+		c.Send("MULTI")
+		c.Send("ZREMRANGEBYSCORE", metricDataKey(metric), from, fmt.Sprintf("(%d", until))
+		for start := 0; start < len(values); {
+			bucket := values[start].RetentionTimestamp / precision * precision
+			end := start
+			bucketValues := make([]float64, 0, expected)
+			for end < len(values) && values[end].RetentionTimestamp/precision*precision == bucket {
+				bucketValues = append(bucketValues, values[end].Value)
+				end++
+			}
+			if value, ok := schema.Aggregate(bucketValues, expected); ok {
+				c.Send("ZADD", metricDataKey(metric), bucket, fmt.Sprintf("%v %v", bucket, value))
+			}
+			start = end
+		}
+		c.Send("HMSET", metricDownsamplingKey(metric), i, until, archivePrecisionField(i), precision)
+		if _, err := c.Do("EXEC"); err != nil {
+			return fmt.Errorf("Failed to EXEC: %s", err.Error())
+		}
+	}
+
+	lastArchive := schema.Archives[len(schema.Archives)-1]
+	c.Send("MULTI")
+	c.Send("HSET", metricDownsamplingKey(metric), storageRetentionField, lastArchive.Retention)
+	c.Send("ZREMRANGEBYSCORE", metricDataKey(metric), "-inf", fmt.Sprintf("(%d", now-lastArchive.Retention))
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to remove metric %s values older than last archive: %s", metric, err.Error())
+	}
+	connector.storageRetentionCache.Set(metric, lastArchive.Retention, 0)
+	return nil
+}
+
+// GetMetricStorageRetention gets retention of the last storage schema archive of downsampled metric, it returns 0 if metric is not downsampled
+// Retention is cached for a minute, so checker does not request it on every check
+func (connector *DbConnector) GetMetricStorageRetention(metric string) (int64, error) {
+	if retention, ok := connector.getCachedStorageRetention(metric); ok {
+		return retention, nil
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+
+	retention, err := redis.Int64(c.Do("HGET", metricDownsamplingKey(metric), storageRetentionField))
+	if err != nil {
+		if err != redis.ErrNil {
+			return 0, fmt.Errorf("Failed to get metric %s storage retention: %s", metric, err.Error())
+		}
+		retention = 0
+	}
+	connector.storageRetentionCache.Set(metric, retention, 0)
+	return retention, nil
+}
+
+func (connector *DbConnector) getCachedStorageRetention(metric string) (int64, bool) {
+	value, ok := connector.storageRetentionCache.Get(metric)
+	if !ok {
+		return 0, false
+	}
+	retention, ok := value.(int64)
+	return retention, ok
+}
+
+// setValuesSteps sets step of values older than time archive is downsampled until to archive precision
+func setValuesSteps(values []*moira.MetricValue, downsampledUntil map[string]int64) {
+	for i := 1; ; i++ {
+		until, ok := downsampledUntil[strconv.Itoa(i)]
+		if !ok {
+			return
+		}
+		precision := downsampledUntil[archivePrecisionField(i)]
+		for _, value := range values {
+			if value.RetentionTimestamp < until {
+				value.Step = precision
+			}
+		}
+	}
+}
+
+const storageRetentionField = "retention"
+
+func archivePrecisionField(archive int) string {
+	return fmt.Sprintf("precision:%d", archive)
+}
+
+func metricDownsamplingKey(metric string) string {
+	return fmt.Sprintf("moira-metric-downsampling:%s", metric)
+}
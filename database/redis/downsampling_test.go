@@ -0,0 +1,114 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestDownsampleMetricValues(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	metric := "my.test.super.metric"
+	schema := &moira.MetricSchema{
+		Archives: []moira.RetentionArchive{
+			{Precision: 60, Retention: 600},
+			{Precision: 300, Retention: 1800},
+			{Precision: 900, Retention: 3600},
+		},
+		AggregationMethod: moira.AggregationSum,
+		XFilesFactor:      moira.DefaultXFilesFactor,
+	}
+
+	Convey("Values older than archive retention are aggregated with next archive precision", t, func() {
+		metrics := make(map[string]*moira.MatchedMetric)
+		for timestamp := int64(0); timestamp < 3600; timestamp += 60 {
+			// first 5 minutes have only two values, so they are dropped by xFilesFactor on the first downsampling
+			if timestamp < 300 && timestamp >= 120 {
+				continue
+			}
+			metrics[metric] = &moira.MatchedMetric{Metric: metric, Timestamp: timestamp, RetentionTimestamp: timestamp, Retention: 60, Value: 1}
+			So(dataBase.SaveMetrics(metrics), ShouldBeNil)
+		}
+
+		err := dataBase.DownsampleMetricValues(metric, schema, 3600)
+		So(err, ShouldBeNil)
+
+		values, err := dataBase.GetMetricsValues([]string{metric}, 0, 3600)
+		So(err, ShouldBeNil)
+		expected := []*moira.MetricValue{
+			{RetentionTimestamp: 0, Timestamp: 0, Value: 10, Step: 900},
+			{RetentionTimestamp: 900, Timestamp: 900, Value: 15, Step: 900},
+			{RetentionTimestamp: 1800, Timestamp: 1800, Value: 5, Step: 300},
+			{RetentionTimestamp: 2100, Timestamp: 2100, Value: 5, Step: 300},
+			{RetentionTimestamp: 2400, Timestamp: 2400, Value: 5, Step: 300},
+			{RetentionTimestamp: 2700, Timestamp: 2700, Value: 5, Step: 300},
+		}
+		for timestamp := int64(3000); timestamp < 3600; timestamp += 60 {
+			expected = append(expected, &moira.MetricValue{RetentionTimestamp: timestamp, Timestamp: timestamp, Value: 1})
+		}
+		So(values[metric], ShouldResemble, expected)
+
+		retention, err := dataBase.GetMetricStorageRetention(metric)
+		So(err, ShouldBeNil)
+		So(retention, ShouldEqual, 3600)
+
+		// downsampled value covering interval start is returned, value with metric retention is not
+		values, err = dataBase.GetMetricsValues([]string{metric}, 1000, 2100)
+		So(err, ShouldBeNil)
+		So(values[metric], ShouldResemble, expected[1:4])
+		values, err = dataBase.GetMetricsValues([]string{metric}, 3030, 3060)
+		So(err, ShouldBeNil)
+		So(values[metric], ShouldResemble, expected[7:8])
+
+		Convey("Downsampling is not repeated until second archive precision passes", func() {
+			err := dataBase.DownsampleMetricValues(metric, schema, 7200)
+			So(err, ShouldBeNil)
+			values, err := dataBase.GetMetricsValues([]string{metric}, 0, 3600)
+			So(err, ShouldBeNil)
+			So(values[metric], ShouldResemble, expected)
+		})
+	})
+}
+
+func TestMetricStorageRetentionCache(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	metric := "my.test.super.metric"
+
+	Convey("Metric known to be not downsampled is read without downsampling state", t, func() {
+		value := &moira.MatchedMetric{Metric: metric, Timestamp: 60, RetentionTimestamp: 60, Retention: 60, Value: 1}
+		So(dataBase.SaveMetrics(map[string]*moira.MatchedMetric{metric: value}), ShouldBeNil)
+		values, err := dataBase.GetMetricsValues([]string{metric}, 0, 600)
+		So(err, ShouldBeNil)
+		So(values[metric], ShouldResemble, []*moira.MetricValue{{RetentionTimestamp: 60, Timestamp: 60, Value: 1}})
+
+		// downsampling state saved by other process is not read until cached retention expires
+		c := dataBase.pool.Get()
+		_, err = c.Do("HMSET", metricDownsamplingKey(metric), 1, 300, archivePrecisionField(1), 300, storageRetentionField, 3600)
+		c.Close()
+		So(err, ShouldBeNil)
+
+		retention, err := dataBase.GetMetricStorageRetention(metric)
+		So(err, ShouldBeNil)
+		So(retention, ShouldEqual, 0)
+		values, err = dataBase.GetMetricsValues([]string{metric}, 0, 600)
+		So(err, ShouldBeNil)
+		So(values[metric], ShouldResemble, []*moira.MetricValue{{RetentionTimestamp: 60, Timestamp: 60, Value: 1}})
+
+		dataBase.storageRetentionCache.Flush()
+		retention, err = dataBase.GetMetricStorageRetention(metric)
+		So(err, ShouldBeNil)
+		So(retention, ShouldEqual, 3600)
+		values, err = dataBase.GetMetricsValues([]string{metric}, 0, 600)
+		So(err, ShouldBeNil)
+		So(values[metric], ShouldResemble, []*moira.MetricValue{{RetentionTimestamp: 60, Timestamp: 60, Value: 1, Step: 300}})
+	})
+}
@@ -0,0 +1,123 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+
+	"github.com/moira-alert/moira"
+)
+
+// RegisterFilterShardIfAlreadyNot creates registration of filter shard in redis, it fails if shard with same id is registered by other instance
+// Registration with the same address is taken over, so restarted filter gets its shard id back without waiting for expiration
+// Registration expires after ttl if it is not renewed
+func (connector *DbConnector) RegisterFilterShardIfAlreadyNot(shard moira.FilterShard, ttl time.Duration) bool {
+	c := connector.pool.Get()
+	defer c.Close()
+	now := time.Now()
+	registered, err := redis.Bool(registerFilterShardScript.Do(c, filterShardsKey, filterShardsExpirationKey, shard.ID, shard.Address, now.Unix(), now.Add(ttl).Unix()))
+	if err != nil {
+		connector.logger.Errorf("Failed to register filter shard %s: %s", shard.ID, err.Error())
+		return false
+	}
+	return registered
+}
+
+// RenewFilterShardRegistration extends filter shard registration for given ttl, it fails if registration expired and was taken by other instance
+func (connector *DbConnector) RenewFilterShardRegistration(shard moira.FilterShard, ttl time.Duration) bool {
+	c := connector.pool.Get()
+	defer c.Close()
+	now := time.Now()
+	renewed, err := redis.Bool(renewFilterShardScript.Do(c, filterShardsKey, filterShardsExpirationKey, shard.ID, shard.Address, now.Unix(), now.Add(ttl).Unix()))
+	if err != nil {
+		connector.logger.Errorf("Failed to renew filter shard %s expiration: %s", shard.ID, err.Error())
+		return false
+	}
+	return renewed
+}
+
+// DeregisterFilterShard removes registration of filter shard from redis
+// Registration is removed only if it still has shard address, so registration of other instance that took over shard id is kept
+func (connector *DbConnector) DeregisterFilterShard(shard moira.FilterShard) bool {
+	c := connector.pool.Get()
+	defer c.Close()
+	removed, err := redis.Bool(deregisterFilterShardScript.Do(c, filterShardsKey, filterShardsExpirationKey, shard.ID, shard.Address))
+	if err != nil {
+		connector.logger.Errorf("Failed to deregister filter shard %s: %s", shard.ID, err.Error())
+		return false
+	}
+	return removed
+}
+
+// GetFilterShards returns filter shards with not expired registration
+func (connector *DbConnector) GetFilterShards() ([]moira.FilterShard, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	shardIDs, err := redis.Strings(c.Do("ZRANGEBYSCORE", filterShardsExpirationKey, time.Now().Unix(), "+inf"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get filter shards: %s", err.Error())
+	}
+	shards := make([]moira.FilterShard, 0, len(shardIDs))
+	if len(shardIDs) == 0 {
+		return shards, nil
+	}
+	args := make([]interface{}, 0, len(shardIDs)+1)
+	args = append(args, filterShardsKey)
+	for _, shardID := range shardIDs {
+		args = append(args, shardID)
+	}
+	addresses, err := redis.Strings(c.Do("HMGET", args...))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get filter shards addresses: %s", err.Error())
+	}
+	for i, shardID := range shardIDs {
+		if addresses[i] == "" {
+			continue
+		}
+		shards = append(shards, moira.FilterShard{ID: shardID, Address: addresses[i]})
+	}
+	return shards, nil
+}
+
+// removeExpiredFilterShards is part of registration scripts which removes registrations expired before ARGV[3] timestamp
+const removeExpiredFilterShards = `
+local expired = redis.call("ZRANGEBYSCORE", KEYS[2], "-inf", "(" .. ARGV[3])
+for _, shardID in ipairs(expired) do
+	redis.call("HDEL", KEYS[1], shardID)
+end
+redis.call("ZREMRANGEBYSCORE", KEYS[2], "-inf", "(" .. ARGV[3])
+`
+
+// registerFilterShardScript saves shard address and expiration if shard is not registered with other address
+var registerFilterShardScript = redis.NewScript(2, removeExpiredFilterShards+`
+local address = redis.call("HGET", KEYS[1], ARGV[1])
+if address and address ~= ARGV[2] then
+	return 0
+end
+redis.call("HSET", KEYS[1], ARGV[1], ARGV[2])
+redis.call("ZADD", KEYS[2], ARGV[4], ARGV[1])
+return 1
+`)
+
+// renewFilterShardScript updates shard expiration if shard is still registered with the same address
+var renewFilterShardScript = redis.NewScript(2, removeExpiredFilterShards+`
+if redis.call("HGET", KEYS[1], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call("ZADD", KEYS[2], ARGV[4], ARGV[1])
+return 1
+`)
+
+// deregisterFilterShardScript removes shard registration if shard address is not changed
+var deregisterFilterShardScript = redis.NewScript(2, `
+if redis.call("HGET", KEYS[1], ARGV[1]) ~= ARGV[2] then
+	return 0
+end
+redis.call("HDEL", KEYS[1], ARGV[1])
+redis.call("ZREM", KEYS[2], ARGV[1])
+return 1
+`)
+
+var filterShardsKey = "moira-filter-shards"
+var filterShardsExpirationKey = "moira-filter-shards-expiration"
@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestFilterShards(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	shard1 := moira.FilterShard{ID: "filter-1", Address: "filter-1:2003"}
+	shard2 := moira.FilterShard{ID: "filter-2", Address: "filter-2:2003"}
+
+	Convey("Test filter shards registration", t, func() {
+		shards, err := dataBase.GetFilterShards()
+		So(err, ShouldBeNil)
+		So(shards, ShouldBeEmpty)
+
+		So(dataBase.RenewFilterShardRegistration(shard1, time.Minute), ShouldBeFalse)
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard1, time.Minute), ShouldBeTrue)
+		So(dataBase.RegisterFilterShardIfAlreadyNot(moira.FilterShard{ID: shard1.ID, Address: "filter-1-new:2003"}, time.Minute), ShouldBeFalse)
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard2, time.Minute), ShouldBeTrue)
+		So(dataBase.RenewFilterShardRegistration(shard1, time.Minute), ShouldBeTrue)
+
+		shards, err = dataBase.GetFilterShards()
+		So(err, ShouldBeNil)
+		So(shards, ShouldHaveLength, 2)
+		So(shards, ShouldContain, shard1)
+		So(shards, ShouldContain, shard2)
+
+		So(dataBase.DeregisterFilterShard(shard1), ShouldBeTrue)
+		So(dataBase.DeregisterFilterShard(shard1), ShouldBeFalse)
+
+		shards, err = dataBase.GetFilterShards()
+		So(err, ShouldBeNil)
+		So(shards, ShouldResemble, []moira.FilterShard{shard2})
+
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard1, time.Minute), ShouldBeTrue)
+		So(dataBase.DeregisterFilterShard(shard1), ShouldBeTrue)
+		So(dataBase.DeregisterFilterShard(shard2), ShouldBeTrue)
+	})
+
+	Convey("Test restarted filter takes over its own shard registration", t, func() {
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard1, time.Minute), ShouldBeTrue)
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard1, time.Minute), ShouldBeTrue)
+		shards, err := dataBase.GetFilterShards()
+		So(err, ShouldBeNil)
+		So(shards, ShouldResemble, []moira.FilterShard{shard1})
+		So(dataBase.DeregisterFilterShard(shard1), ShouldBeTrue)
+	})
+
+	Convey("Test expired filter shard registrations are removed", t, func() {
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard1, -time.Minute), ShouldBeTrue)
+		newShard1 := moira.FilterShard{ID: shard1.ID, Address: "filter-1-new:2003"}
+		So(dataBase.RenewFilterShardRegistration(shard1, time.Minute), ShouldBeFalse)
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard2, -time.Minute), ShouldBeTrue)
+		So(dataBase.RegisterFilterShardIfAlreadyNot(newShard1, time.Minute), ShouldBeTrue)
+
+		c := dataBase.pool.Get()
+		defer c.Close()
+		addresses, err := redis.StringMap(c.Do("HGETALL", filterShardsKey))
+		So(err, ShouldBeNil)
+		So(addresses, ShouldResemble, map[string]string{newShard1.ID: newShard1.Address})
+		count, err := redis.Int(c.Do("ZCARD", filterShardsExpirationKey))
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 1)
+		So(dataBase.DeregisterFilterShard(newShard1), ShouldBeTrue)
+	})
+
+	Convey("Test filter shard registration of other instance is not removed", t, func() {
+		So(dataBase.RegisterFilterShardIfAlreadyNot(shard1, time.Minute), ShouldBeTrue)
+		c := dataBase.pool.Get()
+		defer c.Close()
+		_, err := c.Do("HSET", filterShardsKey, shard1.ID, "filter-1-new:2003")
+		So(err, ShouldBeNil)
+
+		So(dataBase.DeregisterFilterShard(shard1), ShouldBeFalse)
+		shards, err := dataBase.GetFilterShards()
+		So(err, ShouldBeNil)
+		So(shards, ShouldResemble, []moira.FilterShard{{ID: shard1.ID, Address: "filter-1-new:2003"}})
+	})
+}
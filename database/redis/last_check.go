@@ -24,6 +24,10 @@ func (connector *DbConnector) SetTriggerLastCheck(triggerID string, checkData *m
 	if err != nil {
 		return err
 	}
+	searchState, err := getTriggerSearchState(checkData)
+	if err != nil {
+		return err
+	}
 	c := connector.pool.Get()
 	defer c.Close()
 	c.Send("MULTI")
@@ -35,6 +39,8 @@ func (connector *DbConnector) SetTriggerLastCheck(triggerID string, checkData *m
 	} else {
 		c.Send("SREM", badStateTriggersKey, triggerID)
 	}
+	c.Send("HSET", triggerSearchStateKey, triggerID, searchState)
+	connector.sendStreamEvent(c, &moira.StreamEvent{Type: moira.StreamEventTriggerState, TriggerID: triggerID, State: getStreamCheckData(checkData)})
 	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -42,6 +48,11 @@ func (connector *DbConnector) SetTriggerLastCheck(triggerID string, checkData *m
 	return nil
 }
 
+// getStreamCheckData returns trigger-level part of check data, metrics states are not published to keep stream events small
+func getStreamCheckData(checkData *moira.CheckData) *moira.CheckData {
+	return &moira.CheckData{Score: checkData.Score, State: checkData.State, Timestamp: checkData.Timestamp}
+}
+
 // RemoveTriggerLastCheck removes trigger last check data
 func (connector *DbConnector) RemoveTriggerLastCheck(triggerID string) error {
 	c := connector.pool.Get()
@@ -50,6 +61,7 @@ func (connector *DbConnector) RemoveTriggerLastCheck(triggerID string) error {
 	c.Send("DEL", metricLastCheckKey(triggerID))
 	c.Send("ZREM", triggersChecksKey, triggerID)
 	c.Send("SREM", badStateTriggersKey, triggerID)
+	c.Send("HDEL", triggerSearchStateKey, triggerID)
 	_, err := c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -97,6 +109,13 @@ func (connector *DbConnector) SetTriggerCheckMetricsMaintenance(triggerID string
 			return readingErr
 		}
 		if prev == lastCheckString {
+			searchState, err := getTriggerSearchState(&lastCheck)
+			if err != nil {
+				return err
+			}
+			if _, err := c.Do("HSET", triggerSearchStateKey, triggerID, searchState); err != nil {
+				return fmt.Errorf("Failed to update trigger search state: %s", err.Error())
+			}
 			break
 		}
 		lastCheckString = prev
@@ -0,0 +1,78 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database/redis/reply"
+)
+
+// GetMaintenanceWindow returns maintenance window by given id, if no value, return database.ErrNil error
+func (connector *DbConnector) GetMaintenanceWindow(windowID string) (moira.MaintenanceWindow, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	return reply.MaintenanceWindow(c.Do("GET", maintenanceWindowKey(windowID)))
+}
+
+// GetMaintenanceWindows returns all stored maintenance windows
+func (connector *DbConnector) GetMaintenanceWindows() ([]*moira.MaintenanceWindow, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	windowIDs, err := redis.Strings(c.Do("SMEMBERS", maintenanceWindowsKey))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get maintenance windows ids: %s", err.Error())
+	}
+	c.Send("MULTI")
+	for _, id := range windowIDs {
+		c.Send("GET", maintenanceWindowKey(id))
+	}
+	values, err := reply.MaintenanceWindows(c.Do("EXEC"))
+	if err != nil {
+		return nil, err
+	}
+	windows := make([]*moira.MaintenanceWindow, 0, len(values))
+	for _, window := range values {
+		if window != nil {
+			windows = append(windows, window)
+		}
+	}
+	return windows, nil
+}
+
+// SaveMaintenanceWindow writes maintenance window data and adds its id to windows set
+func (connector *DbConnector) SaveMaintenanceWindow(window *moira.MaintenanceWindow) error {
+	windowString, err := json.Marshal(window)
+	if err != nil {
+		return err
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	c.Send("SET", maintenanceWindowKey(window.ID), windowString)
+	c.Send("SADD", maintenanceWindowsKey, window.ID)
+	if _, err = c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// RemoveMaintenanceWindow deletes maintenance window data and its id from windows set
+func (connector *DbConnector) RemoveMaintenanceWindow(windowID string) error {
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	c.Send("DEL", maintenanceWindowKey(windowID))
+	c.Send("SREM", maintenanceWindowsKey, windowID)
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+var maintenanceWindowsKey = "moira-maintenance-windows"
+
+func maintenanceWindowKey(id string) string {
+	return fmt.Sprintf("moira-maintenance-window:%s", id)
+}
@@ -0,0 +1,52 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+func TestMaintenanceWindowStoring(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	window := moira.MaintenanceWindow{
+		ID:         "window-1",
+		Name:       "DC works",
+		Tags:       []string{"dc-eu"},
+		Start:      7200,
+		End:        14400,
+		Recurrence: moira.MaintenanceRecurrenceWeekly,
+		User:       user1,
+	}
+
+	Convey("Maintenance windows manipulation", t, func() {
+		_, err := dataBase.GetMaintenanceWindow(window.ID)
+		So(err, ShouldResemble, database.ErrNil)
+		windows, err := dataBase.GetMaintenanceWindows()
+		So(err, ShouldBeNil)
+		So(windows, ShouldBeEmpty)
+
+		So(dataBase.SaveMaintenanceWindow(&window), ShouldBeNil)
+		actual, err := dataBase.GetMaintenanceWindow(window.ID)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, window)
+
+		windows, err = dataBase.GetMaintenanceWindows()
+		So(err, ShouldBeNil)
+		So(windows, ShouldResemble, []*moira.MaintenanceWindow{&window})
+
+		So(dataBase.RemoveMaintenanceWindow(window.ID), ShouldBeNil)
+		_, err = dataBase.GetMaintenanceWindow(window.ID)
+		So(err, ShouldResemble, database.ErrNil)
+		windows, err = dataBase.GetMaintenanceWindows()
+		So(err, ShouldBeNil)
+		So(windows, ShouldBeEmpty)
+	})
+}
@@ -24,6 +24,8 @@ func (connector *DbConnector) GetPatterns() ([]string, error) {
 }
 
 // GetMetricsValues gets metrics values for given interval
+// Downsampled value preceding interval is returned too if its step covers interval start
+// Downsampling state is requested only for metrics not known to be never downsampled
 func (connector *DbConnector) GetMetricsValues(metrics []string, from int64, until int64) (map[string][]*moira.MetricValue, error) {
 	c := connector.pool.Get()
 	defer c.Close()
@@ -32,19 +34,63 @@ func (connector *DbConnector) GetMetricsValues(metrics []string, from int64, unt
 	for _, metric := range metrics {
 		c.Send("ZRANGEBYSCORE", metricDataKey(metric), from, until, "WITHSCORES")
 	}
+	requestedMetrics := make([]string, 0)
+	for _, metric := range metrics {
+		if retention, ok := connector.getCachedStorageRetention(metric); !ok || retention > 0 {
+			c.Send("HGETALL", metricDownsamplingKey(metric))
+			requestedMetrics = append(requestedMetrics, metric)
+		}
+	}
 	resultByMetrics, err := redis.Values(c.Do("EXEC"))
 	if err != nil {
 		return nil, fmt.Errorf("Failed to EXEC: %v", err)
 	}
 
 	res := make(map[string][]*moira.MetricValue)
+	for i, metric := range metrics {
+		metricsValues, err := reply.MetricValues(resultByMetrics[i])
+		if err != nil {
+			return nil, err
+		}
+		res[metric] = metricsValues
+	}
 
-	for i, resultByMetric := range resultByMetrics {
-		metric := metrics[i]
-		metricsValues, err := reply.MetricValues(resultByMetric)
+	downsampledMetrics := make([]string, 0)
+	downsampledUntil := make(map[string]map[string]int64)
+	for i, metric := range requestedMetrics {
+		metricDownsampledUntil, err := redis.Int64Map(resultByMetrics[len(metrics)+i], nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get metric %s downsampling state: %v", metric, err)
+		}
+		connector.storageRetentionCache.Set(metric, metricDownsampledUntil[storageRetentionField], 0)
+		if len(metricDownsampledUntil) > 0 {
+			downsampledMetrics = append(downsampledMetrics, metric)
+			downsampledUntil[metric] = metricDownsampledUntil
+		}
+	}
+	if len(downsampledMetrics) == 0 {
+		return res, nil
+	}
+
+	c.Send("MULTI")
+	for _, metric := range downsampledMetrics {
+		c.Send("ZREVRANGEBYSCORE", metricDataKey(metric), fmt.Sprintf("(%d", from), "-inf", "WITHSCORES", "LIMIT", 0, 1)
+	}
+	previousByMetrics, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to EXEC: %v", err)
+	}
+	for i, metric := range downsampledMetrics {
+		previousValues, err := reply.MetricValues(previousByMetrics[i])
 		if err != nil {
 			return nil, err
 		}
+		metricsValues := res[metric]
+		setValuesSteps(metricsValues, downsampledUntil[metric])
+		setValuesSteps(previousValues, downsampledUntil[metric])
+		if len(previousValues) == 1 && previousValues[0].RetentionTimestamp+previousValues[0].Step > from {
+			metricsValues = append(previousValues, metricsValues...)
+		}
 		res[metric] = metricsValues
 	}
 	return res, nil
@@ -90,14 +136,18 @@ func (connector *DbConnector) getMetricRetention(metric string) (int64, error) {
 	return retention, nil
 }
 
-// SaveMetrics saves new metrics
+// SaveMetrics saves new metrics, tagged series not indexed during last minute are indexed by their tags
 func (connector *DbConnector) SaveMetrics(metrics map[string]*moira.MatchedMetric) error {
 	c := connector.pool.Get()
 	defer c.Close()
+	taggedSeries := make([]string, 0)
 	for _, metric := range metrics {
 		metricValue := fmt.Sprintf("%v %v", metric.Timestamp, metric.Value)
 		c.Send("ZADD", metricDataKey(metric.Metric), metric.RetentionTimestamp, metricValue)
 		c.Send("SET", metricRetentionKey(metric.Metric), metric.Retention)
+		if connector.needIndexSeriesTags(metric.Metric) {
+			taggedSeries = append(taggedSeries, metric.Metric)
+		}
 
 		for _, pattern := range metric.Patterns {
 			event, err := json.Marshal(&moira.MetricEvent{
@@ -110,6 +160,9 @@ func (connector *DbConnector) SaveMetrics(metrics map[string]*moira.MatchedMetri
 			c.Send("PUBLISH", metricEventKey, event)
 		}
 	}
+	if len(taggedSeries) > 0 {
+		return connector.execSeriesTags(c, taggedSeries)
+	}
 	return c.Flush()
 }
 
@@ -170,7 +223,10 @@ func (connector *DbConnector) GetPatternMetrics(pattern string) ([]string, error
 func (connector *DbConnector) RemovePattern(pattern string) error {
 	c := connector.pool.Get()
 	defer c.Close()
-	if _, err := c.Do("SREM", patternsListKey, pattern); err != nil {
+	c.Send("MULTI")
+	c.Send("SREM", patternsListKey, pattern)
+	connector.sendPatternEvent(c, moira.PatternRemoved, pattern)
+	if _, err := c.Do("EXEC"); err != nil {
 		return fmt.Errorf("Failed to remove pattern: %s, error: %v", pattern, err)
 	}
 	return nil
@@ -200,8 +256,10 @@ func (connector *DbConnector) RemovePatternWithMetrics(pattern string) error {
 	defer c.Close()
 	c.Send("MULTI")
 	c.Send("SREM", patternsListKey, pattern)
+	connector.sendPatternEvent(c, moira.PatternRemoved, pattern)
 	for _, metric := range metrics {
 		c.Send("DEL", metricDataKey(metric))
+		c.Send("DEL", metricDownsamplingKey(metric))
 	}
 	c.Send("DEL", patternMetricsKey(pattern))
 	if _, err = c.Do("EXEC"); err != nil {
@@ -31,7 +31,65 @@ func (connector *DbConnector) GetNotificationEvents(triggerID string, start int6
 	return eventsData, nil
 }
 
-// PushNotificationEvent adds new NotificationEvent to events list and to given triggerID events list and deletes events who are older than 30 days
+// GetNotificationEventsByTime gets size NotificationEvents of all triggers with timestamp in given range skipping start newest events,
+// newest events go first, negative size gets all events
+func (connector *DbConnector) GetNotificationEventsByTime(from int64, to int64, start int64, size int64) ([]*moira.NotificationEvent, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	eventsData, err := reply.Events(c.Do("ZREVRANGEBYSCORE", eventsTimelineKey, to, from, "LIMIT", start, size))
+	if err != nil {
+		if err == redis.ErrNil {
+			return make([]*moira.NotificationEvent, 0), nil
+		}
+		return nil, fmt.Errorf("Failed to get range for events from %v to %v, error: %s", from, to, err.Error())
+	}
+
+	return eventsData, nil
+}
+
+// GetNotificationEventCountByTime returns count of events of all triggers with timestamp in given range
+func (connector *DbConnector) GetNotificationEventCountByTime(from int64, to int64) (int64, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	count, err := redis.Int64(c.Do("ZCOUNT", eventsTimelineKey, from, to))
+	if err != nil {
+		return 0, fmt.Errorf("Failed to count events from %v to %v, error: %s", from, to, err.Error())
+	}
+	return count, nil
+}
+
+// FillNotificationEventsTimeline adds events of all triggers stored for last 30 days to events timeline
+// Events pushed before events timeline was introduced are selected by time range only after fill
+func (connector *DbConnector) FillNotificationEventsTimeline() error {
+	triggerIDs, err := connector.GetTriggerIDs()
+	if err != nil {
+		return err
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	from := time.Now().Unix() - eventsTTL
+	for _, triggerID := range triggerIDs {
+		values, err := redis.Values(c.Do("ZRANGEBYSCORE", triggerEventsKey(triggerID), from, "+inf", "WITHSCORES"))
+		if err != nil {
+			return fmt.Errorf("Failed to get trigger events, triggerID: %s, error: %s", triggerID, err.Error())
+		}
+		if len(values) == 0 {
+			continue
+		}
+		args := []interface{}{eventsTimelineKey}
+		for i := 0; i+1 < len(values); i += 2 {
+			args = append(args, values[i+1], values[i])
+		}
+		if _, err := c.Do("ZADD", args...); err != nil {
+			return fmt.Errorf("Failed to add trigger events to events timeline, triggerID: %s, error: %s", triggerID, err.Error())
+		}
+	}
+	return nil
+}
+
+// PushNotificationEvent adds new NotificationEvent to events list, to given triggerID events list and to events timeline, and deletes events who are older than 30 days
 // If ui=true, then add to ui events list
 func (connector *DbConnector) PushNotificationEvent(event *moira.NotificationEvent, ui bool) error {
 	eventBytes, err := json.Marshal(event)
@@ -46,11 +104,14 @@ func (connector *DbConnector) PushNotificationEvent(event *moira.NotificationEve
 	if event.TriggerID != "" {
 		c.Send("ZADD", triggerEventsKey(event.TriggerID), event.Timestamp, eventBytes)
 		c.Send("ZREMRANGEBYSCORE", triggerEventsKey(event.TriggerID), "-inf", time.Now().Unix()-eventsTTL)
+		c.Send("ZADD", eventsTimelineKey, event.Timestamp, eventBytes)
+		c.Send("ZREMRANGEBYSCORE", eventsTimelineKey, "-inf", time.Now().Unix()-eventsTTL)
 	}
 	if ui {
 		c.Send("LPUSH", eventsUIListKey, eventBytes)
 		c.Send("LTRIM", eventsUIListKey, 0, 100)
 	}
+	connector.sendStreamEvent(c, &moira.StreamEvent{Type: moira.StreamEventNotification, TriggerID: event.TriggerID, Event: event})
 	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -98,6 +159,10 @@ func (connector *DbConnector) FetchNotificationEvent() (moira.NotificationEvent,
 var eventsListKey = "moira-trigger-events"
 var eventsUIListKey = "moira-trigger-events-ui"
 
+// eventsTimelineKey is sorted set of events of all triggers scored by timestamp
+// It stores one more copy of every event json besides trigger events lists, so its size grows with number of events for 30 days
+var eventsTimelineKey = "moira-events-timeline"
+
 func triggerEventsKey(triggerID string) string {
 	return fmt.Sprintf("moira-trigger-events:%s", triggerID)
 }
@@ -151,6 +151,74 @@ func TestNotificationEvents(t *testing.T) {
 	})
 }
 
+func TestNotificationEventsTimeline(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Events of all triggers should be got by time range", t, func() {
+		now := time.Now().Unix()
+		older := moira.NotificationEvent{Timestamp: now - 60, State: "ERROR", OldState: "OK", TriggerID: uuid.NewV4().String(), Metric: "my.metric"}
+		newer := moira.NotificationEvent{Timestamp: now, State: "OK", OldState: "ERROR", TriggerID: uuid.NewV4().String(), Metric: "my.metric"}
+		withoutTrigger := moira.NotificationEvent{Timestamp: now, State: "TEST", OldState: "TEST", Metric: "Test metric"}
+		So(dataBase.PushNotificationEvent(&older, true), ShouldBeNil)
+		So(dataBase.PushNotificationEvent(&newer, true), ShouldBeNil)
+		So(dataBase.PushNotificationEvent(&withoutTrigger, false), ShouldBeNil)
+
+		actual, err := dataBase.GetNotificationEventsByTime(now-60, now, 0, -1)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, []*moira.NotificationEvent{&newer, &older})
+
+		actual, err = dataBase.GetNotificationEventsByTime(now-60, now, 1, 1)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, []*moira.NotificationEvent{&older})
+
+		actual, err = dataBase.GetNotificationEventsByTime(now-120, now-1, 0, -1)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, []*moira.NotificationEvent{&older})
+
+		actual, err = dataBase.GetNotificationEventsByTime(now+1, now+60, 0, -1)
+		So(err, ShouldBeNil)
+		So(actual, ShouldBeEmpty)
+
+		count, err := dataBase.GetNotificationEventCountByTime(now-60, now)
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 2)
+
+		count, err = dataBase.GetNotificationEventCountByTime(now+1, now+60)
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 0)
+	})
+
+	Convey("Events timeline should be filled with events of triggers and cleared on trigger removal", t, func() {
+		dataBase.flush()
+		now := time.Now().Unix()
+		trigger1 := moira.Trigger{ID: uuid.NewV4().String(), Name: "trigger1"}
+		trigger2 := moira.Trigger{ID: uuid.NewV4().String(), Name: "trigger2"}
+		So(dataBase.SaveTrigger(trigger1.ID, &trigger1), ShouldBeNil)
+		So(dataBase.SaveTrigger(trigger2.ID, &trigger2), ShouldBeNil)
+		event1 := moira.NotificationEvent{Timestamp: now - 60, State: "ERROR", OldState: "OK", TriggerID: trigger1.ID, Metric: "my.metric"}
+		event2 := moira.NotificationEvent{Timestamp: now, State: "OK", OldState: "ERROR", TriggerID: trigger2.ID, Metric: "my.metric"}
+		So(dataBase.PushNotificationEvent(&event1, true), ShouldBeNil)
+		So(dataBase.PushNotificationEvent(&event2, true), ShouldBeNil)
+
+		c := dataBase.pool.Get()
+		_, err := c.Do("DEL", eventsTimelineKey)
+		c.Close()
+		So(err, ShouldBeNil)
+		So(dataBase.FillNotificationEventsTimeline(), ShouldBeNil)
+		actual, err := dataBase.GetNotificationEventsByTime(now-60, now, 0, -1)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, []*moira.NotificationEvent{&event2, &event1})
+
+		So(dataBase.RemoveTrigger(trigger1.ID), ShouldBeNil)
+		actual, err = dataBase.GetNotificationEventsByTime(now-60, now, 0, -1)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, []*moira.NotificationEvent{&event2})
+	})
+}
+
 func TestNotificationEventErrorConnection(t *testing.T) {
 	logger, _ := logging.GetLogger("dataBase")
 	dataBase := NewDatabase(logger, emptyConfig)
@@ -170,6 +238,14 @@ func TestNotificationEventErrorConnection(t *testing.T) {
 		So(actual1, ShouldBeNil)
 		So(err, ShouldNotBeNil)
 
+		actual1, err = dataBase.GetNotificationEventsByTime(0, 1, 0, -1)
+		So(actual1, ShouldBeNil)
+		So(err, ShouldNotBeNil)
+
+		count, err := dataBase.GetNotificationEventCountByTime(0, 1)
+		So(count, ShouldEqual, 0)
+		So(err, ShouldNotBeNil)
+
 		err = dataBase.PushNotificationEvent(&notificationEvent, true)
 		So(err, ShouldNotBeNil)
 
@@ -0,0 +1,53 @@
+package redis
+
+import (
+	"encoding/json"
+
+	"github.com/garyburd/redigo/redis"
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+// SubscribePatternEvents creates subscription for patterns list changes and return channel for this events
+func (connector *DbConnector) SubscribePatternEvents(tomb *tomb.Tomb) (<-chan *moira.PatternEvent, error) {
+	patternsChannel := make(chan *moira.PatternEvent, 100)
+	dataChannel, err := connector.manageSubscriptions(tomb, patternEventKey)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			data, ok := <-dataChannel
+			if !ok {
+				connector.logger.Info("No more subscriptions, channel is closed. Stop process data...")
+				close(patternsChannel)
+				return
+			}
+			patternEvent := &moira.PatternEvent{}
+			if err := json.Unmarshal(data, patternEvent); err != nil {
+				connector.logger.Errorf("Failed to parse PatternEvent: %s, error : %v", string(data), err)
+				continue
+			}
+			patternsChannel <- patternEvent
+		}
+	}()
+
+	return patternsChannel, nil
+}
+
+// sendPatternEvent adds publishing of pattern event to connection commands
+func (connector *DbConnector) sendPatternEvent(c redis.Conn, eventType string, pattern string) {
+	event, err := json.Marshal(&moira.PatternEvent{
+		Type:    eventType,
+		Pattern: pattern,
+	})
+	if err != nil {
+		connector.logger.Errorf("Failed to marshal PatternEvent for pattern %s: %s", pattern, err.Error())
+		return
+	}
+	c.Send("PUBLISH", patternEventKey, event)
+}
+
+var patternEventKey = "pattern-event"
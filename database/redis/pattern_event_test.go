@@ -0,0 +1,42 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestPatternEventsSubscription(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	pattern := "my.test.*.metric*"
+
+	Convey("Saving and removing trigger publishes pattern events", t, func() {
+		var tomb1 tomb.Tomb
+		ch, err := dataBase.SubscribePatternEvents(&tomb1)
+		So(err, ShouldBeNil)
+		So(ch, ShouldNotBeNil)
+
+		err = dataBase.SaveTrigger("id", &moira.Trigger{ID: "id", Patterns: []string{pattern}})
+		So(err, ShouldBeNil)
+		So(<-ch, ShouldResemble, &moira.PatternEvent{Type: moira.PatternAdded, Pattern: pattern})
+
+		err = dataBase.RemoveTrigger("id")
+		So(err, ShouldBeNil)
+		So(<-ch, ShouldResemble, &moira.PatternEvent{Type: moira.PatternRemoved, Pattern: pattern})
+
+		err = dataBase.RemovePattern(pattern)
+		So(err, ShouldBeNil)
+		So(<-ch, ShouldResemble, &moira.PatternEvent{Type: moira.PatternRemoved, Pattern: pattern})
+
+		tomb1.Kill(nil)
+		_, ok := <-ch
+		So(ok, ShouldBeFalse)
+	})
+}
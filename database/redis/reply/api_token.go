@@ -0,0 +1,49 @@
+package reply
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+// APIToken converts redis DB reply to moira.APIToken object
+func APIToken(rep interface{}, err error) (moira.APIToken, error) {
+	token := moira.APIToken{}
+	bytes, err := redis.Bytes(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return token, database.ErrNil
+		}
+		return token, fmt.Errorf("Failed to read api token: %s", err.Error())
+	}
+	err = json.Unmarshal(bytes, &token)
+	if err != nil {
+		return token, fmt.Errorf("Failed to parse api token json %s: %s", string(bytes), err.Error())
+	}
+	return token, nil
+}
+
+// APITokens converts redis DB reply to moira.APIToken objects array
+func APITokens(rep interface{}, err error) ([]*moira.APIToken, error) {
+	values, err := redis.Values(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return make([]*moira.APIToken, 0), nil
+		}
+		return nil, fmt.Errorf("Failed to read api tokens: %s", err.Error())
+	}
+	tokens := make([]*moira.APIToken, len(values))
+	for i, value := range values {
+		token, err2 := APIToken(value, err)
+		if err2 != nil && err2 != database.ErrNil {
+			return nil, err2
+		} else if err2 == database.ErrNil {
+			tokens[i] = nil
+		} else {
+			tokens[i] = &token
+		}
+	}
+	return tokens, nil
+}
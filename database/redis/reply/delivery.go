@@ -0,0 +1,49 @@
+package reply
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+// Delivery converts redis DB reply to moira.DeliveryData object
+func Delivery(rep interface{}, err error) (moira.DeliveryData, error) {
+	delivery := moira.DeliveryData{}
+	bytes, err := redis.Bytes(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return delivery, database.ErrNil
+		}
+		return delivery, fmt.Errorf("Failed to read delivery: %s", err.Error())
+	}
+	err = json.Unmarshal(bytes, &delivery)
+	if err != nil {
+		return delivery, fmt.Errorf("Failed to parse delivery json %s: %s", string(bytes), err.Error())
+	}
+	return delivery, nil
+}
+
+// Deliveries converts redis DB reply to moira.DeliveryData objects array
+func Deliveries(rep interface{}, err error) ([]*moira.DeliveryData, error) {
+	values, err := redis.Values(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return make([]*moira.DeliveryData, 0), nil
+		}
+		return nil, fmt.Errorf("Failed to read deliveries: %s", err.Error())
+	}
+	deliveries := make([]*moira.DeliveryData, len(values))
+	for i, value := range values {
+		delivery, err2 := Delivery(value, err)
+		if err2 != nil && err2 != database.ErrNil {
+			return nil, err2
+		} else if err2 == database.ErrNil {
+			deliveries[i] = nil
+		} else {
+			deliveries[i] = &delivery
+		}
+	}
+	return deliveries, nil
+}
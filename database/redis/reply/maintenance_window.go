@@ -0,0 +1,49 @@
+package reply
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+// MaintenanceWindow converts redis DB reply to moira.MaintenanceWindow object
+func MaintenanceWindow(rep interface{}, err error) (moira.MaintenanceWindow, error) {
+	window := moira.MaintenanceWindow{}
+	bytes, err := redis.Bytes(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return window, database.ErrNil
+		}
+		return window, fmt.Errorf("Failed to read maintenance window: %s", err.Error())
+	}
+	err = json.Unmarshal(bytes, &window)
+	if err != nil {
+		return window, fmt.Errorf("Failed to parse maintenance window json %s: %s", string(bytes), err.Error())
+	}
+	return window, nil
+}
+
+// MaintenanceWindows converts redis DB reply to moira.MaintenanceWindow objects array
+func MaintenanceWindows(rep interface{}, err error) ([]*moira.MaintenanceWindow, error) {
+	values, err := redis.Values(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return make([]*moira.MaintenanceWindow, 0), nil
+		}
+		return nil, fmt.Errorf("Failed to read maintenance windows: %s", err.Error())
+	}
+	windows := make([]*moira.MaintenanceWindow, len(values))
+	for i, value := range values {
+		window, err2 := MaintenanceWindow(value, err)
+		if err2 != nil && err2 != database.ErrNil {
+			return nil, err2
+		} else if err2 == database.ErrNil {
+			windows[i] = nil
+		} else {
+			windows[i] = &window
+		}
+	}
+	return windows, nil
+}
@@ -24,6 +24,7 @@ type triggerStorageElement struct {
 	PythonExpression *string             `json:"expression,omitempty"`
 	Patterns         []string            `json:"patterns"`
 	TTL              string              `json:"ttl,omitempty"`
+	TeamID           string              `json:"team_id,omitempty"`
 }
 
 func (storageElement *triggerStorageElement) toTrigger() moira.Trigger {
@@ -41,6 +42,7 @@ func (storageElement *triggerStorageElement) toTrigger() moira.Trigger {
 		PythonExpression: storageElement.PythonExpression,
 		Patterns:         storageElement.Patterns,
 		TTL:              getTriggerTTL(storageElement.TTL),
+		TeamID:           storageElement.TeamID,
 	}
 }
 
@@ -59,6 +61,7 @@ func toTriggerStorageElement(trigger *moira.Trigger, triggerID string) *triggerS
 		PythonExpression: trigger.PythonExpression,
 		Patterns:         trigger.Patterns,
 		TTL:              getTriggerTTLString(trigger.TTL),
+		TeamID:           trigger.TeamID,
 	}
 }
 
@@ -0,0 +1,58 @@
+package reply
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+// TriggerVersion converts redis DB reply to moira.TriggerVersion object
+func TriggerVersion(rep interface{}, err error) (moira.TriggerVersion, error) {
+	version := moira.TriggerVersion{}
+	bytes, err := redis.Bytes(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return version, database.ErrNil
+		}
+		return version, fmt.Errorf("Failed to read trigger version: %s", err.Error())
+	}
+	err = json.Unmarshal(bytes, &version)
+	if err != nil {
+		return version, fmt.Errorf("Failed to parse trigger version json %s: %s", string(bytes), err.Error())
+	}
+	return version, nil
+}
+
+// TriggerVersions converts redis DB reply to moira.TriggerVersion objects array
+// Versions saved without number precede numbered ones and are numbered back from them, or by position in history starting from 1
+func TriggerVersions(rep interface{}, err error) ([]*moira.TriggerVersion, error) {
+	values, err := redis.Values(rep, err)
+	if err != nil {
+		if err == redis.ErrNil {
+			return make([]*moira.TriggerVersion, 0), nil
+		}
+		return nil, fmt.Errorf("Failed to read trigger versions: %s", err.Error())
+	}
+	versions := make([]*moira.TriggerVersion, 0, len(values))
+	for _, value := range values {
+		version, err2 := TriggerVersion(value, err)
+		if err2 != nil {
+			return nil, err2
+		}
+		versions = append(versions, &version)
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if versions[i].Version != 0 {
+			continue
+		}
+		if i == len(versions)-1 {
+			versions[i].Version = int64(i + 1)
+		} else {
+			versions[i].Version = versions[i+1].Version - 1
+		}
+	}
+	return versions, nil
+}
@@ -0,0 +1,128 @@
+package redis
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+
+	"github.com/moira-alert/moira"
+)
+
+// seriesTagsTTL is time in seconds after which tagged series which were not saved again are dropped from series tags index
+const seriesTagsTTL int64 = 24 * 3600
+
+// GetSeriesTagValues gets all known values of given tag of graphite tagged series
+func (connector *DbConnector) GetSeriesTagValues(tag string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	values, err := redis.Strings(c.Do("ZRANGEBYSCORE", seriesTagValuesKey(tag), time.Now().Unix()-seriesTagsTTL, "+inf"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get values of series tag %s, error: %v", tag, err)
+	}
+	return values, nil
+}
+
+// GetSeriesByTag gets tagged series which have given tag with any of given values
+func (connector *DbConnector) GetSeriesByTag(tag string, values []string) ([]string, error) {
+	if len(values) == 0 {
+		return make([]string, 0), nil
+	}
+
+	c := connector.pool.Get()
+	defer c.Close()
+
+	from := time.Now().Unix() - seriesTagsTTL
+	c.Send("MULTI")
+	for _, value := range values {
+		c.Send("ZRANGEBYSCORE", seriesTagKey(tag, value), from, "+inf")
+	}
+	seriesByValues, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get series by tag %s, error: %v", tag, err)
+	}
+	unique := make(map[string]bool)
+	series := make([]string, 0)
+	for _, seriesByValue := range seriesByValues {
+		valueSeries, err := redis.Strings(seriesByValue, nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to get series by tag %s, error: %v", tag, err)
+		}
+		for _, metric := range valueSeries {
+			if !unique[metric] {
+				unique[metric] = true
+				series = append(series, metric)
+			}
+		}
+	}
+	return series, nil
+}
+
+// RemoveSeriesTags removes tagged series from series tags index, series is indexed again when it is saved next time
+func (connector *DbConnector) RemoveSeriesTags(metric string) error {
+	tags, err := moira.ParseTaggedMetric(metric)
+	if err != nil {
+		return nil
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	for tag, value := range tags {
+		c.Send("ZREM", seriesTagKey(tag, value), metric)
+	}
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to remove series %s tags: %s", metric, err.Error())
+	}
+	connector.seriesTagsCache.Delete(metric)
+	return nil
+}
+
+// needIndexSeriesTags checks that metric is tagged series which was not indexed during last minute
+func (connector *DbConnector) needIndexSeriesTags(metric string) bool {
+	if !moira.IsTaggedMetric(metric) {
+		return false
+	}
+	_, indexed := connector.seriesTagsCache.Get(metric)
+	return !indexed
+}
+
+// execSeriesTags indexes tagged series by their tags with current time and prunes series not saved for seriesTagsTTL,
+// it executes commands already sent to connection too, series are cached as indexed only if all commands succeed
+func (connector *DbConnector) execSeriesTags(c redis.Conn, metrics []string) error {
+	now := time.Now().Unix()
+	keys := make(map[string]bool)
+	c.Send("MULTI")
+	for _, metric := range metrics {
+		tags, err := moira.ParseTaggedMetric(metric)
+		if err != nil {
+			continue
+		}
+		for tag, value := range tags {
+			c.Send("ZADD", seriesTagValuesKey(tag), now, value)
+			c.Send("ZADD", seriesTagKey(tag, value), now, metric)
+			keys[seriesTagValuesKey(tag)] = true
+			keys[seriesTagKey(tag, value)] = true
+		}
+	}
+	for key := range keys {
+		c.Send("ZREMRANGEBYSCORE", key, "-inf", fmt.Sprintf("(%d", now-seriesTagsTTL))
+		c.Send("EXPIRE", key, seriesTagsTTL)
+	}
+	if _, err := c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to index series tags: %s", err.Error())
+	}
+	for _, metric := range metrics {
+		connector.seriesTagsCache.Set(metric, true, 0)
+	}
+	return nil
+}
+
+// Series tags index keys differ from keys of sets used before, sets under old moira-series-tag keys are not used anymore
+func seriesTagValuesKey(tag string) string {
+	return fmt.Sprintf("moira-tag-values:%s", tag)
+}
+
+func seriesTagKey(tag, value string) string {
+	return fmt.Sprintf("moira-tag-series:%s:%s", tag, value)
+}
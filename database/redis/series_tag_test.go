@@ -0,0 +1,113 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestSeriesTags(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Tagged series are indexed on save", t, func() {
+		err := dataBase.SaveMetrics(map[string]*moira.MatchedMetric{
+			"cpu;dc=eu1;host=a": {Metric: "cpu;dc=eu1;host=a", Timestamp: 1502712000, RetentionTimestamp: 1502712000, Retention: 60},
+			"cpu;dc=us;host=b":  {Metric: "cpu;dc=us;host=b", Timestamp: 1502712000, RetentionTimestamp: 1502712000, Retention: 60},
+			"plain.metric":      {Metric: "plain.metric", Timestamp: 1502712000, RetentionTimestamp: 1502712000, Retention: 60},
+		})
+		So(err, ShouldBeNil)
+
+		values, err := dataBase.GetSeriesTagValues("dc")
+		So(err, ShouldBeNil)
+		So(values, ShouldHaveLength, 2)
+		So(values, ShouldContain, "eu1")
+		So(values, ShouldContain, "us")
+
+		values, err = dataBase.GetSeriesTagValues("name")
+		So(err, ShouldBeNil)
+		So(values, ShouldResemble, []string{"cpu"})
+
+		series, err := dataBase.GetSeriesByTag("dc", []string{"eu1"})
+		So(err, ShouldBeNil)
+		So(series, ShouldResemble, []string{"cpu;dc=eu1;host=a"})
+
+		series, err = dataBase.GetSeriesByTag("name", []string{"cpu", "mem"})
+		So(err, ShouldBeNil)
+		So(series, ShouldHaveLength, 2)
+
+		series, err = dataBase.GetSeriesByTag("dc", []string{})
+		So(err, ShouldBeNil)
+		So(series, ShouldBeEmpty)
+	})
+
+	Convey("Removed series is not found and is indexed again on next save", t, func() {
+		metric := "mem;dc=eu1"
+		metrics := map[string]*moira.MatchedMetric{metric: {Metric: metric, Timestamp: 1502712000, RetentionTimestamp: 1502712000, Retention: 60}}
+		So(dataBase.SaveMetrics(metrics), ShouldBeNil)
+		So(dataBase.RemoveSeriesTags(metric), ShouldBeNil)
+		series, err := dataBase.GetSeriesByTag("name", []string{"mem"})
+		So(err, ShouldBeNil)
+		So(series, ShouldBeEmpty)
+
+		So(dataBase.SaveMetrics(metrics), ShouldBeNil)
+		series, err = dataBase.GetSeriesByTag("name", []string{"mem"})
+		So(err, ShouldBeNil)
+		So(series, ShouldResemble, []string{metric})
+	})
+
+	Convey("Series not saved for series tags TTL are not found and are pruned on next index", t, func() {
+		c := dataBase.pool.Get()
+		lastSeen := time.Now().Unix() - seriesTagsTTL - 1
+		c.Do("ZADD", seriesTagValuesKey("dc"), lastSeen, "old")
+		c.Do("ZADD", seriesTagKey("dc", "eu1"), lastSeen, "disk;dc=eu1")
+		c.Close()
+
+		values, err := dataBase.GetSeriesTagValues("dc")
+		So(err, ShouldBeNil)
+		So(values, ShouldNotContain, "old")
+		series, err := dataBase.GetSeriesByTag("dc", []string{"eu1"})
+		So(err, ShouldBeNil)
+		So(series, ShouldNotContain, "disk;dc=eu1")
+
+		metric := "net;dc=eu1"
+		So(dataBase.SaveMetrics(map[string]*moira.MatchedMetric{metric: {Metric: metric, Timestamp: 1502712000, RetentionTimestamp: 1502712000, Retention: 60}}), ShouldBeNil)
+		c = dataBase.pool.Get()
+		defer c.Close()
+		count, err := redis.Int(c.Do("ZCARD", seriesTagValuesKey("dc")))
+		So(err, ShouldBeNil)
+		So(count, ShouldEqual, 2)
+		ttl, err := redis.Int64(c.Do("TTL", seriesTagKey("dc", "eu1")))
+		So(err, ShouldBeNil)
+		So(ttl, ShouldBeGreaterThan, 0)
+	})
+
+	Convey("Series is not cached as indexed if saving fails", t, func() {
+		metric := "swap;dc=eu1"
+		brokenDataBase := NewDatabase(logger, emptyConfig)
+		err := brokenDataBase.SaveMetrics(map[string]*moira.MatchedMetric{metric: {Metric: metric, Timestamp: 1502712000, RetentionTimestamp: 1502712000, Retention: 60}})
+		So(err, ShouldNotBeNil)
+		So(brokenDataBase.needIndexSeriesTags(metric), ShouldBeTrue)
+	})
+
+	Convey("Test on bad connection", t, func() {
+		dataBase = NewDatabase(logger, emptyConfig)
+		dataBase.flush()
+
+		_, err := dataBase.GetSeriesTagValues("dc")
+		So(err, ShouldNotBeNil)
+
+		_, err = dataBase.GetSeriesByTag("dc", []string{"eu1"})
+		So(err, ShouldNotBeNil)
+
+		err = dataBase.RemoveSeriesTags("cpu;dc=eu1")
+		So(err, ShouldNotBeNil)
+	})
+}
@@ -0,0 +1,50 @@
+package redis
+
+import (
+	"encoding/json"
+
+	"github.com/garyburd/redigo/redis"
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+// SubscribeStreamEvents creates subscription for trigger check results and new notification events and return channel for this events
+func (connector *DbConnector) SubscribeStreamEvents(tomb *tomb.Tomb) (<-chan *moira.StreamEvent, error) {
+	streamChannel := make(chan *moira.StreamEvent, 100)
+	dataChannel, err := connector.manageSubscriptions(tomb, streamEventKey)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		for {
+			data, ok := <-dataChannel
+			if !ok {
+				connector.logger.Info("No more subscriptions, channel is closed. Stop process data...")
+				close(streamChannel)
+				return
+			}
+			streamEvent := &moira.StreamEvent{}
+			if err := json.Unmarshal(data, streamEvent); err != nil {
+				connector.logger.Errorf("Failed to parse StreamEvent: %s, error : %v", string(data), err)
+				continue
+			}
+			streamChannel <- streamEvent
+		}
+	}()
+
+	return streamChannel, nil
+}
+
+// sendStreamEvent adds publishing of stream event to connection commands
+func (connector *DbConnector) sendStreamEvent(c redis.Conn, event *moira.StreamEvent) {
+	bytes, err := json.Marshal(event)
+	if err != nil {
+		connector.logger.Errorf("Failed to marshal StreamEvent for trigger %s: %s", event.TriggerID, err.Error())
+		return
+	}
+	c.Send("PUBLISH", streamEventKey, bytes)
+}
+
+var streamEventKey = "stream-event"
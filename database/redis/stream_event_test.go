@@ -0,0 +1,45 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestStreamEventsSubscription(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	Convey("Saving last check and pushing notification event publishes stream events", t, func() {
+		var tomb1 tomb.Tomb
+		ch, err := dataBase.SubscribeStreamEvents(&tomb1)
+		So(err, ShouldBeNil)
+		So(ch, ShouldNotBeNil)
+
+		checkData := &moira.CheckData{
+			State:     "ERROR",
+			Score:     100,
+			Timestamp: 1500000000,
+			Metrics:   map[string]moira.MetricState{"my.metric": {State: "ERROR", Timestamp: 1500000000}},
+		}
+		err = dataBase.SetTriggerLastCheck("id", checkData)
+		So(err, ShouldBeNil)
+		streamState := &moira.CheckData{State: "ERROR", Score: 100, Timestamp: 1500000000}
+		So(<-ch, ShouldResemble, &moira.StreamEvent{Type: moira.StreamEventTriggerState, TriggerID: "id", State: streamState})
+
+		event := &moira.NotificationEvent{Timestamp: 1500000000, Metric: "my.metric", State: "ERROR", OldState: "OK", TriggerID: "id"}
+		err = dataBase.PushNotificationEvent(event, true)
+		So(err, ShouldBeNil)
+		So(<-ch, ShouldResemble, &moira.StreamEvent{Type: moira.StreamEventNotification, TriggerID: "id", Event: event})
+
+		tomb1.Kill(nil)
+		_, ok := <-ch
+		So(ok, ShouldBeFalse)
+	})
+}
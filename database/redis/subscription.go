@@ -103,6 +103,9 @@ func (connector *DbConnector) RemoveSubscription(subscriptionID string) error {
 	defer c.Close()
 	c.Send("MULTI")
 	c.Send("SREM", userSubscriptionsKey(subscription.User), subscriptionID)
+	if subscription.TeamID != "" {
+		c.Send("SREM", teamSubscriptionsKey(subscription.TeamID), subscriptionID)
+	}
 	for _, tag := range subscription.Tags {
 		c.Send("SREM", tagSubscriptionKey(tag), subscriptionID)
 	}
@@ -167,11 +170,17 @@ func addSendSubscriptionRequest(c redis.Conn, subscription *moira.SubscriptionDa
 		if oldSubscription.User != subscription.User {
 			c.Send("SREM", userSubscriptionsKey(oldSubscription.User), subscription.ID)
 		}
+		if oldSubscription.TeamID != "" && oldSubscription.TeamID != subscription.TeamID {
+			c.Send("SREM", teamSubscriptionsKey(oldSubscription.TeamID), subscription.ID)
+		}
 	}
 	for _, tag := range subscription.Tags {
 		c.Send("SADD", tagSubscriptionKey(tag), subscription.ID)
 	}
 	c.Send("SADD", userSubscriptionsKey(subscription.User), subscription.ID)
+	if subscription.TeamID != "" {
+		c.Send("SADD", teamSubscriptionsKey(subscription.TeamID), subscription.ID)
+	}
 	c.Send("SET", subscriptionKey(subscription.ID), bytes)
 	return nil
 }
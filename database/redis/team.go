@@ -0,0 +1,144 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/database/redis/reply"
+)
+
+// GetTeam returns team by given id, if no value, return database.ErrNil error
+func (connector *DbConnector) GetTeam(teamID string) (moira.Team, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	return reply.Team(c.Do("GET", teamKey(teamID)))
+}
+
+// GetTeams returns teams by given ids, len of teamIDs is equal to len of returned values array.
+// If there is no object by current ID, then nil is returned
+func (connector *DbConnector) GetTeams(teamIDs []string) ([]*moira.Team, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	for _, id := range teamIDs {
+		c.Send("GET", teamKey(id))
+	}
+	return reply.Teams(c.Do("EXEC"))
+}
+
+// GetUserTeamIDs returns ids of teams where user is member
+func (connector *DbConnector) GetUserTeamIDs(login string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	teamIDs, err := redis.Strings(c.Do("SMEMBERS", userTeamsKey(login)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get teams for user login %s: %s", login, err.Error())
+	}
+	return teamIDs, nil
+}
+
+// SaveTeam writes team data and updates teams of its members
+func (connector *DbConnector) SaveTeam(team *moira.Team) error {
+	existing, getTeamErr := connector.GetTeam(team.ID)
+	if getTeamErr != nil && getTeamErr != database.ErrNil {
+		return getTeamErr
+	}
+	teamString, err := json.Marshal(team)
+	if err != nil {
+		return err
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	c.Send("SET", teamKey(team.ID), teamString)
+	for login := range existing.Members {
+		if _, ok := team.Members[login]; !ok {
+			c.Send("SREM", userTeamsKey(login), team.ID)
+		}
+	}
+	for login := range team.Members {
+		c.Send("SADD", userTeamsKey(login), team.ID)
+	}
+	if _, err = c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// RemoveTeam deletes team data and teamID from teams of its members
+func (connector *DbConnector) RemoveTeam(teamID string) error {
+	existing, err := connector.GetTeam(teamID)
+	if err != nil {
+		if err == database.ErrNil {
+			return nil
+		}
+		return err
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	c.Send("MULTI")
+	c.Send("DEL", teamKey(teamID))
+	for login := range existing.Members {
+		c.Send("SREM", userTeamsKey(login), teamID)
+	}
+	if _, err = c.Do("EXEC"); err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// GetTeamTriggerIDs returns ids of triggers owned by team
+func (connector *DbConnector) GetTeamTriggerIDs(teamID string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	triggerIDs, err := redis.Strings(c.Do("SMEMBERS", teamTriggersKey(teamID)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get triggers for team %s: %s", teamID, err.Error())
+	}
+	return triggerIDs, nil
+}
+
+// GetTeamContactIDs returns ids of contacts shared with team
+func (connector *DbConnector) GetTeamContactIDs(teamID string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	contactIDs, err := redis.Strings(c.Do("SMEMBERS", teamContactsKey(teamID)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get contacts for team %s: %s", teamID, err.Error())
+	}
+	return contactIDs, nil
+}
+
+// GetTeamSubscriptionIDs returns ids of subscriptions shared with team
+func (connector *DbConnector) GetTeamSubscriptionIDs(teamID string) ([]string, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	subscriptionIDs, err := redis.Strings(c.Do("SMEMBERS", teamSubscriptionsKey(teamID)))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get subscriptions for team %s: %s", teamID, err.Error())
+	}
+	return subscriptionIDs, nil
+}
+
+func teamKey(id string) string {
+	return fmt.Sprintf("moira-team:%s", id)
+}
+
+func userTeamsKey(login string) string {
+	return fmt.Sprintf("moira-user-teams:%s", login)
+}
+
+func teamTriggersKey(teamID string) string {
+	return fmt.Sprintf("moira-team-triggers:%s", teamID)
+}
+
+func teamContactsKey(teamID string) string {
+	return fmt.Sprintf("moira-team-contacts:%s", teamID)
+}
+
+func teamSubscriptionsKey(teamID string) string {
+	return fmt.Sprintf("moira-team-subscriptions:%s", teamID)
+}
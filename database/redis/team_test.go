@@ -0,0 +1,91 @@
+package redis
+
+import (
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+func TestTeamStoring(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	team := moira.Team{
+		ID:      "team-1",
+		Name:    "Ops",
+		Members: map[string]string{user1: moira.TeamRoleAdmin, user2: moira.TeamRoleViewer},
+	}
+
+	Convey("Teams manipulation", t, func() {
+		_, err := dataBase.GetTeam(team.ID)
+		So(err, ShouldResemble, database.ErrNil)
+
+		So(dataBase.SaveTeam(&team), ShouldBeNil)
+		actual, err := dataBase.GetTeam(team.ID)
+		So(err, ShouldBeNil)
+		So(actual, ShouldResemble, team)
+
+		teams, err := dataBase.GetTeams([]string{team.ID, "unknown"})
+		So(err, ShouldBeNil)
+		So(teams, ShouldResemble, []*moira.Team{&team, nil})
+
+		ids, err := dataBase.GetUserTeamIDs(user2)
+		So(err, ShouldBeNil)
+		So(ids, ShouldResemble, []string{team.ID})
+
+		Convey("Removed member should lose team", func() {
+			updated := moira.Team{ID: team.ID, Name: team.Name, Members: map[string]string{user1: moira.TeamRoleAdmin}}
+			So(dataBase.SaveTeam(&updated), ShouldBeNil)
+			ids, err := dataBase.GetUserTeamIDs(user2)
+			So(err, ShouldBeNil)
+			So(ids, ShouldBeEmpty)
+		})
+
+		Convey("Team triggers, contacts and subscriptions should be indexed", func() {
+			trigger := moira.Trigger{ID: "team-trigger", Name: "trigger", Targets: []string{"my.metric"}, Patterns: []string{"my.metric"}, Tags: []string{"tag"}, TeamID: team.ID}
+			So(dataBase.SaveTrigger(trigger.ID, &trigger), ShouldBeNil)
+			contact := moira.ContactData{ID: "team-contact", Type: "mail", Value: "ops@example.com", User: user1, TeamID: team.ID}
+			So(dataBase.SaveContact(&contact), ShouldBeNil)
+			subscription := moira.SubscriptionData{ID: "team-subscription", Tags: []string{"tag"}, Contacts: []string{contact.ID}, User: user1, TeamID: team.ID}
+			So(dataBase.SaveSubscription(&subscription), ShouldBeNil)
+
+			triggerIDs, err := dataBase.GetTeamTriggerIDs(team.ID)
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{trigger.ID})
+			contactIDs, err := dataBase.GetTeamContactIDs(team.ID)
+			So(err, ShouldBeNil)
+			So(contactIDs, ShouldResemble, []string{contact.ID})
+			subscriptionIDs, err := dataBase.GetTeamSubscriptionIDs(team.ID)
+			So(err, ShouldBeNil)
+			So(subscriptionIDs, ShouldResemble, []string{subscription.ID})
+
+			trigger.TeamID = ""
+			So(dataBase.SaveTrigger(trigger.ID, &trigger), ShouldBeNil)
+			So(dataBase.RemoveContact(contact.ID), ShouldBeNil)
+			So(dataBase.RemoveSubscription(subscription.ID), ShouldBeNil)
+
+			triggerIDs, err = dataBase.GetTeamTriggerIDs(team.ID)
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldBeEmpty)
+			contactIDs, err = dataBase.GetTeamContactIDs(team.ID)
+			So(err, ShouldBeNil)
+			So(contactIDs, ShouldBeEmpty)
+			subscriptionIDs, err = dataBase.GetTeamSubscriptionIDs(team.ID)
+			So(err, ShouldBeNil)
+			So(subscriptionIDs, ShouldBeEmpty)
+		})
+
+		So(dataBase.RemoveTeam(team.ID), ShouldBeNil)
+		_, err = dataBase.GetTeam(team.ID)
+		So(err, ShouldResemble, database.ErrNil)
+		ids, err = dataBase.GetUserTeamIDs(user1)
+		So(err, ShouldBeNil)
+		So(ids, ShouldBeEmpty)
+	})
+}
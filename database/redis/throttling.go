@@ -41,6 +41,28 @@ func (connector *DbConnector) DeleteTriggerThrottling(triggerID string) error {
 	return nil
 }
 
+// GetSubscriptionThrottling get the end of subscription throttling window which starts at the last scheduled delivery of notifications about given trigger
+func (connector *DbConnector) GetSubscriptionThrottling(subscriptionID, triggerID string) time.Time {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	next, _ := redis.Int64(c.Do("GET", notifierSubscriptionNextKey(subscriptionID, triggerID)))
+	return time.Unix(next, 0)
+}
+
+// SetSubscriptionThrottling store the end of subscription throttling window which starts at the last scheduled delivery of notifications about given trigger
+func (connector *DbConnector) SetSubscriptionThrottling(subscriptionID, triggerID string, next time.Time) error {
+	c := connector.pool.Get()
+	defer c.Close()
+
+	ttl := int64(next.Sub(time.Now()).Seconds())
+	if ttl <= 0 {
+		ttl = 1
+	}
+	_, err := c.Do("SET", notifierSubscriptionNextKey(subscriptionID, triggerID), next.Unix(), "EX", ttl)
+	return err
+}
+
 func notifierThrottlingBeginningKey(triggerID string) string {
 	return fmt.Sprintf("moira-notifier-throttling-beginning:%s", triggerID)
 }
@@ -48,3 +70,7 @@ func notifierThrottlingBeginningKey(triggerID string) string {
 func notifierNextKey(triggerID string) string {
 	return fmt.Sprintf("moira-notifier-next:%s", triggerID)
 }
+
+func notifierSubscriptionNextKey(subscriptionID, triggerID string) string {
+	return fmt.Sprintf("moira-notifier-subscription-next:%s:%s", subscriptionID, triggerID)
+}
@@ -24,5 +24,32 @@ func TestThrottlingErrorConnection(t *testing.T) {
 
 		err = dataBase.DeleteTriggerThrottling("")
 		So(err, ShouldNotBeNil)
+
+		next := dataBase.GetSubscriptionThrottling("", "")
+		So(next, ShouldResemble, time.Unix(0, 0))
+
+		err = dataBase.SetSubscriptionThrottling("", "", time.Now())
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestSubscriptionThrottling(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+	Convey("Subscription throttling manipulation", t, func() {
+		next := dataBase.GetSubscriptionThrottling("subscription", "trigger")
+		So(next, ShouldResemble, time.Unix(0, 0))
+
+		expected := time.Unix(time.Now().Add(time.Minute*15).Unix(), 0)
+		err := dataBase.SetSubscriptionThrottling("subscription", "trigger", expected)
+		So(err, ShouldBeNil)
+
+		next = dataBase.GetSubscriptionThrottling("subscription", "trigger")
+		So(next, ShouldResemble, expected)
+
+		next = dataBase.GetSubscriptionThrottling("subscription", "other-trigger")
+		So(next, ShouldResemble, time.Unix(0, 0))
 	})
 }
@@ -104,6 +104,10 @@ func (connector *DbConnector) SaveTrigger(triggerID string, trigger *moira.Trigg
 	if err != nil {
 		return err
 	}
+	searchText, err := getTriggerSearchText(trigger)
+	if err != nil {
+		return err
+	}
 	c := connector.pool.Get()
 	defer c.Close()
 	c.Send("MULTI")
@@ -117,18 +121,27 @@ func (connector *DbConnector) SaveTrigger(triggerID string, trigger *moira.Trigg
 			c.Send("SREM", triggerTagsKey(triggerID), tag)
 			c.Send("SREM", tagTriggersKey(tag), triggerID)
 		}
+		if existing.TeamID != "" && existing.TeamID != trigger.TeamID {
+			c.Send("SREM", teamTriggersKey(existing.TeamID), triggerID)
+		}
 	}
 	c.Do("SET", triggerKey(triggerID), bytes)
 	c.Do("SADD", triggersListKey, triggerID)
 	for _, pattern := range trigger.Patterns {
 		c.Do("SADD", patternsListKey, pattern)
 		c.Do("SADD", patternTriggersKey(pattern), triggerID)
+		connector.sendPatternEvent(c, moira.PatternAdded, pattern)
 	}
 	for _, tag := range trigger.Tags {
 		c.Send("SADD", triggerTagsKey(triggerID), tag)
 		c.Send("SADD", tagTriggersKey(tag), triggerID)
 		c.Send("SADD", tagsKey, tag)
 	}
+	if trigger.TeamID != "" {
+		c.Send("SADD", teamTriggersKey(trigger.TeamID), triggerID)
+	}
+	c.Send("HSET", triggerSearchTextKey, triggerID, searchText)
+	sendTriggerSearchTokens(c, triggerID, &existing, trigger)
 	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -147,7 +160,7 @@ func (connector *DbConnector) SaveTrigger(triggerID string, trigger *moira.Trigg
 	return nil
 }
 
-// RemoveTrigger deletes trigger data by given triggerID, delete trigger tag list,
+// RemoveTrigger deletes trigger data by given triggerID, delete trigger tag list and trigger events from events timeline,
 // Deletes triggerID from containing tags triggers list and from containing patterns triggers list
 // If containing patterns doesn't used in another triggers, then delete this patterns with metrics data
 func (connector *DbConnector) RemoveTrigger(triggerID string) error {
@@ -161,6 +174,10 @@ func (connector *DbConnector) RemoveTrigger(triggerID string) error {
 
 	c := connector.pool.Get()
 	defer c.Close()
+	events, err := redis.Values(c.Do("ZRANGE", triggerEventsKey(triggerID), 0, -1))
+	if err != nil {
+		return fmt.Errorf("Failed to get trigger events: %s", err.Error())
+	}
 
 	c.Send("MULTI")
 	c.Send("DEL", triggerKey(triggerID))
@@ -172,6 +189,14 @@ func (connector *DbConnector) RemoveTrigger(triggerID string) error {
 	for _, pattern := range trigger.Patterns {
 		c.Send("SREM", patternTriggersKey(pattern), triggerID)
 	}
+	if trigger.TeamID != "" {
+		c.Send("SREM", teamTriggersKey(trigger.TeamID), triggerID)
+	}
+	c.Send("HDEL", triggerSearchTextKey, triggerID)
+	sendTriggerSearchTokens(c, triggerID, &trigger, nil)
+	if len(events) > 0 {
+		c.Send("ZREM", append([]interface{}{eventsTimelineKey}, events...)...)
+	}
 	_, err = c.Do("EXEC")
 	if err != nil {
 		return fmt.Errorf("Failed to EXEC: %s", err.Error())
@@ -0,0 +1,95 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/garyburd/redigo/redis"
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+	"github.com/moira-alert/moira/database/redis/reply"
+)
+
+// AddTriggerVersion appends trigger snapshot to trigger history and sets its version number
+// Version numbers are taken from separate counter, so they are not reused when the oldest versions are trimmed from history
+// History is kept after trigger removal to allow its restore
+func (connector *DbConnector) AddTriggerVersion(triggerID string, version *moira.TriggerVersion) error {
+	c := connector.pool.Get()
+	defer c.Close()
+	for try := 0; try < addTriggerVersionTries; try++ {
+		added, err := connector.addTriggerVersion(c, triggerID, version)
+		if err != nil {
+			return err
+		}
+		if added {
+			return nil
+		}
+	}
+	return fmt.Errorf("Failed to add trigger version: history of trigger %s is concurrently modified", triggerID)
+}
+
+// addTriggerVersion increments version counter and appends version in one transaction,
+// false is returned if counter or history was changed by other client after the counter was read
+// Counter of history written before it was introduced starts from history length
+func (connector *DbConnector) addTriggerVersion(c redis.Conn, triggerID string, version *moira.TriggerVersion) (bool, error) {
+	if _, err := c.Do("WATCH", triggerHistoryKey(triggerID), triggerHistoryVersionKey(triggerID)); err != nil {
+		return false, fmt.Errorf("Failed to WATCH trigger history: %s", err.Error())
+	}
+	number, err := redis.Int64(c.Do("GET", triggerHistoryVersionKey(triggerID)))
+	if err == redis.ErrNil {
+		number, err = redis.Int64(c.Do("LLEN", triggerHistoryKey(triggerID)))
+	}
+	if err != nil {
+		c.Do("UNWATCH")
+		return false, fmt.Errorf("Failed to get trigger version number: %s", err.Error())
+	}
+	version.Version = number + 1
+	bytes, err := json.Marshal(version)
+	if err != nil {
+		c.Do("UNWATCH")
+		return false, err
+	}
+	c.Send("MULTI")
+	c.Send("SET", triggerHistoryVersionKey(triggerID), version.Version)
+	c.Send("RPUSH", triggerHistoryKey(triggerID), bytes)
+	if connector.historyLimit > 0 {
+		c.Send("LTRIM", triggerHistoryKey(triggerID), -connector.historyLimit, -1)
+	}
+	rawResponse, err := c.Do("EXEC")
+	if err != nil {
+		return false, fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return rawResponse != nil, nil
+}
+
+// GetTriggerVersions returns trigger history sorted from the oldest version
+func (connector *DbConnector) GetTriggerVersions(triggerID string) ([]*moira.TriggerVersion, error) {
+	c := connector.pool.Get()
+	defer c.Close()
+	return reply.TriggerVersions(c.Do("LRANGE", triggerHistoryKey(triggerID), 0, -1))
+}
+
+// GetTriggerVersion returns trigger snapshot of given version, if no value, return database.ErrNil error
+func (connector *DbConnector) GetTriggerVersion(triggerID string, version int64) (moira.TriggerVersion, error) {
+	versions, err := connector.GetTriggerVersions(triggerID)
+	if err != nil {
+		return moira.TriggerVersion{}, err
+	}
+	for _, triggerVersion := range versions {
+		if triggerVersion.Version == version {
+			return *triggerVersion, nil
+		}
+	}
+	return moira.TriggerVersion{}, database.ErrNil
+}
+
+// addTriggerVersionTries is number of attempts to add version while history is modified by other clients
+const addTriggerVersionTries = 10
+
+func triggerHistoryKey(triggerID string) string {
+	return fmt.Sprintf("moira-trigger-history:%s", triggerID)
+}
+
+func triggerHistoryVersionKey(triggerID string) string {
+	return fmt.Sprintf("moira-trigger-history-version:%s", triggerID)
+}
@@ -0,0 +1,107 @@
+package redis
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/database"
+)
+
+func TestTriggerHistory(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	trigger := moira.Trigger{ID: "trigger", Name: "cpu", Targets: []string{"my.metric"}, Tags: []string{"tag"}, Patterns: []string{"my.metric"}}
+
+	Convey("Trigger history manipulation", t, func() {
+		versions, err := dataBase.GetTriggerVersions(trigger.ID)
+		So(err, ShouldBeNil)
+		So(versions, ShouldBeEmpty)
+
+		created := &moira.TriggerVersion{Action: moira.TriggerActionCreate, Author: user1, Timestamp: 100, Trigger: trigger}
+		So(dataBase.AddTriggerVersion(trigger.ID, created), ShouldBeNil)
+		So(created.Version, ShouldEqual, 1)
+		deleted := &moira.TriggerVersion{Action: moira.TriggerActionDelete, Author: user2, Timestamp: 200, Comment: "unused", Trigger: trigger}
+		So(dataBase.AddTriggerVersion(trigger.ID, deleted), ShouldBeNil)
+		So(deleted.Version, ShouldEqual, 2)
+
+		versions, err = dataBase.GetTriggerVersions(trigger.ID)
+		So(err, ShouldBeNil)
+		So(versions, ShouldResemble, []*moira.TriggerVersion{created, deleted})
+
+		version, err := dataBase.GetTriggerVersion(trigger.ID, 2)
+		So(err, ShouldBeNil)
+		So(version, ShouldResemble, *deleted)
+
+		_, err = dataBase.GetTriggerVersion(trigger.ID, 3)
+		So(err, ShouldResemble, database.ErrNil)
+		_, err = dataBase.GetTriggerVersion(trigger.ID, 0)
+		So(err, ShouldResemble, database.ErrNil)
+	})
+
+	Convey("Trigger history is trimmed to limit keeping version numbers", t, func() {
+		dataBase.flush()
+		limitedDataBase := NewDatabase(logger, Config{Port: config.Port, Host: config.Host, TriggerHistoryLimit: 2})
+		for i := int64(1); i <= 3; i++ {
+			version := &moira.TriggerVersion{Action: moira.TriggerActionUpdate, Author: user1, Timestamp: 100 * i, Trigger: trigger}
+			So(limitedDataBase.AddTriggerVersion(trigger.ID, version), ShouldBeNil)
+			So(version.Version, ShouldEqual, i)
+		}
+
+		versions, err := limitedDataBase.GetTriggerVersions(trigger.ID)
+		So(err, ShouldBeNil)
+		So(versions, ShouldHaveLength, 2)
+		So(versions[0].Version, ShouldEqual, 2)
+		So(versions[1].Version, ShouldEqual, 3)
+
+		_, err = limitedDataBase.GetTriggerVersion(trigger.ID, 1)
+		So(err, ShouldResemble, database.ErrNil)
+		version, err := limitedDataBase.GetTriggerVersion(trigger.ID, 3)
+		So(err, ShouldBeNil)
+		So(version.Timestamp, ShouldEqual, 300)
+	})
+
+	Convey("Concurrently added versions get unique numbers", t, func() {
+		dataBase.flush()
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				dataBase.AddTriggerVersion(trigger.ID, &moira.TriggerVersion{Action: moira.TriggerActionUpdate, Author: user1, Trigger: trigger})
+			}()
+		}
+		wg.Wait()
+
+		versions, err := dataBase.GetTriggerVersions(trigger.ID)
+		So(err, ShouldBeNil)
+		So(versions, ShouldHaveLength, 5)
+		for i, version := range versions {
+			So(version.Version, ShouldEqual, i+1)
+		}
+	})
+
+	Convey("History saved without version numbers is numbered by position", t, func() {
+		dataBase.flush()
+		c := dataBase.pool.Get()
+		defer c.Close()
+		_, err := c.Do("RPUSH", triggerHistoryKey(trigger.ID), `{"version":0,"action":"create","timestamp":100}`, `{"version":0,"action":"update","timestamp":200}`)
+		So(err, ShouldBeNil)
+
+		updated := &moira.TriggerVersion{Action: moira.TriggerActionUpdate, Author: user1, Timestamp: 300, Trigger: trigger}
+		So(dataBase.AddTriggerVersion(trigger.ID, updated), ShouldBeNil)
+		So(updated.Version, ShouldEqual, 3)
+
+		versions, err := dataBase.GetTriggerVersions(trigger.ID)
+		So(err, ShouldBeNil)
+		So(versions, ShouldHaveLength, 3)
+		So(versions[0].Version, ShouldEqual, 1)
+		So(versions[1].Version, ShouldEqual, 2)
+	})
+}
@@ -0,0 +1,464 @@
+package redis
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/garyburd/redigo/redis"
+
+	"github.com/moira-alert/moira"
+)
+
+// triggerSearchText is trigger data indexed on trigger save
+type triggerSearchText struct {
+	Name     string   `json:"name"`
+	Desc     string   `json:"desc,omitempty"`
+	Targets  []string `json:"targets"`
+	Patterns []string `json:"patterns"`
+	Tags     []string `json:"tags"`
+	TeamID   string   `json:"team_id,omitempty"`
+}
+
+// triggerSearchState is trigger data indexed on trigger check, maintenance is the latest metric maintenance timestamp
+type triggerSearchState struct {
+	State          string `json:"state"`
+	Score          int64  `json:"score"`
+	EventTimestamp int64  `json:"event_timestamp,omitempty"`
+	Maintenance    int64  `json:"maintenance,omitempty"`
+}
+
+type triggerSearchDocument struct {
+	ID string
+	triggerSearchText
+	triggerSearchState
+}
+
+// SearchTriggerIDs gets triggerIDs matched by given query from trigger search index sorted by query sort field
+// Text, name, target, tag and owner filters select candidates by intersecting token sets,
+// only candidates documents are loaded to check states and substrings and to sort
+func (connector *DbConnector) SearchTriggerIDs(query moira.TriggerSearchQuery) ([]string, error) {
+	if err := connector.checkTriggerSearchIndexVersion(); err != nil {
+		return nil, err
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+
+	tokenKeys := make([]interface{}, 0)
+	for _, token := range getTriggerSearchQueryTokens(query) {
+		tokenKeys = append(tokenKeys, triggerSearchTokenKey(token))
+	}
+	ownerKeys := make([]interface{}, 0, len(query.Owners))
+	for _, owner := range query.Owners {
+		ownerKeys = append(ownerKeys, triggerSearchTokenKey(triggerSearchOwnerToken(owner)))
+	}
+	c.Send("MULTI")
+	if len(tokenKeys) > 0 {
+		c.Send("SINTER", tokenKeys...)
+	} else {
+		c.Send("HKEYS", triggerSearchTextKey)
+	}
+	if len(ownerKeys) > 0 {
+		c.Send("SUNION", ownerKeys...)
+	}
+	rawResponse, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	candidateIDs, err := redis.Strings(rawResponse[0], nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve trigger search candidates: %s", err.Error())
+	}
+	if len(ownerKeys) > 0 {
+		ownerTriggerIDs, err := redis.Strings(rawResponse[1], nil)
+		if err != nil {
+			return nil, fmt.Errorf("Failed to retrieve trigger search owners: %s", err.Error())
+		}
+		candidateIDs = intersectStrings(candidateIDs, ownerTriggerIDs)
+	}
+	if len(candidateIDs) == 0 {
+		return make([]string, 0), nil
+	}
+
+	documents, err := connector.getTriggerSearchDocuments(c, candidateIDs)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	matched := make([]*triggerSearchDocument, 0, len(documents))
+	for _, document := range documents {
+		if document.matches(query, now) {
+			matched = append(matched, document)
+		}
+	}
+	sortTriggerSearchDocuments(matched, query.Sort, query.SortDesc)
+
+	triggerIDs := make([]string, 0, len(matched))
+	for _, document := range matched {
+		triggerIDs = append(triggerIDs, document.ID)
+	}
+	return triggerIDs, nil
+}
+
+func (connector *DbConnector) getTriggerSearchDocuments(c redis.Conn, triggerIDs []string) ([]*triggerSearchDocument, error) {
+	textArgs := []interface{}{triggerSearchTextKey}
+	stateArgs := []interface{}{triggerSearchStateKey}
+	for _, triggerID := range triggerIDs {
+		textArgs = append(textArgs, triggerID)
+		stateArgs = append(stateArgs, triggerID)
+	}
+	c.Send("MULTI")
+	c.Send("HMGET", textArgs...)
+	c.Send("HMGET", stateArgs...)
+	rawResponse, err := redis.Values(c.Do("EXEC"))
+	if err != nil {
+		return nil, fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	texts, err := redis.Values(rawResponse[0], nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve trigger search texts: %s", err.Error())
+	}
+	states, err := redis.Values(rawResponse[1], nil)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to retrieve trigger search states: %s", err.Error())
+	}
+
+	documents := make([]*triggerSearchDocument, 0, len(triggerIDs))
+	for i, triggerID := range triggerIDs {
+		text, err := redis.Bytes(texts[i], nil)
+		if err == redis.ErrNil {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("Failed to retrieve trigger search text: %s", err.Error())
+		}
+		document := &triggerSearchDocument{ID: triggerID}
+		if err := json.Unmarshal(text, &document.triggerSearchText); err != nil {
+			return nil, fmt.Errorf("Failed to parse trigger search text json %s: %s", string(text), err.Error())
+		}
+		if states[i] != nil {
+			state, err := redis.Bytes(states[i], nil)
+			if err != nil {
+				return nil, fmt.Errorf("Failed to retrieve trigger search state: %s", err.Error())
+			}
+			if err := json.Unmarshal(state, &document.triggerSearchState); err != nil {
+				return nil, fmt.Errorf("Failed to parse trigger search state json %s: %s", string(state), err.Error())
+			}
+		}
+		documents = append(documents, document)
+	}
+	return documents, nil
+}
+
+// checkTriggerSearchIndexVersion rebuilds trigger search index if it was built by previous version or was never built
+func (connector *DbConnector) checkTriggerSearchIndexVersion() error {
+	c := connector.pool.Get()
+	version, err := redis.String(c.Do("GET", triggerSearchVersionKey))
+	c.Close()
+	if err != nil && err != redis.ErrNil {
+		return fmt.Errorf("Failed to get trigger search index version: %s", err.Error())
+	}
+	if version == triggerSearchIndexVersion {
+		return nil
+	}
+	connector.logger.Infof("Trigger search index version is '%s', rebuilding index version '%s'", version, triggerSearchIndexVersion)
+	return connector.RebuildTriggerSearchIndex()
+}
+
+// RebuildTriggerSearchIndex replaces trigger search index with data of all stored triggers and their last checks
+// Index is rebuilt automatically by first search if it was never built or was built by previous version
+func (connector *DbConnector) RebuildTriggerSearchIndex() error {
+	triggerIDs, err := connector.GetTriggerIDs()
+	if err != nil {
+		return err
+	}
+	triggerChecks, err := connector.GetTriggerChecks(triggerIDs)
+	if err != nil {
+		return err
+	}
+	texts := make(map[string][]byte)
+	states := make(map[string][]byte)
+	tokens := make(map[string][]interface{})
+	for _, triggerCheck := range triggerChecks {
+		if triggerCheck == nil {
+			continue
+		}
+		if texts[triggerCheck.ID], err = getTriggerSearchText(&triggerCheck.Trigger); err != nil {
+			return err
+		}
+		for _, token := range getTriggerSearchTokens(&triggerCheck.Trigger) {
+			tokens[token] = append(tokens[token], triggerCheck.ID)
+		}
+		if triggerCheck.LastCheck.State != "" {
+			if states[triggerCheck.ID], err = getTriggerSearchState(&triggerCheck.LastCheck); err != nil {
+				return err
+			}
+		}
+	}
+	c := connector.pool.Get()
+	defer c.Close()
+	indexedTokens, err := redis.Strings(c.Do("SMEMBERS", triggerSearchTokensKey))
+	if err != nil {
+		return fmt.Errorf("Failed to get trigger search tokens: %s", err.Error())
+	}
+	c.Send("MULTI")
+	c.Send("DEL", triggerSearchTextKey)
+	c.Send("DEL", triggerSearchStateKey)
+	c.Send("DEL", triggerSearchTokensKey)
+	for _, token := range indexedTokens {
+		c.Send("DEL", triggerSearchTokenKey(token))
+	}
+	for triggerID, text := range texts {
+		c.Send("HSET", triggerSearchTextKey, triggerID, text)
+	}
+	for triggerID, state := range states {
+		c.Send("HSET", triggerSearchStateKey, triggerID, state)
+	}
+	for token, tokenTriggerIDs := range tokens {
+		c.Send("SADD", append([]interface{}{triggerSearchTokenKey(token)}, tokenTriggerIDs...)...)
+		c.Send("SADD", triggerSearchTokensKey, token)
+	}
+	c.Send("SET", triggerSearchVersionKey, triggerSearchIndexVersion)
+	_, err = c.Do("EXEC")
+	if err != nil {
+		return fmt.Errorf("Failed to EXEC: %s", err.Error())
+	}
+	return nil
+}
+
+// sendTriggerSearchTokens updates trigger token sets, tokens of existing trigger version which are not used anymore are removed
+func sendTriggerSearchTokens(c redis.Conn, triggerID string, existing, trigger *moira.Trigger) {
+	tokens := make([]string, 0)
+	if trigger != nil {
+		tokens = getTriggerSearchTokens(trigger)
+	}
+	if existing != nil {
+		for _, token := range leftJoin(getTriggerSearchTokens(existing), tokens) {
+			c.Send("SREM", triggerSearchTokenKey(token), triggerID)
+		}
+	}
+	for _, token := range tokens {
+		c.Send("SADD", triggerSearchTokenKey(token), triggerID)
+		c.Send("SADD", triggerSearchTokensKey, token)
+	}
+}
+
+// getTriggerSearchTokens returns tokens of trigger fields, each token is prefixed by field it found in
+func getTriggerSearchTokens(trigger *moira.Trigger) []string {
+	tokens := make([]string, 0)
+	added := make(map[string]bool)
+	add := func(token string) {
+		if !added[token] {
+			added[token] = true
+			tokens = append(tokens, token)
+		}
+	}
+	for _, word := range splitTriggerSearchWords(trigger.Name) {
+		add("name:" + word)
+		add("text:" + word)
+	}
+	for _, word := range splitTriggerSearchWords(moira.UseString(trigger.Desc)) {
+		add("text:" + word)
+	}
+	for _, target := range append(append([]string{}, trigger.Targets...), trigger.Patterns...) {
+		for _, word := range splitTriggerSearchWords(target) {
+			add("target:" + word)
+		}
+	}
+	for _, tag := range trigger.Tags {
+		add(triggerSearchTagToken(tag))
+	}
+	if trigger.TeamID != "" {
+		add(triggerSearchOwnerToken(trigger.TeamID))
+	}
+	return tokens
+}
+
+// getTriggerSearchQueryTokens returns tokens every matched trigger must have, owners are matched separately as any of them is enough
+func getTriggerSearchQueryTokens(query moira.TriggerSearchQuery) []string {
+	tokens := make([]string, 0)
+	for _, text := range query.Text {
+		for _, word := range splitTriggerSearchWords(text) {
+			tokens = append(tokens, "text:"+word)
+		}
+	}
+	for _, name := range query.Names {
+		for _, word := range splitTriggerSearchWords(name) {
+			tokens = append(tokens, "name:"+word)
+		}
+	}
+	for _, target := range query.Targets {
+		for _, word := range splitTriggerSearchWords(target) {
+			tokens = append(tokens, "target:"+word)
+		}
+	}
+	for _, tag := range query.Tags {
+		tokens = append(tokens, triggerSearchTagToken(tag))
+	}
+	return tokens
+}
+
+// splitTriggerSearchWords splits value to lowercase words of letters and digits
+func splitTriggerSearchWords(value string) []string {
+	return strings.FieldsFunc(strings.ToLower(value), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+func triggerSearchTagToken(tag string) string {
+	return "tag:" + strings.ToLower(tag)
+}
+
+func triggerSearchOwnerToken(owner string) string {
+	return "owner:" + strings.ToLower(owner)
+}
+
+func intersectStrings(left, right []string) []string {
+	rightSet := make(map[string]bool, len(right))
+	for _, value := range right {
+		rightSet[value] = true
+	}
+	result := make([]string, 0)
+	for _, value := range left {
+		if rightSet[value] {
+			result = append(result, value)
+		}
+	}
+	return result
+}
+
+func getTriggerSearchText(trigger *moira.Trigger) ([]byte, error) {
+	return json.Marshal(triggerSearchText{
+		Name:     trigger.Name,
+		Desc:     moira.UseString(trigger.Desc),
+		Targets:  trigger.Targets,
+		Patterns: trigger.Patterns,
+		Tags:     trigger.Tags,
+		TeamID:   trigger.TeamID,
+	})
+}
+
+func getTriggerSearchState(checkData *moira.CheckData) ([]byte, error) {
+	state := triggerSearchState{
+		State:          checkData.State,
+		Score:          checkData.Score,
+		EventTimestamp: checkData.EventTimestamp,
+	}
+	for _, metricState := range checkData.Metrics {
+		if metricState.Maintenance > state.Maintenance {
+			state.Maintenance = metricState.Maintenance
+		}
+	}
+	return json.Marshal(state)
+}
+
+func (document *triggerSearchDocument) matches(query moira.TriggerSearchQuery, now int64) bool {
+	for _, text := range query.Text {
+		if !containsFold(document.Name, text) && !containsFold(document.Desc, text) {
+			return false
+		}
+	}
+	for _, name := range query.Names {
+		if !containsFold(document.Name, name) {
+			return false
+		}
+	}
+	for _, target := range query.Targets {
+		if !anyContainsFold(document.Targets, target) && !anyContainsFold(document.Patterns, target) {
+			return false
+		}
+	}
+	if len(query.States) > 0 && !anyEqualFold(query.States, document.State) {
+		return false
+	}
+	if len(query.Owners) > 0 && !anyEqualFold(query.Owners, document.TeamID) {
+		return false
+	}
+	for _, tag := range query.Tags {
+		if !anyEqualFold(document.Tags, tag) {
+			return false
+		}
+	}
+	if query.OnlyProblems && document.Score <= 0 {
+		return false
+	}
+	if query.Maintenance != nil && (document.Maintenance > now) != *query.Maintenance {
+		return false
+	}
+	return true
+}
+
+// sortTriggerSearchDocuments sorts documents by given field, documents with equal field values are sorted by name
+func sortTriggerSearchDocuments(documents []*triggerSearchDocument, field string, desc bool) {
+	if field == "" {
+		field = moira.TriggerSortScore
+		desc = true
+	}
+	sort.SliceStable(documents, func(i, j int) bool {
+		var compare int
+		switch field {
+		case moira.TriggerSortName:
+			compare = strings.Compare(strings.ToLower(documents[i].Name), strings.ToLower(documents[j].Name))
+		case moira.TriggerSortScore:
+			compare = compareInt64(documents[i].Score, documents[j].Score)
+		case moira.TriggerSortEventTime:
+			compare = compareInt64(documents[i].EventTimestamp, documents[j].EventTimestamp)
+		}
+		if desc {
+			compare = -compare
+		}
+		if compare == 0 {
+			compare = strings.Compare(strings.ToLower(documents[i].Name), strings.ToLower(documents[j].Name))
+		}
+		if compare == 0 {
+			compare = strings.Compare(documents[i].ID, documents[j].ID)
+		}
+		return compare < 0
+	})
+}
+
+func compareInt64(left, right int64) int {
+	switch {
+	case left < right:
+		return -1
+	case left > right:
+		return 1
+	}
+	return 0
+}
+
+func containsFold(value, substring string) bool {
+	return strings.Contains(strings.ToLower(value), strings.ToLower(substring))
+}
+
+func anyContainsFold(values []string, substring string) bool {
+	for _, value := range values {
+		if containsFold(value, substring) {
+			return true
+		}
+	}
+	return false
+}
+
+func anyEqualFold(values []string, expected string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, expected) {
+			return true
+		}
+	}
+	return false
+}
+
+var triggerSearchTextKey = "moira-trigger-search-text"
+var triggerSearchStateKey = "moira-trigger-search-state"
+var triggerSearchTokensKey = "moira-trigger-search-tokens"
+var triggerSearchVersionKey = "moira-trigger-search-version"
+
+const triggerSearchIndexVersion = "2"
+
+func triggerSearchTokenKey(token string) string {
+	return fmt.Sprintf("moira-trigger-search-token:%s", token)
+}
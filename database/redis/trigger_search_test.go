@@ -0,0 +1,170 @@
+package redis
+
+import (
+	"testing"
+	"time"
+
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestTriggerSearch(t *testing.T) {
+	logger, _ := logging.GetLogger("dataBase")
+	dataBase := NewDatabase(logger, config)
+	dataBase.flush()
+	defer dataBase.flush()
+
+	desc := "Disk usage of database servers"
+	cpu := moira.Trigger{ID: "cpu", Name: "CPU load", Targets: []string{"Servers.*.cpu"}, Patterns: []string{"Servers.*.cpu"}, Tags: []string{"prod"}, TeamID: "team1"}
+	disk := moira.Trigger{ID: "disk", Name: "Disk", Desc: &desc, Targets: []string{"Servers.db*.disk"}, Patterns: []string{"Servers.db*.disk"}, Tags: []string{"prod", "db"}}
+	memory := moira.Trigger{ID: "memory", Name: "Memory", Targets: []string{"Servers.*.memory"}, Patterns: []string{"Servers.*.memory"}, Tags: []string{"dev"}}
+	maintenance := time.Now().Unix() + 3600
+
+	Convey("Trigger search index manipulation", t, func() {
+		So(dataBase.SaveTrigger(cpu.ID, &cpu), ShouldBeNil)
+		So(dataBase.SaveTrigger(disk.ID, &disk), ShouldBeNil)
+		So(dataBase.SaveTrigger(memory.ID, &memory), ShouldBeNil)
+		So(dataBase.SetTriggerLastCheck(cpu.ID, &moira.CheckData{State: "ERROR", Score: 1000, EventTimestamp: 300}), ShouldBeNil)
+		So(dataBase.SetTriggerLastCheck(disk.ID, &moira.CheckData{State: "WARN", Score: 100, EventTimestamp: 200, Metrics: map[string]moira.MetricState{"Servers.db1.disk": {State: "WARN"}}}), ShouldBeNil)
+
+		Convey("Search by text, name and target", func() {
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"cpu", "disk", "memory"})
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Text: []string{"DATABASE"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"disk"})
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Names: []string{"database"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldBeEmpty)
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Targets: []string{"servers.*"}, Sort: moira.TriggerSortName, SortDesc: true})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"memory", "cpu"})
+		})
+
+		Convey("Search by state, owner and tags", func() {
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{States: []string{"WARN", "ERROR"}, Sort: moira.TriggerSortEventTime})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"disk", "cpu"})
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Owners: []string{"team1"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"cpu"})
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Tags: []string{"prod", "db"}, OnlyProblems: true})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"disk"})
+		})
+
+		Convey("Search by maintenance", func() {
+			hasMaintenance := true
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Maintenance: &hasMaintenance})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldBeEmpty)
+
+			So(dataBase.SetTriggerCheckMetricsMaintenance(disk.ID, map[string]int64{"Servers.db1.disk": maintenance}), ShouldBeNil)
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Maintenance: &hasMaintenance})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"disk"})
+		})
+
+		Convey("Removed trigger is not found", func() {
+			So(dataBase.RemoveTrigger(cpu.ID), ShouldBeNil)
+			So(dataBase.RemoveTriggerLastCheck(cpu.ID), ShouldBeNil)
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"disk", "memory"})
+		})
+
+		Convey("Search by words uses token index", func() {
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Text: []string{"usage servers"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldBeEmpty)
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Text: []string{"usage of database"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"disk"})
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Text: []string{"data"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldBeEmpty)
+
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Owners: []string{"TEAM1", "team2"}, Tags: []string{"PROD"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"cpu"})
+		})
+
+		Convey("Updated trigger is found by new tokens only", func() {
+			updated := memory
+			updated.Name = "Swap"
+			So(dataBase.SaveTrigger(updated.ID, &updated), ShouldBeNil)
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Names: []string{"memory"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldBeEmpty)
+			triggerIDs, err = dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Names: []string{"swap"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"memory"})
+			So(dataBase.SaveTrigger(memory.ID, &memory), ShouldBeNil)
+		})
+
+		Convey("Rebuilt index finds the same triggers", func() {
+			So(dataBase.RebuildTriggerSearchIndex(), ShouldBeNil)
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{States: []string{"ERROR"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"cpu"})
+		})
+
+		Convey("Index without version is rebuilt by search", func() {
+			c := dataBase.pool.Get()
+			c.Do("DEL", triggerSearchTextKey, triggerSearchStateKey, triggerSearchVersionKey, triggerSearchTokenKey("target:servers"))
+			c.Close()
+			triggerIDs, err := dataBase.SearchTriggerIDs(moira.TriggerSearchQuery{Targets: []string{"servers"}})
+			So(err, ShouldBeNil)
+			So(triggerIDs, ShouldResemble, []string{"cpu", "disk", "memory"})
+		})
+	})
+}
+
+func TestSortTriggerSearchDocuments(t *testing.T) {
+	newDocument := func(id, name string, score, eventTimestamp int64) *triggerSearchDocument {
+		return &triggerSearchDocument{
+			ID:                 id,
+			triggerSearchText:  triggerSearchText{Name: name},
+			triggerSearchState: triggerSearchState{Score: score, EventTimestamp: eventTimestamp},
+		}
+	}
+	getIDs := func(documents []*triggerSearchDocument) []string {
+		ids := make([]string, 0, len(documents))
+		for _, document := range documents {
+			ids = append(ids, document.ID)
+		}
+		return ids
+	}
+	documents := []*triggerSearchDocument{
+		newDocument("1", "b", 0, 300),
+		newDocument("2", "A", 100, 100),
+		newDocument("3", "c", 100, 200),
+	}
+
+	Convey("Default order is score descending then name", t, func() {
+		sortTriggerSearchDocuments(documents, "", false)
+		So(getIDs(documents), ShouldResemble, []string{"2", "3", "1"})
+	})
+
+	Convey("Sort by name ignores case", t, func() {
+		sortTriggerSearchDocuments(documents, moira.TriggerSortName, false)
+		So(getIDs(documents), ShouldResemble, []string{"2", "1", "3"})
+		sortTriggerSearchDocuments(documents, moira.TriggerSortName, true)
+		So(getIDs(documents), ShouldResemble, []string{"3", "1", "2"})
+	})
+
+	Convey("Sort by last event time", t, func() {
+		sortTriggerSearchDocuments(documents, moira.TriggerSortEventTime, true)
+		So(getIDs(documents), ShouldResemble, []string{"1", "3", "2"})
+	})
+}
@@ -40,22 +40,29 @@ type TriggerData struct {
 }
 
 // ContactData represents contact object
+// Fallback is the ID of contact used instead of this one while its sender is degraded
 type ContactData struct {
-	Type  string `json:"type"`
-	Value string `json:"value"`
-	ID    string `json:"id"`
-	User  string `json:"user"`
+	Type     string `json:"type"`
+	Value    string `json:"value"`
+	ID       string `json:"id"`
+	User     string `json:"user"`
+	TeamID   string `json:"team_id,omitempty"`
+	Fallback string `json:"fallback,omitempty"`
 }
 
 // SubscriptionData represent user subscription
+// ThrottlingWindow is the minimal interval in minutes between two notifications about the same trigger
 type SubscriptionData struct {
-	Contacts          []string     `json:"contacts"`
-	Tags              []string     `json:"tags"`
-	Schedule          ScheduleData `json:"sched"`
-	ID                string       `json:"id"`
-	Enabled           bool         `json:"enabled"`
-	ThrottlingEnabled bool         `json:"throttling"`
-	User              string       `json:"user"`
+	Contacts          []string      `json:"contacts"`
+	Tags              []string      `json:"tags"`
+	Schedule          ScheduleData  `json:"sched"`
+	ID                string        `json:"id"`
+	Enabled           bool          `json:"enabled"`
+	ThrottlingEnabled bool          `json:"throttling"`
+	ThrottlingWindow  int64         `json:"throttling_window,omitempty"`
+	Holidays          *HolidaysData `json:"holidays,omitempty"`
+	User              string        `json:"user"`
+	TeamID            string        `json:"team_id,omitempty"`
 }
 
 // ScheduleData represent subscription schedule
@@ -82,6 +89,23 @@ type ScheduledNotification struct {
 	Timestamp int64             `json:"timestamp"`
 }
 
+// Delivery results
+const (
+	DeliveryOK     = "OK"
+	DeliveryFailed = "FAILED"
+)
+
+// DeliveryData represent single attempt to send notifications package to contact
+type DeliveryData struct {
+	Timestamp   int64       `json:"timestamp"`
+	Contact     ContactData `json:"contact"`
+	TriggerID   string      `json:"trigger_id"`
+	EventsCount int         `json:"events_count"`
+	Result      string      `json:"result"`
+	Error       string      `json:"error,omitempty"`
+	Attempt     int         `json:"attempt"`
+}
+
 // MatchedMetric represent parsed and matched metric data
 type MatchedMetric struct {
 	Metric             string
@@ -90,30 +114,83 @@ type MatchedMetric struct {
 	Timestamp          int64
 	RetentionTimestamp int64
 	Retention          int
+	Schema             *MetricSchema
 }
 
 // MetricValue represent metric data
+// Step is precision of storage schema archive for downsampled values and zero for values with metric retention
 type MetricValue struct {
 	RetentionTimestamp int64   `json:"step,omitempty"`
 	Timestamp          int64   `json:"ts"`
 	Value              float64 `json:"value"`
+	Step               int64   `json:"-"`
 }
 
 // Trigger represents trigger data object
 type Trigger struct {
-	ID               string        `json:"id"`
-	Name             string        `json:"name"`
-	Desc             *string       `json:"desc,omitempty"`
-	Targets          []string      `json:"targets"`
-	WarnValue        *float64      `json:"warn_value"`
-	ErrorValue       *float64      `json:"error_value"`
-	Tags             []string      `json:"tags"`
-	TTLState         *string       `json:"ttl_state,omitempty"`
-	TTL              int64         `json:"ttl,omitempty"`
-	Schedule         *ScheduleData `json:"sched,omitempty"`
-	Expression       *string       `json:"expression,omitempty"`
-	PythonExpression *string       `json:"python_expression,omitempty"`
-	Patterns         []string      `json:"patterns"`
+	ID               string             `json:"id"`
+	Name             string             `json:"name"`
+	Desc             *string            `json:"desc,omitempty"`
+	Targets          []string           `json:"targets"`
+	WarnValue        *float64           `json:"warn_value"`
+	ErrorValue       *float64           `json:"error_value"`
+	Tags             []string           `json:"tags"`
+	TTLState         *string            `json:"ttl_state,omitempty"`
+	TTL              int64              `json:"ttl,omitempty"`
+	Schedule         *ScheduleData      `json:"sched,omitempty"`
+	Expression       *string            `json:"expression,omitempty"`
+	PythonExpression *string            `json:"python_expression,omitempty"`
+	Patterns         []string           `json:"patterns"`
+	TeamID           string             `json:"team_id,omitempty"`
+	Managed          *TriggerManagement `json:"managed,omitempty"`
+}
+
+// TriggerManagement marks trigger saved by sync from definition file
+// Checksum is calculated from synced definition, so trigger with other checksum was edited manually
+type TriggerManagement struct {
+	Source   string `json:"source"`
+	Checksum string `json:"checksum"`
+}
+
+// Trigger history actions
+const (
+	TriggerActionCreate  = "create"
+	TriggerActionUpdate  = "update"
+	TriggerActionDelete  = "delete"
+	TriggerActionRestore = "restore"
+)
+
+// TriggerVersion represents trigger snapshot stored in trigger change history
+// Snapshot of deleted trigger holds its last state before deletion
+type TriggerVersion struct {
+	Version   int64   `json:"version"`
+	Action    string  `json:"action"`
+	Author    string  `json:"author"`
+	Timestamp int64   `json:"timestamp"`
+	Comment   string  `json:"comment,omitempty"`
+	Trigger   Trigger `json:"trigger"`
+}
+
+// Trigger search sort fields
+const (
+	TriggerSortName      = "name"
+	TriggerSortScore     = "score"
+	TriggerSortEventTime = "event"
+)
+
+// TriggerSearchQuery represents trigger search filters, trigger is found if it matches all given filters
+// Text values are matched as case insensitive substrings
+type TriggerSearchQuery struct {
+	Text         []string // every value is contained in trigger name or description
+	Names        []string // every value is contained in trigger name
+	Targets      []string // every value is contained in one of trigger targets or patterns
+	States       []string // trigger last check state is one of values
+	Owners       []string // trigger team is one of values
+	Tags         []string // trigger has all of tags
+	OnlyProblems bool     // trigger last check score is greater than zero
+	Maintenance  *bool    // trigger has or has not metrics in maintenance
+	Sort         string   // one of TriggerSort fields, triggers are sorted by score descending if empty
+	SortDesc     bool
 }
 
 // TriggerCheck represent trigger data with last check data and check timestamp
@@ -150,6 +227,84 @@ type MetricEvent struct {
 	Pattern string `json:"pattern"`
 }
 
+// Pattern event types
+const (
+	PatternAdded   = "added"
+	PatternRemoved = "removed"
+)
+
+// PatternEvent represent change of patterns list, it is used by filter to update pattern tree incrementally
+type PatternEvent struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern"`
+}
+
+// Stream event types
+const (
+	StreamEventTriggerState = "trigger_state"
+	StreamEventNotification = "notification_event"
+)
+
+// StreamEvent represent trigger check result or new notification event, it is published for real-time api clients
+// State of trigger check result contains only trigger state, score and timestamp without metrics states
+type StreamEvent struct {
+	Type      string             `json:"type"`
+	TriggerID string             `json:"trigger_id"`
+	State     *CheckData         `json:"state,omitempty"`
+	Event     *NotificationEvent `json:"event,omitempty"`
+}
+
+// FilterShard represents moira-filter instance registered to receive part of metrics from relay
+type FilterShard struct {
+	ID      string `json:"id"`
+	Address string `json:"address"`
+}
+
+// APIToken represents personal API token of user, only hash of token secret is stored
+type APIToken struct {
+	ID        string `json:"id"`
+	Login     string `json:"login"`
+	Name      string `json:"name"`
+	Hash      string `json:"hash"`
+	CreatedAt int64  `json:"created_at"`
+}
+
+// Team member roles, every next role includes permissions of previous ones
+const (
+	TeamRoleViewer = "viewer"
+	TeamRoleEditor = "editor"
+	TeamRoleAdmin  = "admin"
+)
+
+var teamRoleLevels = map[string]int{
+	TeamRoleViewer: 1,
+	TeamRoleEditor: 2,
+	TeamRoleAdmin:  3,
+}
+
+// Team represents group of users which owns triggers and shares contacts and subscriptions
+// Members maps user login to its role in team
+type Team struct {
+	ID          string            `json:"id"`
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Members     map[string]string `json:"members"`
+}
+
+// IsValidTeamRole checks that role is one of known team roles
+func IsValidTeamRole(role string) bool {
+	_, ok := teamRoleLevels[role]
+	return ok
+}
+
+// HasRole checks that user is team member with given or higher role
+func (team *Team) HasRole(login string, role string) bool {
+	if login == "" {
+		return false
+	}
+	return teamRoleLevels[team.Members[login]] >= teamRoleLevels[role]
+}
+
 // GetSubjectState returns the most critical state of events
 func (events NotificationEvents) GetSubjectState() string {
 	result := ""
@@ -262,7 +417,7 @@ func (trigger *Trigger) IsSimple() bool {
 		return false
 	}
 	for _, pattern := range trigger.Patterns {
-		if strings.ContainsAny(pattern, "*{?[") {
+		if strings.ContainsAny(pattern, "*{?[") || IsSeriesByTag(pattern) {
 			return false
 		}
 	}
@@ -253,3 +253,23 @@ func getDefaultSchedule() ScheduleData {
 		},
 	}
 }
+
+func TestTeam_HasRole(t *testing.T) {
+	team := Team{Members: map[string]string{
+		"viewer": TeamRoleViewer,
+		"editor": TeamRoleEditor,
+		"admin":  TeamRoleAdmin,
+	}}
+	Convey("Role includes permissions of lower roles", t, func() {
+		So(team.HasRole("viewer", TeamRoleViewer), ShouldBeTrue)
+		So(team.HasRole("viewer", TeamRoleEditor), ShouldBeFalse)
+		So(team.HasRole("editor", TeamRoleViewer), ShouldBeTrue)
+		So(team.HasRole("editor", TeamRoleEditor), ShouldBeTrue)
+		So(team.HasRole("editor", TeamRoleAdmin), ShouldBeFalse)
+		So(team.HasRole("admin", TeamRoleAdmin), ShouldBeTrue)
+	})
+	Convey("Not members and anonymous users have no roles", t, func() {
+		So(team.HasRole("other", TeamRoleViewer), ShouldBeFalse)
+		So(team.HasRole("", TeamRoleViewer), ShouldBeFalse)
+	})
+}
@@ -1,7 +1,7 @@
 package filter
 
 import (
-	"bufio"
+	"fmt"
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/metrics/graphite"
 	"io"
@@ -12,13 +12,30 @@ import (
 
 var defaultRetention = 60
 
+var retentionUnits = map[string]int{
+	"s": 1, "sec": 1, "second": 1, "seconds": 1,
+	"m": 60, "min": 60, "minute": 60, "minutes": 60,
+	"h": 60 * 60, "hour": 60 * 60, "hours": 60 * 60,
+	"d": 60 * 60 * 24, "day": 60 * 60 * 24, "days": 60 * 60 * 24,
+	"w": 60 * 60 * 24 * 7, "week": 60 * 60 * 24 * 7, "weeks": 60 * 60 * 24 * 7,
+	"y": 60 * 60 * 24 * 365, "year": 60 * 60 * 24 * 365, "years": 60 * 60 * 24 * 365,
+}
+
 type retentionMatcher struct {
 	pattern   *regexp.Regexp
 	retention int
+	archives  []moira.RetentionArchive
+}
+
+type aggregationMatcher struct {
+	pattern      *regexp.Regexp
+	method       string
+	xFilesFactor float64
 }
 
 type retentionCacheItem struct {
 	value     int
+	schema    *moira.MetricSchema
 	timestamp int64
 }
 
@@ -26,6 +43,7 @@ type retentionCacheItem struct {
 type Storage struct {
 	metrics         *graphite.FilterMetrics
 	retentions      []retentionMatcher
+	aggregations    []aggregationMatcher
 	retentionsCache map[string]*retentionCacheItem
 	metricsCache    map[string]*moira.MatchedMetric
 }
@@ -38,15 +56,27 @@ func NewCacheStorage(metrics *graphite.FilterMetrics, reader io.Reader) (*Storag
 		metrics:         metrics,
 	}
 
-	if err := storage.buildRetentions(bufio.NewScanner(reader)); err != nil {
+	if err := storage.buildRetentions(reader); err != nil {
 		return nil, err
 	}
 	return storage, nil
 }
 
+// LoadAggregations reads storage-aggregation.conf, metrics without matched section are aggregated by average
+func (storage *Storage) LoadAggregations(reader io.Reader) error {
+	aggregations, err := parseStorageAggregations(reader)
+	if err != nil {
+		return err
+	}
+	storage.aggregations = aggregations
+	storage.retentionsCache = make(map[string]*retentionCacheItem)
+	return nil
+}
+
 // EnrichMatchedMetric calculate retention and filter cached values
+// Metrics with several archives in storage schema get schema to downsample old values
 func (storage *Storage) EnrichMatchedMetric(buffer map[string]*moira.MatchedMetric, m *moira.MatchedMetric) {
-	m.Retention = storage.getRetention(m)
+	m.Retention, m.Schema = storage.getRetention(m)
 	m.RetentionTimestamp = roundToNearestRetention(m.Timestamp, int64(m.Retention))
 	if ex, ok := storage.metricsCache[m.Metric]; ok && ex.RetentionTimestamp == m.RetentionTimestamp && ex.Value == m.Value {
 		return
@@ -55,78 +85,70 @@ func (storage *Storage) EnrichMatchedMetric(buffer map[string]*moira.MatchedMetr
 	buffer[m.Metric] = m
 }
 
-// getRetention returns first matched retention for metric
-func (storage *Storage) getRetention(m *moira.MatchedMetric) int {
+// getRetention returns first matched retention and storage schema for metric
+func (storage *Storage) getRetention(m *moira.MatchedMetric) (int, *moira.MetricSchema) {
 	if item, ok := storage.retentionsCache[m.Metric]; ok && item.timestamp+60 > m.Timestamp {
-		return item.value
+		return item.value, item.schema
 	}
 	for _, matcher := range storage.retentions {
 		if matcher.pattern.MatchString(m.Metric) {
-			storage.retentionsCache[m.Metric] = &retentionCacheItem{
+			item := &retentionCacheItem{
 				value:     matcher.retention,
+				schema:    storage.getSchema(m.Metric, matcher.archives),
 				timestamp: m.Timestamp,
 			}
-			return matcher.retention
+			storage.retentionsCache[m.Metric] = item
+			return item.value, item.schema
 		}
 	}
-	return defaultRetention
+	return defaultRetention, nil
 }
 
-func (storage *Storage) buildRetentions(retentionScanner *bufio.Scanner) error {
-	storage.retentions = make([]retentionMatcher, 0, 100)
-
-	for retentionScanner.Scan() {
-		line := retentionScanner.Text()
-		if strings.HasPrefix(line, "#") || strings.Count(line, "=") != 1 {
-			continue
-		}
-
-		pattern, err := regexp.Compile(strings.TrimSpace(strings.Split(line, "=")[1]))
-		if err != nil {
-			return err
-		}
-
-		retentionScanner.Scan()
-		line = retentionScanner.Text()
-		retentions := strings.TrimSpace(strings.Split(line, "=")[1])
-		retention, err := rawRetentionToSeconds(retentions[0:strings.Index(retentions, ":")])
-		if err != nil {
-			return err
+// getSchema returns storage schema with first matched aggregation, schema is not needed for metrics with single archive
+func (storage *Storage) getSchema(metric string, archives []moira.RetentionArchive) *moira.MetricSchema {
+	if len(archives) < 2 {
+		return nil
+	}
+	schema := &moira.MetricSchema{
+		Archives:          archives,
+		AggregationMethod: moira.AggregationAverage,
+		XFilesFactor:      moira.DefaultXFilesFactor,
+	}
+	for _, matcher := range storage.aggregations {
+		if matcher.pattern.MatchString(metric) {
+			schema.AggregationMethod = matcher.method
+			schema.XFilesFactor = matcher.xFilesFactor
+			break
 		}
+	}
+	return schema
+}
 
-		storage.retentions = append(storage.retentions, retentionMatcher{
-			pattern:   pattern,
-			retention: retention,
-		})
+func (storage *Storage) buildRetentions(reader io.Reader) error {
+	retentions, err := parseStorageSchemas(reader)
+	if err != nil {
+		return err
 	}
-	return retentionScanner.Err()
+	storage.retentions = retentions
+	return nil
 }
 
+// rawRetentionToSeconds converts carbon time like 60, 60s, 10min, 2d to seconds
 func rawRetentionToSeconds(rawRetention string) (int, error) {
-	retention, err := strconv.Atoi(rawRetention)
-	if err == nil {
-		return retention, nil
+	index := strings.IndexFunc(rawRetention, func(r rune) bool {
+		return r < '0' || r > '9'
+	})
+	if index < 0 {
+		return strconv.Atoi(rawRetention)
 	}
-
-	multiplier := 1
-	switch {
-	case strings.HasSuffix(rawRetention, "m"):
-		multiplier = 60
-	case strings.HasSuffix(rawRetention, "h"):
-		multiplier = 60 * 60
-	case strings.HasSuffix(rawRetention, "d"):
-		multiplier = 60 * 60 * 24
-	case strings.HasSuffix(rawRetention, "w"):
-		multiplier = 60 * 60 * 24 * 7
-	case strings.HasSuffix(rawRetention, "y"):
-		multiplier = 60 * 60 * 24 * 365
+	multiplier, ok := retentionUnits[rawRetention[index:]]
+	if !ok {
+		return 0, fmt.Errorf("Unknown time unit in '%s'", rawRetention)
 	}
-
-	retention, err = strconv.Atoi(rawRetention[0 : len(rawRetention)-1])
+	retention, err := strconv.Atoi(rawRetention[:index])
 	if err != nil {
 		return 0, err
 	}
-
 	return retention * multiplier, nil
 }
 
@@ -230,3 +230,36 @@ func TestRetentions(t *testing.T) {
 		So(metr.RetentionTimestamp, should.Equal, 120)
 	})
 }
+
+func TestMetricSchema(t *testing.T) {
+	metrics2 := metrics.ConfigureFilterMetrics("test")
+	storage, _ := NewCacheStorage(metrics2, strings.NewReader(testRetentions))
+
+	Convey("Metric with single archive, should not have schema", t, func() {
+		buffer := make(map[string]*moira.MatchedMetric)
+		metr := matchedMetrics[14]
+		storage.EnrichMatchedMetric(buffer, &metr)
+		So(metr.Schema, ShouldBeNil)
+	})
+
+	Convey("Metric with several archives, should have schema with default aggregation", t, func() {
+		buffer := make(map[string]*moira.MatchedMetric)
+		metr := matchedMetrics[0]
+		storage.EnrichMatchedMetric(buffer, &metr)
+		So(metr.Schema, ShouldResemble, &moira.MetricSchema{
+			Archives:          []moira.RetentionArchive{{Precision: 60, Retention: 172800}, {Precision: 600, Retention: 2592000}, {Precision: 6000, Retention: 7776000}},
+			AggregationMethod: moira.AggregationAverage,
+			XFilesFactor:      moira.DefaultXFilesFactor,
+		})
+	})
+
+	Convey("Load aggregations, should have schema with matched aggregation", t, func() {
+		err := storage.LoadAggregations(strings.NewReader("[sum]\npattern = ^Simple\\.\naggregationMethod = sum\nxFilesFactor = 0"))
+		So(err, ShouldBeNil)
+		buffer := make(map[string]*moira.MatchedMetric)
+		metr := matchedMetrics[0]
+		storage.EnrichMatchedMetric(buffer, &metr)
+		So(metr.Schema.AggregationMethod, ShouldEqual, moira.AggregationSum)
+		So(metr.Schema.XFilesFactor, ShouldEqual, 0)
+	})
+}
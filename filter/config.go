@@ -1,8 +1,39 @@
 package filter
 
+import "time"
+
+// Overflow policies of queue between listener and metrics matcher
+const (
+	QueueOverflowBlock = "block"
+	QueueOverflowDrop  = "drop"
+)
+
+// Filter modes, filter matches and saves metrics, relay routes metrics to filter shards
+const (
+	ModeFilter = "filter"
+	ModeRelay  = "relay"
+)
+
 // Config is filter configuration settings
 type Config struct {
-	Enabled         bool
-	Listen          string
-	RetentionConfig string
+	Enabled                 bool
+	Mode                    string
+	Listen                  string
+	ListenPickle            string
+	ListenUDP               string
+	ListenPrometheus        string
+	PrometheusPathLabels    []string
+	RetentionConfig         string
+	AggregationConfig       string
+	RewriteConfig           string
+	PatternsRefreshInterval time.Duration
+	QueueSize               int
+	QueueOverflowPolicy     string
+	BatchSize               int
+	FlushInterval           time.Duration
+	SaveRetries             int
+	SaveRetryInterval       time.Duration
+	ShardID                 string
+	ShardAddress            string
+	RelayRefreshInterval    time.Duration
 }
@@ -2,30 +2,51 @@ package connection
 
 import (
 	"bufio"
+	"bytes"
+	"encoding/binary"
 	"io"
+	"io/ioutil"
 	"net"
+	"net/http"
 
 	"gopkg.in/tomb.v2"
 
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/filter"
+	"github.com/moira-alert/moira/metrics/graphite"
 )
 
 // Handler handling connection data and shift it to MatchedMetrics channel
 type Handler struct {
 	logger          moira.Logger
+	metrics         *graphite.FilterMetrics
 	patternsStorage *filter.PatternStorage
+	dropOnOverflow  bool
 	tomb            tomb.Tomb
 }
 
 // NewConnectionHandler creates new Handler
-func NewConnectionHandler(logger moira.Logger, patternsStorage *filter.PatternStorage) *Handler {
+func NewConnectionHandler(logger moira.Logger, metrics *graphite.FilterMetrics, patternsStorage *filter.PatternStorage) *Handler {
 	return &Handler{
 		logger:          logger,
+		metrics:         metrics,
 		patternsStorage: patternsStorage,
 	}
 }
 
+// send puts matched metric to MatchedMetric channel, if channel is full metric is dropped or handler waits for free space
+func (handler *Handler) send(matchedMetricsChan chan *moira.MatchedMetric, metric *moira.MatchedMetric) {
+	if !handler.dropOnOverflow {
+		matchedMetricsChan <- metric
+		return
+	}
+	select {
+	case matchedMetricsChan <- metric:
+	default:
+		handler.metrics.DroppedMetrics.Mark(1)
+	}
+}
+
 // HandleConnection convert every line from connection to metric and send it to MatchedMetric channel
 func (handler *Handler) HandleConnection(connection net.Conn, matchedMetricsChan chan *moira.MatchedMetric) error {
 	buffer := bufio.NewReader(connection)
@@ -45,8 +66,116 @@ func (handler *Handler) HandleConnection(connection net.Conn, matchedMetricsChan
 			}
 			lineBytes = lineBytes[:len(lineBytes)-1]
 			if m := handler.patternsStorage.ProcessIncomingMetric(lineBytes); m != nil {
-				matchedMetricsChan <- m
+				handler.send(matchedMetricsChan, m)
+			}
+		}
+	}
+}
+
+// HandlePickleConnection reads graphite pickle protocol batches from connection and sends matched metrics to MatchedMetric channel
+// Every batch is prefixed by 4-byte big-endian length of pickled list of (path, (timestamp, value)) tuples
+func (handler *Handler) HandlePickleConnection(connection net.Conn, matchedMetricsChan chan *moira.MatchedMetric) error {
+	buffer := bufio.NewReader(connection)
+	defer connection.Close()
+	header := make([]byte, 4)
+	for {
+		select {
+		case <-handler.tomb.Dying():
+			connection.Close()
+			return nil
+		default:
+			if _, err := io.ReadFull(buffer, header); err != nil {
+				if err != io.EOF {
+					handler.logger.Errorf("read failed: %s", err)
+				}
+				return nil
+			}
+			size := binary.BigEndian.Uint32(header)
+			if size > maxPickleSize {
+				handler.logger.Errorf("pickle batch of %d bytes from %s exceeds limit, closing connection", size, connection.RemoteAddr())
+				return nil
+			}
+			data := make([]byte, size)
+			if _, err := io.ReadFull(buffer, data); err != nil {
+				handler.logger.Errorf("read failed: %s", err)
+				return nil
+			}
+			lines, invalid, err := parsePickleMetrics(data)
+			if err != nil {
+				handler.logger.Errorf("cannot parse pickle batch from %s: %s", connection.RemoteAddr(), err)
+				continue
+			}
+			for _, err := range invalid {
+				handler.metrics.TotalMetricsReceived.Mark(1)
+				handler.logger.Debugf("cannot parse input: %v", err)
+			}
+			for _, lineBytes := range lines {
+				if m := handler.patternsStorage.ProcessIncomingMetric(lineBytes); m != nil {
+					handler.send(matchedMetricsChan, m)
+				}
+			}
+		}
+	}
+}
+
+// HandlePacketConnection reads datagrams with plaintext metric lines and sends matched metrics to MatchedMetric channel until connection is closed
+func (handler *Handler) HandlePacketConnection(connection net.PacketConn, matchedMetricsChan chan *moira.MatchedMetric) error {
+	packet := make([]byte, 65535)
+	for {
+		size, _, err := connection.ReadFrom(packet)
+		if err != nil {
+			if isDying(&handler.tomb) {
+				return nil
+			}
+			handler.logger.Errorf("read failed: %s", err)
+			continue
+		}
+		for _, lineBytes := range bytes.Split(packet[:size], []byte{'\n'}) {
+			if len(lineBytes) == 0 {
+				continue
+			}
+			if m := handler.patternsStorage.ProcessIncomingMetric(lineBytes); m != nil {
+				handler.send(matchedMetricsChan, m)
+			}
+		}
+	}
+}
+
+// HandleRemoteWrite returns http handler for prometheus remote_write requests, it converts every sample to metric
+// with path built from pathLabels values and sends matched metrics to MatchedMetric channel
+func (handler *Handler) HandleRemoteWrite(pathLabels []string, matchedMetricsChan chan *moira.MatchedMetric) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != http.MethodPost {
+			http.Error(writer, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		compressed, err := ioutil.ReadAll(io.LimitReader(request.Body, maxRemoteWriteSize+1))
+		if err != nil {
+			handler.logger.Errorf("read failed: %s", err)
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if len(compressed) > maxRemoteWriteSize {
+			http.Error(writer, "Request is too large", http.StatusRequestEntityTooLarge)
+			return
+		}
+		data, err := decodeSnappy(compressed)
+		if err != nil {
+			handler.logger.Errorf("cannot decode remote_write request from %s: %s", request.RemoteAddr, err)
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		lines, err := parseRemoteWrite(data, pathLabels)
+		if err != nil {
+			handler.logger.Errorf("cannot parse remote_write request from %s: %s", request.RemoteAddr, err)
+			http.Error(writer, err.Error(), http.StatusBadRequest)
+			return
+		}
+		for _, lineBytes := range lines {
+			if m := handler.patternsStorage.ProcessIncomingMetric(lineBytes); m != nil {
+				handler.send(matchedMetricsChan, m)
 			}
 		}
+		writer.WriteHeader(http.StatusNoContent)
 	}
 }
@@ -1,73 +1,193 @@
 package connection
 
 import (
+	"context"
 	"fmt"
 	"net"
+	"net/http"
+	"sync"
 
 	"gopkg.in/tomb.v2"
 
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/filter"
+	"github.com/moira-alert/moira/metrics/graphite"
 )
 
 // MetricsListener is facade for standard net.MetricsListener and accept connection for handling it
 type MetricsListener struct {
-	listener net.Listener
-	handler  *Handler
-	logger   moira.Logger
-	tomb     tomb.Tomb
+	listener       net.Listener
+	pickleListener net.Listener
+	udpConnection  net.PacketConn
+	remoteWrite    *remoteWriteServer
+	handler        *Handler
+	logger         moira.Logger
+	tomb           tomb.Tomb
+	handlersWG     sync.WaitGroup
+	metricsChan    chan *moira.MatchedMetric
+	queueSize      int
 }
 
 // NewListener creates new listener
-func NewListener(port string, logger moira.Logger, patternStorage *filter.PatternStorage) (*MetricsListener, error) {
+func NewListener(port string, logger moira.Logger, metrics *graphite.FilterMetrics, patternStorage *filter.PatternStorage) (*MetricsListener, error) {
 	listen := port
 	newListener, err := net.Listen("tcp", listen)
 	if err != nil {
 		return nil, fmt.Errorf("Failed to listen on [%s]: %s", listen, err.Error())
 	}
 	listener := MetricsListener{
-		listener: newListener,
-		logger:   logger,
-		handler:  NewConnectionHandler(logger, patternStorage),
+		listener:  newListener,
+		logger:    logger,
+		handler:   NewConnectionHandler(logger, metrics, patternStorage),
+		queueSize: 10,
 	}
 	return &listener, nil
 }
 
+// SetQueue sets size of matched metrics queue and policy of its overflow, it must be called before Listen
+// With block policy handlers wait for free space in queue, with drop policy metrics which don't fit in queue are dropped
+func (listener *MetricsListener) SetQueue(size int, overflowPolicy string) error {
+	if size < 1 {
+		return fmt.Errorf("Invalid queue size %d", size)
+	}
+	switch overflowPolicy {
+	case filter.QueueOverflowBlock:
+		listener.handler.dropOnOverflow = false
+	case filter.QueueOverflowDrop:
+		listener.handler.dropOnOverflow = true
+	default:
+		return fmt.Errorf("Unknown queue overflow policy '%s'", overflowPolicy)
+	}
+	listener.queueSize = size
+	return nil
+}
+
+// ListenPickle opens tcp port for graphite pickle protocol, it must be called before Listen
+func (listener *MetricsListener) ListenPickle(listen string) error {
+	pickleListener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("Failed to listen pickle on [%s]: %s", listen, err.Error())
+	}
+	listener.pickleListener = pickleListener
+	return nil
+}
+
+// ListenUDP opens udp port for plaintext protocol, it must be called before Listen
+func (listener *MetricsListener) ListenUDP(listen string) error {
+	udpConnection, err := net.ListenPacket("udp", listen)
+	if err != nil {
+		return fmt.Errorf("Failed to listen udp on [%s]: %s", listen, err.Error())
+	}
+	listener.udpConnection = udpConnection
+	return nil
+}
+
+type remoteWriteServer struct {
+	listener   net.Listener
+	server     *http.Server
+	pathLabels []string
+}
+
+// ListenPrometheus opens http port for prometheus remote_write requests, it must be called before Listen
+// Samples are converted to metrics with path built from values of pathLabels
+func (listener *MetricsListener) ListenPrometheus(listen string, pathLabels []string) error {
+	if len(pathLabels) == 0 {
+		return fmt.Errorf("Prometheus path labels are not set")
+	}
+	remoteWriteListener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return fmt.Errorf("Failed to listen prometheus on [%s]: %s", listen, err.Error())
+	}
+	listener.remoteWrite = &remoteWriteServer{
+		listener:   remoteWriteListener,
+		pathLabels: pathLabels,
+	}
+	return nil
+}
+
 // Listen waits for new data in connection and handles it in ConnectionHandler
 // All handled data sets to metricsChan
 func (listener *MetricsListener) Listen() chan *moira.MatchedMetric {
-	metricsChan := make(chan *moira.MatchedMetric, 10)
+	listener.metricsChan = make(chan *moira.MatchedMetric, listener.queueSize)
 	listener.tomb.Go(func() error {
-		for {
-			select {
-			case <-listener.tomb.Dying():
-				{
-					listener.logger.Info("Moira Filter Listener Stopped")
-					listener.handler.tomb.Kill(nil)
-					listener.handler.tomb.Wait()
-					close(metricsChan)
+		return listener.accept(listener.listener, listener.handler.HandleConnection)
+	})
+	if listener.pickleListener != nil {
+		listener.tomb.Go(func() error {
+			return listener.accept(listener.pickleListener, listener.handler.HandlePickleConnection)
+		})
+	}
+	if listener.udpConnection != nil {
+		listener.tomb.Go(func() error {
+			return listener.handler.HandlePacketConnection(listener.udpConnection, listener.metricsChan)
+		})
+	}
+	if listener.remoteWrite != nil {
+		mux := http.NewServeMux()
+		mux.Handle("/write", listener.handler.HandleRemoteWrite(listener.remoteWrite.pathLabels, listener.metricsChan))
+		listener.remoteWrite.server = &http.Server{Handler: mux}
+		listener.tomb.Go(func() error {
+			err := listener.remoteWrite.server.Serve(listener.remoteWrite.listener)
+			if err == http.ErrServerClosed {
+				return nil
+			}
+			return err
+		})
+	}
+	listener.logger.Info("Moira Filter Listener Started")
+	return listener.metricsChan
+}
+
+func (listener *MetricsListener) accept(netListener net.Listener, handle func(net.Conn, chan *moira.MatchedMetric) error) error {
+	for {
+		select {
+		case <-listener.tomb.Dying():
+			return nil
+		default:
+			conn, err := netListener.Accept()
+			if err != nil {
+				if isDying(&listener.tomb) {
 					return nil
 				}
-			default:
-				{
-					conn, err := listener.listener.Accept()
-					if err != nil {
-						listener.logger.Infof("Failed to accept connection: %s", err.Error())
-						continue
-					}
-					listener.handler.tomb.Go(func() error {
-						return listener.handler.HandleConnection(conn, metricsChan)
-					})
-				}
+				listener.logger.Infof("Failed to accept connection: %s", err.Error())
+				continue
 			}
+			listener.handlersWG.Add(1)
+			go func() {
+				defer listener.handlersWG.Done()
+				handle(conn, listener.metricsChan)
+			}()
 		}
-	})
-	listener.logger.Info("Moira Filter Listener Started")
-	return metricsChan
+	}
 }
 
 // Stop stops listening connection
 func (listener *MetricsListener) Stop() error {
 	listener.tomb.Kill(nil)
-	return listener.tomb.Wait()
+	listener.handler.tomb.Kill(nil)
+	listener.listener.Close()
+	if listener.pickleListener != nil {
+		listener.pickleListener.Close()
+	}
+	if listener.udpConnection != nil {
+		listener.udpConnection.Close()
+	}
+	if listener.remoteWrite != nil {
+		// Shutdown waits for active remote_write requests, so no one writes to metricsChan after it is closed
+		listener.remoteWrite.server.Shutdown(context.Background())
+	}
+	err := listener.tomb.Wait()
+	listener.handlersWG.Wait()
+	close(listener.metricsChan)
+	listener.logger.Info("Moira Filter Listener Stopped")
+	return err
+}
+
+func isDying(t *tomb.Tomb) bool {
+	select {
+	case <-t.Dying():
+		return true
+	default:
+		return false
+	}
 }
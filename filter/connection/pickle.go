@@ -0,0 +1,528 @@
+package connection
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxPickleSize is the same limit of pickled batch length as in carbon-cache
+const maxPickleSize = 1048576
+
+// pickle opcodes allowed in carbon batches, all other opcodes (including GLOBAL and REDUCE) are rejected
+const (
+	opMark            = '('
+	opStop            = '.'
+	opPop             = '0'
+	opNone            = 'N'
+	opInt             = 'I'
+	opBinInt          = 'J'
+	opBinInt1         = 'K'
+	opBinInt2         = 'M'
+	opLong            = 'L'
+	opFloat           = 'F'
+	opBinFloat        = 'G'
+	opString          = 'S'
+	opBinString       = 'T'
+	opShortBinString  = 'U'
+	opUnicode         = 'V'
+	opBinUnicode      = 'X'
+	opEmptyList       = ']'
+	opAppend          = 'a'
+	opAppends         = 'e'
+	opList            = 'l'
+	opEmptyTuple      = ')'
+	opTuple           = 't'
+	opPut             = 'p'
+	opBinPut          = 'q'
+	opLongBinPut      = 'r'
+	opGet             = 'g'
+	opBinGet          = 'h'
+	opLongBinGet      = 'j'
+	opProto           = 0x80
+	opTuple1          = 0x85
+	opTuple2          = 0x86
+	opTuple3          = 0x87
+	opNewTrue         = 0x88
+	opNewFalse        = 0x89
+	opLong1           = 0x8a
+	opShortBinUnicode = 0x8c
+	opMemoize         = 0x94
+	opFrame           = 0x95
+)
+
+type pickleMark struct{}
+
+type pickleList struct {
+	items []interface{}
+}
+
+// unpickler is a restricted pickle decoder which can only build lists, tuples, strings and numbers
+type unpickler struct {
+	reader *bytes.Reader
+	stack  []interface{}
+	memo   map[int]interface{}
+}
+
+// parsePickleMetrics decodes carbon pickle batch [(path, (timestamp, value)), ...] into plaintext protocol lines
+// Malformed tuples are skipped like invalid plaintext lines, their errors are returned with lines of valid tuples
+func parsePickleMetrics(data []byte) ([][]byte, []error, error) {
+	decoder := &unpickler{
+		reader: bytes.NewReader(data),
+		stack:  make([]interface{}, 0),
+		memo:   make(map[int]interface{}),
+	}
+	result, err := decoder.load()
+	if err != nil {
+		return nil, nil, err
+	}
+	list, ok := result.(*pickleList)
+	if !ok {
+		return nil, nil, fmt.Errorf("pickle batch must be a list, got %T", result)
+	}
+	lines := make([][]byte, 0, len(list.items))
+	invalid := make([]error, 0)
+	for _, item := range list.items {
+		line, err := pickleMetricToLine(item)
+		if err != nil {
+			invalid = append(invalid, err)
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines, invalid, nil
+}
+
+func pickleMetricToLine(item interface{}) ([]byte, error) {
+	metric, ok := asPickleSequence(item)
+	if !ok || len(metric) != 2 {
+		return nil, fmt.Errorf("metric must be a tuple (path, (timestamp, value))")
+	}
+	path, ok := metric[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("metric path must be a string, got %T", metric[0])
+	}
+	datapoint, ok := asPickleSequence(metric[1])
+	if !ok || len(datapoint) != 2 {
+		return nil, fmt.Errorf("metric %s datapoint must be a tuple (timestamp, value)", path)
+	}
+	timestamp, err := formatPickleTimestamp(datapoint[0])
+	if err != nil {
+		return nil, fmt.Errorf("metric %s timestamp: %s", path, err.Error())
+	}
+	value, err := formatPickleNumber(datapoint[1])
+	if err != nil {
+		return nil, fmt.Errorf("metric %s value: %s", path, err.Error())
+	}
+	return []byte(fmt.Sprintf("%s %s %s", path, value, timestamp)), nil
+}
+
+// asPickleSequence returns items of tuple or list
+func asPickleSequence(value interface{}) ([]interface{}, bool) {
+	switch sequence := value.(type) {
+	case []interface{}:
+		return sequence, true
+	case *pickleList:
+		return sequence.items, true
+	default:
+		return nil, false
+	}
+}
+
+// formatPickleTimestamp truncates float timestamps to seconds as plaintext protocol accepts only integer timestamps
+func formatPickleTimestamp(value interface{}) (string, error) {
+	switch timestamp := value.(type) {
+	case int64:
+		return strconv.FormatInt(timestamp, 10), nil
+	case float64:
+		return strconv.FormatInt(int64(timestamp), 10), nil
+	case string:
+		parsed, err := strconv.ParseFloat(timestamp, 64)
+		if err != nil {
+			return "", fmt.Errorf("'%s' is not a number", timestamp)
+		}
+		return strconv.FormatInt(int64(parsed), 10), nil
+	default:
+		return "", fmt.Errorf("number expected, got %T", value)
+	}
+}
+
+func formatPickleNumber(value interface{}) (string, error) {
+	switch number := value.(type) {
+	case int64:
+		return strconv.FormatInt(number, 10), nil
+	case float64:
+		return strconv.FormatFloat(number, 'f', -1, 64), nil
+	case string:
+		if _, err := strconv.ParseFloat(number, 64); err != nil {
+			return "", fmt.Errorf("'%s' is not a number", number)
+		}
+		return number, nil
+	default:
+		return "", fmt.Errorf("number expected, got %T", value)
+	}
+}
+
+func (decoder *unpickler) load() (interface{}, error) {
+	for {
+		opcode, err := decoder.reader.ReadByte()
+		if err != nil {
+			return nil, fmt.Errorf("unexpected end of pickle data")
+		}
+		switch opcode {
+		case opStop:
+			return decoder.pop()
+		case opProto:
+			if _, err = decoder.readBytes(1); err != nil {
+				return nil, err
+			}
+		case opFrame:
+			if _, err = decoder.readBytes(8); err != nil {
+				return nil, err
+			}
+		case opMark:
+			decoder.push(pickleMark{})
+		case opPop:
+			_, err = decoder.pop()
+		case opNone:
+			decoder.push(nil)
+		case opNewTrue:
+			decoder.push(true)
+		case opNewFalse:
+			decoder.push(false)
+		case opInt, opLong:
+			err = decoder.loadTextInt()
+		case opBinInt:
+			err = decoder.loadBinInt(4)
+		case opBinInt1:
+			err = decoder.loadBinInt(1)
+		case opBinInt2:
+			err = decoder.loadBinInt(2)
+		case opLong1:
+			err = decoder.loadLong1()
+		case opFloat:
+			err = decoder.loadTextFloat()
+		case opBinFloat:
+			err = decoder.loadBinFloat()
+		case opString:
+			err = decoder.loadQuotedString()
+		case opUnicode:
+			err = decoder.loadLineString()
+		case opBinString, opBinUnicode:
+			err = decoder.loadBinString(4)
+		case opShortBinString, opShortBinUnicode:
+			err = decoder.loadBinString(1)
+		case opEmptyList:
+			decoder.push(&pickleList{items: make([]interface{}, 0)})
+		case opList:
+			var items []interface{}
+			if items, err = decoder.popMark(); err == nil {
+				decoder.push(&pickleList{items: items})
+			}
+		case opAppend:
+			err = decoder.loadAppend()
+		case opAppends:
+			err = decoder.loadAppends()
+		case opEmptyTuple:
+			decoder.push([]interface{}{})
+		case opTuple:
+			var items []interface{}
+			if items, err = decoder.popMark(); err == nil {
+				decoder.push(items)
+			}
+		case opTuple1, opTuple2, opTuple3:
+			err = decoder.loadTupleN(int(opcode-opTuple1) + 1)
+		case opPut, opGet:
+			err = decoder.loadTextMemo(opcode == opPut)
+		case opBinPut, opBinGet:
+			err = decoder.loadBinMemo(1, opcode == opBinPut)
+		case opLongBinPut, opLongBinGet:
+			err = decoder.loadBinMemo(4, opcode == opLongBinPut)
+		case opMemoize:
+			var top interface{}
+			if top, err = decoder.top(); err == nil {
+				decoder.memo[len(decoder.memo)] = top
+			}
+		default:
+			return nil, fmt.Errorf("unsupported pickle opcode 0x%x", opcode)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (decoder *unpickler) push(value interface{}) {
+	decoder.stack = append(decoder.stack, value)
+}
+
+func (decoder *unpickler) pop() (interface{}, error) {
+	value, err := decoder.top()
+	if err != nil {
+		return nil, err
+	}
+	decoder.stack = decoder.stack[:len(decoder.stack)-1]
+	return value, nil
+}
+
+func (decoder *unpickler) top() (interface{}, error) {
+	if len(decoder.stack) == 0 {
+		return nil, fmt.Errorf("pickle stack is empty")
+	}
+	value := decoder.stack[len(decoder.stack)-1]
+	if _, ok := value.(pickleMark); ok {
+		return nil, fmt.Errorf("unexpected pickle mark")
+	}
+	return value, nil
+}
+
+// popMark pops all items pushed after the last mark
+func (decoder *unpickler) popMark() ([]interface{}, error) {
+	for i := len(decoder.stack) - 1; i >= 0; i-- {
+		if _, ok := decoder.stack[i].(pickleMark); ok {
+			items := make([]interface{}, len(decoder.stack)-i-1)
+			copy(items, decoder.stack[i+1:])
+			decoder.stack = decoder.stack[:i]
+			return items, nil
+		}
+	}
+	return nil, fmt.Errorf("pickle mark not found")
+}
+
+func (decoder *unpickler) readBytes(size int) ([]byte, error) {
+	if size < 0 || size > decoder.reader.Len() {
+		return nil, fmt.Errorf("unexpected end of pickle data")
+	}
+	data := make([]byte, size)
+	_, err := decoder.reader.Read(data)
+	return data, err
+}
+
+func (decoder *unpickler) readLine() (string, error) {
+	line := make([]byte, 0)
+	for {
+		char, err := decoder.reader.ReadByte()
+		if err != nil {
+			return "", fmt.Errorf("unexpected end of pickle data")
+		}
+		if char == '\n' {
+			return string(line), nil
+		}
+		line = append(line, char)
+	}
+}
+
+func (decoder *unpickler) loadTextInt() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	line = strings.TrimSuffix(line, "L")
+	switch line {
+	case "00":
+		decoder.push(false)
+		return nil
+	case "01":
+		decoder.push(true)
+		return nil
+	}
+	value, err := strconv.ParseInt(line, 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pickle int '%s'", line)
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadBinInt(size int) error {
+	data, err := decoder.readBytes(size)
+	if err != nil {
+		return err
+	}
+	switch size {
+	case 1:
+		decoder.push(int64(data[0]))
+	case 2:
+		decoder.push(int64(binary.LittleEndian.Uint16(data)))
+	default:
+		decoder.push(int64(int32(binary.LittleEndian.Uint32(data))))
+	}
+	return nil
+}
+
+// loadLong1 reads little-endian two's complement integer of up to 8 bytes
+func (decoder *unpickler) loadLong1() error {
+	size, err := decoder.readBytes(1)
+	if err != nil {
+		return err
+	}
+	if size[0] > 8 {
+		return fmt.Errorf("pickle long is too big")
+	}
+	data, err := decoder.readBytes(int(size[0]))
+	if err != nil {
+		return err
+	}
+	var value int64
+	for i := len(data) - 1; i >= 0; i-- {
+		value = value<<8 | int64(data[i])
+	}
+	if len(data) > 0 && len(data) < 8 && data[len(data)-1]&0x80 != 0 {
+		value -= 1 << uint(8*len(data))
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadTextFloat() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	value, err := strconv.ParseFloat(line, 64)
+	if err != nil {
+		return fmt.Errorf("invalid pickle float '%s'", line)
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadBinFloat() error {
+	data, err := decoder.readBytes(8)
+	if err != nil {
+		return err
+	}
+	decoder.push(math.Float64frombits(binary.BigEndian.Uint64(data)))
+	return nil
+}
+
+func (decoder *unpickler) loadQuotedString() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	value, err := strconv.Unquote(line)
+	if err != nil && len(line) >= 2 && line[0] == '\'' && line[len(line)-1] == '\'' {
+		value, err = strconv.Unquote("\"" + line[1:len(line)-1] + "\"")
+	}
+	if err != nil {
+		return fmt.Errorf("invalid pickle string %s", line)
+	}
+	decoder.push(value)
+	return nil
+}
+
+func (decoder *unpickler) loadLineString() error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	decoder.push(line)
+	return nil
+}
+
+func (decoder *unpickler) loadBinString(sizeLength int) error {
+	sizeBytes, err := decoder.readBytes(sizeLength)
+	if err != nil {
+		return err
+	}
+	size := int(sizeBytes[0])
+	if sizeLength == 4 {
+		size = int(binary.LittleEndian.Uint32(sizeBytes))
+	}
+	data, err := decoder.readBytes(size)
+	if err != nil {
+		return err
+	}
+	decoder.push(string(data))
+	return nil
+}
+
+func (decoder *unpickler) loadAppend() error {
+	value, err := decoder.pop()
+	if err != nil {
+		return err
+	}
+	return decoder.appendToList(value)
+}
+
+func (decoder *unpickler) loadAppends() error {
+	items, err := decoder.popMark()
+	if err != nil {
+		return err
+	}
+	return decoder.appendToList(items...)
+}
+
+func (decoder *unpickler) appendToList(values ...interface{}) error {
+	top, err := decoder.top()
+	if err != nil {
+		return err
+	}
+	list, ok := top.(*pickleList)
+	if !ok {
+		return fmt.Errorf("pickle append to %T", top)
+	}
+	list.items = append(list.items, values...)
+	return nil
+}
+
+func (decoder *unpickler) loadTupleN(size int) error {
+	if len(decoder.stack) < size {
+		return fmt.Errorf("pickle stack is too short for tuple")
+	}
+	items := make([]interface{}, size)
+	for i := size - 1; i >= 0; i-- {
+		value, err := decoder.pop()
+		if err != nil {
+			return err
+		}
+		items[i] = value
+	}
+	decoder.push(items)
+	return nil
+}
+
+func (decoder *unpickler) loadTextMemo(put bool) error {
+	line, err := decoder.readLine()
+	if err != nil {
+		return err
+	}
+	index, err := strconv.Atoi(line)
+	if err != nil {
+		return fmt.Errorf("invalid pickle memo index '%s'", line)
+	}
+	return decoder.memoize(index, put)
+}
+
+func (decoder *unpickler) loadBinMemo(size int, put bool) error {
+	data, err := decoder.readBytes(size)
+	if err != nil {
+		return err
+	}
+	index := int(data[0])
+	if size == 4 {
+		index = int(binary.LittleEndian.Uint32(data))
+	}
+	return decoder.memoize(index, put)
+}
+
+func (decoder *unpickler) memoize(index int, put bool) error {
+	if put {
+		top, err := decoder.top()
+		if err != nil {
+			return err
+		}
+		decoder.memo[index] = top
+		return nil
+	}
+	value, ok := decoder.memo[index]
+	if !ok {
+		return fmt.Errorf("pickle memo %d not found", index)
+	}
+	decoder.push(value)
+	return nil
+}
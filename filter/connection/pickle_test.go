@@ -0,0 +1,74 @@
+package connection
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParsePickleMetrics(t *testing.T) {
+	expected := [][]byte{
+		[]byte("a.b.c 1.5 1500000000"),
+		[]byte("x.y -2 1500000001"),
+		[]byte("u.v 1000000000000 1500000002"),
+	}
+
+	Convey("Given carbon batches pickled by different protocols, should return plaintext lines", t, func() {
+		batches := map[string]string{
+			"protocol 0": "(lp0\n(Va.b.c\np1\n(I1500000000\nF1.5\ntp2\ntp3\na(Vx.y\np4\n(F1500000001.0\nI-2\ntp5\ntp6\na(Vu.v\np7\n(I1500000002\nL1000000000000L\ntp8\ntp9\na.",
+			"protocol 2": "\x80\x02]q\x00(X\x05\x00\x00\x00a.b.cq\x01J\x00/hYG?\xf8\x00\x00\x00\x00\x00\x00\x86q\x02\x86q\x03X\x03\x00\x00\x00x.yq\x04GA\xd6Z\x0b\xc0@\x00\x00J\xfe\xff\xff\xff\x86q\x05\x86q\x06X\x03\x00\x00\x00u.vq\x07J\x02/hY\x8a\x06\x00\x10\xa5\xd4\xe8\x00\x86q\x08\x86q\te.",
+			"protocol 4": "\x80\x04\x95N\x00\x00\x00\x00\x00\x00\x00]\x94(\x8c\x05a.b.c\x94J\x00/hYG?\xf8\x00\x00\x00\x00\x00\x00\x86\x94\x86\x94\x8c\x03x.y\x94GA\xd6Z\x0b\xc0@\x00\x00J\xfe\xff\xff\xff\x86\x94\x86\x94\x8c\x03u.v\x94J\x02/hY\x8a\x06\x00\x10\xa5\xd4\xe8\x00\x86\x94\x86\x94e.",
+		}
+		for name, batch := range batches {
+			Convey(name, func() {
+				lines, invalid, err := parsePickleMetrics([]byte(batch))
+				So(err, ShouldBeNil)
+				So(invalid, ShouldBeEmpty)
+				So(lines, ShouldResemble, expected)
+			})
+		}
+	})
+
+	Convey("Given python 2 string batch, should return plaintext lines", t, func() {
+		lines, invalid, err := parsePickleMetrics([]byte("(lp0\n(S'a.b.c'\np1\n(I1500000000\nF1.5\ntp2\ntp3\na."))
+		So(err, ShouldBeNil)
+		So(invalid, ShouldBeEmpty)
+		So(lines, ShouldResemble, expected[:1])
+	})
+
+	Convey("Given batch with malformed tuples, should skip them and return valid lines", t, func() {
+		lines, invalid, err := parsePickleMetrics([]byte("(lp0\n(I1\n(I1500000000\nF1.5\ntp1\ntp2\na(S'a.b.c'\np3\n(I1500000000\nF1.5\ntp4\ntp5\na."))
+		So(err, ShouldBeNil)
+		So(invalid, ShouldHaveLength, 1)
+		So(lines, ShouldResemble, expected[:1])
+
+		malformedTuples := []string{
+			"(lp0\n(S'a.b.c'\np1\nI1500000000\ntp3\na.",
+			"(lp0\n(S'a.b.c'\np1\n(I1500000000\nS'abc'\ntp2\ntp3\na.",
+			"(lp0\n(I1\n(I1500000000\nF1.5\ntp2\ntp3\na.",
+		}
+		for _, batch := range malformedTuples {
+			lines, invalid, err := parsePickleMetrics([]byte(batch))
+			So(err, ShouldBeNil)
+			So(invalid, ShouldHaveLength, 1)
+			So(lines, ShouldBeEmpty)
+		}
+	})
+
+	Convey("Given unsafe or invalid pickle, should return error", t, func() {
+		invalidBatches := []string{
+			"cos\nsystem\n(S'echo hi'\ntR.",
+			"\x80\x02cos\nsystem\nq\x00.",
+			"(lp0\n(S'a.b.c'\np1\n(I1500000000\nF1.5\ntp2\ntp3\na",
+			"(S'a.b.c'\np1\n(I1500000000\nF1.5\ntp2\ntp3\n.",
+			"(lp0\ng5\n.",
+			"\x80\x02]q\x00X\xff\xff\xff\x00a.",
+			"a.",
+			"",
+		}
+		for _, batch := range invalidBatches {
+			_, _, err := parsePickleMetrics([]byte(batch))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
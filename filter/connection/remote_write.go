@@ -0,0 +1,240 @@
+package connection
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// maxRemoteWriteSize limits both compressed and decompressed size of prometheus remote_write request
+const maxRemoteWriteSize = 32 * 1048576
+
+// protobuf wire types used in prometheus WriteRequest
+const (
+	wireVarint          = 0
+	wireFixed64         = 1
+	wireLengthDelimited = 2
+	wireFixed32         = 5
+)
+
+// decodeSnappy decodes snappy block format used by prometheus remote_write (not framed stream format)
+func decodeSnappy(src []byte) ([]byte, error) {
+	length, n := binary.Uvarint(src)
+	if n <= 0 {
+		return nil, fmt.Errorf("snappy: invalid length header")
+	}
+	if length > maxRemoteWriteSize {
+		return nil, fmt.Errorf("snappy: decoded length %d exceeds limit", length)
+	}
+	dst := make([]byte, 0, length)
+	for pos := n; pos < len(src); {
+		tag := src[pos]
+		pos++
+		var size, offset int
+		switch tag & 0x03 {
+		case 0x00:
+			size = int(tag >> 2)
+			if size >= 60 {
+				lengthBytes := size - 59
+				if pos+lengthBytes > len(src) {
+					return nil, fmt.Errorf("snappy: truncated literal length")
+				}
+				size = 0
+				for i := 0; i < lengthBytes; i++ {
+					size |= int(src[pos+i]) << uint(8*i)
+				}
+				pos += lengthBytes
+			}
+			size++
+			if size <= 0 || pos+size > len(src) || len(dst)+size > int(length) {
+				return nil, fmt.Errorf("snappy: invalid literal length")
+			}
+			dst = append(dst, src[pos:pos+size]...)
+			pos += size
+			continue
+		case 0x01:
+			if pos >= len(src) {
+				return nil, fmt.Errorf("snappy: truncated copy")
+			}
+			size = 4 + int(tag>>2)&0x07
+			offset = int(tag&0xe0)<<3 | int(src[pos])
+			pos++
+		case 0x02:
+			if pos+2 > len(src) {
+				return nil, fmt.Errorf("snappy: truncated copy")
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint16(src[pos:]))
+			pos += 2
+		case 0x03:
+			if pos+4 > len(src) {
+				return nil, fmt.Errorf("snappy: truncated copy")
+			}
+			size = 1 + int(tag>>2)
+			offset = int(binary.LittleEndian.Uint32(src[pos:]))
+			pos += 4
+		}
+		if offset <= 0 || offset > len(dst) || len(dst)+size > int(length) {
+			return nil, fmt.Errorf("snappy: invalid copy offset")
+		}
+		// copy may overlap with itself, so bytes are appended one by one
+		from := len(dst) - offset
+		for i := 0; i < size; i++ {
+			dst = append(dst, dst[from+i])
+		}
+	}
+	if len(dst) != int(length) {
+		return nil, fmt.Errorf("snappy: decoded length %d does not match header %d", len(dst), length)
+	}
+	return dst, nil
+}
+
+// protoField is single field of protobuf message, value is set for length-delimited fields and number for others
+type protoField struct {
+	number   int
+	wireType int
+	value    []byte
+	number64 uint64
+}
+
+// readProtoFields reads top level fields of protobuf message, unknown fields are returned too and should be skipped by caller
+func readProtoFields(data []byte, fn func(field protoField) error) error {
+	for pos := 0; pos < len(data); {
+		key, n := binary.Uvarint(data[pos:])
+		if n <= 0 {
+			return fmt.Errorf("protobuf: invalid field key")
+		}
+		pos += n
+		field := protoField{number: int(key >> 3), wireType: int(key & 0x07)}
+		switch field.wireType {
+		case wireVarint:
+			if field.number64, n = binary.Uvarint(data[pos:]); n <= 0 {
+				return fmt.Errorf("protobuf: invalid varint in field %d", field.number)
+			}
+			pos += n
+		case wireFixed64:
+			if pos+8 > len(data) {
+				return fmt.Errorf("protobuf: truncated field %d", field.number)
+			}
+			field.number64 = binary.LittleEndian.Uint64(data[pos:])
+			pos += 8
+		case wireLengthDelimited:
+			size, n := binary.Uvarint(data[pos:])
+			if n <= 0 || size > uint64(len(data)-pos-n) {
+				return fmt.Errorf("protobuf: invalid length of field %d", field.number)
+			}
+			pos += n
+			field.value = data[pos : pos+int(size)]
+			pos += int(size)
+		case wireFixed32:
+			if pos+4 > len(data) {
+				return fmt.Errorf("protobuf: truncated field %d", field.number)
+			}
+			field.number64 = uint64(binary.LittleEndian.Uint32(data[pos:]))
+			pos += 4
+		default:
+			return fmt.Errorf("protobuf: unsupported wire type %d of field %d", field.wireType, field.number)
+		}
+		if err := fn(field); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// parseRemoteWrite converts prometheus WriteRequest into plaintext metric lines "path value timestamp"
+// Path is built from values of pathLabels in given order, absent labels are skipped
+// Stale markers and other NaN or infinite samples are skipped
+func parseRemoteWrite(data []byte, pathLabels []string) ([][]byte, error) {
+	lines := make([][]byte, 0)
+	err := readProtoFields(data, func(field protoField) error {
+		// WriteRequest: repeated TimeSeries timeseries = 1
+		if field.number != 1 || field.wireType != wireLengthDelimited {
+			return nil
+		}
+		labels := make(map[string]string)
+		samples := make([]protoField, 0, 1)
+		err := readProtoFields(field.value, func(field protoField) error {
+			if field.wireType != wireLengthDelimited {
+				return nil
+			}
+			switch field.number {
+			case 1: // repeated Label labels = 1
+				var name, value string
+				err := readProtoFields(field.value, func(labelField protoField) error {
+					if labelField.wireType != wireLengthDelimited {
+						return nil
+					}
+					switch labelField.number {
+					case 1:
+						name = string(labelField.value)
+					case 2:
+						value = string(labelField.value)
+					}
+					return nil
+				})
+				labels[name] = value
+				return err
+			case 2: // repeated Sample samples = 2
+				samples = append(samples, field)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		path := getRemoteWritePath(labels, pathLabels)
+		if path == "" {
+			return nil
+		}
+		for _, sample := range samples {
+			var value float64
+			var timestamp int64
+			err := readProtoFields(sample.value, func(sampleField protoField) error {
+				switch {
+				case sampleField.number == 1 && sampleField.wireType == wireFixed64:
+					value = math.Float64frombits(sampleField.number64)
+				case sampleField.number == 2 && sampleField.wireType == wireVarint:
+					timestamp = int64(sampleField.number64)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			if math.IsNaN(value) || math.IsInf(value, 0) {
+				continue
+			}
+			line := fmt.Sprintf("%s %s %d", path, strconv.FormatFloat(value, 'f', -1, 64), timestamp/1000)
+			lines = append(lines, []byte(line))
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func getRemoteWritePath(labels map[string]string, pathLabels []string) string {
+	parts := make([]string, 0, len(pathLabels))
+	for _, label := range pathLabels {
+		if value := sanitizePathNode(labels[label]); value != "" {
+			parts = append(parts, value)
+		}
+	}
+	return strings.Join(parts, ".")
+}
+
+// sanitizePathNode replaces dots, spaces and other characters not allowed in graphite path node with underscore
+func sanitizePathNode(value string) string {
+	return strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '-', r == ':':
+			return r
+		}
+		return '_'
+	}, value)
+}
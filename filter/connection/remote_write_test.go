@@ -0,0 +1,127 @@
+package connection
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestDecodeSnappy(t *testing.T) {
+	Convey("Given snappy block with literal and overlapped copy, should decode it", t, func() {
+		decoded, err := decodeSnappy([]byte("\x0c\x0cabcd\x11\x04"))
+		So(err, ShouldBeNil)
+		So(string(decoded), ShouldEqual, "abcdabcdabcd")
+	})
+
+	Convey("Given snappy block with long literal and two bytes offset copy, should decode it", t, func() {
+		literal := bytes.Repeat([]byte("x"), 100)
+		block := append([]byte{200, 1, 0xf0, 99}, literal...)
+		block = append(block, 0x02|(63<<2), 100, 0, 0x02|(35<<2), 100, 0)
+		decoded, err := decodeSnappy(block)
+		So(err, ShouldBeNil)
+		So(decoded, ShouldResemble, bytes.Repeat([]byte("x"), 200))
+	})
+
+	Convey("Given invalid snappy block, should return error", t, func() {
+		invalidBlocks := []string{
+			"",
+			"\x0c\x0cabcd",
+			"\x04\x0cabcd\x11\x04",
+			"\x0c\x0cabcd\x11\x05",
+			"\x0c\x11\x04",
+			"\x0c\x0cab",
+			"\xff\xff\xff\xff\x0f\x00a",
+		}
+		for _, block := range invalidBlocks {
+			_, err := decodeSnappy([]byte(block))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestParseRemoteWrite(t *testing.T) {
+	pathLabels := []string{"job", "instance", "__name__"}
+
+	Convey("Given write request, should return plaintext lines", t, func() {
+		request := appendProtoBytes(nil, 1, newTimeSeries(
+			map[string]string{"__name__": "node_load1", "job": "node", "instance": "host.example.com:9100", "env": "prod"},
+			newSample(1.5, 1500000000123), newSample(-2, 1500000060000),
+		))
+		request = appendProtoBytes(request, 1, newTimeSeries(
+			map[string]string{"__name__": "up", "instance": "db 1"},
+			newSample(1, 1500000000000),
+		))
+		lines, err := parseRemoteWrite(request, pathLabels)
+		So(err, ShouldBeNil)
+		So(lines, ShouldResemble, [][]byte{
+			[]byte("node.host_example_com:9100.node_load1 1.5 1500000000"),
+			[]byte("node.host_example_com:9100.node_load1 -2 1500000060"),
+			[]byte("db_1.up 1 1500000000"),
+		})
+	})
+
+	Convey("Given stale markers and series without path labels, should skip them", t, func() {
+		request := appendProtoBytes(nil, 1, newTimeSeries(
+			map[string]string{"__name__": "up", "job": "node"},
+			newSample(math.NaN(), 1500000000000), newSample(math.Inf(1), 1500000000000), newSample(0, 1500000060000),
+		))
+		request = appendProtoBytes(request, 1, newTimeSeries(
+			map[string]string{"env": "prod"},
+			newSample(1, 1500000000000),
+		))
+		lines, err := parseRemoteWrite(request, pathLabels)
+		So(err, ShouldBeNil)
+		So(lines, ShouldResemble, [][]byte{[]byte("node.up 0 1500000060")})
+	})
+
+	Convey("Given invalid write request, should return error", t, func() {
+		invalidRequests := [][]byte{
+			{0x0a, 0x05, 0x0a},
+			{0x0a, 0x02, 0x12, 0x05},
+			appendProtoBytes(nil, 1, newTimeSeries(map[string]string{"job": "node"}, []byte{0x09, 0x00})),
+			{0x0b},
+		}
+		for _, request := range invalidRequests {
+			_, err := parseRemoteWrite(request, pathLabels)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func newTimeSeries(labels map[string]string, samples ...[]byte) []byte {
+	var series []byte
+	for name, value := range labels {
+		label := appendProtoBytes(nil, 1, []byte(name))
+		label = appendProtoBytes(label, 2, []byte(value))
+		series = appendProtoBytes(series, 1, label)
+	}
+	for _, sample := range samples {
+		series = appendProtoBytes(series, 2, sample)
+	}
+	return series
+}
+
+func newSample(value float64, timestamp int64) []byte {
+	sample := append(appendProtoKey(nil, 1, wireFixed64), make([]byte, 8)...)
+	binary.LittleEndian.PutUint64(sample[1:], math.Float64bits(value))
+	sample = appendProtoKey(sample, 2, wireVarint)
+	return appendVarint(sample, uint64(timestamp))
+}
+
+func appendProtoBytes(data []byte, field int, value []byte) []byte {
+	data = appendProtoKey(data, field, wireLengthDelimited)
+	data = appendVarint(data, uint64(len(value)))
+	return append(data, value...)
+}
+
+func appendProtoKey(data []byte, field int, wireType int) []byte {
+	return appendVarint(data, uint64(field<<3|wireType))
+}
+
+func appendVarint(data []byte, value uint64) []byte {
+	buffer := make([]byte, binary.MaxVarintLen64)
+	return append(data, buffer[:binary.PutUvarint(buffer, value)]...)
+}
@@ -0,0 +1,121 @@
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// globSymbols are symbols that make pattern part a glob instead of plain metric name part
+const globSymbols = "*?[{"
+
+// isGlob checks if pattern part contains graphite glob symbols
+func isGlob(part string) bool {
+	return strings.ContainsAny(part, globSymbols)
+}
+
+// compileGlob compiles single pattern part in graphite glob syntax to anchored regular expression
+// Supported syntax is the same as in graphite-web:
+// * matches any number of any chars, ? matches any single char,
+// [0-9a-z] and [!0-9] match char classes, {one,two{a,b}} matches any of nested comma-separated alternatives
+func compileGlob(part string) (*regexp.Regexp, error) {
+	var expression bytes.Buffer
+	expression.WriteString("^")
+	braces := 0
+	for i := 0; i < len(part); i++ {
+		switch c := part[i]; c {
+		case '*':
+			expression.WriteString(".*")
+		case '?':
+			expression.WriteString(".")
+		case '[':
+			end := strings.IndexByte(part[i+1:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("Unclosed character class in '%s'", part)
+			}
+			class := part[i+1 : i+1+end]
+			if class == "" {
+				return nil, fmt.Errorf("Empty character class in '%s'", part)
+			}
+			expression.WriteString(compileGlobClass(class))
+			i += end + 1
+		case '{':
+			braces++
+			expression.WriteString("(?:")
+		case '}':
+			if braces == 0 {
+				return nil, fmt.Errorf("Unexpected '}' in '%s'", part)
+			}
+			braces--
+			expression.WriteString(")")
+		case ',':
+			if braces > 0 {
+				expression.WriteString("|")
+			} else {
+				expression.WriteString(",")
+			}
+		default:
+			expression.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if braces > 0 {
+		return nil, fmt.Errorf("Unclosed '{' in '%s'", part)
+	}
+	expression.WriteString("$")
+	return regexp.Compile(expression.String())
+}
+
+// compileGlobClass converts glob character class content to regular expression class, leading ! or ^ negates class
+func compileGlobClass(class string) string {
+	var expression bytes.Buffer
+	expression.WriteString("[")
+	if class[0] == '!' || class[0] == '^' {
+		expression.WriteString("^")
+		class = class[1:]
+	}
+	for i := 0; i < len(class); i++ {
+		c := class[i]
+		if c == '-' && i > 0 && i < len(class)-1 {
+			expression.WriteByte(c)
+			continue
+		}
+		if c == '\\' || c == '[' || c == ']' || c == '^' || c == '-' {
+			expression.WriteByte('\\')
+		}
+		expression.WriteByte(c)
+	}
+	expression.WriteString("]")
+	return expression.String()
+}
+
+// MetricGlob matches whole metric names by graphite glob pattern, each dot-separated part is matched separately
+type MetricGlob []*regexp.Regexp
+
+// CompileMetricGlob compiles all dot-separated parts of graphite glob pattern
+func CompileMetricGlob(pattern string) (MetricGlob, error) {
+	parts := strings.Split(pattern, ".")
+	glob := make(MetricGlob, len(parts))
+	for i, part := range parts {
+		matcher, err := compileGlob(part)
+		if err != nil {
+			return nil, err
+		}
+		glob[i] = matcher
+	}
+	return glob, nil
+}
+
+// Match checks that metric name has the same number of parts as pattern and all parts are matched
+func (glob MetricGlob) Match(metric string) bool {
+	parts := strings.Split(metric, ".")
+	if len(parts) != len(glob) {
+		return false
+	}
+	for i, part := range parts {
+		if !glob[i].MatchString(part) {
+			return false
+		}
+	}
+	return true
+}
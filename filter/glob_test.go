@@ -0,0 +1,65 @@
+package filter
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestCompileGlob(t *testing.T) {
+	Convey("Given valid globs, should match and not match parts", t, func() {
+		type globCase struct {
+			glob       string
+			matched    []string
+			notMatched []string
+		}
+		cases := []globCase{
+			{"prefix*", []string{"prefix", "prefix_suffix"}, []string{"pre", "a_prefix"}},
+			{"?at", []string{"cat", "bat"}, []string{"at", "scat"}},
+			{"host[0-9]", []string{"host1", "host9"}, []string{"host", "hosta", "host10"}},
+			{"host[!0-9]", []string{"hosta"}, []string{"host1"}},
+			{"host[ab-]", []string{"hosta", "host-"}, []string{"hostc"}},
+			{"{one,two}", []string{"one", "two"}, []string{"three", "onetwo"}},
+			{"pr{one,two{a,b}}suf", []string{"pronesuf", "prtwoasuf", "prtwobsuf"}, []string{"prtwosuf", "prtwocsuf"}},
+			{"{web,db}[0-9][0-9]*", []string{"web01", "db12-main"}, []string{"web1", "cache01"}},
+			{"a+b(c)", []string{"a+b(c)"}, []string{"aab(c)", "a+bc"}},
+			{"a,b", []string{"a,b"}, []string{"a", "b"}},
+		}
+		for _, testCase := range cases {
+			matcher, err := compileGlob(testCase.glob)
+			So(err, ShouldBeNil)
+			for _, part := range testCase.matched {
+				So(matcher.MatchString(part), ShouldBeTrue)
+			}
+			for _, part := range testCase.notMatched {
+				So(matcher.MatchString(part), ShouldBeFalse)
+			}
+		}
+	})
+
+	Convey("Given invalid globs, should return error", t, func() {
+		invalidGlobs := []string{"{one,two", "one}", "host[0-9", "host[]", "{a,{b}", "[z-a]"}
+		for _, glob := range invalidGlobs {
+			_, err := compileGlob(glob)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestCompileMetricGlob(t *testing.T) {
+	Convey("Given metric glob, should match all parts", t, func() {
+		glob, err := CompileMetricGlob("servers.{web,db}[0-9].cpu.*")
+		So(err, ShouldBeNil)
+		So(glob.Match("servers.web1.cpu.user"), ShouldBeTrue)
+		So(glob.Match("servers.db2.cpu.system"), ShouldBeTrue)
+		So(glob.Match("servers.cache1.cpu.user"), ShouldBeFalse)
+		So(glob.Match("servers.web1.cpu"), ShouldBeFalse)
+		So(glob.Match("servers.web1.cpu.user.total"), ShouldBeFalse)
+		So(glob.Match("serversXweb1.cpu.user.total"), ShouldBeFalse)
+	})
+
+	Convey("Given invalid metric glob, should return error", t, func() {
+		_, err := CompileMetricGlob("servers.{web,db.cpu")
+		So(err, ShouldNotBeNil)
+	})
+}
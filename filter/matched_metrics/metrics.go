@@ -8,56 +8,121 @@ import (
 	"time"
 )
 
+// Config contains batching and saving settings of matched metrics
+type Config struct {
+	QueueSize         int
+	BatchSize         int
+	FlushInterval     time.Duration
+	SaveRetries       int
+	SaveRetryInterval time.Duration
+}
+
 // MetricsMatcher make buffer of metrics and save it
 type MetricsMatcher struct {
 	logger       moira.Logger
 	metrics      *graphite.FilterMetrics
 	database     moira.Database
 	cacheStorage *filter.Storage
+	config       Config
 }
 
 // NewMetricsMatcher creates new MetricsMatcher
-func NewMetricsMatcher(metrics *graphite.FilterMetrics, logger moira.Logger, database moira.Database, cacheStorage *filter.Storage) *MetricsMatcher {
+func NewMetricsMatcher(metrics *graphite.FilterMetrics, logger moira.Logger, database moira.Database, cacheStorage *filter.Storage, config Config) *MetricsMatcher {
+	if config.BatchSize < 1 {
+		config.BatchSize = 1
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
 	return &MetricsMatcher{
 		metrics:      metrics,
 		logger:       logger,
 		database:     database,
 		cacheStorage: cacheStorage,
+		config:       config,
 	}
 }
 
 // Start process matched metrics from channel and save it in cache storage
+// Metrics are collected to batches which are saved when BatchSize metrics are collected or every FlushInterval.
+// Batches are saved by separate writer through bounded queue, so slow database blocks reading from channel
 func (matcher *MetricsMatcher) Start(channel chan *moira.MatchedMetric, wg *sync.WaitGroup) {
+	batchesQueueSize := matcher.config.QueueSize / matcher.config.BatchSize
+	if batchesQueueSize < 1 {
+		batchesQueueSize = 1
+	}
+	batches := make(chan map[string]*moira.MatchedMetric, batchesQueueSize)
+
 	go func() {
-		defer wg.Done()
+		defer close(batches)
+		flushTicker := time.NewTicker(matcher.config.FlushInterval)
+		defer flushTicker.Stop()
 		buffer := make(map[string]*moira.MatchedMetric)
 		for {
 			select {
 			case metric, ok := <-channel:
 				if !ok {
 					matcher.logger.Info("Channel was closed, stop Metrics Matcher")
+					if len(buffer) > 0 {
+						batches <- buffer
+					}
 					return
 				}
 				matcher.cacheStorage.EnrichMatchedMetric(buffer, metric)
-				if len(buffer) < 10 {
+				if len(buffer) < matcher.config.BatchSize {
 					continue
 				}
-			case <-time.After(time.Second):
+			case <-flushTicker.C:
 			}
 			if len(buffer) == 0 {
 				continue
 			}
+			batches <- buffer
+			buffer = make(map[string]*moira.MatchedMetric)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for batch := range batches {
 			timer := time.Now()
-			matcher.save(buffer)
+			matcher.save(batch)
 			matcher.metrics.SavingTimer.UpdateSince(timer)
-			buffer = make(map[string]*moira.MatchedMetric)
 		}
 	}()
 	matcher.logger.Info("Moira Filter Metrics Matcher started")
 }
 
 func (matcher *MetricsMatcher) save(buffer map[string]*moira.MatchedMetric) {
-	if err := matcher.database.SaveMetrics(buffer); err != nil {
-		matcher.logger.Infof("Failed to save value in cache storage: %s", err.Error())
+	if !matcher.saveMetrics(buffer) {
+		return
+	}
+	now := time.Now().Unix()
+	for _, metric := range buffer {
+		if metric.Schema == nil {
+			continue
+		}
+		if err := matcher.database.DownsampleMetricValues(metric.Metric, metric.Schema, now); err != nil {
+			matcher.logger.Infof("Failed to downsample metric values: %s", err.Error())
+		}
+	}
+}
+
+// saveMetrics saves metrics retrying SaveRetries times on failure, metrics are dropped if all attempts fail
+func (matcher *MetricsMatcher) saveMetrics(buffer map[string]*moira.MatchedMetric) bool {
+	for attempt := 0; ; attempt++ {
+		err := matcher.database.SaveMetrics(buffer)
+		if err == nil {
+			return true
+		}
+		if attempt >= matcher.config.SaveRetries {
+			matcher.logger.Errorf("Failed to save %d metrics in cache storage, metrics are dropped: %s", len(buffer), err.Error())
+			matcher.metrics.DroppedMetrics.Mark(int64(len(buffer)))
+			return false
+		}
+		matcher.logger.Infof("Failed to save value in cache storage, retry in %v: %s", matcher.config.SaveRetryInterval, err.Error())
+		matcher.metrics.RetriedMetrics.Mark(int64(len(buffer)))
+		time.Sleep(matcher.config.SaveRetryInterval)
 	}
 }
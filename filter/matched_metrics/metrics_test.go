@@ -0,0 +1,74 @@
+package matchedmetrics
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/filter"
+	"github.com/moira-alert/moira/metrics/graphite/go-metrics"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestMetricsMatcher(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Filter")
+
+	newMatcher := func(config Config) *MetricsMatcher {
+		cacheStorage, err := filter.NewCacheStorage(metrics.ConfigureFilterMetrics("test"), strings.NewReader("[default]\npattern = .*\nretentions = 60s:1d\n"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewMetricsMatcher(metrics.ConfigureFilterMetrics("test"), logger, database, cacheStorage, config)
+	}
+	sendMetrics := func(matcher *MetricsMatcher, count int) {
+		channel := make(chan *moira.MatchedMetric, count)
+		for i := 0; i < count; i++ {
+			channel <- &moira.MatchedMetric{Metric: fmt.Sprintf("metric.%d", i), Patterns: []string{"metric.*"}, Value: 1, Timestamp: 60}
+		}
+		close(channel)
+		var wg sync.WaitGroup
+		matcher.Start(channel, &wg)
+		wg.Wait()
+	}
+
+	Convey("Given metrics, should save them by batches and flush rest when channel is closed", t, func() {
+		matcher := newMatcher(Config{QueueSize: 10, BatchSize: 2, FlushInterval: time.Minute})
+		batchSizes := make([]int, 0)
+		database.EXPECT().SaveMetrics(gomock.Any()).Do(func(buffer map[string]*moira.MatchedMetric) {
+			batchSizes = append(batchSizes, len(buffer))
+		}).Return(nil).Times(3)
+		sendMetrics(matcher, 5)
+		So(batchSizes, ShouldResemble, []int{2, 2, 1})
+		So(matcher.metrics.RetriedMetrics.Count(), ShouldEqual, 0)
+		So(matcher.metrics.DroppedMetrics.Count(), ShouldEqual, 0)
+	})
+
+	Convey("Given failing database, should retry saving", t, func() {
+		matcher := newMatcher(Config{QueueSize: 10, BatchSize: 2, FlushInterval: time.Minute, SaveRetries: 2})
+		gomock.InOrder(
+			database.EXPECT().SaveMetrics(gomock.Any()).Return(fmt.Errorf("connection refused")),
+			database.EXPECT().SaveMetrics(gomock.Any()).Return(nil),
+		)
+		sendMetrics(matcher, 2)
+		So(matcher.metrics.RetriedMetrics.Count(), ShouldEqual, 2)
+		So(matcher.metrics.DroppedMetrics.Count(), ShouldEqual, 0)
+	})
+
+	Convey("Given database failing more than retries count, should drop metrics", t, func() {
+		matcher := newMatcher(Config{QueueSize: 10, BatchSize: 2, FlushInterval: time.Minute, SaveRetries: 2})
+		database.EXPECT().SaveMetrics(gomock.Any()).Return(fmt.Errorf("connection refused")).Times(3)
+		sendMetrics(matcher, 2)
+		So(matcher.metrics.RetriedMetrics.Count(), ShouldEqual, 4)
+		So(matcher.metrics.DroppedMetrics.Count(), ShouldEqual, 2)
+	})
+}
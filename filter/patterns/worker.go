@@ -12,38 +12,54 @@ import (
 
 // RefreshPatternWorker realization
 type RefreshPatternWorker struct {
-	database       moira.Database
-	logger         moira.Logger
-	metrics        *graphite.FilterMetrics
-	patternStorage *filter.PatternStorage
-	tomb           tomb.Tomb
+	database        moira.Database
+	logger          moira.Logger
+	metrics         *graphite.FilterMetrics
+	patternStorage  *filter.PatternStorage
+	refreshInterval time.Duration
+	tomb            tomb.Tomb
 }
 
 // NewRefreshPatternWorker creates new RefreshPatternWorker
-func NewRefreshPatternWorker(database moira.Database, metrics *graphite.FilterMetrics, logger moira.Logger, patternStorage *filter.PatternStorage) *RefreshPatternWorker {
+func NewRefreshPatternWorker(database moira.Database, metrics *graphite.FilterMetrics, logger moira.Logger, patternStorage *filter.PatternStorage, refreshInterval time.Duration) *RefreshPatternWorker {
 	return &RefreshPatternWorker{
-		database:       database,
-		metrics:        metrics,
-		logger:         logger,
-		patternStorage: patternStorage,
+		database:        database,
+		metrics:         metrics,
+		logger:          logger,
+		patternStorage:  patternStorage,
+		refreshInterval: refreshInterval,
 	}
 }
 
-// Start process to refresh pattern tree every second
+// Start process to apply pattern add and remove events to pattern tree
+// Full pattern tree refresh is performed every refresh interval to fix missed events
 func (worker *RefreshPatternWorker) Start() error {
-	err := worker.patternStorage.RefreshTree()
+	patternEvents, err := worker.database.SubscribePatternEvents(&worker.tomb)
+	if err != nil {
+		worker.logger.Errorf("pattern events subscription failed: %s", err.Error())
+		return err
+	}
+
+	err = worker.patternStorage.RefreshTree()
 	if err != nil {
 		worker.logger.Errorf("pattern refresh failed: %s", err.Error())
 		return err
 	}
 
 	worker.tomb.Go(func() error {
+		checkTicker := time.NewTicker(worker.refreshInterval)
+		defer checkTicker.Stop()
 		for {
-			checkTicker := time.NewTicker(time.Second)
 			select {
 			case <-worker.tomb.Dying():
 				worker.logger.Info("Moira Filter pattern updater stopped")
 				return nil
+			case patternEvent, ok := <-patternEvents:
+				if !ok {
+					patternEvents = nil
+					continue
+				}
+				worker.applyPatternEvent(patternEvent)
 			case <-checkTicker.C:
 				timer := time.Now()
 				err := worker.patternStorage.RefreshTree()
@@ -58,6 +74,19 @@ func (worker *RefreshPatternWorker) Start() error {
 	return nil
 }
 
+func (worker *RefreshPatternWorker) applyPatternEvent(patternEvent *moira.PatternEvent) {
+	switch patternEvent.Type {
+	case moira.PatternAdded:
+		if err := worker.patternStorage.AddPattern(patternEvent.Pattern); err != nil {
+			worker.logger.Warningf("Skip invalid pattern %s: %s", patternEvent.Pattern, err.Error())
+		}
+	case moira.PatternRemoved:
+		worker.patternStorage.RemovePattern(patternEvent.Pattern)
+	default:
+		worker.logger.Warningf("Unknown pattern event type '%s' for pattern %s", patternEvent.Type, patternEvent.Pattern)
+	}
+}
+
 // Stop stops update pattern tree
 func (worker *RefreshPatternWorker) Stop() error {
 	worker.tomb.Kill(nil)
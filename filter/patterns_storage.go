@@ -4,31 +4,45 @@ import (
 	"fmt"
 	"github.com/moira-alert/moira"
 	"github.com/moira-alert/moira/metrics/graphite"
-	"github.com/vova616/xxhash"
-	"path"
+	"io"
+	"regexp"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 	"unicode"
 )
 
-var asteriskHash = xxhash.Checksum32([]byte("*"))
-
 // PatternStorage contains pattern tree
+// Tree is never changed after it is built: updates create copies of changed nodes and replace tree root,
+// so read lock is held only to take current root and metrics are matched against it while tree is updated
 type PatternStorage struct {
-	database    moira.Database
-	metrics     *graphite.FilterMetrics
-	logger      moira.Logger
-	PatternTree *patternNode
+	database     moira.Database
+	metrics      *graphite.FilterMetrics
+	logger       moira.Logger
+	PatternTree  *patternNode
+	tagPatterns  []*tagPattern
+	rewriteRules []*rewriteRule
+	treeMutex    sync.RWMutex
+	updateMutex  sync.Mutex
+}
+
+// tagPattern contains parsed seriesByTag pattern
+type tagPattern struct {
+	pattern string
+	specs   []moira.TagSpec
 }
 
 // patternNode contains pattern node
+// Children without glob symbols are indexed by part, glob children are matched by compiled regular expressions
 type patternNode struct {
-	Children   []*patternNode
-	Part       string
-	Hash       uint32
-	Prefix     string
-	InnerParts []string
+	Children        []*patternNode
+	Part            string
+	Prefix          string
+	Terminal        bool
+	literalChildren map[string]*patternNode
+	globChildren    []*patternNode
+	matcher         *regexp.Regexp
 }
 
 // NewPatternStorage creates new PatternStorage struct
@@ -44,6 +58,8 @@ func NewPatternStorage(database moira.Database, metrics *graphite.FilterMetrics,
 
 // RefreshTree builds pattern tree from redis data
 func (storage *PatternStorage) RefreshTree() error {
+	storage.updateMutex.Lock()
+	defer storage.updateMutex.Unlock()
 	patterns, err := storage.database.GetPatterns()
 	if err != nil {
 		return err
@@ -51,11 +67,93 @@ func (storage *PatternStorage) RefreshTree() error {
 	return storage.buildTree(patterns)
 }
 
+// LoadRewriteRules reads metric name rewrite rules config, rules are applied to incoming metrics before matching
+func (storage *PatternStorage) LoadRewriteRules(reader io.Reader) error {
+	rules, err := parseRewriteRules(reader)
+	if err != nil {
+		return err
+	}
+	for _, rule := range rules {
+		storage.metrics.RewriteRulesMetrics.AddMetric(rule.name, fmt.Sprintf("filter.rewrite.%s", rule.name))
+	}
+	storage.rewriteRules = rules
+	return nil
+}
+
+// AddPattern adds single pattern to pattern tree
+func (storage *PatternStorage) AddPattern(pattern string) error {
+	storage.updateMutex.Lock()
+	defer storage.updateMutex.Unlock()
+	tree, tagPatterns := storage.getTree()
+	if moira.IsSeriesByTag(pattern) {
+		for _, tagPattern := range tagPatterns {
+			if tagPattern.pattern == pattern {
+				return nil
+			}
+		}
+		specs, err := moira.ParseSeriesByTag(pattern)
+		if err != nil {
+			return err
+		}
+		newTagPatterns := make([]*tagPattern, len(tagPatterns), len(tagPatterns)+1)
+		copy(newTagPatterns, tagPatterns)
+		storage.setTree(tree, append(newTagPatterns, &tagPattern{pattern: pattern, specs: specs}))
+		return nil
+	}
+	newTree := tree.copyPath(strings.Split(pattern, "."))
+	if err := newTree.addPattern(pattern); err != nil {
+		return err
+	}
+	storage.setTree(newTree, tagPatterns)
+	return nil
+}
+
+// RemovePattern removes single pattern from pattern tree
+func (storage *PatternStorage) RemovePattern(pattern string) {
+	storage.updateMutex.Lock()
+	defer storage.updateMutex.Unlock()
+	tree, tagPatterns := storage.getTree()
+	if moira.IsSeriesByTag(pattern) {
+		newTagPatterns := make([]*tagPattern, 0, len(tagPatterns))
+		for _, tagPattern := range tagPatterns {
+			if tagPattern.pattern != pattern {
+				newTagPatterns = append(newTagPatterns, tagPattern)
+			}
+		}
+		storage.setTree(tree, newTagPatterns)
+		return
+	}
+	parts := strings.Split(pattern, ".")
+	newTree := tree.copyPath(parts)
+	newTree.removePattern(parts)
+	storage.setTree(newTree, tagPatterns)
+}
+
+func (storage *PatternStorage) getTree() (*patternNode, []*tagPattern) {
+	storage.treeMutex.RLock()
+	defer storage.treeMutex.RUnlock()
+	return storage.PatternTree, storage.tagPatterns
+}
+
+func (storage *PatternStorage) setTree(tree *patternNode, tagPatterns []*tagPattern) {
+	storage.treeMutex.Lock()
+	defer storage.treeMutex.Unlock()
+	storage.PatternTree = tree
+	storage.tagPatterns = tagPatterns
+}
+
 // ProcessIncomingMetric validates, parses and matches incoming raw string
 func (storage *PatternStorage) ProcessIncomingMetric(lineBytes []byte) *moira.MatchedMetric {
 	storage.metrics.TotalMetricsReceived.Mark(1)
 	count := storage.metrics.TotalMetricsReceived.Count()
 
+	if len(storage.rewriteRules) > 0 {
+		lineBytes = storage.rewriteMetricLine(lineBytes)
+		if lineBytes == nil {
+			return nil
+		}
+	}
+
 	metric, value, timestamp, err := storage.parseMetricFromString(lineBytes)
 	if err != nil {
 		storage.logger.Debugf("cannot parse input: %v", err)
@@ -65,7 +163,18 @@ func (storage *PatternStorage) ProcessIncomingMetric(lineBytes []byte) *moira.Ma
 	storage.metrics.ValidMetricsReceived.Mark(1)
 
 	matchingStart := time.Now()
-	matched := storage.matchPattern(metric)
+	var matched []string
+	if moira.IsTaggedMetric(string(metric)) {
+		tags, err := moira.ParseTaggedMetric(string(metric))
+		if err != nil {
+			storage.logger.Debugf("cannot parse input: %v", err)
+			return nil
+		}
+		metric = []byte(moira.FormatTaggedMetric(tags))
+		matched = storage.matchTagPatterns(tags)
+	} else {
+		matched = storage.matchPattern(metric)
+	}
 	if count%10 == 0 {
 		storage.metrics.MatchingTimer.UpdateSince(matchingStart)
 	}
@@ -83,10 +192,27 @@ func (storage *PatternStorage) ProcessIncomingMetric(lineBytes []byte) *moira.Ma
 	return nil
 }
 
+// MatchMetric validates metric name and returns name as it is saved by filter with patterns matched by this name
+// Rewrite rules are not applied, so metric name must be given as filter receives it after rewriting
+func (storage *PatternStorage) MatchMetric(metric string) (string, []string, error) {
+	if _, _, _, err := storage.parseMetricFromString([]byte(metric + " 0 1")); err != nil {
+		return metric, nil, err
+	}
+	if moira.IsTaggedMetric(metric) {
+		tags, err := moira.ParseTaggedMetric(metric)
+		if err != nil {
+			return metric, nil, err
+		}
+		return moira.FormatTaggedMetric(tags), storage.matchTagPatterns(tags), nil
+	}
+	return metric, storage.matchPattern([]byte(metric)), nil
+}
+
 // matchPattern returns array of matched patterns
 func (storage *PatternStorage) matchPattern(metric []byte) []string {
-	currentLevel := []*patternNode{storage.PatternTree}
-	var found, index int
+	tree, _ := storage.getTree()
+	currentLevel := []*patternNode{tree}
+	var index int
 	for i, c := range metric {
 		if c == '.' {
 			part := metric[index:i]
@@ -97,22 +223,19 @@ func (storage *PatternStorage) matchPattern(metric []byte) []string {
 
 			index = i + 1
 
-			currentLevel, found = findPart(part, currentLevel)
-			if found == 0 {
+			currentLevel = findPart(part, currentLevel)
+			if len(currentLevel) == 0 {
 				return []string{}
 			}
 		}
 	}
 
 	part := metric[index:]
-	currentLevel, found = findPart(part, currentLevel)
-	if found == 0 {
-		return []string{}
-	}
+	currentLevel = findPart(part, currentLevel)
 
-	matched := make([]string, 0, found)
+	matched := make([]string, 0, len(currentLevel))
 	for _, node := range currentLevel {
-		if len(node.Children) == 0 {
+		if node.Terminal {
 			matched = append(matched, node.Prefix)
 		}
 	}
@@ -120,6 +243,18 @@ func (storage *PatternStorage) matchPattern(metric []byte) []string {
 	return matched
 }
 
+// matchTagPatterns returns array of seriesByTag patterns matched by tagged series tags
+func (storage *PatternStorage) matchTagPatterns(tags map[string]string) []string {
+	_, tagPatterns := storage.getTree()
+	matched := make([]string, 0)
+	for _, tagPattern := range tagPatterns {
+		if moira.MatchTagSpecs(tagPattern.specs, tags) {
+			matched = append(matched, tagPattern.pattern)
+		}
+	}
+	return matched
+}
+
 // parseMetricFromString parses metric from string
 // supported format: "<metricString> <valueFloat64> <timestampInt64>"
 func (*PatternStorage) parseMetricFromString(line []byte) ([]byte, float64, int64, error) {
@@ -166,87 +301,183 @@ func (*PatternStorage) parseMetricFromString(line []byte) ([]byte, float64, int6
 }
 
 func (storage *PatternStorage) buildTree(patterns []string) error {
-	newTree := &patternNode{}
+	newTree := newPatternNode("", "")
+	newTagPatterns := make([]*tagPattern, 0)
 
 	for _, pattern := range patterns {
-		currentNode := newTree
-		parts := strings.Split(pattern, ".")
-		for _, part := range parts {
-			found := false
-			for _, child := range currentNode.Children {
-				if part == child.Part {
-					currentNode = child
-					found = true
-					break
-				}
-			}
-			if !found {
-				newNode := &patternNode{Part: part}
-
-				if currentNode.Prefix == "" {
-					newNode.Prefix = part
-				} else {
-					newNode.Prefix = fmt.Sprintf("%s.%s", currentNode.Prefix, part)
-				}
-
-				if part == "*" || !strings.ContainsAny(part, "{*?") {
-					newNode.Hash = xxhash.Checksum32([]byte(part))
-				} else {
-					if strings.Contains(part, "{") && strings.Contains(part, "}") {
-						prefix, bigSuffix := split2(part, "{")
-						inner, suffix := split2(bigSuffix, "}")
-						innerParts := strings.Split(inner, ",")
-
-						newNode.InnerParts = make([]string, 0, len(innerParts))
-						for _, innerPart := range innerParts {
-							newNode.InnerParts = append(newNode.InnerParts, fmt.Sprintf("%s%s%s", prefix, innerPart, suffix))
-						}
-					} else {
-						newNode.InnerParts = []string{part}
-					}
-
-				}
-				currentNode.Children = append(currentNode.Children, newNode)
-				currentNode = newNode
+		if moira.IsSeriesByTag(pattern) {
+			specs, err := moira.ParseSeriesByTag(pattern)
+			if err != nil {
+				storage.logger.Warningf("Skip invalid pattern %s: %s", pattern, err.Error())
+				continue
 			}
+			newTagPatterns = append(newTagPatterns, &tagPattern{pattern: pattern, specs: specs})
+			continue
+		}
+		if err := newTree.addPattern(pattern); err != nil {
+			storage.logger.Warningf("Skip invalid pattern %s: %s", pattern, err.Error())
 		}
 	}
 
-	storage.PatternTree = newTree
+	storage.setTree(newTree, newTagPatterns)
 	return nil
 }
 
-func findPart(part []byte, currentLevel []*patternNode) ([]*patternNode, int) {
-	nextLevel := make([]*patternNode, 0, 64)
-	hash := xxhash.Checksum32(part)
-	for _, node := range currentLevel {
-		for _, child := range node.Children {
-			match := false
-
-			if child.Hash == asteriskHash || child.Hash == hash {
-				match = true
-			} else if len(child.InnerParts) > 0 {
-				for _, innerPart := range child.InnerParts {
-					innerMatch, _ := path.Match(innerPart, string(part))
-					if innerMatch {
-						match = true
-						break
-					}
-				}
+func newPatternNode(part, prefix string) *patternNode {
+	return &patternNode{
+		Part:            part,
+		Prefix:          prefix,
+		literalChildren: make(map[string]*patternNode),
+	}
+}
+
+// addPattern adds all pattern parts to tree, pattern is added only if all its parts are valid
+func (node *patternNode) addPattern(pattern string) error {
+	parts := strings.Split(pattern, ".")
+	matchers := make([]*regexp.Regexp, len(parts))
+	for i, part := range parts {
+		if part != "*" && isGlob(part) {
+			matcher, err := compileGlob(part)
+			if err != nil {
+				return err
 			}
+			matchers[i] = matcher
+		}
+	}
 
-			if match {
-				nextLevel = append(nextLevel, child)
+	currentNode := node
+	for i, part := range parts {
+		child := currentNode.findChild(part)
+		if child == nil {
+			prefix := part
+			if currentNode.Prefix != "" {
+				prefix = fmt.Sprintf("%s.%s", currentNode.Prefix, part)
 			}
+			child = newPatternNode(part, prefix)
+			child.matcher = matchers[i]
+			currentNode.addChild(child)
+		}
+		currentNode = child
+	}
+	currentNode.Terminal = true
+	return nil
+}
+
+// removePattern removes terminal mark from pattern leaf and removes nodes which are not used by other patterns
+func (node *patternNode) removePattern(parts []string) {
+	if len(parts) == 0 {
+		node.Terminal = false
+		return
+	}
+	child := node.findChild(parts[0])
+	if child == nil {
+		return
+	}
+	child.removePattern(parts[1:])
+	if !child.Terminal && len(child.Children) == 0 {
+		node.removeChild(child)
+	}
+}
+
+// copyPath returns copy of tree in which nodes on the path of pattern parts are copied, so they can be changed in place
+func (node *patternNode) copyPath(parts []string) *patternNode {
+	newNode := node.clone()
+	if len(parts) > 0 {
+		if child := node.findChild(parts[0]); child != nil {
+			newNode.replaceChild(child, child.copyPath(parts[1:]))
 		}
 	}
-	return nextLevel, len(nextLevel)
+	return newNode
+}
+
+// clone returns copy of node with own children collections
+func (node *patternNode) clone() *patternNode {
+	newNode := *node
+	newNode.Children = make([]*patternNode, len(node.Children))
+	copy(newNode.Children, node.Children)
+	newNode.globChildren = make([]*patternNode, len(node.globChildren))
+	copy(newNode.globChildren, node.globChildren)
+	newNode.literalChildren = make(map[string]*patternNode, len(node.literalChildren))
+	for part, child := range node.literalChildren {
+		newNode.literalChildren[part] = child
+	}
+	return &newNode
+}
+
+func (node *patternNode) replaceChild(child, newChild *patternNode) {
+	node.Children = replaceNode(node.Children, child, newChild)
+	if _, ok := node.literalChildren[child.Part]; ok {
+		node.literalChildren[child.Part] = newChild
+	} else {
+		node.globChildren = replaceNode(node.globChildren, child, newChild)
+	}
 }
 
-func split2(s, sep string) (string, string) {
-	splitResult := strings.SplitN(s, sep, 2)
-	if len(splitResult) < 2 {
-		return splitResult[0], ""
+func (node *patternNode) removeChild(child *patternNode) {
+	node.Children = replaceNode(node.Children, child, nil)
+	if _, ok := node.literalChildren[child.Part]; ok {
+		delete(node.literalChildren, child.Part)
+	} else {
+		node.globChildren = replaceNode(node.globChildren, child, nil)
+	}
+}
+
+// replaceNode replaces node in list with newNode or removes it if newNode is nil
+func replaceNode(nodes []*patternNode, node, newNode *patternNode) []*patternNode {
+	for i := range nodes {
+		if nodes[i] == node {
+			if newNode == nil {
+				return append(nodes[:i], nodes[i+1:]...)
+			}
+			nodes[i] = newNode
+			return nodes
+		}
+	}
+	return nodes
+}
+
+func (node *patternNode) findChild(part string) *patternNode {
+	if child, ok := node.literalChildren[part]; ok {
+		return child
+	}
+	for _, child := range node.globChildren {
+		if child.Part == part {
+			return child
+		}
+	}
+	return nil
+}
+
+func (node *patternNode) addChild(child *patternNode) {
+	node.Children = append(node.Children, child)
+	if child.Part == "*" || child.matcher != nil {
+		node.globChildren = append(node.globChildren, child)
+	} else {
+		node.literalChildren[child.Part] = child
+	}
+}
+
+// match checks if metric part satisfies glob node
+func (node *patternNode) match(part []byte) bool {
+	if node.matcher == nil {
+		return node.Part == "*"
+	}
+	return node.matcher.Match(part)
+}
+
+// findPart returns children of current level nodes matched by metric part
+// Literal children are found by hash index, only glob children are checked one by one
+func findPart(part []byte, currentLevel []*patternNode) []*patternNode {
+	var nextLevel []*patternNode
+	for _, node := range currentLevel {
+		if child, ok := node.literalChildren[string(part)]; ok {
+			nextLevel = append(nextLevel, child)
+		}
+		for _, child := range node.globChildren {
+			if child.match(part) {
+				nextLevel = append(nextLevel, child)
+			}
+		}
 	}
-	return splitResult[0], splitResult[1]
+	return nextLevel
 }
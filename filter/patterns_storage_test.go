@@ -79,6 +79,11 @@ func TestProcessIncomingMetric(t *testing.T) {
 		"Complex.*{one,two,three}suf*.pattern",
 		"Question.?at_begin",
 		"Question.at_the_end?",
+		"Class.host[0-9][0-9].cpu",
+		"Class.host[!0-9].cpu",
+		"Nested.{web{1,2},db}.load",
+		"Prefix.pattern",
+		"Prefix.pattern.longer",
 	}
 
 	nonMatchingMetrics := []string{
@@ -87,6 +92,11 @@ func TestProcessIncomingMetric(t *testing.T) {
 		"Bracket.one.nothing",
 		"Bracket.nothing.pattern",
 		"Complex.prefixonesuffix",
+		"Class.host1.cpu",
+		"Class.host123.cpu",
+		"Nested.web3.load",
+		"Nested.web.load",
+		"Prefix",
 	}
 
 	matchingMetrics := []string{
@@ -106,6 +116,13 @@ func TestProcessIncomingMetric(t *testing.T) {
 		"Complex.anything.pattern",
 		"Question.1at_begin",
 		"Question.at_the_end2",
+		"Class.host01.cpu",
+		"Class.hostA.cpu",
+		"Nested.web1.load",
+		"Nested.web2.load",
+		"Nested.db.load",
+		"Prefix.pattern",
+		"Prefix.pattern.longer",
 	}
 
 	metrics2 := metrics.ConfigureFilterMetrics("test")
@@ -173,5 +190,108 @@ func TestProcessIncomingMetric(t *testing.T) {
 		})
 	})
 
+	Convey("When metric matches several patterns, should return all of them", t, func() {
+		matchedMetric := patternsStorage.ProcessIncomingMetric([]byte("Complex.prefixonesuffix.pattern 12 1234567890"))
+		So(matchedMetric, ShouldNotBeNil)
+		So(matchedMetric.Patterns, ShouldResemble, []string{"Complex.*.*", "Complex.*{one,two,three}suf*.pattern"})
+	})
+
 	mockCtrl.Finish()
 }
+
+func TestProcessIncomingTaggedMetric(t *testing.T) {
+	testPatterns := []string{
+		"cpu.*",
+		"seriesByTag('name=cpu','dc=~eu.*')",
+		"seriesByTag('name=cpu','host!=a')",
+		"seriesByTag('name=cpu', 'dc!=~eu.*')",
+		"seriesByTag('dc!=eu')",
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Scheduler")
+
+	database.EXPECT().GetPatterns().Return(testPatterns, nil)
+	patternsStorage, err := NewPatternStorage(database, metrics.ConfigureFilterMetrics("test"), logger)
+
+	Convey("Invalid seriesByTag pattern is skipped", t, func() {
+		So(err, ShouldBeNil)
+		So(patternsStorage.tagPatterns, ShouldHaveLength, 3)
+	})
+
+	Convey("Tagged metric matches seriesByTag patterns only", t, func() {
+		matchedMetric := patternsStorage.ProcessIncomingMetric([]byte("cpu;host=a;dc=eu1 12 1234567890"))
+		So(matchedMetric, ShouldNotBeNil)
+		So(matchedMetric.Metric, ShouldEqual, "cpu;dc=eu1;host=a")
+		So(matchedMetric.Patterns, ShouldResemble, []string{"seriesByTag('name=cpu','dc=~eu.*')"})
+
+		matchedMetric = patternsStorage.ProcessIncomingMetric([]byte("cpu;host=b 12 1234567890"))
+		So(matchedMetric, ShouldNotBeNil)
+		So(matchedMetric.Patterns, ShouldResemble, []string{"seriesByTag('name=cpu','host!=a')", "seriesByTag('name=cpu', 'dc!=~eu.*')"})
+	})
+
+	Convey("Not matching or invalid tagged metric", t, func() {
+		matchedMetric := patternsStorage.ProcessIncomingMetric([]byte("mem;dc=eu1 12 1234567890"))
+		So(matchedMetric, ShouldBeNil)
+
+		matchedMetric = patternsStorage.ProcessIncomingMetric([]byte("cpu;dc 12 1234567890"))
+		So(matchedMetric, ShouldBeNil)
+
+		matchedMetric = patternsStorage.ProcessIncomingMetric([]byte(";dc=eu1 12 1234567890"))
+		So(matchedMetric, ShouldBeNil)
+	})
+}
+
+func TestIncrementalPatternUpdates(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Scheduler")
+
+	database.EXPECT().GetPatterns().Return([]string{"Simple.matching.pattern", "Star.*"}, nil)
+	patternsStorage, err := NewPatternStorage(database, metrics.ConfigureFilterMetrics("test"), logger)
+
+	matchPatterns := func(metric string) []string {
+		return patternsStorage.matchPattern([]byte(metric))
+	}
+
+	Convey("Add patterns, should match new patterns and not change previous tree", t, func() {
+		So(err, ShouldBeNil)
+		previousTree := patternsStorage.PatternTree
+
+		So(patternsStorage.AddPattern("Simple.matching.{other,second}"), ShouldBeNil)
+		So(patternsStorage.AddPattern("Simple.matching"), ShouldBeNil)
+		So(patternsStorage.AddPattern("seriesByTag('name=cpu')"), ShouldBeNil)
+		So(patternsStorage.AddPattern("Simple.matching.{other"), ShouldNotBeNil)
+
+		So(matchPatterns("Simple.matching.pattern"), ShouldResemble, []string{"Simple.matching.pattern"})
+		So(matchPatterns("Simple.matching.second"), ShouldResemble, []string{"Simple.matching.{other,second}"})
+		So(matchPatterns("Simple.matching"), ShouldResemble, []string{"Simple.matching"})
+		So(matchPatterns("Star.anything"), ShouldResemble, []string{"Star.*"})
+		So(patternsStorage.matchTagPatterns(map[string]string{"name": "cpu"}), ShouldResemble, []string{"seriesByTag('name=cpu')"})
+
+		So(previousTree.Children, ShouldHaveLength, 2)
+		So(previousTree.Children[0].Children[0].Terminal, ShouldBeFalse)
+		So(previousTree.Children[0].Children[0].Children, ShouldHaveLength, 1)
+	})
+
+	Convey("Remove patterns, should not match removed patterns only", t, func() {
+		patternsStorage.RemovePattern("Simple.matching.pattern")
+		patternsStorage.RemovePattern("Star.*")
+		patternsStorage.RemovePattern("Unknown.pattern")
+		patternsStorage.RemovePattern("seriesByTag('name=cpu')")
+
+		So(matchPatterns("Simple.matching.pattern"), ShouldBeEmpty)
+		So(matchPatterns("Star.anything"), ShouldBeEmpty)
+		So(matchPatterns("Simple.matching.other"), ShouldResemble, []string{"Simple.matching.{other,second}"})
+		So(matchPatterns("Simple.matching"), ShouldResemble, []string{"Simple.matching"})
+		So(patternsStorage.matchTagPatterns(map[string]string{"name": "cpu"}), ShouldBeEmpty)
+		So(patternsStorage.PatternTree.Children, ShouldHaveLength, 1)
+
+		patternsStorage.RemovePattern("Simple.matching")
+		patternsStorage.RemovePattern("Simple.matching.{other,second}")
+		So(patternsStorage.PatternTree.Children, ShouldBeEmpty)
+	})
+}
@@ -0,0 +1,212 @@
+package relay
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/filter/sharding"
+	"github.com/moira-alert/moira/metrics/graphite"
+)
+
+// Relay accepts plaintext protocol connections and routes every metric to filter shard chosen by consistent hashing of metric name
+// so every metric is always matched and cached by the same filter
+type Relay struct {
+	database        moira.Database
+	logger          moira.Logger
+	metrics         *graphite.FilterMetrics
+	listener        net.Listener
+	refreshInterval time.Duration
+	queueSize       int
+	ring            *sharding.Ring
+	senders         map[string]*shardSender
+	mutex           sync.RWMutex
+	tomb            tomb.Tomb
+	handlersWG      sync.WaitGroup
+}
+
+// NewRelay creates new Relay listening plaintext protocol connections
+// Filter shards list is refreshed from database every refreshInterval, every shard has queue of queueSize lines
+func NewRelay(listen string, database moira.Database, metrics *graphite.FilterMetrics, logger moira.Logger, refreshInterval time.Duration, queueSize int) (*Relay, error) {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return nil, fmt.Errorf("Failed to listen on [%s]: %s", listen, err.Error())
+	}
+	return &Relay{
+		database:        database,
+		logger:          logger,
+		metrics:         metrics,
+		listener:        listener,
+		refreshInterval: refreshInterval,
+		queueSize:       queueSize,
+		ring:            sharding.NewRing(nil),
+		senders:         make(map[string]*shardSender),
+	}, nil
+}
+
+// Start loads filter shards and starts accepting connections
+func (relay *Relay) Start() error {
+	if err := relay.refreshShards(); err != nil {
+		return err
+	}
+	relay.tomb.Go(func() error {
+		refreshTicker := time.NewTicker(relay.refreshInterval)
+		defer refreshTicker.Stop()
+		for {
+			select {
+			case <-relay.tomb.Dying():
+				return nil
+			case <-refreshTicker.C:
+				if err := relay.refreshShards(); err != nil {
+					relay.logger.Errorf("Filter shards refresh failed: %s", err.Error())
+				}
+			}
+		}
+	})
+	relay.tomb.Go(relay.accept)
+	relay.logger.Info("Moira Filter Relay started")
+	return nil
+}
+
+// Stop stops accepting connections and sends queued metrics to filter shards
+func (relay *Relay) Stop() error {
+	relay.tomb.Kill(nil)
+	relay.listener.Close()
+	err := relay.tomb.Wait()
+	relay.handlersWG.Wait()
+	relay.mutex.Lock()
+	defer relay.mutex.Unlock()
+	for _, sender := range relay.senders {
+		sender.stop()
+	}
+	relay.logger.Info("Moira Filter Relay stopped")
+	return err
+}
+
+// refreshShards rebuilds hashing ring, starts senders to new shards and stops senders to removed shards
+func (relay *Relay) refreshShards() error {
+	shards, err := relay.database.GetFilterShards()
+	if err != nil {
+		return err
+	}
+	if len(shards) == 0 {
+		relay.logger.Warning("No filter shards registered, metrics are dropped")
+	}
+	relay.mutex.Lock()
+	removedSenders := make([]*shardSender, 0)
+	senders := make(map[string]*shardSender, len(shards))
+	for _, shard := range shards {
+		if sender, ok := relay.senders[shard.ID]; ok && sender.shard.Address == shard.Address {
+			senders[shard.ID] = sender
+			continue
+		}
+		relay.logger.Infof("Filter shard %s [%s] added", shard.ID, shard.Address)
+		senders[shard.ID] = newShardSender(shard, relay.queueSize, relay.logger, relay.metrics)
+	}
+	for shardID, sender := range relay.senders {
+		if senders[shardID] != sender {
+			relay.logger.Infof("Filter shard %s [%s] removed", shardID, sender.shard.Address)
+			removedSenders = append(removedSenders, sender)
+		}
+	}
+	relay.senders = senders
+	relay.ring = sharding.NewRing(shards)
+	relay.mutex.Unlock()
+
+	for _, sender := range removedSenders {
+		sender.stop()
+	}
+	return nil
+}
+
+func (relay *Relay) accept() error {
+	for {
+		connection, err := relay.listener.Accept()
+		if err != nil {
+			select {
+			case <-relay.tomb.Dying():
+				return nil
+			default:
+			}
+			relay.logger.Infof("Failed to accept connection: %s", err.Error())
+			continue
+		}
+		relay.handlersWG.Add(1)
+		go func() {
+			defer relay.handlersWG.Done()
+			relay.handleConnection(connection)
+		}()
+	}
+}
+
+func (relay *Relay) handleConnection(connection net.Conn) {
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-relay.tomb.Dying():
+			connection.Close()
+		case <-done:
+		}
+	}()
+	defer connection.Close()
+
+	buffer := bufio.NewReader(connection)
+	for {
+		lineBytes, err := buffer.ReadBytes('\n')
+		if len(lineBytes) > 0 {
+			if lineBytes[len(lineBytes)-1] != '\n' {
+				lineBytes = append(lineBytes, '\n')
+			}
+			relay.route(lineBytes)
+		}
+		if err != nil {
+			if err != io.EOF && !isDying(&relay.tomb) {
+				relay.logger.Errorf("read failed: %s", err)
+			}
+			return
+		}
+	}
+}
+
+// route sends line to filter shard chosen by metric name, tags of tagged metrics are sorted before hashing
+func (relay *Relay) route(line []byte) {
+	relay.metrics.TotalMetricsReceived.Mark(1)
+	metric := getMetricName(line)
+	if moira.IsTaggedMetric(metric) {
+		if tags, err := moira.ParseTaggedMetric(metric); err == nil {
+			metric = moira.FormatTaggedMetric(tags)
+		}
+	}
+	relay.mutex.RLock()
+	defer relay.mutex.RUnlock()
+	shard, ok := relay.ring.GetShard(metric)
+	if !ok {
+		relay.metrics.DroppedMetrics.Mark(1)
+		return
+	}
+	relay.senders[shard.ID].send(line)
+}
+
+func getMetricName(line []byte) string {
+	if index := bytes.IndexByte(line, ' '); index >= 0 {
+		return string(line[:index])
+	}
+	return string(bytes.TrimRight(line, "\r\n"))
+}
+
+func isDying(t *tomb.Tomb) bool {
+	select {
+	case <-t.Dying():
+		return true
+	default:
+		return false
+	}
+}
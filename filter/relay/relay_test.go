@@ -0,0 +1,126 @@
+package relay
+
+import (
+	"bufio"
+	"fmt"
+	"net"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/filter/sharding"
+	"github.com/moira-alert/moira/metrics/graphite/go-metrics"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+// listenShard accepts single connection and sends received lines to channel
+func listenShard(t *testing.T) (net.Listener, chan string) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := make(chan string, 100)
+	go func() {
+		connection, err := listener.Accept()
+		if err != nil {
+			return
+		}
+		defer connection.Close()
+		scanner := bufio.NewScanner(connection)
+		for scanner.Scan() {
+			lines <- scanner.Text()
+		}
+	}()
+	return listener, lines
+}
+
+func receiveLines(lines chan string, count int) []string {
+	received := make([]string, 0, count)
+	for len(received) < count {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+		case <-time.After(time.Second * 5):
+			return received
+		}
+	}
+	return received
+}
+
+func TestRelay(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Filter")
+
+	shardListener1, shardLines1 := listenShard(t)
+	defer shardListener1.Close()
+	shardListener2, shardLines2 := listenShard(t)
+	defer shardListener2.Close()
+	shards := []moira.FilterShard{
+		{ID: "filter-1", Address: shardListener1.Addr().String()},
+		{ID: "filter-2", Address: shardListener2.Addr().String()},
+	}
+	database.EXPECT().GetFilterShards().Return(shards, nil)
+
+	relay, err := NewRelay("127.0.0.1:0", database, metrics.ConfigureFilterMetrics("test"), logger, time.Hour, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Given metrics, should send every metric to its shard", t, func() {
+		So(relay.Start(), ShouldBeNil)
+		connection, err := net.Dial("tcp", relay.listener.Addr().String())
+		So(err, ShouldBeNil)
+
+		ring := sharding.NewRing(shards)
+		expected := map[string][]string{}
+		for i := 0; i < 20; i++ {
+			metric := fmt.Sprintf("Servers.host%d.cpu", i)
+			line := fmt.Sprintf("%s %d 1234567890", metric, i)
+			fmt.Fprintln(connection, line)
+			shard, _ := ring.GetShard(metric)
+			expected[shard.ID] = append(expected[shard.ID], line)
+		}
+		fmt.Fprintln(connection, "name;b=2;a=1 1 1234567890")
+		shard, _ := ring.GetShard("name;a=1;b=2")
+		expected[shard.ID] = append(expected[shard.ID], "name;b=2;a=1 1 1234567890")
+		connection.Close()
+
+		received1 := receiveLines(shardLines1, len(expected["filter-1"]))
+		received2 := receiveLines(shardLines2, len(expected["filter-2"]))
+		sort.Strings(received1)
+		sort.Strings(received2)
+		sort.Strings(expected["filter-1"])
+		sort.Strings(expected["filter-2"])
+		So(received1, ShouldResemble, expected["filter-1"])
+		So(received2, ShouldResemble, expected["filter-2"])
+		So(relay.metrics.RelayedMetrics.Count(), ShouldEqual, 21)
+		So(relay.Stop(), ShouldBeNil)
+	})
+}
+
+func TestRelayWithoutShards(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Filter")
+	database.EXPECT().GetFilterShards().Return([]moira.FilterShard{}, nil)
+
+	relay, err := NewRelay("127.0.0.1:0", database, metrics.ConfigureFilterMetrics("test"), logger, time.Hour, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("No shards registered, should drop metrics", t, func() {
+		So(relay.Start(), ShouldBeNil)
+		relay.route([]byte("Servers.host.cpu 1 1234567890\n"))
+		So(relay.metrics.DroppedMetrics.Count(), ShouldEqual, 1)
+		So(relay.Stop(), ShouldBeNil)
+	})
+}
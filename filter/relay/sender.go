@@ -0,0 +1,112 @@
+package relay
+
+import (
+	"bufio"
+	"net"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/metrics/graphite"
+)
+
+const (
+	dialTimeout    = time.Second * 5
+	reconnectDelay = time.Second
+)
+
+// shardSender writes metric lines to filter shard through persistent connection
+// Lines are queued in bounded queue, lines which don't fit in queue are dropped
+type shardSender struct {
+	shard   moira.FilterShard
+	lines   chan []byte
+	logger  moira.Logger
+	metrics *graphite.FilterMetrics
+	tomb    tomb.Tomb
+}
+
+func newShardSender(shard moira.FilterShard, queueSize int, logger moira.Logger, metrics *graphite.FilterMetrics) *shardSender {
+	sender := &shardSender{
+		shard:   shard,
+		lines:   make(chan []byte, queueSize),
+		logger:  logger,
+		metrics: metrics,
+	}
+	sender.tomb.Go(sender.run)
+	return sender
+}
+
+func (sender *shardSender) send(line []byte) {
+	select {
+	case sender.lines <- line:
+	default:
+		sender.metrics.DroppedMetrics.Mark(1)
+	}
+}
+
+func (sender *shardSender) run() error {
+	for {
+		connection, err := net.DialTimeout("tcp", sender.shard.Address, dialTimeout)
+		if err != nil {
+			sender.logger.Errorf("Failed to connect to filter shard %s [%s]: %s", sender.shard.ID, sender.shard.Address, err.Error())
+			select {
+			case <-sender.tomb.Dying():
+				return nil
+			case <-time.After(reconnectDelay):
+				continue
+			}
+		}
+		if stopped := sender.write(connection); stopped {
+			return nil
+		}
+	}
+}
+
+// write sends queued lines until connection fails or sender is stopped, returns true if sender is stopped
+func (sender *shardSender) write(connection net.Conn) bool {
+	defer connection.Close()
+	writer := bufio.NewWriter(connection)
+	for {
+		select {
+		case <-sender.tomb.Dying():
+			sender.flushQueue(writer)
+			return true
+		case line := <-sender.lines:
+			if _, err := writer.Write(line); err != nil {
+				sender.logger.Errorf("Failed to send metrics to filter shard %s [%s]: %s", sender.shard.ID, sender.shard.Address, err.Error())
+				sender.metrics.DroppedMetrics.Mark(1)
+				return false
+			}
+			sender.metrics.RelayedMetrics.Mark(1)
+			if len(sender.lines) > 0 {
+				continue
+			}
+			if err := writer.Flush(); err != nil {
+				sender.logger.Errorf("Failed to send metrics to filter shard %s [%s]: %s", sender.shard.ID, sender.shard.Address, err.Error())
+				return false
+			}
+		}
+	}
+}
+
+// flushQueue writes lines queued before sender is stopped
+func (sender *shardSender) flushQueue(writer *bufio.Writer) {
+	for {
+		select {
+		case line := <-sender.lines:
+			if _, err := writer.Write(line); err != nil {
+				return
+			}
+			sender.metrics.RelayedMetrics.Mark(1)
+		default:
+			writer.Flush()
+			return
+		}
+	}
+}
+
+func (sender *shardSender) stop() {
+	sender.tomb.Kill(nil)
+	sender.tomb.Wait()
+}
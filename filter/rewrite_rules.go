@@ -0,0 +1,125 @@
+package filter
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// Rewrite rule types
+const (
+	rewriteReplace   = "replace"
+	rewriteLowercase = "lowercase"
+	rewriteDrop      = "drop"
+	rewriteAllow     = "allow"
+)
+
+var rewriteRuleNameRegexp = regexp.MustCompile(`^[A-Za-z0-9_\-]+$`)
+
+// rewriteRule is single metric name rewrite rule, rules are applied in order of config file sections
+// replace rule replaces pattern matches with replacement, lowercase rule lowercases matched metric names,
+// drop rule drops matched metrics and allow rule drops metrics which are not matched
+type rewriteRule struct {
+	name        string
+	ruleType    string
+	pattern     *regexp.Regexp
+	replacement string
+}
+
+// parseRewriteRules parses rewrite rules config, every [section] is single rule named by section name
+func parseRewriteRules(reader io.Reader) ([]*rewriteRule, error) {
+	sections, err := parseConfigSections(reader)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*rewriteRule, 0, len(sections))
+	names := make(map[string]bool, len(sections))
+	for _, section := range sections {
+		if !rewriteRuleNameRegexp.MatchString(section.name) {
+			return nil, fmt.Errorf("Section [%s] has invalid name, only letters, digits, '_' and '-' are allowed", section.name)
+		}
+		if names[section.name] {
+			return nil, fmt.Errorf("Section [%s] is duplicated", section.name)
+		}
+		names[section.name] = true
+		pattern, err := section.getPattern()
+		if err != nil {
+			return nil, err
+		}
+		rule := &rewriteRule{
+			name:        section.name,
+			ruleType:    section.options["type"],
+			pattern:     pattern,
+			replacement: section.options["replacement"],
+		}
+		switch rule.ruleType {
+		case rewriteReplace:
+			if _, ok := section.options["replacement"]; !ok {
+				return nil, fmt.Errorf("Section [%s] has no replacement", section.name)
+			}
+		case rewriteLowercase, rewriteDrop, rewriteAllow:
+		default:
+			return nil, fmt.Errorf("Section [%s] has unknown type '%s'", section.name, rule.ruleType)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// apply returns rewritten metric name, false if metric must be dropped and true if rule has affected metric
+func (rule *rewriteRule) apply(metric string) (string, bool, bool) {
+	switch rule.ruleType {
+	case rewriteReplace:
+		rewritten := rule.pattern.ReplaceAllString(metric, rule.replacement)
+		return rewritten, true, rewritten != metric
+	case rewriteLowercase:
+		if !rule.pattern.MatchString(metric) {
+			return metric, true, false
+		}
+		rewritten := strings.ToLower(metric)
+		return rewritten, true, rewritten != metric
+	case rewriteDrop:
+		matched := rule.pattern.MatchString(metric)
+		return metric, !matched, matched
+	case rewriteAllow:
+		matched := rule.pattern.MatchString(metric)
+		return metric, matched, !matched
+	}
+	return metric, true, false
+}
+
+// rewriteMetricLine applies rewrite rules to metric name of raw "metric value timestamp" line
+// Metric name is everything before two last space-separated items, so names with spaces can be fixed by rules
+// Returns nil if metric is dropped by rules
+func (storage *PatternStorage) rewriteMetricLine(line []byte) []byte {
+	valueIndex := bytes.LastIndexByte(line, ' ')
+	if valueIndex < 1 {
+		return line
+	}
+	nameEnd := bytes.LastIndexByte(line[:valueIndex], ' ')
+	if nameEnd < 1 {
+		return line
+	}
+	original := string(line[:nameEnd])
+	metric := original
+	for _, rule := range storage.rewriteRules {
+		rewritten, keep, affected := rule.apply(metric)
+		if affected {
+			if meter, found := storage.metrics.RewriteRulesMetrics.GetMetric(rule.name); found {
+				meter.Mark(1)
+			}
+		}
+		if !keep {
+			return nil
+		}
+		metric = rewritten
+	}
+	if metric == original {
+		return line
+	}
+	rewrittenLine := make([]byte, 0, len(metric)+len(line)-nameEnd)
+	rewrittenLine = append(rewrittenLine, metric...)
+	return append(rewrittenLine, line[nameEnd:]...)
+}
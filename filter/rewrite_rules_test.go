@@ -0,0 +1,125 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/metrics/graphite/go-metrics"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+const testRewriteRules = `
+# applied in order of sections
+[drop_tmp]
+type = drop
+pattern = ^tmp\.
+
+[spaces]
+type = replace
+pattern = \s+
+replacement = _
+
+[random_suffix]
+type = replace
+pattern = \.[0-9a-f]{8}$
+replacement =
+
+[hosts]
+type = lowercase
+pattern = ^Hosts\.
+
+[allow_known]
+type = allow
+pattern = ^(hosts|services)\.
+`
+
+func TestParseRewriteRules(t *testing.T) {
+	Convey("Given valid rules, should parse them in order", t, func() {
+		rules, err := parseRewriteRules(strings.NewReader(testRewriteRules))
+		So(err, ShouldBeNil)
+		So(rules, ShouldHaveLength, 5)
+		So(rules[0].name, ShouldEqual, "drop_tmp")
+		So(rules[0].ruleType, ShouldEqual, rewriteDrop)
+		So(rules[1].replacement, ShouldEqual, "_")
+		So(rules[2].replacement, ShouldEqual, "")
+		So(rules[3].ruleType, ShouldEqual, rewriteLowercase)
+		So(rules[4].ruleType, ShouldEqual, rewriteAllow)
+	})
+
+	Convey("Given invalid rules, should return error", t, func() {
+		invalidRules := []string{
+			"type = drop\npattern = .*",
+			"[no_pattern]\ntype = drop",
+			"[invalid_pattern]\ntype = drop\npattern = (",
+			"[no_type]\npattern = .*",
+			"[unknown_type]\ntype = rename\npattern = .*",
+			"[no_replacement]\ntype = replace\npattern = .*",
+			"[invalid.name]\ntype = drop\npattern = .*",
+			"[twice]\ntype = drop\npattern = a\n[twice]\ntype = drop\npattern = b",
+		}
+		for _, rules := range invalidRules {
+			_, err := parseRewriteRules(strings.NewReader(rules))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestRewriteRules(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Filter")
+
+	database.EXPECT().GetPatterns().Return([]string{"hosts.*.cpu", "services.api.rps"}, nil)
+	patternsStorage, err := NewPatternStorage(database, metrics.ConfigureFilterMetrics("test"), logger)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = patternsStorage.LoadRewriteRules(strings.NewReader(testRewriteRules)); err != nil {
+		t.Fatal(err)
+	}
+
+	Convey("Given dirty metric names, should rewrite them before matching", t, func() {
+		matchedMetric := patternsStorage.ProcessIncomingMetric([]byte("hosts.web 01.cpu 12 1234567890"))
+		So(matchedMetric, ShouldNotBeNil)
+		So(matchedMetric.Metric, ShouldEqual, "hosts.web_01.cpu")
+		So(matchedMetric.Patterns, ShouldResemble, []string{"hosts.*.cpu"})
+
+		matchedMetric = patternsStorage.ProcessIncomingMetric([]byte("services.api.rps.1a2b3c4d 12 1234567890"))
+		So(matchedMetric, ShouldNotBeNil)
+		So(matchedMetric.Metric, ShouldEqual, "services.api.rps")
+
+		matchedMetric = patternsStorage.ProcessIncomingMetric([]byte("Hosts.Web.cpu 12 1234567890"))
+		So(matchedMetric, ShouldNotBeNil)
+		So(matchedMetric.Metric, ShouldEqual, "hosts.web.cpu")
+	})
+
+	Convey("Given dropped and not allowed metrics, should not match them", t, func() {
+		So(patternsStorage.ProcessIncomingMetric([]byte("tmp.hosts.web.cpu 12 1234567890")), ShouldBeNil)
+		So(patternsStorage.ProcessIncomingMetric([]byte("unknown.web.cpu 12 1234567890")), ShouldBeNil)
+	})
+
+	Convey("Should count metrics affected by every rule", t, func() {
+		expected := map[string]int64{
+			"drop_tmp":      1,
+			"spaces":        1,
+			"random_suffix": 1,
+			"hosts":         1,
+			"allow_known":   1,
+		}
+		for name, count := range expected {
+			meter, found := patternsStorage.metrics.RewriteRulesMetrics.GetMetric(name)
+			So(found, ShouldBeTrue)
+			So(meter.Count(), ShouldEqual, count)
+		}
+	})
+
+	Convey("Given line without value and timestamp, should not rewrite it", t, func() {
+		So(patternsStorage.rewriteMetricLine([]byte("hosts.web 01.cpu")), ShouldResemble, []byte("hosts.web 01.cpu"))
+		So(patternsStorage.ProcessIncomingMetric([]byte("hosts.web.cpu")), ShouldBeNil)
+	})
+}
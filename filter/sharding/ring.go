@@ -0,0 +1,59 @@
+package sharding
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"github.com/moira-alert/moira"
+)
+
+// replicasCount is number of points of every shard on ring, more points give more even distribution of metrics
+const replicasCount = 128
+
+// Ring is consistent hashing ring of filter shards
+// Adding or removing shard moves only metrics of neighbour ring points, so other shards keep their metrics
+// and their pattern trees and dedup caches stay warm
+type Ring struct {
+	hashes []uint32
+	shards map[uint32]moira.FilterShard
+}
+
+// NewRing creates consistent hashing ring of given shards
+func NewRing(shards []moira.FilterShard) *Ring {
+	ring := &Ring{
+		hashes: make([]uint32, 0, len(shards)*replicasCount),
+		shards: make(map[uint32]moira.FilterShard, len(shards)*replicasCount),
+	}
+	for _, shard := range shards {
+		for i := 0; i < replicasCount; i++ {
+			hash := hashKey(shard.ID + "-" + strconv.Itoa(i))
+			if _, ok := ring.shards[hash]; ok {
+				continue
+			}
+			ring.shards[hash] = shard
+			ring.hashes = append(ring.hashes, hash)
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool { return ring.hashes[i] < ring.hashes[j] })
+	return ring
+}
+
+// GetShard returns shard which metric belongs to, false if ring is empty
+func (ring *Ring) GetShard(metric string) (moira.FilterShard, bool) {
+	if len(ring.hashes) == 0 {
+		return moira.FilterShard{}, false
+	}
+	hash := hashKey(metric)
+	index := sort.Search(len(ring.hashes), func(i int) bool { return ring.hashes[i] >= hash })
+	if index == len(ring.hashes) {
+		index = 0
+	}
+	return ring.shards[ring.hashes[index]], true
+}
+
+func hashKey(key string) uint32 {
+	hash := fnv.New32a()
+	hash.Write([]byte(key))
+	return hash.Sum32()
+}
@@ -0,0 +1,54 @@
+package sharding
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestRing(t *testing.T) {
+	shards := []moira.FilterShard{
+		{ID: "filter-1", Address: "filter-1:2003"},
+		{ID: "filter-2", Address: "filter-2:2003"},
+		{ID: "filter-3", Address: "filter-3:2003"},
+	}
+	metrics := make([]string, 0, 3000)
+	for i := 0; i < 3000; i++ {
+		metrics = append(metrics, fmt.Sprintf("Servers.host%d.cpu.user", i))
+	}
+
+	Convey("Empty ring, should not return shard", t, func() {
+		_, ok := NewRing(nil).GetShard("Servers.host.cpu.user")
+		So(ok, ShouldBeFalse)
+	})
+
+	Convey("Given shards, should route every metric to the same shard and use all shards", t, func() {
+		ring := NewRing(shards)
+		counts := make(map[string]int)
+		for _, metric := range metrics {
+			shard, ok := ring.GetShard(metric)
+			So(ok, ShouldBeTrue)
+			sameShard, _ := NewRing([]moira.FilterShard{shards[2], shards[0], shards[1]}).GetShard(metric)
+			So(sameShard, ShouldResemble, shard)
+			counts[shard.ID]++
+		}
+		for _, shard := range shards {
+			So(counts[shard.ID], ShouldBeGreaterThan, len(metrics)/6)
+		}
+	})
+
+	Convey("Given shard is removed, should move only metrics of removed shard", t, func() {
+		ring := NewRing(shards)
+		reducedRing := NewRing(shards[:2])
+		for _, metric := range metrics {
+			shard, _ := ring.GetShard(metric)
+			newShard, _ := reducedRing.GetShard(metric)
+			if shard.ID != "filter-3" {
+				So(newShard, ShouldResemble, shard)
+			}
+		}
+	})
+}
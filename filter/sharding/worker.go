@@ -0,0 +1,69 @@
+package sharding
+
+import (
+	"fmt"
+	"time"
+
+	"gopkg.in/tomb.v2"
+
+	"github.com/moira-alert/moira"
+)
+
+// RegistrationTTL is time after which registration of stopped or hung filter shard expires
+const RegistrationTTL = time.Second * 30
+
+// RegistrationWorker keeps filter shard registered in database, so relays route metrics to it
+type RegistrationWorker struct {
+	database moira.Database
+	logger   moira.Logger
+	shard    moira.FilterShard
+	tomb     tomb.Tomb
+}
+
+// NewRegistrationWorker creates new RegistrationWorker
+func NewRegistrationWorker(database moira.Database, logger moira.Logger, shard moira.FilterShard) *RegistrationWorker {
+	return &RegistrationWorker{
+		database: database,
+		logger:   logger,
+		shard:    shard,
+	}
+}
+
+// Start registers filter shard and renews registration every third of RegistrationTTL
+// Start fails if shard with same id is already registered by other filter with different address
+func (worker *RegistrationWorker) Start() error {
+	if worker.shard.ID == "" || worker.shard.Address == "" {
+		return fmt.Errorf("Filter shard id and address must be set")
+	}
+	if !worker.database.RegisterFilterShardIfAlreadyNot(worker.shard, RegistrationTTL) {
+		return fmt.Errorf("Filter shard %s is already registered", worker.shard.ID)
+	}
+	worker.tomb.Go(func() error {
+		renewTicker := time.NewTicker(RegistrationTTL / 3)
+		defer renewTicker.Stop()
+		for {
+			select {
+			case <-worker.tomb.Dying():
+				worker.database.DeregisterFilterShard(worker.shard)
+				worker.logger.Info("Moira Filter shard registration stopped")
+				return nil
+			case <-renewTicker.C:
+				if worker.database.RenewFilterShardRegistration(worker.shard, RegistrationTTL) {
+					continue
+				}
+				worker.logger.Warningf("Failed to renew filter shard %s registration, try to register again", worker.shard.ID)
+				if !worker.database.RegisterFilterShardIfAlreadyNot(worker.shard, RegistrationTTL) {
+					worker.logger.Errorf("Failed to register filter shard %s", worker.shard.ID)
+				}
+			}
+		}
+	})
+	worker.logger.Infof("Moira Filter shard %s registered with address %s", worker.shard.ID, worker.shard.Address)
+	return nil
+}
+
+// Stop stops registration renewal and removes filter shard registration
+func (worker *RegistrationWorker) Stop() error {
+	worker.tomb.Kill(nil)
+	return worker.tomb.Wait()
+}
@@ -0,0 +1,39 @@
+package sharding
+
+import (
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestRegistrationWorker(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	database := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Filter")
+	shard := moira.FilterShard{ID: "filter-1", Address: "filter-1:2003"}
+
+	Convey("Shard without address, should not register", t, func() {
+		worker := NewRegistrationWorker(database, logger, moira.FilterShard{ID: "filter-1"})
+		So(worker.Start(), ShouldNotBeNil)
+	})
+
+	Convey("Shard is registered by other filter, should return error", t, func() {
+		database.EXPECT().RegisterFilterShardIfAlreadyNot(shard, RegistrationTTL).Return(false)
+		worker := NewRegistrationWorker(database, logger, shard)
+		So(worker.Start(), ShouldNotBeNil)
+	})
+
+	Convey("Shard is registered, should deregister on stop", t, func() {
+		database.EXPECT().RegisterFilterShardIfAlreadyNot(shard, RegistrationTTL).Return(true)
+		database.EXPECT().DeregisterFilterShard(shard).Return(true)
+		worker := NewRegistrationWorker(database, logger, shard)
+		So(worker.Start(), ShouldBeNil)
+		So(worker.Stop(), ShouldBeNil)
+	})
+}
@@ -0,0 +1,159 @@
+package filter
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/moira-alert/moira"
+)
+
+// configSection is single [section] of carbon ini-like config file
+type configSection struct {
+	name    string
+	options map[string]string
+}
+
+// parseConfigSections reads carbon config file sections, options of section may be in any order
+func parseConfigSections(reader io.Reader) ([]configSection, error) {
+	scanner := bufio.NewScanner(reader)
+	sections := make([]configSection, 0)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			sections = append(sections, configSection{
+				name:    strings.TrimSpace(line[1 : len(line)-1]),
+				options: make(map[string]string),
+			})
+			continue
+		}
+		index := strings.Index(line, "=")
+		if index < 1 || len(sections) == 0 {
+			return nil, fmt.Errorf("Invalid line %d: '%s'", lineNumber, line)
+		}
+		sections[len(sections)-1].options[strings.TrimSpace(line[:index])] = strings.TrimSpace(line[index+1:])
+	}
+	return sections, scanner.Err()
+}
+
+func (section configSection) getPattern() (*regexp.Regexp, error) {
+	rawPattern, ok := section.options["pattern"]
+	if !ok {
+		return nil, fmt.Errorf("Section [%s] has no pattern", section.name)
+	}
+	pattern, err := regexp.Compile(rawPattern)
+	if err != nil {
+		return nil, fmt.Errorf("Section [%s] has invalid pattern: %s", section.name, err.Error())
+	}
+	return pattern, nil
+}
+
+// parseStorageSchemas parses storage-schemas.conf sections with pattern and retentions
+func parseStorageSchemas(reader io.Reader) ([]retentionMatcher, error) {
+	sections, err := parseConfigSections(reader)
+	if err != nil {
+		return nil, err
+	}
+	matchers := make([]retentionMatcher, 0, len(sections))
+	for _, section := range sections {
+		pattern, err := section.getPattern()
+		if err != nil {
+			return nil, err
+		}
+		archives, err := parseRetentions(section.options["retentions"])
+		if err != nil {
+			return nil, fmt.Errorf("Section [%s] has invalid retentions: %s", section.name, err.Error())
+		}
+		matchers = append(matchers, retentionMatcher{
+			pattern:   pattern,
+			retention: int(archives[0].Precision),
+			archives:  archives,
+		})
+	}
+	return matchers, nil
+}
+
+// parseStorageAggregations parses storage-aggregation.conf sections with pattern, xFilesFactor and aggregationMethod
+func parseStorageAggregations(reader io.Reader) ([]aggregationMatcher, error) {
+	sections, err := parseConfigSections(reader)
+	if err != nil {
+		return nil, err
+	}
+	matchers := make([]aggregationMatcher, 0, len(sections))
+	for _, section := range sections {
+		pattern, err := section.getPattern()
+		if err != nil {
+			return nil, err
+		}
+		matcher := aggregationMatcher{
+			pattern:      pattern,
+			method:       moira.AggregationAverage,
+			xFilesFactor: moira.DefaultXFilesFactor,
+		}
+		if rawFactor, ok := section.options["xFilesFactor"]; ok {
+			matcher.xFilesFactor, err = strconv.ParseFloat(rawFactor, 64)
+			if err != nil || matcher.xFilesFactor < 0 || matcher.xFilesFactor > 1 {
+				return nil, fmt.Errorf("Section [%s] has invalid xFilesFactor '%s'", section.name, rawFactor)
+			}
+		}
+		if method, ok := section.options["aggregationMethod"]; ok {
+			if err := moira.ValidateAggregationMethod(method); err != nil {
+				return nil, fmt.Errorf("Section [%s] has invalid aggregationMethod: %s", section.name, err.Error())
+			}
+			matcher.method = method
+		}
+		matchers = append(matchers, matcher)
+	}
+	return matchers, nil
+}
+
+// parseRetentions parses comma-separated archives like 60s:2d,10m:30d or 60:1440 where 1440 is number of points
+// Archives must be ordered by precision, every precision must be divisible by previous and cover longer period
+func parseRetentions(rawRetentions string) ([]moira.RetentionArchive, error) {
+	if rawRetentions == "" {
+		return nil, fmt.Errorf("no retentions")
+	}
+	archives := make([]moira.RetentionArchive, 0)
+	for _, rawArchive := range strings.Split(rawRetentions, ",") {
+		parts := strings.Split(strings.TrimSpace(rawArchive), ":")
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid archive '%s'", rawArchive)
+		}
+		precision, err := rawRetentionToSeconds(parts[0])
+		if err != nil || precision <= 0 {
+			return nil, fmt.Errorf("invalid precision of archive '%s'", rawArchive)
+		}
+		archive := moira.RetentionArchive{Precision: int64(precision)}
+		if points, err := strconv.Atoi(parts[1]); err == nil {
+			archive.Retention = int64(points * precision)
+		} else {
+			retention, err := rawRetentionToSeconds(parts[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid retention of archive '%s'", rawArchive)
+			}
+			archive.Retention = int64(retention)
+		}
+		if archive.Retention < archive.Precision {
+			return nil, fmt.Errorf("archive '%s' retention is less than precision", rawArchive)
+		}
+		if len(archives) > 0 {
+			previous := archives[len(archives)-1]
+			if archive.Precision <= previous.Precision || archive.Precision%previous.Precision != 0 {
+				return nil, fmt.Errorf("archive '%s' precision must be greater than and divisible by previous archive precision", rawArchive)
+			}
+			if archive.Retention <= previous.Retention {
+				return nil, fmt.Errorf("archive '%s' must cover longer period than previous archive", rawArchive)
+			}
+		}
+		archives = append(archives, archive)
+	}
+	return archives, nil
+}
@@ -0,0 +1,91 @@
+package filter
+
+import (
+	"strings"
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+)
+
+func TestParseStorageSchemas(t *testing.T) {
+	Convey("Given schemas with options in any order and several archives, should parse all archives", t, func() {
+		matchers, err := parseStorageSchemas(strings.NewReader(`
+			; comment
+			[multiple]
+			retentions = 10s:6h, 1min:7d ,10min:5y
+			pattern = ^multiple\.
+
+			[points]
+			pattern = points$
+			retentions = 60:1440,300:2016
+
+			[units]
+			pattern = .*
+			retentions = 30seconds:2hours,1h:1w
+			`))
+		So(err, ShouldBeNil)
+		So(matchers, ShouldHaveLength, 3)
+		So(matchers[0].pattern.String(), ShouldEqual, `^multiple\.`)
+		So(matchers[0].retention, ShouldEqual, 10)
+		So(matchers[0].archives, ShouldResemble, []moira.RetentionArchive{{Precision: 10, Retention: 21600}, {Precision: 60, Retention: 604800}, {Precision: 600, Retention: 157680000}})
+		So(matchers[1].archives, ShouldResemble, []moira.RetentionArchive{{Precision: 60, Retention: 86400}, {Precision: 300, Retention: 604800}})
+		So(matchers[2].archives, ShouldResemble, []moira.RetentionArchive{{Precision: 30, Retention: 7200}, {Precision: 3600, Retention: 604800}})
+	})
+
+	Convey("Given invalid schemas, should return error", t, func() {
+		invalidSchemas := []string{
+			"pattern = .*\nretentions = 60:1d",
+			"[no_pattern]\nretentions = 60:1d",
+			"[invalid_pattern]\npattern = (\nretentions = 60:1d",
+			"[no_retentions]\npattern = .*",
+			"[invalid_archive]\npattern = .*\nretentions = 60",
+			"[invalid_unit]\npattern = .*\nretentions = 60:1fortnight",
+			"[not_divisible]\npattern = .*\nretentions = 60:1d,90:7d",
+			"[shorter_period]\npattern = .*\nretentions = 60:7d,300:1d",
+			"[invalid_line]\npattern = .*\nretentions",
+		}
+		for _, schema := range invalidSchemas {
+			_, err := parseStorageSchemas(strings.NewReader(schema))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestParseStorageAggregations(t *testing.T) {
+	Convey("Given aggregations, should parse methods and xFilesFactor with defaults", t, func() {
+		matchers, err := parseStorageAggregations(strings.NewReader(`
+			[min]
+			aggregationMethod = min
+			pattern = \.lower$
+			xFilesFactor = 0.1
+
+			[sum]
+			pattern = \.count$
+			aggregationMethod = sum
+
+			[default_average]
+			pattern = .*
+			`))
+		So(err, ShouldBeNil)
+		So(matchers, ShouldHaveLength, 3)
+		So(matchers[0].method, ShouldEqual, moira.AggregationMin)
+		So(matchers[0].xFilesFactor, ShouldEqual, 0.1)
+		So(matchers[1].method, ShouldEqual, moira.AggregationSum)
+		So(matchers[1].xFilesFactor, ShouldEqual, moira.DefaultXFilesFactor)
+		So(matchers[2].method, ShouldEqual, moira.AggregationAverage)
+	})
+
+	Convey("Given invalid aggregations, should return error", t, func() {
+		invalidAggregations := []string{
+			"[no_pattern]\naggregationMethod = sum",
+			"[invalid_method]\npattern = .*\naggregationMethod = median",
+			"[invalid_factor]\npattern = .*\nxFilesFactor = 2",
+		}
+		for _, aggregation := range invalidAggregations {
+			_, err := parseStorageAggregations(strings.NewReader(aggregation))
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
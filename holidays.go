@@ -0,0 +1,180 @@
+package moira
+
+import (
+	"bufio"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Holiday calendar actions
+const (
+	HolidayActionSuppress = "suppress"
+	HolidayActionReroute  = "reroute"
+)
+
+const (
+	holidayDateFormat  = "2006-01-02"
+	icalDateFormat     = "20060102"
+	maxHolidayDuration = 366
+)
+
+// HolidaysData represent subscription holiday calendar
+// Notifications on listed dates are delayed to the first allowed non-holiday time (suppress)
+// or delivered to the fallback contact instead of subscription contacts (reroute)
+type HolidaysData struct {
+	Dates   []string `json:"dates"`
+	Action  string   `json:"action,omitempty"`
+	Contact string   `json:"contact,omitempty"`
+}
+
+// Validate checks holiday dates format and action settings
+func (holidays *HolidaysData) Validate() error {
+	if holidays == nil {
+		return nil
+	}
+	for _, date := range holidays.Dates {
+		if _, err := time.Parse(holidayDateFormat, date); err != nil {
+			return fmt.Errorf("Invalid holiday date '%s', must be in format YYYY-MM-DD", date)
+		}
+	}
+	switch holidays.Action {
+	case "", HolidayActionSuppress:
+	case HolidayActionReroute:
+		if holidays.Contact == "" {
+			return fmt.Errorf("Holidays reroute action requires contact")
+		}
+	default:
+		return fmt.Errorf("Unknown holidays action '%s'", holidays.Action)
+	}
+	return nil
+}
+
+// IsReroute returns true if notifications on holidays must be sent to fallback contact
+func (holidays *HolidaysData) IsReroute() bool {
+	return holidays != nil && holidays.Action == HolidayActionReroute
+}
+
+// IsHoliday checks if the time falls on holiday date, tzOffset is timezone offset in minutes like in ScheduleData
+func (holidays *HolidaysData) IsHoliday(t time.Time, tzOffset int64) bool {
+	if holidays == nil {
+		return false
+	}
+	localDate := t.Add(-time.Duration(tzOffset) * time.Minute).UTC().Format(holidayDateFormat)
+	for _, date := range holidays.Dates {
+		if date == localDate {
+			return true
+		}
+	}
+	return false
+}
+
+// AddDates merges new dates into holiday calendar, keeping dates sorted and unique
+func (holidays *HolidaysData) AddDates(dates []string) {
+	unique := make(map[string]bool)
+	for _, date := range append(holidays.Dates, dates...) {
+		unique[date] = true
+	}
+	holidays.Dates = make([]string, 0, len(unique))
+	for date := range unique {
+		holidays.Dates = append(holidays.Dates, date)
+	}
+	sort.Strings(holidays.Dates)
+}
+
+// ParseICalHolidays extracts holiday dates from VEVENT entries of iCalendar data
+// DTEND of all-day events is exclusive, recurrence rules are not expanded
+func ParseICalHolidays(data string) ([]string, error) {
+	dates := make([]string, 0)
+	var (
+		inEvent      bool
+		start, end   string
+		endInclusive bool
+	)
+	for _, line := range unfoldICalLines(data) {
+		name, value := splitICalLine(line)
+		switch {
+		case name == "BEGIN" && value == "VEVENT":
+			inEvent, start, end, endInclusive = true, "", "", false
+		case name == "END" && value == "VEVENT":
+			if !inEvent {
+				continue
+			}
+			inEvent = false
+			eventDates, err := expandICalEvent(start, end, endInclusive)
+			if err != nil {
+				return nil, err
+			}
+			dates = append(dates, eventDates...)
+		case inEvent && strings.HasPrefix(name, "DTSTART"):
+			start = value
+		case inEvent && strings.HasPrefix(name, "DTEND"):
+			end = value
+			// date-time end includes its own day unless it is exactly the midnight
+			endInclusive = len(value) > len(icalDateFormat) && !strings.HasPrefix(value[len(icalDateFormat):], "T000000")
+		}
+	}
+	if inEvent {
+		return nil, fmt.Errorf("Invalid iCal data: VEVENT is not closed")
+	}
+	return dates, nil
+}
+
+func expandICalEvent(start, end string, endInclusive bool) ([]string, error) {
+	startDate, err := parseICalDate(start)
+	if err != nil {
+		return nil, err
+	}
+	if end == "" {
+		return []string{startDate.Format(holidayDateFormat)}, nil
+	}
+	endDate, err := parseICalDate(end)
+	if err != nil {
+		return nil, err
+	}
+	if endInclusive || !endDate.After(startDate) {
+		endDate = endDate.AddDate(0, 0, 1)
+	}
+	dates := make([]string, 0)
+	for day := startDate; day.Before(endDate); day = day.AddDate(0, 0, 1) {
+		if len(dates) >= maxHolidayDuration {
+			return nil, fmt.Errorf("Invalid iCal data: event from %s to %s is too long", start, end)
+		}
+		dates = append(dates, day.Format(holidayDateFormat))
+	}
+	return dates, nil
+}
+
+func parseICalDate(value string) (time.Time, error) {
+	if len(value) < len(icalDateFormat) {
+		return time.Time{}, fmt.Errorf("Invalid iCal date '%s'", value)
+	}
+	date, err := time.Parse(icalDateFormat, value[:len(icalDateFormat)])
+	if err != nil {
+		return time.Time{}, fmt.Errorf("Invalid iCal date '%s'", value)
+	}
+	return date, nil
+}
+
+func unfoldICalLines(data string) []string {
+	lines := make([]string, 0)
+	scanner := bufio.NewScanner(strings.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if len(lines) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+func splitICalLine(line string) (string, string) {
+	parts := strings.SplitN(line, ":", 2)
+	if len(parts) != 2 {
+		return strings.ToUpper(strings.TrimSpace(line)), ""
+	}
+	return strings.ToUpper(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,103 @@
+package moira
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestHolidaysValidate(t *testing.T) {
+	Convey("No holidays", t, func() {
+		var holidays *HolidaysData
+		So(holidays.Validate(), ShouldBeNil)
+	})
+
+	Convey("Valid dates with default action", t, func() {
+		holidays := &HolidaysData{Dates: []string{"2018-01-01", "2018-12-31"}}
+		So(holidays.Validate(), ShouldBeNil)
+	})
+
+	Convey("Invalid date", t, func() {
+		holidays := &HolidaysData{Dates: []string{"01.01.2018"}}
+		So(holidays.Validate(), ShouldNotBeNil)
+	})
+
+	Convey("Reroute without contact", t, func() {
+		holidays := &HolidaysData{Action: HolidayActionReroute}
+		So(holidays.Validate(), ShouldNotBeNil)
+		holidays.Contact = "contact-id"
+		So(holidays.Validate(), ShouldBeNil)
+	})
+
+	Convey("Unknown action", t, func() {
+		holidays := &HolidaysData{Action: "drop"}
+		So(holidays.Validate(), ShouldNotBeNil)
+	})
+}
+
+func TestIsHoliday(t *testing.T) {
+	holidays := &HolidaysData{Dates: []string{"1970-01-05"}}
+
+	// 367980 - 01/05/1970 6:13am (UTC) Mon
+	Convey("No holidays", t, func() {
+		var noHolidays *HolidaysData
+		So(noHolidays.IsHoliday(time.Unix(367980, 0), 0), ShouldBeFalse)
+	})
+
+	Convey("Holiday in UTC", t, func() {
+		So(holidays.IsHoliday(time.Unix(367980, 0), 0), ShouldBeTrue)
+		So(holidays.IsHoliday(time.Unix(367980+86400, 0), 0), ShouldBeFalse)
+	})
+
+	Convey("Holiday with timezone offset", t, func() {
+		// 01/04/1970 11:00pm (UTC) is 01/05/1970 4:00am in GMT +5
+		So(holidays.IsHoliday(time.Unix(342000, 0), -300), ShouldBeTrue)
+		So(holidays.IsHoliday(time.Unix(342000, 0), 0), ShouldBeFalse)
+	})
+}
+
+func TestAddDates(t *testing.T) {
+	Convey("Dates are merged, sorted and unique", t, func() {
+		holidays := &HolidaysData{Dates: []string{"2018-05-09", "2018-01-01"}}
+		holidays.AddDates([]string{"2018-01-02", "2018-01-01"})
+		So(holidays.Dates, ShouldResemble, []string{"2018-01-01", "2018-01-02", "2018-05-09"})
+	})
+}
+
+func TestParseICalHolidays(t *testing.T) {
+	Convey("All-day events, DTEND is exclusive", t, func() {
+		data := "BEGIN:VCALENDAR\r\nVERSION:2.0\r\nBEGIN:VEVENT\r\nSUMMARY:New Year\r\nDTSTART;VALUE=DATE:20180101\r\nDTEND;VALUE=DATE:20180103\r\nEND:VEVENT\r\nBEGIN:VEVENT\r\nDTSTART;VALUE=DATE:20180509\r\nEND:VEVENT\r\nEND:VCALENDAR\r\n"
+		dates, err := ParseICalHolidays(data)
+		So(err, ShouldBeNil)
+		So(dates, ShouldResemble, []string{"2018-01-01", "2018-01-02", "2018-05-09"})
+	})
+
+	Convey("Date-time events include end day", t, func() {
+		data := "BEGIN:VEVENT\nDTSTART:20180101T090000Z\nDTEND:20180102T180000Z\nEND:VEVENT\n"
+		dates, err := ParseICalHolidays(data)
+		So(err, ShouldBeNil)
+		So(dates, ShouldResemble, []string{"2018-01-01", "2018-01-02"})
+	})
+
+	Convey("Folded lines", t, func() {
+		data := "BEGIN:VEVENT\r\nDTSTART;VALUE=\r\n DATE:20180101\r\nEND:VEVENT\r\n"
+		dates, err := ParseICalHolidays(data)
+		So(err, ShouldBeNil)
+		So(dates, ShouldResemble, []string{"2018-01-01"})
+	})
+
+	Convey("Invalid date", t, func() {
+		_, err := ParseICalHolidays("BEGIN:VEVENT\nDTSTART:2018\nEND:VEVENT\n")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Not closed event", t, func() {
+		_, err := ParseICalHolidays("BEGIN:VEVENT\nDTSTART:20180101\n")
+		So(err, ShouldNotBeNil)
+	})
+
+	Convey("Too long event", t, func() {
+		_, err := ParseICalHolidays("BEGIN:VEVENT\nDTSTART:20180101\nDTEND:20200101\nEND:VEVENT\n")
+		So(err, ShouldNotBeNil)
+	})
+}
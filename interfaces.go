@@ -35,13 +35,27 @@ type Database interface {
 	GetPatternTriggerIDs(pattern string) ([]string, error)
 	RemovePatternTriggerIDs(pattern string) error
 
+	// Trigger search index storing
+	SearchTriggerIDs(query TriggerSearchQuery) ([]string, error)
+	RebuildTriggerSearchIndex() error
+
+	// Trigger history storing
+	AddTriggerVersion(triggerID string, version *TriggerVersion) error
+	GetTriggerVersions(triggerID string) ([]*TriggerVersion, error)
+	GetTriggerVersion(triggerID string, version int64) (TriggerVersion, error)
+
 	// Throttling
 	GetTriggerThrottling(triggerID string) (time.Time, time.Time)
 	SetTriggerThrottling(triggerID string, next time.Time) error
 	DeleteTriggerThrottling(triggerID string) error
+	GetSubscriptionThrottling(subscriptionID, triggerID string) time.Time
+	SetSubscriptionThrottling(subscriptionID, triggerID string, next time.Time) error
 
 	// NotificationEvent storing
 	GetNotificationEvents(triggerID string, start, size int64) ([]*NotificationEvent, error)
+	GetNotificationEventsByTime(from, to, start, size int64) ([]*NotificationEvent, error)
+	GetNotificationEventCountByTime(from, to int64) (int64, error)
+	FillNotificationEventsTimeline() error
 	PushNotificationEvent(event *NotificationEvent, ui bool) error
 	GetNotificationEventCount(triggerID string, from int64) int64
 	FetchNotificationEvent() (NotificationEvent, error)
@@ -70,6 +84,15 @@ type Database interface {
 	AddNotification(notification *ScheduledNotification) error
 	AddNotifications(notification []*ScheduledNotification, timestamp int64) error
 
+	// DeliveryData storing
+	AddDelivery(delivery *DeliveryData) error
+	GetContactDeliveries(contactID string, start, size int64) ([]*DeliveryData, int64, error)
+	GetTriggerDeliveries(triggerID string, start, size int64) ([]*DeliveryData, int64, error)
+
+	// Trigger acknowledgement storing
+	SetTriggerAck(triggerID string, timestamp int64) error
+	GetTriggerAck(triggerID string) (int64, error)
+
 	// Patterns and metrics storing
 	GetPatterns() ([]string, error)
 	AddPatternMetric(pattern, metric string) error
@@ -77,12 +100,19 @@ type Database interface {
 	RemovePattern(pattern string) error
 	RemovePatternsMetrics(pattern []string) error
 	RemovePatternWithMetrics(pattern string) error
+	GetSeriesTagValues(tag string) ([]string, error)
+	GetSeriesByTag(tag string, values []string) ([]string, error)
+	RemoveSeriesTags(metric string) error
 
 	SubscribeMetricEvents(tomb *tomb.Tomb) (<-chan *MetricEvent, error)
+	SubscribePatternEvents(tomb *tomb.Tomb) (<-chan *PatternEvent, error)
+	SubscribeStreamEvents(tomb *tomb.Tomb) (<-chan *StreamEvent, error)
 	SaveMetrics(buffer map[string]*MatchedMetric) error
 	GetMetricRetention(metric string) (int64, error)
 	GetMetricsValues(metrics []string, from int64, until int64) (map[string][]*MetricValue, error)
 	RemoveMetricValues(metric string, toTime int64) error
+	DownsampleMetricValues(metric string, schema *MetricSchema, now int64) error
+	GetMetricStorageRetention(metric string) (int64, error)
 
 	// TriggerCheckLock storing
 	AcquireTriggerCheckLock(triggerID string, timeout int) error
@@ -97,6 +127,36 @@ type Database interface {
 	RenewBotRegistration(messenger string) bool
 	DeregisterBots()
 	DeregisterBot(messenger string) bool
+
+	// API tokens storing
+	GetAPIToken(tokenID string) (APIToken, error)
+	GetAPITokens(tokenIDs []string) ([]*APIToken, error)
+	GetAPITokenByHash(hash string) (APIToken, error)
+	GetUserAPITokenIDs(login string) ([]string, error)
+	SaveAPIToken(token *APIToken) error
+	RemoveAPIToken(tokenID string) error
+
+	// Teams storing
+	GetTeam(teamID string) (Team, error)
+	GetTeams(teamIDs []string) ([]*Team, error)
+	GetUserTeamIDs(login string) ([]string, error)
+	SaveTeam(team *Team) error
+	RemoveTeam(teamID string) error
+	GetTeamTriggerIDs(teamID string) ([]string, error)
+	GetTeamContactIDs(teamID string) ([]string, error)
+	GetTeamSubscriptionIDs(teamID string) ([]string, error)
+
+	// Maintenance windows storing
+	GetMaintenanceWindow(windowID string) (MaintenanceWindow, error)
+	GetMaintenanceWindows() ([]*MaintenanceWindow, error)
+	SaveMaintenanceWindow(window *MaintenanceWindow) error
+	RemoveMaintenanceWindow(windowID string) error
+
+	// Filter shards storing
+	RegisterFilterShardIfAlreadyNot(shard FilterShard, ttl time.Duration) bool
+	RenewFilterShardRegistration(shard FilterShard, ttl time.Duration) bool
+	DeregisterFilterShard(shard FilterShard) bool
+	GetFilterShards() ([]FilterShard, error)
 }
 
 // Logger implements logger abstraction
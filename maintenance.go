@@ -0,0 +1,110 @@
+package moira
+
+import (
+	"fmt"
+)
+
+// Maintenance window recurrences
+const (
+	MaintenanceRecurrenceDaily  = "daily"
+	MaintenanceRecurrenceWeekly = "weekly"
+)
+
+var maintenanceRecurrencePeriods = map[string]int64{
+	"":                          0,
+	MaintenanceRecurrenceDaily:  24 * 3600,
+	MaintenanceRecurrenceWeekly: 7 * 24 * 3600,
+}
+
+// MaintenanceWindow suppresses events of triggers listed by id or having all window tags from start to end timestamp
+// Global window suppresses events of all triggers
+// Recurring window is repeated daily or weekly since start until recurrence end, repeats are not shifted by daylight saving time
+type MaintenanceWindow struct {
+	ID            string   `json:"id"`
+	Name          string   `json:"name"`
+	TriggerIDs    []string `json:"trigger_ids,omitempty"`
+	Tags          []string `json:"tags,omitempty"`
+	Global        bool     `json:"global,omitempty"`
+	Start         int64    `json:"start"`
+	End           int64    `json:"end"`
+	Recurrence    string   `json:"recurrence,omitempty"`
+	RecurrenceEnd int64    `json:"recurrence_end,omitempty"`
+	User          string   `json:"user"`
+	TeamID        string   `json:"team_id,omitempty"`
+}
+
+// Validate checks window selector, time range and recurrence settings
+func (window *MaintenanceWindow) Validate() error {
+	if window.Global && (len(window.TriggerIDs) != 0 || len(window.Tags) != 0) {
+		return fmt.Errorf("Global maintenance window can not have trigger ids or tags")
+	}
+	if !window.Global && len(window.TriggerIDs) == 0 && len(window.Tags) == 0 {
+		return fmt.Errorf("Maintenance window requires trigger ids, tags or global flag")
+	}
+	for _, tag := range window.Tags {
+		if tag == "" {
+			return fmt.Errorf("Maintenance window tag can not be empty")
+		}
+	}
+	if window.Start <= 0 || window.End <= window.Start {
+		return fmt.Errorf("Maintenance window end must be greater than start")
+	}
+	period, ok := maintenanceRecurrencePeriods[window.Recurrence]
+	if !ok {
+		return fmt.Errorf("Unknown maintenance window recurrence '%s'", window.Recurrence)
+	}
+	if period == 0 {
+		if window.RecurrenceEnd != 0 {
+			return fmt.Errorf("Maintenance window recurrence end requires recurrence")
+		}
+		return nil
+	}
+	if window.End-window.Start > period {
+		return fmt.Errorf("Maintenance window is longer than its %s recurrence", window.Recurrence)
+	}
+	if window.RecurrenceEnd != 0 && window.RecurrenceEnd < window.End {
+		return fmt.Errorf("Maintenance window recurrence end must not be less than end")
+	}
+	return nil
+}
+
+// Matches checks that window selects trigger with given id and tags
+func (window *MaintenanceWindow) Matches(triggerID string, tags []string) bool {
+	if window.Global {
+		return true
+	}
+	for _, id := range window.TriggerIDs {
+		if id == triggerID {
+			return true
+		}
+	}
+	if len(window.Tags) == 0 {
+		return false
+	}
+	triggerTags := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		triggerTags[tag] = true
+	}
+	for _, tag := range window.Tags {
+		if !triggerTags[tag] {
+			return false
+		}
+	}
+	return true
+}
+
+// IsActive checks that timestamp falls into window or into one of its repeats
+func (window *MaintenanceWindow) IsActive(timestamp int64) bool {
+	if timestamp < window.Start {
+		return false
+	}
+	period := maintenanceRecurrencePeriods[window.Recurrence]
+	if period == 0 {
+		return timestamp < window.End
+	}
+	repeatStart := timestamp - (timestamp-window.Start)%period
+	if window.RecurrenceEnd != 0 && repeatStart >= window.RecurrenceEnd {
+		return false
+	}
+	return timestamp-repeatStart < window.End-window.Start
+}
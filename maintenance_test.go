@@ -0,0 +1,134 @@
+package moira
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+)
+
+func TestMaintenanceWindowValidate(t *testing.T) {
+	Convey("Valid window", t, func() {
+		window := &MaintenanceWindow{Tags: []string{"dc-eu"}, Start: 7200, End: 14400}
+		So(window.Validate(), ShouldBeNil)
+	})
+
+	Convey("Window without trigger ids and tags", t, func() {
+		window := &MaintenanceWindow{Start: 7200, End: 14400}
+		So(window.Validate(), ShouldNotBeNil)
+		window.TriggerIDs = []string{"trigger-id"}
+		So(window.Validate(), ShouldBeNil)
+	})
+
+	Convey("Global window", t, func() {
+		window := &MaintenanceWindow{Global: true, Start: 7200, End: 14400}
+		So(window.Validate(), ShouldBeNil)
+		window.Tags = []string{"dc-eu"}
+		So(window.Validate(), ShouldNotBeNil)
+		window.Tags, window.TriggerIDs = nil, []string{"trigger-id"}
+		So(window.Validate(), ShouldNotBeNil)
+	})
+
+	Convey("Empty tag", t, func() {
+		window := &MaintenanceWindow{Tags: []string{""}, Start: 7200, End: 14400}
+		So(window.Validate(), ShouldNotBeNil)
+	})
+
+	Convey("End is not greater than start", t, func() {
+		window := &MaintenanceWindow{Tags: []string{"dc-eu"}, Start: 7200, End: 7200}
+		So(window.Validate(), ShouldNotBeNil)
+	})
+
+	Convey("Unknown recurrence", t, func() {
+		window := &MaintenanceWindow{Tags: []string{"dc-eu"}, Start: 7200, End: 14400, Recurrence: "monthly"}
+		So(window.Validate(), ShouldNotBeNil)
+	})
+
+	Convey("Window longer than recurrence period", t, func() {
+		window := &MaintenanceWindow{Tags: []string{"dc-eu"}, Start: 7200, End: 7200 + 25*3600, Recurrence: MaintenanceRecurrenceDaily}
+		So(window.Validate(), ShouldNotBeNil)
+		window.Recurrence = MaintenanceRecurrenceWeekly
+		So(window.Validate(), ShouldBeNil)
+	})
+
+	Convey("Recurrence end", t, func() {
+		window := &MaintenanceWindow{Tags: []string{"dc-eu"}, Start: 7200, End: 14400, RecurrenceEnd: 86400}
+		So(window.Validate(), ShouldNotBeNil)
+		window.Recurrence = MaintenanceRecurrenceDaily
+		So(window.Validate(), ShouldBeNil)
+		window.RecurrenceEnd = 10000
+		So(window.Validate(), ShouldNotBeNil)
+	})
+}
+
+func TestMaintenanceWindowMatches(t *testing.T) {
+	Convey("Trigger ids selector", t, func() {
+		window := &MaintenanceWindow{TriggerIDs: []string{"trigger1", "trigger2"}}
+		So(window.Matches("trigger2", nil), ShouldBeTrue)
+		So(window.Matches("trigger3", []string{"dc-eu"}), ShouldBeFalse)
+	})
+
+	Convey("Tags selector requires all tags", t, func() {
+		window := &MaintenanceWindow{Tags: []string{"dc-eu", "db"}}
+		So(window.Matches("trigger1", []string{"db", "web", "dc-eu"}), ShouldBeTrue)
+		So(window.Matches("trigger1", []string{"dc-eu"}), ShouldBeFalse)
+		So(window.Matches("trigger1", nil), ShouldBeFalse)
+	})
+
+	Convey("Trigger ids and tags selectors", t, func() {
+		window := &MaintenanceWindow{TriggerIDs: []string{"trigger1"}, Tags: []string{"dc-eu"}}
+		So(window.Matches("trigger1", nil), ShouldBeTrue)
+		So(window.Matches("trigger2", []string{"dc-eu"}), ShouldBeTrue)
+		So(window.Matches("trigger2", []string{"dc-us"}), ShouldBeFalse)
+	})
+}
+
+func TestGlobalMaintenanceWindowMatches(t *testing.T) {
+	Convey("Global window selects all triggers", t, func() {
+		window := &MaintenanceWindow{Global: true}
+		So(window.Matches("trigger1", nil), ShouldBeTrue)
+		So(window.Matches("trigger2", []string{"dc-us"}), ShouldBeTrue)
+	})
+}
+
+func TestMaintenanceWindowIsActive(t *testing.T) {
+	// 1970-01-01 is thursday, window is from 02:00 to 04:00
+	const day, week = 24 * 3600, 7 * 24 * 3600
+
+	Convey("Single window", t, func() {
+		window := &MaintenanceWindow{Start: 7200, End: 14400}
+		So(window.IsActive(7199), ShouldBeFalse)
+		So(window.IsActive(7200), ShouldBeTrue)
+		So(window.IsActive(14399), ShouldBeTrue)
+		So(window.IsActive(14400), ShouldBeFalse)
+		So(window.IsActive(day+7200), ShouldBeFalse)
+	})
+
+	Convey("Daily window", t, func() {
+		window := &MaintenanceWindow{Start: 7200, End: 14400, Recurrence: MaintenanceRecurrenceDaily}
+		So(window.IsActive(7199), ShouldBeFalse)
+		So(window.IsActive(day+7200), ShouldBeTrue)
+		So(window.IsActive(10*day+14399), ShouldBeTrue)
+		So(window.IsActive(10*day+14400), ShouldBeFalse)
+		So(window.IsActive(10*day+3600), ShouldBeFalse)
+	})
+
+	Convey("Weekly window", t, func() {
+		window := &MaintenanceWindow{Start: 7200, End: 14400, Recurrence: MaintenanceRecurrenceWeekly}
+		So(window.IsActive(week+10000), ShouldBeTrue)
+		So(window.IsActive(day+10000), ShouldBeFalse)
+		So(window.IsActive(52*week+7200), ShouldBeTrue)
+	})
+
+	Convey("Weekly window over midnight", t, func() {
+		window := &MaintenanceWindow{Start: day - 3600, End: day + 3600, Recurrence: MaintenanceRecurrenceWeekly}
+		So(window.IsActive(week+day-1), ShouldBeTrue)
+		So(window.IsActive(week+day+1), ShouldBeTrue)
+		So(window.IsActive(week+day+3600), ShouldBeFalse)
+	})
+
+	Convey("Recurring window with recurrence end", t, func() {
+		window := &MaintenanceWindow{Start: 7200, End: 14400, Recurrence: MaintenanceRecurrenceDaily, RecurrenceEnd: 2*day + 7200}
+		So(window.IsActive(day+7200), ShouldBeTrue)
+		So(window.IsActive(2*day+7200), ShouldBeFalse)
+		So(window.IsActive(3*day+7200), ShouldBeFalse)
+	})
+}
@@ -0,0 +1,77 @@
+package moira
+
+import (
+	"fmt"
+	"math"
+)
+
+// Carbon aggregation methods used to downsample metric values
+const (
+	AggregationAverage = "average"
+	AggregationSum     = "sum"
+	AggregationMin     = "min"
+	AggregationMax     = "max"
+	AggregationLast    = "last"
+)
+
+// DefaultXFilesFactor is carbon default ratio of known values required to aggregate values
+const DefaultXFilesFactor = 0.5
+
+// RetentionArchive represents single archive of carbon storage schema, both precision and retention are in seconds
+type RetentionArchive struct {
+	Precision int64
+	Retention int64
+}
+
+// MetricSchema represents carbon storage schema and aggregation settings of metric, it is used to downsample metric values
+// Values older than retention of archive are aggregated with precision of the next archive
+type MetricSchema struct {
+	Archives          []RetentionArchive
+	AggregationMethod string
+	XFilesFactor      float64
+}
+
+// ValidateAggregationMethod checks if aggregation method is supported
+func ValidateAggregationMethod(method string) error {
+	switch method {
+	case AggregationAverage, AggregationSum, AggregationMin, AggregationMax, AggregationLast:
+		return nil
+	}
+	return fmt.Errorf("Unknown aggregation method '%s'", method)
+}
+
+// Aggregate aggregates values ordered by time with schema aggregation method
+// It returns false if ratio of known values to expected values count is less than xFilesFactor
+func (schema *MetricSchema) Aggregate(values []float64, expected int) (float64, bool) {
+	if len(values) == 0 || float64(len(values)) < schema.XFilesFactor*float64(expected) {
+		return 0, false
+	}
+	switch schema.AggregationMethod {
+	case AggregationSum:
+		var sum float64
+		for _, value := range values {
+			sum += value
+		}
+		return sum, true
+	case AggregationMin:
+		min := math.Inf(1)
+		for _, value := range values {
+			min = math.Min(min, value)
+		}
+		return min, true
+	case AggregationMax:
+		max := math.Inf(-1)
+		for _, value := range values {
+			max = math.Max(max, value)
+		}
+		return max, true
+	case AggregationLast:
+		return values[len(values)-1], true
+	default:
+		var sum float64
+		for _, value := range values {
+			sum += value
+		}
+		return sum / float64(len(values)), true
+	}
+}
@@ -0,0 +1,45 @@
+package moira
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestMetricSchemaAggregate(t *testing.T) {
+	values := []float64{4, 1, 3}
+
+	Convey("Aggregate values with all methods", t, func() {
+		expected := map[string]float64{
+			AggregationAverage: 8.0 / 3,
+			AggregationSum:     8,
+			AggregationMin:     1,
+			AggregationMax:     4,
+			AggregationLast:    3,
+		}
+		for method, expectedValue := range expected {
+			So(ValidateAggregationMethod(method), ShouldBeNil)
+			schema := MetricSchema{AggregationMethod: method, XFilesFactor: DefaultXFilesFactor}
+			value, ok := schema.Aggregate(values, 5)
+			So(ok, ShouldBeTrue)
+			So(value, ShouldEqual, expectedValue)
+		}
+	})
+
+	Convey("Not enough known values, should not aggregate", t, func() {
+		schema := MetricSchema{AggregationMethod: AggregationAverage, XFilesFactor: DefaultXFilesFactor}
+		_, ok := schema.Aggregate(values, 7)
+		So(ok, ShouldBeFalse)
+		_, ok = schema.Aggregate(nil, 1)
+		So(ok, ShouldBeFalse)
+
+		schema.XFilesFactor = 0
+		value, ok := schema.Aggregate(values[:1], 10)
+		So(ok, ShouldBeTrue)
+		So(value, ShouldEqual, 4)
+	})
+
+	Convey("Unknown aggregation method, should return error", t, func() {
+		So(ValidateAggregationMethod("median"), ShouldNotBeNil)
+	})
+}
@@ -2,10 +2,14 @@ package graphite
 
 // FilterMetrics is a collection of metrics used in filter
 type FilterMetrics struct {
-	TotalMetricsReceived    Meter // TotalMetricsReceived metrics counter
-	ValidMetricsReceived    Meter // ValidMetricsReceived metrics counter
-	MatchingMetricsReceived Meter // MatchingMetricsReceived metrics counter
-	MatchingTimer           Timer // MatchingTimer metrics timer
-	SavingTimer             Timer // SavingTimer metrics timer
-	BuildTreeTimer          Timer // BuildTreeTimer metrics timer
+	TotalMetricsReceived    Meter      // TotalMetricsReceived metrics counter
+	ValidMetricsReceived    Meter      // ValidMetricsReceived metrics counter
+	MatchingMetricsReceived Meter      // MatchingMetricsReceived metrics counter
+	MatchingTimer           Timer      // MatchingTimer metrics timer
+	SavingTimer             Timer      // SavingTimer metrics timer
+	BuildTreeTimer          Timer      // BuildTreeTimer metrics timer
+	RewriteRulesMetrics     MetricsMap // RewriteRulesMetrics per rule counters of rewritten and dropped metrics
+	DroppedMetrics          Meter      // DroppedMetrics counter of matched metrics dropped because of full queue or failed saving
+	RetriedMetrics          Meter      // RetriedMetrics counter of matched metrics saving retries
+	RelayedMetrics          Meter      // RelayedMetrics counter of metrics sent by relay to filter shards
 }
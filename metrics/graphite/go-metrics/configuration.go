@@ -14,6 +14,10 @@ func ConfigureFilterMetrics(prefix string) *graphite.FilterMetrics {
 		MatchingTimer:           newRegisteredTimer(metricNameWithPrefix(prefix, "time.match")),
 		SavingTimer:             newRegisteredTimer(metricNameWithPrefix(prefix, "time.save")),
 		BuildTreeTimer:          newRegisteredTimer(metricNameWithPrefix(prefix, "time.buildtree")),
+		RewriteRulesMetrics:     newMetricsMap(),
+		DroppedMetrics:          newRegisteredMeter(metricNameWithPrefix(prefix, "matching.dropped")),
+		RetriedMetrics:          newRegisteredMeter(metricNameWithPrefix(prefix, "matching.retried")),
+		RelayedMetrics:          newRegisteredMeter(metricNameWithPrefix(prefix, "relay.sent")),
 	}
 }
 
@@ -47,6 +47,18 @@ func (mr *MockDatabaseMockRecorder) AcquireTriggerCheckLock(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AcquireTriggerCheckLock", reflect.TypeOf((*MockDatabase)(nil).AcquireTriggerCheckLock), arg0, arg1)
 }
 
+// AddDelivery mocks base method
+func (m *MockDatabase) AddDelivery(arg0 *moira.DeliveryData) error {
+	ret := m.ctrl.Call(m, "AddDelivery", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddDelivery indicates an expected call of AddDelivery
+func (mr *MockDatabaseMockRecorder) AddDelivery(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddDelivery", reflect.TypeOf((*MockDatabase)(nil).AddDelivery), arg0)
+}
+
 // AddNotification mocks base method
 func (m *MockDatabase) AddNotification(arg0 *moira.ScheduledNotification) error {
 	ret := m.ctrl.Call(m, "AddNotification", arg0)
@@ -83,6 +95,18 @@ func (mr *MockDatabaseMockRecorder) AddPatternMetric(arg0, arg1 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddPatternMetric", reflect.TypeOf((*MockDatabase)(nil).AddPatternMetric), arg0, arg1)
 }
 
+// AddTriggerVersion mocks base method
+func (m *MockDatabase) AddTriggerVersion(arg0 string, arg1 *moira.TriggerVersion) error {
+	ret := m.ctrl.Call(m, "AddTriggerVersion", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// AddTriggerVersion indicates an expected call of AddTriggerVersion
+func (mr *MockDatabaseMockRecorder) AddTriggerVersion(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddTriggerVersion", reflect.TypeOf((*MockDatabase)(nil).AddTriggerVersion), arg0, arg1)
+}
+
 // DeleteTriggerCheckLock mocks base method
 func (m *MockDatabase) DeleteTriggerCheckLock(arg0 string) error {
 	ret := m.ctrl.Call(m, "DeleteTriggerCheckLock", arg0)
@@ -129,6 +153,30 @@ func (mr *MockDatabaseMockRecorder) DeregisterBots() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeregisterBots", reflect.TypeOf((*MockDatabase)(nil).DeregisterBots))
 }
 
+// DeregisterFilterShard mocks base method
+func (m *MockDatabase) DeregisterFilterShard(arg0 moira.FilterShard) bool {
+	ret := m.ctrl.Call(m, "DeregisterFilterShard", arg0)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// DeregisterFilterShard indicates an expected call of DeregisterFilterShard
+func (mr *MockDatabaseMockRecorder) DeregisterFilterShard(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeregisterFilterShard", reflect.TypeOf((*MockDatabase)(nil).DeregisterFilterShard), arg0)
+}
+
+// DownsampleMetricValues mocks base method
+func (m *MockDatabase) DownsampleMetricValues(arg0 string, arg1 *moira.MetricSchema, arg2 int64) error {
+	ret := m.ctrl.Call(m, "DownsampleMetricValues", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DownsampleMetricValues indicates an expected call of DownsampleMetricValues
+func (mr *MockDatabaseMockRecorder) DownsampleMetricValues(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DownsampleMetricValues", reflect.TypeOf((*MockDatabase)(nil).DownsampleMetricValues), arg0, arg1, arg2)
+}
+
 // FetchNotificationEvent mocks base method
 func (m *MockDatabase) FetchNotificationEvent() (moira.NotificationEvent, error) {
 	ret := m.ctrl.Call(m, "FetchNotificationEvent")
@@ -155,6 +203,57 @@ func (mr *MockDatabaseMockRecorder) FetchNotifications(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FetchNotifications", reflect.TypeOf((*MockDatabase)(nil).FetchNotifications), arg0)
 }
 
+// FillNotificationEventsTimeline mocks base method
+func (m *MockDatabase) FillNotificationEventsTimeline() error {
+	ret := m.ctrl.Call(m, "FillNotificationEventsTimeline")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// FillNotificationEventsTimeline indicates an expected call of FillNotificationEventsTimeline
+func (mr *MockDatabaseMockRecorder) FillNotificationEventsTimeline() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FillNotificationEventsTimeline", reflect.TypeOf((*MockDatabase)(nil).FillNotificationEventsTimeline))
+}
+
+// GetAPIToken mocks base method
+func (m *MockDatabase) GetAPIToken(arg0 string) (moira.APIToken, error) {
+	ret := m.ctrl.Call(m, "GetAPIToken", arg0)
+	ret0, _ := ret[0].(moira.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIToken indicates an expected call of GetAPIToken
+func (mr *MockDatabaseMockRecorder) GetAPIToken(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIToken", reflect.TypeOf((*MockDatabase)(nil).GetAPIToken), arg0)
+}
+
+// GetAPITokenByHash mocks base method
+func (m *MockDatabase) GetAPITokenByHash(arg0 string) (moira.APIToken, error) {
+	ret := m.ctrl.Call(m, "GetAPITokenByHash", arg0)
+	ret0, _ := ret[0].(moira.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokenByHash indicates an expected call of GetAPITokenByHash
+func (mr *MockDatabaseMockRecorder) GetAPITokenByHash(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokenByHash", reflect.TypeOf((*MockDatabase)(nil).GetAPITokenByHash), arg0)
+}
+
+// GetAPITokens mocks base method
+func (m *MockDatabase) GetAPITokens(arg0 []string) ([]*moira.APIToken, error) {
+	ret := m.ctrl.Call(m, "GetAPITokens", arg0)
+	ret0, _ := ret[0].([]*moira.APIToken)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPITokens indicates an expected call of GetAPITokens
+func (mr *MockDatabaseMockRecorder) GetAPITokens(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPITokens", reflect.TypeOf((*MockDatabase)(nil).GetAPITokens), arg0)
+}
+
 // GetAllContacts mocks base method
 func (m *MockDatabase) GetAllContacts() ([]*moira.ContactData, error) {
 	ret := m.ctrl.Call(m, "GetAllContacts")
@@ -194,6 +293,20 @@ func (mr *MockDatabaseMockRecorder) GetContact(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContact", reflect.TypeOf((*MockDatabase)(nil).GetContact), arg0)
 }
 
+// GetContactDeliveries mocks base method
+func (m *MockDatabase) GetContactDeliveries(arg0 string, arg1, arg2 int64) ([]*moira.DeliveryData, int64, error) {
+	ret := m.ctrl.Call(m, "GetContactDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*moira.DeliveryData)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetContactDeliveries indicates an expected call of GetContactDeliveries
+func (mr *MockDatabaseMockRecorder) GetContactDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContactDeliveries", reflect.TypeOf((*MockDatabase)(nil).GetContactDeliveries), arg0, arg1, arg2)
+}
+
 // GetContacts mocks base method
 func (m *MockDatabase) GetContacts(arg0 []string) ([]*moira.ContactData, error) {
 	ret := m.ctrl.Call(m, "GetContacts", arg0)
@@ -207,6 +320,19 @@ func (mr *MockDatabaseMockRecorder) GetContacts(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetContacts", reflect.TypeOf((*MockDatabase)(nil).GetContacts), arg0)
 }
 
+// GetFilterShards mocks base method
+func (m *MockDatabase) GetFilterShards() ([]moira.FilterShard, error) {
+	ret := m.ctrl.Call(m, "GetFilterShards")
+	ret0, _ := ret[0].([]moira.FilterShard)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetFilterShards indicates an expected call of GetFilterShards
+func (mr *MockDatabaseMockRecorder) GetFilterShards() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetFilterShards", reflect.TypeOf((*MockDatabase)(nil).GetFilterShards))
+}
+
 // GetIDByUsername mocks base method
 func (m *MockDatabase) GetIDByUsername(arg0, arg1 string) (string, error) {
 	ret := m.ctrl.Call(m, "GetIDByUsername", arg0, arg1)
@@ -220,6 +346,32 @@ func (mr *MockDatabaseMockRecorder) GetIDByUsername(arg0, arg1 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetIDByUsername", reflect.TypeOf((*MockDatabase)(nil).GetIDByUsername), arg0, arg1)
 }
 
+// GetMaintenanceWindow mocks base method
+func (m *MockDatabase) GetMaintenanceWindow(arg0 string) (moira.MaintenanceWindow, error) {
+	ret := m.ctrl.Call(m, "GetMaintenanceWindow", arg0)
+	ret0, _ := ret[0].(moira.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaintenanceWindow indicates an expected call of GetMaintenanceWindow
+func (mr *MockDatabaseMockRecorder) GetMaintenanceWindow(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenanceWindow", reflect.TypeOf((*MockDatabase)(nil).GetMaintenanceWindow), arg0)
+}
+
+// GetMaintenanceWindows mocks base method
+func (m *MockDatabase) GetMaintenanceWindows() ([]*moira.MaintenanceWindow, error) {
+	ret := m.ctrl.Call(m, "GetMaintenanceWindows")
+	ret0, _ := ret[0].([]*moira.MaintenanceWindow)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMaintenanceWindows indicates an expected call of GetMaintenanceWindows
+func (mr *MockDatabaseMockRecorder) GetMaintenanceWindows() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMaintenanceWindows", reflect.TypeOf((*MockDatabase)(nil).GetMaintenanceWindows))
+}
+
 // GetMetricRetention mocks base method
 func (m *MockDatabase) GetMetricRetention(arg0 string) (int64, error) {
 	ret := m.ctrl.Call(m, "GetMetricRetention", arg0)
@@ -233,6 +385,19 @@ func (mr *MockDatabaseMockRecorder) GetMetricRetention(arg0 interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricRetention", reflect.TypeOf((*MockDatabase)(nil).GetMetricRetention), arg0)
 }
 
+// GetMetricStorageRetention mocks base method
+func (m *MockDatabase) GetMetricStorageRetention(arg0 string) (int64, error) {
+	ret := m.ctrl.Call(m, "GetMetricStorageRetention", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetMetricStorageRetention indicates an expected call of GetMetricStorageRetention
+func (mr *MockDatabaseMockRecorder) GetMetricStorageRetention(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetMetricStorageRetention", reflect.TypeOf((*MockDatabase)(nil).GetMetricStorageRetention), arg0)
+}
+
 // GetMetricsUpdatesCount mocks base method
 func (m *MockDatabase) GetMetricsUpdatesCount() (int64, error) {
 	ret := m.ctrl.Call(m, "GetMetricsUpdatesCount")
@@ -271,6 +436,19 @@ func (mr *MockDatabaseMockRecorder) GetNotificationEventCount(arg0, arg1 interfa
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEventCount", reflect.TypeOf((*MockDatabase)(nil).GetNotificationEventCount), arg0, arg1)
 }
 
+// GetNotificationEventCountByTime mocks base method
+func (m *MockDatabase) GetNotificationEventCountByTime(arg0, arg1 int64) (int64, error) {
+	ret := m.ctrl.Call(m, "GetNotificationEventCountByTime", arg0, arg1)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationEventCountByTime indicates an expected call of GetNotificationEventCountByTime
+func (mr *MockDatabaseMockRecorder) GetNotificationEventCountByTime(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEventCountByTime", reflect.TypeOf((*MockDatabase)(nil).GetNotificationEventCountByTime), arg0, arg1)
+}
+
 // GetNotificationEvents mocks base method
 func (m *MockDatabase) GetNotificationEvents(arg0 string, arg1, arg2 int64) ([]*moira.NotificationEvent, error) {
 	ret := m.ctrl.Call(m, "GetNotificationEvents", arg0, arg1, arg2)
@@ -284,6 +462,19 @@ func (mr *MockDatabaseMockRecorder) GetNotificationEvents(arg0, arg1, arg2 inter
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEvents", reflect.TypeOf((*MockDatabase)(nil).GetNotificationEvents), arg0, arg1, arg2)
 }
 
+// GetNotificationEventsByTime mocks base method
+func (m *MockDatabase) GetNotificationEventsByTime(arg0, arg1, arg2, arg3 int64) ([]*moira.NotificationEvent, error) {
+	ret := m.ctrl.Call(m, "GetNotificationEventsByTime", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].([]*moira.NotificationEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetNotificationEventsByTime indicates an expected call of GetNotificationEventsByTime
+func (mr *MockDatabaseMockRecorder) GetNotificationEventsByTime(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetNotificationEventsByTime", reflect.TypeOf((*MockDatabase)(nil).GetNotificationEventsByTime), arg0, arg1, arg2, arg3)
+}
+
 // GetNotifications mocks base method
 func (m *MockDatabase) GetNotifications(arg0, arg1 int64) ([]*moira.ScheduledNotification, int64, error) {
 	ret := m.ctrl.Call(m, "GetNotifications", arg0, arg1)
@@ -337,6 +528,32 @@ func (mr *MockDatabaseMockRecorder) GetPatterns() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetPatterns", reflect.TypeOf((*MockDatabase)(nil).GetPatterns))
 }
 
+// GetSeriesByTag mocks base method
+func (m *MockDatabase) GetSeriesByTag(arg0 string, arg1 []string) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetSeriesByTag", arg0, arg1)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeriesByTag indicates an expected call of GetSeriesByTag
+func (mr *MockDatabaseMockRecorder) GetSeriesByTag(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeriesByTag", reflect.TypeOf((*MockDatabase)(nil).GetSeriesByTag), arg0, arg1)
+}
+
+// GetSeriesTagValues mocks base method
+func (m *MockDatabase) GetSeriesTagValues(arg0 string) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetSeriesTagValues", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetSeriesTagValues indicates an expected call of GetSeriesTagValues
+func (mr *MockDatabaseMockRecorder) GetSeriesTagValues(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSeriesTagValues", reflect.TypeOf((*MockDatabase)(nil).GetSeriesTagValues), arg0)
+}
+
 // GetSubscription mocks base method
 func (m *MockDatabase) GetSubscription(arg0 string) (moira.SubscriptionData, error) {
 	ret := m.ctrl.Call(m, "GetSubscription", arg0)
@@ -350,6 +567,18 @@ func (mr *MockDatabaseMockRecorder) GetSubscription(arg0 interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscription", reflect.TypeOf((*MockDatabase)(nil).GetSubscription), arg0)
 }
 
+// GetSubscriptionThrottling mocks base method
+func (m *MockDatabase) GetSubscriptionThrottling(arg0, arg1 string) time.Time {
+	ret := m.ctrl.Call(m, "GetSubscriptionThrottling", arg0, arg1)
+	ret0, _ := ret[0].(time.Time)
+	return ret0
+}
+
+// GetSubscriptionThrottling indicates an expected call of GetSubscriptionThrottling
+func (mr *MockDatabaseMockRecorder) GetSubscriptionThrottling(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetSubscriptionThrottling", reflect.TypeOf((*MockDatabase)(nil).GetSubscriptionThrottling), arg0, arg1)
+}
+
 // GetSubscriptions mocks base method
 func (m *MockDatabase) GetSubscriptions(arg0 []string) ([]*moira.SubscriptionData, error) {
 	ret := m.ctrl.Call(m, "GetSubscriptions", arg0)
@@ -402,6 +631,71 @@ func (mr *MockDatabaseMockRecorder) GetTagsSubscriptions(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTagsSubscriptions", reflect.TypeOf((*MockDatabase)(nil).GetTagsSubscriptions), arg0)
 }
 
+// GetTeam mocks base method
+func (m *MockDatabase) GetTeam(arg0 string) (moira.Team, error) {
+	ret := m.ctrl.Call(m, "GetTeam", arg0)
+	ret0, _ := ret[0].(moira.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeam indicates an expected call of GetTeam
+func (mr *MockDatabaseMockRecorder) GetTeam(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeam", reflect.TypeOf((*MockDatabase)(nil).GetTeam), arg0)
+}
+
+// GetTeamContactIDs mocks base method
+func (m *MockDatabase) GetTeamContactIDs(arg0 string) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetTeamContactIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeamContactIDs indicates an expected call of GetTeamContactIDs
+func (mr *MockDatabaseMockRecorder) GetTeamContactIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamContactIDs", reflect.TypeOf((*MockDatabase)(nil).GetTeamContactIDs), arg0)
+}
+
+// GetTeamSubscriptionIDs mocks base method
+func (m *MockDatabase) GetTeamSubscriptionIDs(arg0 string) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetTeamSubscriptionIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeamSubscriptionIDs indicates an expected call of GetTeamSubscriptionIDs
+func (mr *MockDatabaseMockRecorder) GetTeamSubscriptionIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamSubscriptionIDs", reflect.TypeOf((*MockDatabase)(nil).GetTeamSubscriptionIDs), arg0)
+}
+
+// GetTeamTriggerIDs mocks base method
+func (m *MockDatabase) GetTeamTriggerIDs(arg0 string) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetTeamTriggerIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeamTriggerIDs indicates an expected call of GetTeamTriggerIDs
+func (mr *MockDatabaseMockRecorder) GetTeamTriggerIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeamTriggerIDs", reflect.TypeOf((*MockDatabase)(nil).GetTeamTriggerIDs), arg0)
+}
+
+// GetTeams mocks base method
+func (m *MockDatabase) GetTeams(arg0 []string) ([]*moira.Team, error) {
+	ret := m.ctrl.Call(m, "GetTeams", arg0)
+	ret0, _ := ret[0].([]*moira.Team)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTeams indicates an expected call of GetTeams
+func (mr *MockDatabaseMockRecorder) GetTeams(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTeams", reflect.TypeOf((*MockDatabase)(nil).GetTeams), arg0)
+}
+
 // GetTrigger mocks base method
 func (m *MockDatabase) GetTrigger(arg0 string) (moira.Trigger, error) {
 	ret := m.ctrl.Call(m, "GetTrigger", arg0)
@@ -415,6 +709,19 @@ func (mr *MockDatabaseMockRecorder) GetTrigger(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrigger", reflect.TypeOf((*MockDatabase)(nil).GetTrigger), arg0)
 }
 
+// GetTriggerAck mocks base method
+func (m *MockDatabase) GetTriggerAck(arg0 string) (int64, error) {
+	ret := m.ctrl.Call(m, "GetTriggerAck", arg0)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerAck indicates an expected call of GetTriggerAck
+func (mr *MockDatabaseMockRecorder) GetTriggerAck(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerAck", reflect.TypeOf((*MockDatabase)(nil).GetTriggerAck), arg0)
+}
+
 // GetTriggerCheckIDs mocks base method
 func (m *MockDatabase) GetTriggerCheckIDs(arg0 []string, arg1 bool) ([]string, error) {
 	ret := m.ctrl.Call(m, "GetTriggerCheckIDs", arg0, arg1)
@@ -441,6 +748,20 @@ func (mr *MockDatabaseMockRecorder) GetTriggerChecks(arg0 interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerChecks", reflect.TypeOf((*MockDatabase)(nil).GetTriggerChecks), arg0)
 }
 
+// GetTriggerDeliveries mocks base method
+func (m *MockDatabase) GetTriggerDeliveries(arg0 string, arg1, arg2 int64) ([]*moira.DeliveryData, int64, error) {
+	ret := m.ctrl.Call(m, "GetTriggerDeliveries", arg0, arg1, arg2)
+	ret0, _ := ret[0].([]*moira.DeliveryData)
+	ret1, _ := ret[1].(int64)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetTriggerDeliveries indicates an expected call of GetTriggerDeliveries
+func (mr *MockDatabaseMockRecorder) GetTriggerDeliveries(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerDeliveries", reflect.TypeOf((*MockDatabase)(nil).GetTriggerDeliveries), arg0, arg1, arg2)
+}
+
 // GetTriggerIDs mocks base method
 func (m *MockDatabase) GetTriggerIDs() ([]string, error) {
 	ret := m.ctrl.Call(m, "GetTriggerIDs")
@@ -480,6 +801,32 @@ func (mr *MockDatabaseMockRecorder) GetTriggerThrottling(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerThrottling", reflect.TypeOf((*MockDatabase)(nil).GetTriggerThrottling), arg0)
 }
 
+// GetTriggerVersion mocks base method
+func (m *MockDatabase) GetTriggerVersion(arg0 string, arg1 int64) (moira.TriggerVersion, error) {
+	ret := m.ctrl.Call(m, "GetTriggerVersion", arg0, arg1)
+	ret0, _ := ret[0].(moira.TriggerVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerVersion indicates an expected call of GetTriggerVersion
+func (mr *MockDatabaseMockRecorder) GetTriggerVersion(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerVersion", reflect.TypeOf((*MockDatabase)(nil).GetTriggerVersion), arg0, arg1)
+}
+
+// GetTriggerVersions mocks base method
+func (m *MockDatabase) GetTriggerVersions(arg0 string) ([]*moira.TriggerVersion, error) {
+	ret := m.ctrl.Call(m, "GetTriggerVersions", arg0)
+	ret0, _ := ret[0].([]*moira.TriggerVersion)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTriggerVersions indicates an expected call of GetTriggerVersions
+func (mr *MockDatabaseMockRecorder) GetTriggerVersions(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggerVersions", reflect.TypeOf((*MockDatabase)(nil).GetTriggerVersions), arg0)
+}
+
 // GetTriggers mocks base method
 func (m *MockDatabase) GetTriggers(arg0 []string) ([]*moira.Trigger, error) {
 	ret := m.ctrl.Call(m, "GetTriggers", arg0)
@@ -493,6 +840,19 @@ func (mr *MockDatabaseMockRecorder) GetTriggers(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTriggers", reflect.TypeOf((*MockDatabase)(nil).GetTriggers), arg0)
 }
 
+// GetUserAPITokenIDs mocks base method
+func (m *MockDatabase) GetUserAPITokenIDs(arg0 string) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetUserAPITokenIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserAPITokenIDs indicates an expected call of GetUserAPITokenIDs
+func (mr *MockDatabaseMockRecorder) GetUserAPITokenIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserAPITokenIDs", reflect.TypeOf((*MockDatabase)(nil).GetUserAPITokenIDs), arg0)
+}
+
 // GetUserContactIDs mocks base method
 func (m *MockDatabase) GetUserContactIDs(arg0 string) ([]string, error) {
 	ret := m.ctrl.Call(m, "GetUserContactIDs", arg0)
@@ -519,6 +879,19 @@ func (mr *MockDatabaseMockRecorder) GetUserSubscriptionIDs(arg0 interface{}) *go
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserSubscriptionIDs", reflect.TypeOf((*MockDatabase)(nil).GetUserSubscriptionIDs), arg0)
 }
 
+// GetUserTeamIDs mocks base method
+func (m *MockDatabase) GetUserTeamIDs(arg0 string) ([]string, error) {
+	ret := m.ctrl.Call(m, "GetUserTeamIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserTeamIDs indicates an expected call of GetUserTeamIDs
+func (mr *MockDatabaseMockRecorder) GetUserTeamIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserTeamIDs", reflect.TypeOf((*MockDatabase)(nil).GetUserTeamIDs), arg0)
+}
+
 // PushNotificationEvent mocks base method
 func (m *MockDatabase) PushNotificationEvent(arg0 *moira.NotificationEvent, arg1 bool) error {
 	ret := m.ctrl.Call(m, "PushNotificationEvent", arg0, arg1)
@@ -531,6 +904,18 @@ func (mr *MockDatabaseMockRecorder) PushNotificationEvent(arg0, arg1 interface{}
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PushNotificationEvent", reflect.TypeOf((*MockDatabase)(nil).PushNotificationEvent), arg0, arg1)
 }
 
+// RebuildTriggerSearchIndex mocks base method
+func (m *MockDatabase) RebuildTriggerSearchIndex() error {
+	ret := m.ctrl.Call(m, "RebuildTriggerSearchIndex")
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RebuildTriggerSearchIndex indicates an expected call of RebuildTriggerSearchIndex
+func (mr *MockDatabaseMockRecorder) RebuildTriggerSearchIndex() *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RebuildTriggerSearchIndex", reflect.TypeOf((*MockDatabase)(nil).RebuildTriggerSearchIndex))
+}
+
 // RegisterBotIfAlreadyNot mocks base method
 func (m *MockDatabase) RegisterBotIfAlreadyNot(arg0 string, arg1 time.Duration) bool {
 	ret := m.ctrl.Call(m, "RegisterBotIfAlreadyNot", arg0, arg1)
@@ -543,6 +928,30 @@ func (mr *MockDatabaseMockRecorder) RegisterBotIfAlreadyNot(arg0, arg1 interface
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterBotIfAlreadyNot", reflect.TypeOf((*MockDatabase)(nil).RegisterBotIfAlreadyNot), arg0, arg1)
 }
 
+// RegisterFilterShardIfAlreadyNot mocks base method
+func (m *MockDatabase) RegisterFilterShardIfAlreadyNot(arg0 moira.FilterShard, arg1 time.Duration) bool {
+	ret := m.ctrl.Call(m, "RegisterFilterShardIfAlreadyNot", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// RegisterFilterShardIfAlreadyNot indicates an expected call of RegisterFilterShardIfAlreadyNot
+func (mr *MockDatabaseMockRecorder) RegisterFilterShardIfAlreadyNot(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RegisterFilterShardIfAlreadyNot", reflect.TypeOf((*MockDatabase)(nil).RegisterFilterShardIfAlreadyNot), arg0, arg1)
+}
+
+// RemoveAPIToken mocks base method
+func (m *MockDatabase) RemoveAPIToken(arg0 string) error {
+	ret := m.ctrl.Call(m, "RemoveAPIToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveAPIToken indicates an expected call of RemoveAPIToken
+func (mr *MockDatabaseMockRecorder) RemoveAPIToken(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveAPIToken", reflect.TypeOf((*MockDatabase)(nil).RemoveAPIToken), arg0)
+}
+
 // RemoveContact mocks base method
 func (m *MockDatabase) RemoveContact(arg0 string) error {
 	ret := m.ctrl.Call(m, "RemoveContact", arg0)
@@ -555,6 +964,18 @@ func (mr *MockDatabaseMockRecorder) RemoveContact(arg0 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveContact", reflect.TypeOf((*MockDatabase)(nil).RemoveContact), arg0)
 }
 
+// RemoveMaintenanceWindow mocks base method
+func (m *MockDatabase) RemoveMaintenanceWindow(arg0 string) error {
+	ret := m.ctrl.Call(m, "RemoveMaintenanceWindow", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveMaintenanceWindow indicates an expected call of RemoveMaintenanceWindow
+func (mr *MockDatabaseMockRecorder) RemoveMaintenanceWindow(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveMaintenanceWindow", reflect.TypeOf((*MockDatabase)(nil).RemoveMaintenanceWindow), arg0)
+}
+
 // RemoveMetricValues mocks base method
 func (m *MockDatabase) RemoveMetricValues(arg0 string, arg1 int64) error {
 	ret := m.ctrl.Call(m, "RemoveMetricValues", arg0, arg1)
@@ -628,6 +1049,18 @@ func (mr *MockDatabaseMockRecorder) RemovePatternsMetrics(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemovePatternsMetrics", reflect.TypeOf((*MockDatabase)(nil).RemovePatternsMetrics), arg0)
 }
 
+// RemoveSeriesTags mocks base method
+func (m *MockDatabase) RemoveSeriesTags(arg0 string) error {
+	ret := m.ctrl.Call(m, "RemoveSeriesTags", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveSeriesTags indicates an expected call of RemoveSeriesTags
+func (mr *MockDatabaseMockRecorder) RemoveSeriesTags(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveSeriesTags", reflect.TypeOf((*MockDatabase)(nil).RemoveSeriesTags), arg0)
+}
+
 // RemoveSubscription mocks base method
 func (m *MockDatabase) RemoveSubscription(arg0 string) error {
 	ret := m.ctrl.Call(m, "RemoveSubscription", arg0)
@@ -652,6 +1085,18 @@ func (mr *MockDatabaseMockRecorder) RemoveTag(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTag", reflect.TypeOf((*MockDatabase)(nil).RemoveTag), arg0)
 }
 
+// RemoveTeam mocks base method
+func (m *MockDatabase) RemoveTeam(arg0 string) error {
+	ret := m.ctrl.Call(m, "RemoveTeam", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveTeam indicates an expected call of RemoveTeam
+func (mr *MockDatabaseMockRecorder) RemoveTeam(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveTeam", reflect.TypeOf((*MockDatabase)(nil).RemoveTeam), arg0)
+}
+
 // RemoveTrigger mocks base method
 func (m *MockDatabase) RemoveTrigger(arg0 string) error {
 	ret := m.ctrl.Call(m, "RemoveTrigger", arg0)
@@ -700,6 +1145,30 @@ func (mr *MockDatabaseMockRecorder) RenewBotRegistration(arg0 interface{}) *gomo
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewBotRegistration", reflect.TypeOf((*MockDatabase)(nil).RenewBotRegistration), arg0)
 }
 
+// RenewFilterShardRegistration mocks base method
+func (m *MockDatabase) RenewFilterShardRegistration(arg0 moira.FilterShard, arg1 time.Duration) bool {
+	ret := m.ctrl.Call(m, "RenewFilterShardRegistration", arg0, arg1)
+	ret0, _ := ret[0].(bool)
+	return ret0
+}
+
+// RenewFilterShardRegistration indicates an expected call of RenewFilterShardRegistration
+func (mr *MockDatabaseMockRecorder) RenewFilterShardRegistration(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RenewFilterShardRegistration", reflect.TypeOf((*MockDatabase)(nil).RenewFilterShardRegistration), arg0, arg1)
+}
+
+// SaveAPIToken mocks base method
+func (m *MockDatabase) SaveAPIToken(arg0 *moira.APIToken) error {
+	ret := m.ctrl.Call(m, "SaveAPIToken", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveAPIToken indicates an expected call of SaveAPIToken
+func (mr *MockDatabaseMockRecorder) SaveAPIToken(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveAPIToken", reflect.TypeOf((*MockDatabase)(nil).SaveAPIToken), arg0)
+}
+
 // SaveContact mocks base method
 func (m *MockDatabase) SaveContact(arg0 *moira.ContactData) error {
 	ret := m.ctrl.Call(m, "SaveContact", arg0)
@@ -712,6 +1181,18 @@ func (mr *MockDatabaseMockRecorder) SaveContact(arg0 interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveContact", reflect.TypeOf((*MockDatabase)(nil).SaveContact), arg0)
 }
 
+// SaveMaintenanceWindow mocks base method
+func (m *MockDatabase) SaveMaintenanceWindow(arg0 *moira.MaintenanceWindow) error {
+	ret := m.ctrl.Call(m, "SaveMaintenanceWindow", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveMaintenanceWindow indicates an expected call of SaveMaintenanceWindow
+func (mr *MockDatabaseMockRecorder) SaveMaintenanceWindow(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveMaintenanceWindow", reflect.TypeOf((*MockDatabase)(nil).SaveMaintenanceWindow), arg0)
+}
+
 // SaveMetrics mocks base method
 func (m *MockDatabase) SaveMetrics(arg0 map[string]*moira.MatchedMetric) error {
 	ret := m.ctrl.Call(m, "SaveMetrics", arg0)
@@ -748,6 +1229,18 @@ func (mr *MockDatabaseMockRecorder) SaveSubscriptions(arg0 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveSubscriptions", reflect.TypeOf((*MockDatabase)(nil).SaveSubscriptions), arg0)
 }
 
+// SaveTeam mocks base method
+func (m *MockDatabase) SaveTeam(arg0 *moira.Team) error {
+	ret := m.ctrl.Call(m, "SaveTeam", arg0)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SaveTeam indicates an expected call of SaveTeam
+func (mr *MockDatabaseMockRecorder) SaveTeam(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTeam", reflect.TypeOf((*MockDatabase)(nil).SaveTeam), arg0)
+}
+
 // SaveTrigger mocks base method
 func (m *MockDatabase) SaveTrigger(arg0 string, arg1 *moira.Trigger) error {
 	ret := m.ctrl.Call(m, "SaveTrigger", arg0, arg1)
@@ -760,6 +1253,43 @@ func (mr *MockDatabaseMockRecorder) SaveTrigger(arg0, arg1 interface{}) *gomock.
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SaveTrigger", reflect.TypeOf((*MockDatabase)(nil).SaveTrigger), arg0, arg1)
 }
 
+// SearchTriggerIDs mocks base method
+func (m *MockDatabase) SearchTriggerIDs(arg0 moira.TriggerSearchQuery) ([]string, error) {
+	ret := m.ctrl.Call(m, "SearchTriggerIDs", arg0)
+	ret0, _ := ret[0].([]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SearchTriggerIDs indicates an expected call of SearchTriggerIDs
+func (mr *MockDatabaseMockRecorder) SearchTriggerIDs(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SearchTriggerIDs", reflect.TypeOf((*MockDatabase)(nil).SearchTriggerIDs), arg0)
+}
+
+// SetSubscriptionThrottling mocks base method
+func (m *MockDatabase) SetSubscriptionThrottling(arg0, arg1 string, arg2 time.Time) error {
+	ret := m.ctrl.Call(m, "SetSubscriptionThrottling", arg0, arg1, arg2)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetSubscriptionThrottling indicates an expected call of SetSubscriptionThrottling
+func (mr *MockDatabaseMockRecorder) SetSubscriptionThrottling(arg0, arg1, arg2 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetSubscriptionThrottling", reflect.TypeOf((*MockDatabase)(nil).SetSubscriptionThrottling), arg0, arg1, arg2)
+}
+
+// SetTriggerAck mocks base method
+func (m *MockDatabase) SetTriggerAck(arg0 string, arg1 int64) error {
+	ret := m.ctrl.Call(m, "SetTriggerAck", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetTriggerAck indicates an expected call of SetTriggerAck
+func (mr *MockDatabaseMockRecorder) SetTriggerAck(arg0, arg1 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetTriggerAck", reflect.TypeOf((*MockDatabase)(nil).SetTriggerAck), arg0, arg1)
+}
+
 // SetTriggerCheckLock mocks base method
 func (m *MockDatabase) SetTriggerCheckLock(arg0 string) (bool, error) {
 	ret := m.ctrl.Call(m, "SetTriggerCheckLock", arg0)
@@ -834,6 +1364,32 @@ func (mr *MockDatabaseMockRecorder) SubscribeMetricEvents(arg0 interface{}) *gom
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeMetricEvents", reflect.TypeOf((*MockDatabase)(nil).SubscribeMetricEvents), arg0)
 }
 
+// SubscribePatternEvents mocks base method
+func (m *MockDatabase) SubscribePatternEvents(arg0 *tomb_v2.Tomb) (<-chan *moira.PatternEvent, error) {
+	ret := m.ctrl.Call(m, "SubscribePatternEvents", arg0)
+	ret0, _ := ret[0].(<-chan *moira.PatternEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribePatternEvents indicates an expected call of SubscribePatternEvents
+func (mr *MockDatabaseMockRecorder) SubscribePatternEvents(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribePatternEvents", reflect.TypeOf((*MockDatabase)(nil).SubscribePatternEvents), arg0)
+}
+
+// SubscribeStreamEvents mocks base method
+func (m *MockDatabase) SubscribeStreamEvents(arg0 *tomb_v2.Tomb) (<-chan *moira.StreamEvent, error) {
+	ret := m.ctrl.Call(m, "SubscribeStreamEvents", arg0)
+	ret0, _ := ret[0].(<-chan *moira.StreamEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SubscribeStreamEvents indicates an expected call of SubscribeStreamEvents
+func (mr *MockDatabaseMockRecorder) SubscribeStreamEvents(arg0 interface{}) *gomock.Call {
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SubscribeStreamEvents", reflect.TypeOf((*MockDatabase)(nil).SubscribeStreamEvents), arg0)
+}
+
 // UpdateMetricsHeartbeat mocks base method
 func (m *MockDatabase) UpdateMetricsHeartbeat() error {
 	ret := m.ctrl.Call(m, "UpdateMetricsHeartbeat")
@@ -8,6 +8,7 @@ import (
 	moira_alert "github.com/moira-alert/moira"
 	notifier "github.com/moira-alert/moira/notifier"
 	sync "sync"
+	time "time"
 )
 
 // MockNotifier is a mock of Notifier interface
@@ -33,6 +34,18 @@ func (_m *MockNotifier) EXPECT() *MockNotifierMockRecorder {
 	return _m.recorder
 }
 
+// GetDegradedSenders mocks base method
+func (_m *MockNotifier) GetDegradedSenders() map[string]time.Time {
+	ret := _m.ctrl.Call(_m, "GetDegradedSenders")
+	ret0, _ := ret[0].(map[string]time.Time)
+	return ret0
+}
+
+// GetDegradedSenders indicates an expected call of GetDegradedSenders
+func (_mr *MockNotifierMockRecorder) GetDegradedSenders() *gomock.Call {
+	return _mr.mock.ctrl.RecordCall(_mr.mock, "GetDegradedSenders")
+}
+
 // GetSenders mocks base method
 func (_m *MockNotifier) GetSenders() map[string]bool {
 	ret := _m.ctrl.Call(_m, "GetSenders")
@@ -3,13 +3,17 @@ package notifier
 import "time"
 
 // Config is sending settings including log settings
+// Sender is degraded when its failure rate for the last SenderFailuresWindow reaches SenderFailureRate, zero rate disables tracking
 type Config struct {
-	Enabled          bool
-	SendingTimeout   time.Duration
-	ResendingTimeout time.Duration
-	Senders          []map[string]string
-	LogFile          string
-	LogLevel         string
-	FrontURL         string
-	Location         *time.Location
+	Enabled              bool
+	SendingTimeout       time.Duration
+	ResendingTimeout     time.Duration
+	SenderFailureRate    float64
+	SenderFailuresWindow time.Duration
+	SenderMinAttempts    int
+	Senders              []map[string]string
+	LogFile              string
+	LogLevel             string
+	FrontURL             string
+	Location             *time.Location
 }
@@ -89,6 +89,7 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 		}
 
 		tags = append(trigger.Tags, event.GetEventTags()...)
+		tags = append(tags, moira.GetSeriesTags(event.Metric)...)
 		worker.Logger.Debugf("Getting subscriptions for tags %v", tags)
 		subscriptions, err = worker.Database.GetTagsSubscriptions(tags)
 		if err != nil {
@@ -102,6 +103,8 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 		subscriptions = []*moira.SubscriptionData{sub}
 	}
 
+	// all contacts of subscription are scheduled at the same time to get the same delivery
+	now := time.Now()
 	duplications := make(map[string]bool)
 	for _, subscription := range subscriptions {
 		if subscription != nil && (event.State == "TEST" || (subscription.Enabled && subset(subscription.Tags, tags))) {
@@ -113,7 +116,7 @@ func (worker *FetchEventsWorker) processEvent(event moira.NotificationEvent) err
 					continue
 				}
 				event.SubscriptionID = &subscription.ID
-				notification := worker.Scheduler.ScheduleNotification(time.Now(), event, triggerData, contact, false, 0)
+				notification := worker.Scheduler.ScheduleNotification(now, event, triggerData, contact, false, 0)
 				key := notification.GetKey()
 				if _, exist := duplications[key]; !exist {
 					if err := worker.Database.AddNotification(notification); err != nil {
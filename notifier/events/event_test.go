@@ -232,6 +232,49 @@ func TestAddNotification(t *testing.T) {
 	})
 }
 
+func TestSeriesTagsSubscription(t *testing.T) {
+	Convey("When subscription has tags of tagged series, should add new notification", t, func() {
+		mockCtrl := gomock.NewController(t)
+		defer mockCtrl.Finish()
+		dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+		logger, _ := logging.GetLogger("Events")
+		scheduler := mock_scheduler.NewMockScheduler(mockCtrl)
+		worker := FetchEventsWorker{
+			Database:  dataBase,
+			Logger:    logger,
+			Metrics:   metrics2,
+			Scheduler: scheduler,
+		}
+
+		seriesSubscription := moira.SubscriptionData{
+			ID:       "subscriptionID-00000000000005",
+			Enabled:  true,
+			Tags:     []string{"test-tag", "dc=eu1"},
+			Contacts: []string{contact.ID},
+		}
+		event := moira.NotificationEvent{
+			Metric:    "cpu;dc=eu1;host=a",
+			State:     "OK",
+			OldState:  "WARN",
+			TriggerID: triggerData.ID,
+		}
+		event2 := event
+		event2.SubscriptionID = &seriesSubscription.ID
+		emptyNotification := moira.ScheduledNotification{}
+
+		dataBase.EXPECT().GetTrigger(event.TriggerID).Return(trigger, nil)
+		tags := append(triggerData.Tags, event.GetEventTags()...)
+		tags = append(tags, "dc=eu1", "host=a", "name=cpu")
+		dataBase.EXPECT().GetTagsSubscriptions(tags).Times(1).Return([]*moira.SubscriptionData{&seriesSubscription}, nil)
+		dataBase.EXPECT().GetContact(contact.ID).Times(1).Return(contact, nil)
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event2, triggerData, contact, false, 0).Times(1).Return(&emptyNotification)
+		dataBase.EXPECT().AddNotification(&emptyNotification).Times(1).Return(nil)
+
+		err := worker.processEvent(event)
+		So(err, ShouldBeEmpty)
+	})
+}
+
 func TestAddOneNotificationByTwoSubscriptionsWithSame(t *testing.T) {
 	Convey("When good subscription and create 2 same scheduled notifications, should add one new notification", t, func() {
 		mockCtrl := gomock.NewController(t)
@@ -0,0 +1,117 @@
+package notifier
+
+import (
+	"sync"
+	"time"
+)
+
+type sendingResult struct {
+	timestamp time.Time
+	failed    bool
+}
+
+// sendersHealth tracks failure rate of each sender type for the last window
+// sender becomes degraded when it has enough attempts and failure rate reaches threshold
+type sendersHealth struct {
+	mutex       sync.Mutex
+	failureRate float64
+	window      time.Duration
+	minAttempts int
+	results     map[string][]sendingResult
+	degraded    map[string]time.Time
+}
+
+func newSendersHealth(config Config) *sendersHealth {
+	return &sendersHealth{
+		failureRate: config.SenderFailureRate,
+		window:      config.SenderFailuresWindow,
+		minAttempts: config.SenderMinAttempts,
+		results:     make(map[string][]sendingResult),
+		degraded:    make(map[string]time.Time),
+	}
+}
+
+// mark adds sending result for given sender type and returns true if sender health status was changed
+func (health *sendersHealth) mark(senderType string, failed bool, now time.Time) bool {
+	if health.failureRate <= 0 {
+		return false
+	}
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+
+	results := append(health.cleanup(senderType, now), sendingResult{timestamp: now, failed: failed})
+	health.results[senderType] = results
+
+	_, wasDegraded := health.degraded[senderType]
+	isDegraded := health.isFailureRateExceeded(results)
+	if isDegraded && !wasDegraded {
+		health.degraded[senderType] = now
+	}
+	if !isDegraded && wasDegraded {
+		delete(health.degraded, senderType)
+	}
+	return isDegraded != wasDegraded
+}
+
+// isDegraded returns true if sender type failure rate exceeded threshold for the last window
+func (health *sendersHealth) isDegraded(senderType string, now time.Time) bool {
+	if health.failureRate <= 0 {
+		return false
+	}
+	health.mutex.Lock()
+	defer health.mutex.Unlock()
+
+	if _, ok := health.degraded[senderType]; !ok {
+		return false
+	}
+	results := health.cleanup(senderType, now)
+	health.results[senderType] = results
+	if !health.isFailureRateExceeded(results) {
+		delete(health.degraded, senderType)
+		return false
+	}
+	return true
+}
+
+// getDegraded returns degraded sender types with degradation start time
+func (health *sendersHealth) getDegraded(now time.Time) map[string]time.Time {
+	health.mutex.Lock()
+	senderTypes := make([]string, 0, len(health.degraded))
+	for senderType := range health.degraded {
+		senderTypes = append(senderTypes, senderType)
+	}
+	health.mutex.Unlock()
+
+	degraded := make(map[string]time.Time)
+	for _, senderType := range senderTypes {
+		if health.isDegraded(senderType, now) {
+			health.mutex.Lock()
+			degraded[senderType] = health.degraded[senderType]
+			health.mutex.Unlock()
+		}
+	}
+	return degraded
+}
+
+func (health *sendersHealth) cleanup(senderType string, now time.Time) []sendingResult {
+	results := health.results[senderType]
+	from := now.Add(-health.window)
+	i := 0
+	for i < len(results) && results[i].timestamp.Before(from) {
+		i++
+	}
+	return results[i:]
+}
+
+func (health *sendersHealth) isFailureRateExceeded(results []sendingResult) bool {
+	if len(results) == 0 || len(results) < health.minAttempts {
+		return false
+	}
+	var failed int
+	for _, result := range results {
+		if result.failed {
+			failed++
+		}
+	}
+	return float64(failed)/float64(len(results)) >= health.failureRate
+}
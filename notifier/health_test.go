@@ -0,0 +1,57 @@
+package notifier
+
+import (
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestSendersHealth(t *testing.T) {
+	config := Config{
+		SenderFailureRate:    0.5,
+		SenderFailuresWindow: time.Minute * 5,
+		SenderMinAttempts:    4,
+	}
+	now := time.Unix(1441134000, 0)
+
+	Convey("Tracking disabled, sender is never degraded", t, func() {
+		health := newSendersHealth(Config{})
+		for i := 0; i < 10; i++ {
+			So(health.mark("telegram", true, now), ShouldBeFalse)
+		}
+		So(health.isDegraded("telegram", now), ShouldBeFalse)
+		So(health.getDegraded(now), ShouldBeEmpty)
+	})
+
+	Convey("Not enough attempts, sender is not degraded", t, func() {
+		health := newSendersHealth(config)
+		for i := 0; i < 3; i++ {
+			So(health.mark("telegram", true, now), ShouldBeFalse)
+		}
+		So(health.isDegraded("telegram", now), ShouldBeFalse)
+	})
+
+	Convey("Failure rate reached, sender is degraded until rate goes down", t, func() {
+		health := newSendersHealth(config)
+		So(health.mark("telegram", false, now), ShouldBeFalse)
+		So(health.mark("telegram", false, now), ShouldBeFalse)
+		So(health.mark("telegram", true, now), ShouldBeFalse)
+		So(health.mark("telegram", true, now.Add(time.Second)), ShouldBeTrue)
+		So(health.isDegraded("telegram", now.Add(time.Second)), ShouldBeTrue)
+		So(health.isDegraded("slack", now.Add(time.Second)), ShouldBeFalse)
+		So(health.getDegraded(now.Add(time.Second)), ShouldResemble, map[string]time.Time{"telegram": now.Add(time.Second)})
+
+		So(health.mark("telegram", false, now.Add(time.Second*2)), ShouldBeTrue)
+		So(health.isDegraded("telegram", now.Add(time.Second*2)), ShouldBeFalse)
+	})
+
+	Convey("Old failures are out of window, sender is recovered", t, func() {
+		health := newSendersHealth(config)
+		for i := 0; i < 4; i++ {
+			health.mark("telegram", true, now)
+		}
+		So(health.isDegraded("telegram", now), ShouldBeTrue)
+		So(health.isDegraded("telegram", now.Add(time.Minute*6)), ShouldBeFalse)
+		So(health.getDegraded(now.Add(time.Minute*6)), ShouldBeEmpty)
+	})
+}
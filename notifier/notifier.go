@@ -29,6 +29,7 @@ type Notifier interface {
 	RegisterSender(senderSettings map[string]string, sender moira.Sender) error
 	StopSenders()
 	GetSenders() map[string]bool
+	GetDegradedSenders() map[string]time.Time
 }
 
 // StandardNotifier represent notification functionality
@@ -40,6 +41,7 @@ type StandardNotifier struct {
 	scheduler Scheduler
 	config    Config
 	metrics   *graphite.NotifierMetrics
+	health    *sendersHealth
 }
 
 // NewNotifier is initializer for StandardNotifier
@@ -51,14 +53,20 @@ func NewNotifier(database moira.Database, logger moira.Logger, config Config, me
 		scheduler: NewScheduler(database, logger, metrics),
 		config:    config,
 		metrics:   metrics,
+		health:    newSendersHealth(config),
 	}
 }
 
 // Send is realization of StandardNotifier Send functionality
 func (notifier *StandardNotifier) Send(pkg *NotificationPackage, waitGroup *sync.WaitGroup) {
+	if pkg.Contact.Fallback != "" && notifier.health.isDegraded(pkg.Contact.Type, time.Now()) {
+		notifier.useFallbackContact(pkg)
+	}
 	ch, found := notifier.senders[pkg.Contact.Type]
 	if !found {
-		notifier.resend(pkg, fmt.Sprintf("Unknown contact type [%s]", pkg))
+		reason := fmt.Sprintf("Unknown contact type [%s]", pkg)
+		notifier.saveDelivery(pkg, reason)
+		notifier.resend(pkg, reason)
 		return
 	}
 	waitGroup.Add(1)
@@ -69,12 +77,20 @@ func (notifier *StandardNotifier) Send(pkg *NotificationPackage, waitGroup *sync
 		case ch <- *pkg:
 			break
 		case <-time.After(notifier.config.SendingTimeout):
-			notifier.resend(pkg, fmt.Sprintf("Timeout sending %s", pkg))
+			reason := fmt.Sprintf("Timeout sending %s", pkg)
+			notifier.markSenderHealth(pkg.Contact.Type, true)
+			notifier.saveDelivery(pkg, reason)
+			notifier.resend(pkg, reason)
 			break
 		}
 	}(pkg)
 }
 
+// GetDegradedSenders get sender types with too many sending failures and time when they became degraded
+func (notifier *StandardNotifier) GetDegradedSenders() map[string]time.Time {
+	return notifier.health.getDegraded(time.Now())
+}
+
 // GetSenders get hash of registered notifier senders
 func (notifier *StandardNotifier) GetSenders() map[string]bool {
 	hash := make(map[string]bool)
@@ -105,16 +121,97 @@ func (notifier *StandardNotifier) resend(pkg *NotificationPackage, reason string
 	}
 }
 
-func (notifier *StandardNotifier) run(sender moira.Sender, ch chan NotificationPackage) {
+func (notifier *StandardNotifier) run(sender moira.Sender, ch chan NotificationPackage, limits rateLimits) {
 	defer notifier.waitGroup.Done()
-	for pkg := range ch {
-		err := sender.SendEvents(pkg.Events, pkg.Contact, pkg.Trigger, pkg.Throttled)
-		if err == nil {
-			if metric, found := notifier.metrics.SendersOkMetrics.GetMetric(pkg.Contact.Type); found {
-				metric.Mark(1)
+	if !limits.isEnabled() {
+		for pkg := range ch {
+			notifier.sendEvents(sender, &pkg)
+		}
+		return
+	}
+
+	// packages are received from channel immediately and wait for sending in queue, so Send doesn't time out
+	queue := newPackagesQueue(limits, time.Now())
+	for {
+		pkg, delay := queue.pop(time.Now())
+		if pkg != nil {
+			notifier.sendEvents(sender, pkg)
+			continue
+		}
+		var wait <-chan time.Time
+		if delay > 0 {
+			wait = time.After(delay)
+		}
+		select {
+		case newPkg, ok := <-ch:
+			if !ok {
+				for _, queued := range queue.drain() {
+					notifier.resend(queued, fmt.Sprintf("Notifier stopped before sending rate limited %s", queued))
+				}
+				return
+			}
+			if queue.push(newPkg) {
+				notifier.logger.Debugf("Rate limited %s merged with queued package", &newPkg)
 			}
-		} else {
-			notifier.resend(&pkg, err.Error())
+		case <-wait:
+		}
+	}
+}
+
+func (notifier *StandardNotifier) sendEvents(sender moira.Sender, pkg *NotificationPackage) {
+	err := sender.SendEvents(pkg.Events, pkg.Contact, pkg.Trigger, pkg.Throttled)
+	notifier.markSenderHealth(pkg.Contact.Type, err != nil)
+	if err == nil {
+		notifier.saveDelivery(pkg, "")
+		if metric, found := notifier.metrics.SendersOkMetrics.GetMetric(pkg.Contact.Type); found {
+			metric.Mark(1)
 		}
+	} else {
+		notifier.saveDelivery(pkg, err.Error())
+		notifier.resend(pkg, err.Error())
+	}
+}
+
+func (notifier *StandardNotifier) useFallbackContact(pkg *NotificationPackage) {
+	fallback, err := notifier.database.GetContact(pkg.Contact.Fallback)
+	if err != nil {
+		notifier.logger.Errorf("Failed to get fallback contact %s for degraded sender %s: %s", pkg.Contact.Fallback, pkg.Contact.Type, err)
+		return
+	}
+	if _, found := notifier.senders[fallback.Type]; !found || notifier.health.isDegraded(fallback.Type, time.Now()) {
+		notifier.logger.Warningf("Fallback contact %s:%s for degraded sender %s is unavailable", fallback.Type, fallback.Value, pkg.Contact.Type)
+		return
+	}
+	notifier.logger.Infof("Sender %s is degraded, rerouting %s to fallback contact %s:%s", pkg.Contact.Type, pkg, fallback.Type, fallback.Value)
+	pkg.Contact = fallback
+}
+
+func (notifier *StandardNotifier) markSenderHealth(senderType string, failed bool) {
+	if !notifier.health.mark(senderType, failed, time.Now()) {
+		return
+	}
+	if failed {
+		notifier.logger.Warningf("Sender %s is degraded: too many sending failures", senderType)
+	} else {
+		notifier.logger.Infof("Sender %s is recovered", senderType)
+	}
+}
+
+// saveDelivery writes package sending attempt to delivery log, empty reason means successful delivery
+func (notifier *StandardNotifier) saveDelivery(pkg *NotificationPackage, reason string) {
+	delivery := &moira.DeliveryData{
+		Timestamp:   time.Now().Unix(),
+		Contact:     pkg.Contact,
+		TriggerID:   pkg.Trigger.ID,
+		EventsCount: len(pkg.Events),
+		Result:      moira.DeliveryOK,
+		Attempt:     pkg.FailCount + 1,
+	}
+	if reason != "" {
+		delivery.Result = moira.DeliveryFailed
+		delivery.Error = reason
+	}
+	if err := notifier.database.AddDelivery(delivery); err != nil {
+		notifier.logger.Errorf("Failed to save delivery to %s:%s: %s", pkg.Contact.Type, pkg.Contact.Value, err)
 	}
 }
@@ -38,6 +38,7 @@ func TestUnknownContactType(t *testing.T) {
 		},
 	}
 	notification := moira.ScheduledNotification{}
+	dataBase.EXPECT().AddDelivery(&deliveryMatcher{moira.DeliveryFailed, 1}).Return(nil)
 	scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, pkg.Trigger, pkg.Contact, pkg.Throttled, pkg.FailCount+1).Return(&notification)
 	dataBase.EXPECT().AddNotification(&notification).Return(nil)
 
@@ -60,6 +61,7 @@ func TestFailSendEvent(t *testing.T) {
 	}
 	notification := moira.ScheduledNotification{}
 	sender.EXPECT().SendEvents(eventsData, pkg.Contact, pkg.Trigger, pkg.Throttled).Return(fmt.Errorf("Cant't send"))
+	dataBase.EXPECT().AddDelivery(&deliveryMatcher{moira.DeliveryFailed, 1}).Return(nil)
 	scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, pkg.Trigger, pkg.Contact, pkg.Throttled, pkg.FailCount+1).Return(&notification)
 	dataBase.EXPECT().AddNotification(&notification).Return(nil)
 
@@ -94,6 +96,7 @@ func TestTimeout(t *testing.T) {
 		logger.Debugf("Trying to send for 10 second")
 		time.Sleep(time.Second * 10)
 	})
+	dataBase.EXPECT().AddDelivery(gomock.Any()).Return(nil).AnyTimes()
 	scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, pkg2.Trigger, pkg2.Contact, pkg2.Throttled, pkg2.FailCount+1).Return(&notification)
 	dataBase.EXPECT().AddNotification(&notification).Return(nil).Do(func(f ...interface{}) { close(shutdown) })
 
@@ -105,6 +108,141 @@ func TestTimeout(t *testing.T) {
 	waitTestEnd()
 }
 
+func TestSaveDelivery(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+
+	var eventsData moira.NotificationEvents = []moira.NotificationEvent{event}
+
+	pkg := NotificationPackage{
+		Events:    eventsData,
+		Trigger:   moira.TriggerData{ID: event.TriggerID},
+		Contact:   moira.ContactData{ID: "ContactID-000000000000001", Type: "test", Value: "test contact"},
+		FailCount: 2,
+	}
+	done := make(chan bool)
+	sender.EXPECT().SendEvents(eventsData, pkg.Contact, pkg.Trigger, pkg.Throttled).Return(nil)
+	dataBase.EXPECT().AddDelivery(gomock.Any()).Return(nil).Do(func(delivery *moira.DeliveryData) {
+		Convey("Should save successful delivery", t, func() {
+			So(delivery.Contact, ShouldResemble, pkg.Contact)
+			So(delivery.TriggerID, ShouldEqual, event.TriggerID)
+			So(delivery.EventsCount, ShouldEqual, 1)
+			So(delivery.Result, ShouldEqual, moira.DeliveryOK)
+			So(delivery.Error, ShouldBeEmpty)
+			So(delivery.Attempt, ShouldEqual, 3)
+		})
+		close(done)
+	})
+
+	var wg sync.WaitGroup
+	notif.Send(&pkg, &wg)
+	wg.Wait()
+	select {
+	case <-done:
+	case <-time.After(time.Second * 5):
+		t.Error("Delivery was not saved")
+	}
+}
+
+func TestFallbackContact(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+
+	var eventsData moira.NotificationEvents = []moira.NotificationEvent{event}
+	contact := moira.ContactData{ID: "ContactID-000000000000001", Type: "degraded", Value: "degraded contact", Fallback: "ContactID-000000000000002"}
+	fallback := moira.ContactData{ID: "ContactID-000000000000002", Type: "test", Value: "fallback contact"}
+
+	for i := 0; i < 3; i++ {
+		notif.health.mark(contact.Type, true, time.Now())
+	}
+
+	Convey("Sender is degraded, should send package to fallback contact", t, func() {
+		pkg := NotificationPackage{Events: eventsData, Contact: contact}
+		done := make(chan bool)
+		dataBase.EXPECT().GetContact(contact.Fallback).Return(fallback, nil)
+		sender.EXPECT().SendEvents(eventsData, fallback, pkg.Trigger, pkg.Throttled).Return(nil)
+		dataBase.EXPECT().AddDelivery(gomock.Any()).Return(nil).Do(func(delivery *moira.DeliveryData) {
+			close(done)
+		})
+
+		var wg sync.WaitGroup
+		notif.Send(&pkg, &wg)
+		wg.Wait()
+		select {
+		case <-done:
+		case <-time.After(time.Second * 5):
+			t.Error("Package was not sent to fallback contact")
+		}
+		So(notif.GetDegradedSenders(), ShouldContainKey, contact.Type)
+	})
+
+	Convey("Fallback contact is unavailable, should send package to contact", t, func() {
+		pkg := NotificationPackage{Events: eventsData, Contact: contact}
+		dataBase.EXPECT().GetContact(contact.Fallback).Return(moira.ContactData{}, fmt.Errorf("no contact"))
+		dataBase.EXPECT().AddDelivery(gomock.Any()).Return(nil)
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event, pkg.Trigger, contact, pkg.Throttled, pkg.FailCount+1).Return(&moira.ScheduledNotification{})
+		dataBase.EXPECT().AddNotification(gomock.Any()).Return(nil)
+
+		var wg sync.WaitGroup
+		notif.Send(&pkg, &wg)
+		wg.Wait()
+	})
+}
+
+func TestRateLimitedSender(t *testing.T) {
+	configureNotifier(t)
+	defer afterTest()
+
+	limitedSender := mock_moira_alert.NewMockSender(mockCtrl)
+	senderSettings := map[string]string{
+		"type":                   "limited",
+		"destination_rate_limit": "0.1",
+	}
+	limitedSender.EXPECT().Init(senderSettings, logger, gomock.Any()).Return(nil)
+	notif.RegisterSender(senderSettings, limitedSender)
+
+	contact := moira.ContactData{Type: "limited", Value: "limited contact"}
+	event2 := event
+	event2.Metric = "generate.event.2"
+	pkg := NotificationPackage{Events: []moira.NotificationEvent{event}, Trigger: moira.TriggerData{ID: event.TriggerID}, Contact: contact}
+	pkg2 := NotificationPackage{Events: []moira.NotificationEvent{event2}, Trigger: moira.TriggerData{ID: event.TriggerID}, Contact: contact}
+	pkg3 := NotificationPackage{Events: []moira.NotificationEvent{event2}, Trigger: moira.TriggerData{ID: event.TriggerID}, Contact: contact}
+
+	Convey("Rate limited packages should not time out and should be merged in queue", t, func() {
+		sent := make(chan bool)
+		limitedSender.EXPECT().SendEvents(moira.NotificationEvents{event}, contact, pkg.Trigger, false).Return(nil).Do(func(f ...interface{}) { sent <- true })
+		dataBase.EXPECT().AddDelivery(gomock.Any()).Return(nil).AnyTimes()
+
+		var wg sync.WaitGroup
+		notif.Send(&pkg, &wg)
+		wg.Wait()
+		<-sent
+		notif.Send(&pkg2, &wg)
+		notif.Send(&pkg3, &wg)
+		wg.Wait()
+
+		merged := NotificationPackage{Events: []moira.NotificationEvent{event2, event2}, Trigger: pkg.Trigger, Contact: contact, FailCount: 0}
+		scheduler.EXPECT().ScheduleNotification(gomock.Any(), event2, merged.Trigger, contact, false, 1).Return(&moira.ScheduledNotification{}).Times(2)
+		dataBase.EXPECT().AddNotification(gomock.Any()).Return(nil).Times(2)
+		notif.StopSenders()
+	})
+}
+
+// deliveryMatcher matches delivery log record by result and attempt number
+type deliveryMatcher struct {
+	result  string
+	attempt int
+}
+
+func (matcher *deliveryMatcher) Matches(x interface{}) bool {
+	delivery, ok := x.(*moira.DeliveryData)
+	return ok && delivery.Result == matcher.result && delivery.Attempt == matcher.attempt
+}
+
+func (matcher *deliveryMatcher) String() string {
+	return fmt.Sprintf("delivery with result %s and attempt %d", matcher.result, matcher.attempt)
+}
+
 func waitTestEnd() {
 	select {
 	case <-shutdown:
@@ -119,9 +257,12 @@ func configureNotifier(t *testing.T) {
 	metrics := metrics.ConfigureNotifierMetrics("notifier")
 	var location, _ = time.LoadLocation("UTC")
 	config := Config{
-		SendingTimeout:   time.Millisecond * 10,
-		ResendingTimeout: time.Hour * 24,
-		Location:         location,
+		SendingTimeout:       time.Millisecond * 10,
+		ResendingTimeout:     time.Hour * 24,
+		SenderFailureRate:    0.5,
+		SenderFailuresWindow: time.Minute,
+		SenderMinAttempts:    3,
+		Location:             location,
 	}
 
 	mockCtrl = gomock.NewController(t)
@@ -0,0 +1,202 @@
+package notifier
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// rateLimits represent sender rate limits in messages per second, zero rate means no limit
+type rateLimits struct {
+	senderRate       float64
+	senderBurst      int
+	destinationRate  float64
+	destinationBurst int
+}
+
+func (limits rateLimits) isEnabled() bool {
+	return limits.senderRate > 0 || limits.destinationRate > 0
+}
+
+// parseRateLimits reads rate limits from sender settings:
+// rate_limit and rate_limit_burst for all sender messages, destination_rate_limit and destination_rate_limit_burst for every contact
+func parseRateLimits(senderSettings map[string]string) (rateLimits, error) {
+	var (
+		limits rateLimits
+		err    error
+	)
+	if limits.senderRate, limits.senderBurst, err = parseRateLimit(senderSettings, "rate_limit"); err != nil {
+		return limits, err
+	}
+	if limits.destinationRate, limits.destinationBurst, err = parseRateLimit(senderSettings, "destination_rate_limit"); err != nil {
+		return limits, err
+	}
+	return limits, nil
+}
+
+func parseRateLimit(senderSettings map[string]string, name string) (float64, int, error) {
+	var (
+		rate  float64
+		burst = 1
+		err   error
+	)
+	if value := senderSettings[name]; value != "" {
+		if rate, err = strconv.ParseFloat(value, 64); err != nil || rate < 0 {
+			return 0, 0, fmt.Errorf("Invalid %s value '%s'", name, value)
+		}
+	}
+	if value := senderSettings[name+"_burst"]; value != "" {
+		if burst, err = strconv.Atoi(value); err != nil || burst < 1 {
+			return 0, 0, fmt.Errorf("Invalid %s_burst value '%s'", name, value)
+		}
+	}
+	return rate, burst, nil
+}
+
+// tokenBucket allows burst of messages and then rate messages per second
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int, now time.Time) *tokenBucket {
+	return &tokenBucket{
+		rate:   rate,
+		burst:  float64(burst),
+		tokens: float64(burst),
+		last:   now,
+	}
+}
+
+func (bucket *tokenBucket) refill(now time.Time) {
+	if now.After(bucket.last) {
+		bucket.tokens = math.Min(bucket.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.rate)
+		bucket.last = now
+	}
+}
+
+// delay returns time to wait before one token will be available
+func (bucket *tokenBucket) delay(now time.Time) time.Duration {
+	if bucket == nil {
+		return 0
+	}
+	bucket.refill(now)
+	if bucket.tokens >= 1 {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - bucket.tokens) / bucket.rate * float64(time.Second)))
+}
+
+func (bucket *tokenBucket) take(now time.Time) {
+	if bucket == nil {
+		return
+	}
+	bucket.refill(now)
+	bucket.tokens--
+}
+
+func (bucket *tokenBucket) isFull(now time.Time) bool {
+	bucket.refill(now)
+	return bucket.tokens >= bucket.burst
+}
+
+// packagesQueue holds packages exceeded sender rate limits
+// packages to the same contact about the same trigger are merged while waiting in queue
+type packagesQueue struct {
+	limits       rateLimits
+	sender       *tokenBucket
+	destinations map[string]*tokenBucket
+	packages     []*NotificationPackage
+}
+
+func newPackagesQueue(limits rateLimits, now time.Time) *packagesQueue {
+	queue := &packagesQueue{
+		limits:       limits,
+		destinations: make(map[string]*tokenBucket),
+		packages:     make([]*NotificationPackage, 0),
+	}
+	if limits.senderRate > 0 {
+		queue.sender = newTokenBucket(limits.senderRate, limits.senderBurst, now)
+	}
+	return queue
+}
+
+// push adds package to queue and returns true if it was merged with already queued package
+func (queue *packagesQueue) push(pkg NotificationPackage) bool {
+	for _, queued := range queue.packages {
+		if getDestination(queued) == getDestination(&pkg) && queued.Trigger.ID == pkg.Trigger.ID {
+			queued.Events = append(queued.Events, pkg.Events...)
+			queued.Throttled = queued.Throttled || pkg.Throttled
+			queued.DontResend = queued.DontResend && pkg.DontResend
+			if pkg.FailCount > queued.FailCount {
+				queued.FailCount = pkg.FailCount
+			}
+			return true
+		}
+	}
+	queue.packages = append(queue.packages, &pkg)
+	return false
+}
+
+// pop returns first package allowed to be sent now, otherwise returns nil and time to wait
+// negative time to wait means that queue is empty
+func (queue *packagesQueue) pop(now time.Time) (*NotificationPackage, time.Duration) {
+	queue.cleanup(now)
+	if len(queue.packages) == 0 {
+		return nil, -1
+	}
+	if delay := queue.sender.delay(now); delay > 0 {
+		return nil, delay
+	}
+	var minDelay time.Duration = -1
+	for i, pkg := range queue.packages {
+		bucket := queue.getDestinationBucket(pkg, now)
+		delay := bucket.delay(now)
+		if delay == 0 {
+			queue.sender.take(now)
+			bucket.take(now)
+			queue.packages = append(queue.packages[:i], queue.packages[i+1:]...)
+			return pkg, 0
+		}
+		if minDelay < 0 || delay < minDelay {
+			minDelay = delay
+		}
+	}
+	return nil, minDelay
+}
+
+// drain removes all packages from queue
+func (queue *packagesQueue) drain() []*NotificationPackage {
+	packages := queue.packages
+	queue.packages = make([]*NotificationPackage, 0)
+	return packages
+}
+
+func (queue *packagesQueue) getDestinationBucket(pkg *NotificationPackage, now time.Time) *tokenBucket {
+	if queue.limits.destinationRate <= 0 {
+		return nil
+	}
+	destination := getDestination(pkg)
+	bucket, ok := queue.destinations[destination]
+	if !ok {
+		bucket = newTokenBucket(queue.limits.destinationRate, queue.limits.destinationBurst, now)
+		queue.destinations[destination] = bucket
+	}
+	return bucket
+}
+
+// cleanup removes full destination buckets because they are the same as new ones
+func (queue *packagesQueue) cleanup(now time.Time) {
+	for destination, bucket := range queue.destinations {
+		if bucket.isFull(now) {
+			delete(queue.destinations, destination)
+		}
+	}
+}
+
+func getDestination(pkg *NotificationPackage) string {
+	return fmt.Sprintf("%s:%s", pkg.Contact.Type, pkg.Contact.Value)
+}
@@ -0,0 +1,119 @@
+package notifier
+
+import (
+	"github.com/moira-alert/moira"
+	. "github.com/smartystreets/goconvey/convey"
+	"testing"
+	"time"
+)
+
+func TestParseRateLimits(t *testing.T) {
+	Convey("No limits", t, func() {
+		limits, err := parseRateLimits(map[string]string{"type": "slack"})
+		So(err, ShouldBeNil)
+		So(limits.isEnabled(), ShouldBeFalse)
+	})
+
+	Convey("Sender and destination limits", t, func() {
+		limits, err := parseRateLimits(map[string]string{
+			"type":                         "telegram",
+			"rate_limit":                   "30",
+			"rate_limit_burst":             "30",
+			"destination_rate_limit":       "0.5",
+			"destination_rate_limit_burst": "",
+		})
+		So(err, ShouldBeNil)
+		So(limits, ShouldResemble, rateLimits{senderRate: 30, senderBurst: 30, destinationRate: 0.5, destinationBurst: 1})
+		So(limits.isEnabled(), ShouldBeTrue)
+	})
+
+	Convey("Invalid limits", t, func() {
+		_, err := parseRateLimits(map[string]string{"rate_limit": "fast"})
+		So(err, ShouldNotBeNil)
+		_, err = parseRateLimits(map[string]string{"rate_limit": "-1"})
+		So(err, ShouldNotBeNil)
+		_, err = parseRateLimits(map[string]string{"destination_rate_limit": "1", "destination_rate_limit_burst": "0"})
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestTokenBucket(t *testing.T) {
+	now := time.Unix(1441134000, 0)
+
+	Convey("Burst is available at once, then tokens are refilled with rate", t, func() {
+		bucket := newTokenBucket(2, 2, now)
+		So(bucket.delay(now), ShouldEqual, 0)
+		bucket.take(now)
+		So(bucket.delay(now), ShouldEqual, 0)
+		bucket.take(now)
+		So(bucket.delay(now), ShouldEqual, time.Millisecond*500)
+		So(bucket.delay(now.Add(time.Millisecond*200)), ShouldEqual, time.Millisecond*300)
+		So(bucket.delay(now.Add(time.Millisecond*500)), ShouldEqual, 0)
+		So(bucket.isFull(now.Add(time.Second*10)), ShouldBeTrue)
+	})
+
+	Convey("Nil bucket has no limits", t, func() {
+		var bucket *tokenBucket
+		bucket.take(now)
+		So(bucket.delay(now), ShouldEqual, 0)
+	})
+}
+
+func TestPackagesQueue(t *testing.T) {
+	now := time.Unix(1441134000, 0)
+	contact1 := moira.ContactData{Type: "slack", Value: "#channel1"}
+	contact2 := moira.ContactData{Type: "slack", Value: "#channel2"}
+	trigger1 := moira.TriggerData{ID: "trigger1"}
+	trigger2 := moira.TriggerData{ID: "trigger2"}
+	event1 := moira.NotificationEvent{TriggerID: "trigger1", Metric: "metric1"}
+	event2 := moira.NotificationEvent{TriggerID: "trigger1", Metric: "metric2"}
+
+	Convey("Empty queue", t, func() {
+		queue := newPackagesQueue(rateLimits{senderRate: 1, senderBurst: 1}, now)
+		pkg, delay := queue.pop(now)
+		So(pkg, ShouldBeNil)
+		So(delay, ShouldBeLessThan, 0)
+	})
+
+	Convey("Packages to the same contact about the same trigger are merged", t, func() {
+		queue := newPackagesQueue(rateLimits{senderRate: 1, senderBurst: 1}, now)
+		So(queue.push(NotificationPackage{Contact: contact1, Trigger: trigger1, Events: []moira.NotificationEvent{event1}}), ShouldBeFalse)
+		So(queue.push(NotificationPackage{Contact: contact1, Trigger: trigger1, Events: []moira.NotificationEvent{event2}, Throttled: true, FailCount: 2}), ShouldBeTrue)
+		So(queue.push(NotificationPackage{Contact: contact1, Trigger: trigger2}), ShouldBeFalse)
+		So(queue.push(NotificationPackage{Contact: contact2, Trigger: trigger1}), ShouldBeFalse)
+
+		pkg, delay := queue.pop(now)
+		So(delay, ShouldEqual, 0)
+		So(pkg, ShouldResemble, &NotificationPackage{Contact: contact1, Trigger: trigger1, Events: []moira.NotificationEvent{event1, event2}, Throttled: true, FailCount: 2})
+
+		pkg, delay = queue.pop(now)
+		So(pkg, ShouldBeNil)
+		So(delay, ShouldEqual, time.Second)
+
+		pkg, _ = queue.pop(now.Add(time.Second))
+		So(pkg.Trigger, ShouldResemble, trigger2)
+		So(len(queue.drain()), ShouldEqual, 1)
+	})
+
+	Convey("Destination limit, package to other destination goes first", t, func() {
+		queue := newPackagesQueue(rateLimits{destinationRate: 0.5, destinationBurst: 1}, now)
+		queue.push(NotificationPackage{Contact: contact1, Trigger: trigger1})
+		queue.push(NotificationPackage{Contact: contact1, Trigger: trigger2})
+		queue.push(NotificationPackage{Contact: contact2, Trigger: trigger1})
+
+		pkg, _ := queue.pop(now)
+		So(pkg.Contact, ShouldResemble, contact1)
+		So(pkg.Trigger, ShouldResemble, trigger1)
+
+		pkg, _ = queue.pop(now)
+		So(pkg.Contact, ShouldResemble, contact2)
+
+		pkg, delay := queue.pop(now)
+		So(pkg, ShouldBeNil)
+		So(delay, ShouldEqual, time.Second*2)
+
+		pkg, _ = queue.pop(now.Add(time.Second * 2))
+		So(pkg.Contact, ShouldResemble, contact1)
+		So(pkg.Trigger, ShouldResemble, trigger2)
+	})
+}
@@ -46,7 +46,7 @@ func (notifier *StandardNotifier) RegisterSenders(connector moira.Database) erro
 				notifier.logger.Fatalf("Can not register sender %s: %s", senderSettings["type"], err)
 			}
 		case "twilio voice":
-			if err := notifier.RegisterSender(senderSettings, &twilio.Sender{}); err != nil {
+			if err := notifier.RegisterSender(senderSettings, &twilio.Sender{DataBase: connector}); err != nil {
 				notifier.logger.Fatalf("Can not register sender %s: %s", senderSettings["type"], err)
 			}
 		// case "email":
@@ -70,7 +70,11 @@ func (notifier *StandardNotifier) RegisterSender(senderSettings map[string]strin
 	} else {
 		senderIdent = senderSettings["type"]
 	}
-	err := sender.Init(senderSettings, notifier.logger, notifier.config.Location)
+	limits, err := parseRateLimits(senderSettings)
+	if err != nil {
+		return fmt.Errorf("Don't initialize sender [%s], err [%s]", senderIdent, err.Error())
+	}
+	err = sender.Init(senderSettings, notifier.logger, notifier.config.Location)
 	if err != nil {
 		return fmt.Errorf("Don't initialize sender [%s], err [%s]", senderIdent, err.Error())
 	}
@@ -79,7 +83,7 @@ func (notifier *StandardNotifier) RegisterSender(senderSettings map[string]strin
 	notifier.metrics.SendersOkMetrics.AddMetric(senderIdent, fmt.Sprintf("notifier.%s.sends_ok", getGraphiteSenderIdent(senderIdent)))
 	notifier.metrics.SendersFailedMetrics.AddMetric(senderIdent, fmt.Sprintf("notifier.%s.sends_failed", getGraphiteSenderIdent(senderIdent)))
 	notifier.waitGroup.Add(1)
-	go notifier.run(sender, ch)
+	go notifier.run(sender, ch, limits)
 	notifier.logger.Debugf("Sender %s registered", senderIdent)
 	return nil
 }
@@ -48,7 +48,11 @@ func (scheduler *StandardScheduler) ScheduleNotification(now time.Time, event mo
 			next = now
 			throttled = false
 		} else {
-			next, throttled = scheduler.calculateNextDelivery(now, &event)
+			var rerouteContactID string
+			next, throttled, rerouteContactID = scheduler.calculateNextDelivery(now, &event)
+			if rerouteContactID != "" {
+				contact = scheduler.getRerouteContact(contact, rerouteContactID)
+			}
 		}
 	}
 	notification := &moira.ScheduledNotification{
@@ -67,7 +71,8 @@ func (scheduler *StandardScheduler) ScheduleNotification(now time.Time, event mo
 	return notification
 }
 
-func (scheduler *StandardScheduler) calculateNextDelivery(now time.Time, event *moira.NotificationEvent) (time.Time, bool) {
+// calculateNextDelivery returns next delivery time, alarm fatigue flag and fallback contact id if notification must be rerouted due to holidays
+func (scheduler *StandardScheduler) calculateNextDelivery(now time.Time, event *moira.NotificationEvent) (time.Time, bool, string) {
 	// if trigger switches more than .count times in .length seconds, delay next delivery for .delay seconds
 	// processing stops after first condition matches
 	throttlingLevels := []throttlingLevel{
@@ -89,7 +94,7 @@ func (scheduler *StandardScheduler) calculateNextDelivery(now time.Time, event *
 	if err != nil {
 		scheduler.metrics.SubsMalformed.Mark(1)
 		scheduler.logger.Debugf("Failed get subscription by id: %s. %s", moira.UseString(event.SubscriptionID), err.Error())
-		return next, alarmFatigue
+		return next, alarmFatigue, ""
 	}
 
 	if subscription.ThrottlingEnabled {
@@ -118,11 +123,75 @@ func (scheduler *StandardScheduler) calculateNextDelivery(now time.Time, event *
 	} else {
 		next = now
 	}
+
+	// throttling window starts at the last scheduled delivery of subscription notifications about trigger
+	// events up to that delivery join it, later events in window are delayed to the window end and start new delivery
+	throttlingWindow := time.Duration(subscription.ThrottlingWindow) * time.Minute
+	if throttlingWindow > 0 {
+		windowEnd := scheduler.database.GetSubscriptionThrottling(subscription.ID, event.TriggerID)
+		lastDelivery := windowEnd.Add(-throttlingWindow)
+		if !next.After(lastDelivery) {
+			scheduler.logger.Debugf("Trigger %s notification joins scheduled delivery of subscription %s at %s", event.TriggerID, subscription.ID, lastDelivery)
+			return lastDelivery, alarmFatigue, scheduler.getHolidaysRerouteContactID(subscription, lastDelivery)
+		}
+		if windowEnd.After(next) {
+			scheduler.logger.Debugf("Trigger %s is in throttling window of subscription %s, delaying next notification to %s", event.TriggerID, subscription.ID, windowEnd)
+			next = windowEnd
+		}
+	}
+
 	next, err = calculateNextDelivery(&subscription.Schedule, next)
 	if err != nil {
 		scheduler.logger.Errorf("Failed to apply schedule for subscriptionID: %s. %s.", moira.UseString(event.SubscriptionID), err)
 	}
-	return next, alarmFatigue
+
+	rerouteContactID := scheduler.getHolidaysRerouteContactID(subscription, next)
+	if rerouteContactID == "" && subscription.Holidays.IsHoliday(next, subscription.Schedule.TimezoneOffset) {
+		next, err = calculateNextNonHolidayDelivery(&subscription.Schedule, subscription.Holidays, next)
+		if err != nil {
+			scheduler.logger.Errorf("Failed to apply holidays for subscriptionID: %s. %s.", moira.UseString(event.SubscriptionID), err)
+		}
+	}
+
+	if throttlingWindow > 0 {
+		if err = scheduler.database.SetSubscriptionThrottling(subscription.ID, event.TriggerID, next.Add(throttlingWindow)); err != nil {
+			scheduler.logger.Errorf("Failed to set subscription throttling timestamp: %s", err)
+		}
+	}
+	return next, alarmFatigue, rerouteContactID
+}
+
+// getHolidaysRerouteContactID returns holidays contact id if delivery falls on subscription holiday with reroute
+func (scheduler *StandardScheduler) getHolidaysRerouteContactID(subscription moira.SubscriptionData, delivery time.Time) string {
+	if subscription.Holidays.IsReroute() && subscription.Holidays.IsHoliday(delivery, subscription.Schedule.TimezoneOffset) {
+		return subscription.Holidays.Contact
+	}
+	return ""
+}
+
+func (scheduler *StandardScheduler) getRerouteContact(contact moira.ContactData, contactID string) moira.ContactData {
+	rerouteContact, err := scheduler.database.GetContact(contactID)
+	if err != nil {
+		scheduler.logger.Errorf("Failed to get holidays contact %s, notification will be sent to %s:%s. %s", contactID, contact.Type, contact.Value, err)
+		return contact
+	}
+	return rerouteContact
+}
+
+func calculateNextNonHolidayDelivery(schedule *moira.ScheduleData, holidays *moira.HolidaysData, nextTime time.Time) (time.Time, error) {
+	tzOffset := time.Duration(schedule.TimezoneOffset) * time.Minute
+	for i := 0; i < 366; i++ {
+		if !holidays.IsHoliday(nextTime, schedule.TimezoneOffset) {
+			return nextTime, nil
+		}
+		nextLocalDayBegin := nextTime.Add(-tzOffset).Truncate(24 * time.Hour).Add(24 * time.Hour)
+		next, err := calculateNextDelivery(schedule, nextLocalDayBegin.Add(tzOffset))
+		if err != nil {
+			return nextTime, err
+		}
+		nextTime = next
+	}
+	return nextTime, fmt.Errorf("Can not find allowed non-holiday day")
 }
 
 func calculateNextDelivery(schedule *moira.ScheduleData, nextTime time.Time) (time.Time, error) {
@@ -136,7 +136,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, now)
 			So(throttled, ShouldBeFalse)
 			mockCtrl.Finish()
@@ -147,7 +147,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, time.Unix(1441191600, 0))
 			So(throttled, ShouldBeFalse)
 			mockCtrl.Finish()
@@ -159,7 +159,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, time.Unix(1441134000, 0))
 			So(throttled, ShouldBeFalse)
 			mockCtrl.Finish()
@@ -170,7 +170,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(1441187215, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, now)
 			So(throttled, ShouldBeTrue)
 			mockCtrl.Finish()
@@ -187,7 +187,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour*3).Unix()).Return(int64(13))
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour).Unix()).Return(int64(9))
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, now)
 			So(throttled, ShouldBeTrue)
 			mockCtrl.Finish()
@@ -200,7 +200,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour).Unix()).Return(int64(10))
 			dataBase.EXPECT().SetTriggerThrottling(event.TriggerID, now.Add(time.Hour/2)).Return(nil)
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, time.Unix(1441135800, 0))
 			So(throttled, ShouldBeTrue)
 			mockCtrl.Finish()
@@ -212,7 +212,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetNotificationEventCount(event.TriggerID, now.Add(-time.Hour*3).Unix()).Return(int64(20))
 			dataBase.EXPECT().SetTriggerThrottling(event.TriggerID, now.Add(time.Hour)).Return(nil)
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, now.Add(time.Hour))
 			So(throttled, ShouldBeTrue)
 			mockCtrl.Finish()
@@ -222,7 +222,7 @@ func TestSubscriptionSchedule(t *testing.T) {
 			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(1441148000, 0), time.Unix(0, 0))
 			dataBase.EXPECT().GetSubscription(*event.SubscriptionID).Return(subscription, nil)
 
-			next, throttled := scheduler.calculateNextDelivery(now, &event)
+			next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
 			So(next, ShouldResemble, time.Unix(1441148000, 0))
 			So(throttled, ShouldBeTrue)
 			mockCtrl.Finish()
@@ -230,6 +230,176 @@ func TestSubscriptionSchedule(t *testing.T) {
 	})
 }
 
+func TestSubscriptionThrottlingWindow(t *testing.T) {
+	subID := "SubscriptionID-000000000000001"
+	var subscription = moira.SubscriptionData{
+		ID:               subID,
+		Enabled:          true,
+		Tags:             []string{"test-tag"},
+		Contacts:         []string{"ContactID-000000000000001"},
+		ThrottlingWindow: 15,
+	}
+
+	var event = moira.NotificationEvent{
+		Metric:         "generate.event.1",
+		State:          "OK",
+		OldState:       "WARN",
+		TriggerID:      "triggerID-0000000000001",
+		SubscriptionID: &subID,
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Scheduler")
+	scheduler := NewScheduler(dataBase, logger, metrics.ConfigureNotifierMetrics("notifier"))
+	now := time.Unix(1441134000, 0)
+
+	Convey("No previous notification in window, should send notification now", t, func() {
+		dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+		dataBase.EXPECT().GetSubscriptionThrottling(subID, event.TriggerID).Return(time.Unix(0, 0))
+		dataBase.EXPECT().SetSubscriptionThrottling(subID, event.TriggerID, now.Add(15*time.Minute)).Return(nil)
+
+		next, throttled, reroute := scheduler.calculateNextDelivery(now, &event)
+		So(next, ShouldResemble, now)
+		So(throttled, ShouldBeFalse)
+		So(reroute, ShouldBeEmpty)
+	})
+
+	Convey("Previous delivery in window, should send notification at the end of window and start new window there", t, func() {
+		windowEnd := now.Add(5 * time.Minute)
+		dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+		dataBase.EXPECT().GetSubscriptionThrottling(subID, event.TriggerID).Return(windowEnd)
+		dataBase.EXPECT().SetSubscriptionThrottling(subID, event.TriggerID, windowEnd.Add(15*time.Minute)).Return(nil)
+
+		next, throttled, _ := scheduler.calculateNextDelivery(now, &event)
+		So(next, ShouldResemble, windowEnd)
+		So(throttled, ShouldBeFalse)
+	})
+
+	Convey("Burst of events delayed to the same delivery, should join it without moving window", t, func() {
+		delivery := now.Add(5 * time.Minute)
+		for i := 0; i < 3; i++ {
+			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+			dataBase.EXPECT().GetSubscriptionThrottling(subID, event.TriggerID).Return(delivery.Add(15 * time.Minute))
+
+			next, throttled, _ := scheduler.calculateNextDelivery(now.Add(time.Duration(i)*time.Minute), &event)
+			So(next, ShouldResemble, delivery)
+			So(throttled, ShouldBeFalse)
+		}
+	})
+
+	Convey("All contacts of subscription get the same delivery", t, func() {
+		windowEnd := time.Unix(0, 0)
+		for i := 0; i < 2; i++ {
+			dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+			dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+			dataBase.EXPECT().GetSubscriptionThrottling(subID, event.TriggerID).Return(windowEnd)
+			if i == 0 {
+				dataBase.EXPECT().SetSubscriptionThrottling(subID, event.TriggerID, now.Add(15*time.Minute)).Return(nil)
+			}
+
+			next, _, _ := scheduler.calculateNextDelivery(now, &event)
+			So(next, ShouldResemble, now)
+			windowEnd = now.Add(15 * time.Minute)
+		}
+	})
+}
+
+func TestSubscriptionHolidays(t *testing.T) {
+	subID := "SubscriptionID-000000000000001"
+	var subscription = moira.SubscriptionData{
+		ID:       subID,
+		Enabled:  true,
+		Tags:     []string{"test-tag"},
+		Contacts: []string{"ContactID-000000000000001"},
+	}
+
+	var event = moira.NotificationEvent{
+		Metric:         "generate.event.1",
+		State:          "OK",
+		OldState:       "WARN",
+		TriggerID:      "triggerID-0000000000001",
+		SubscriptionID: &subID,
+	}
+
+	var contact = moira.ContactData{
+		ID:    "ContactID-000000000000001",
+		Type:  "email",
+		Value: "mail1@example.com",
+	}
+
+	var fallbackContact = moira.ContactData{
+		ID:    "ContactID-000000000000002",
+		Type:  "email",
+		Value: "duty@example.com",
+	}
+
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Scheduler")
+	scheduler := NewScheduler(dataBase, logger, metrics.ConfigureNotifierMetrics("notifier"))
+
+	// 1441134000 - 09/01/2015 19:00 (UTC) Tue
+	now := time.Unix(1441134000, 0)
+
+	Convey("Not a holiday, should send notification now", t, func() {
+		subscription.Holidays = &moira.HolidaysData{Dates: []string{"2015-09-02"}}
+		dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+
+		next, _, reroute := scheduler.calculateNextDelivery(now, &event)
+		So(next, ShouldResemble, now)
+		So(reroute, ShouldBeEmpty)
+	})
+
+	Convey("Holiday with suppress action, should send notification at the beginning of next non-holiday day", t, func() {
+		subscription.Holidays = &moira.HolidaysData{Dates: []string{"2015-09-01", "2015-09-02"}, Action: moira.HolidayActionSuppress}
+		dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+
+		next, _, reroute := scheduler.calculateNextDelivery(now, &event)
+		So(next, ShouldResemble, time.Unix(1441238400, 0))
+		So(reroute, ShouldBeEmpty)
+	})
+
+	Convey("Holiday in subscription timezone, should use local date", t, func() {
+		subscription.Holidays = &moira.HolidaysData{Dates: []string{"2015-09-02"}}
+		subscription.Schedule = moira.ScheduleData{TimezoneOffset: -300}
+		defer func() { subscription.Schedule = moira.ScheduleData{} }()
+		dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+
+		next, _, _ := scheduler.calculateNextDelivery(now, &event)
+		So(next, ShouldResemble, time.Unix(1441220400, 0))
+	})
+
+	Convey("Holiday with reroute action, should send notification now to fallback contact", t, func() {
+		subscription.Holidays = &moira.HolidaysData{Dates: []string{"2015-09-01"}, Action: moira.HolidayActionReroute, Contact: fallbackContact.ID}
+		dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+		dataBase.EXPECT().GetContact(fallbackContact.ID).Return(fallbackContact, nil)
+
+		notification := scheduler.ScheduleNotification(now, event, moira.TriggerData{}, contact, false, 0)
+		So(notification.Contact, ShouldResemble, fallbackContact)
+		So(notification.Timestamp, ShouldEqual, now.Unix())
+	})
+
+	Convey("Holiday with reroute action and missing fallback contact, should send notification to subscription contact", t, func() {
+		subscription.Holidays = &moira.HolidaysData{Dates: []string{"2015-09-01"}, Action: moira.HolidayActionReroute, Contact: fallbackContact.ID}
+		dataBase.EXPECT().GetTriggerThrottling(event.TriggerID).Return(time.Unix(0, 0), time.Unix(0, 0))
+		dataBase.EXPECT().GetSubscription(subID).Return(subscription, nil)
+		dataBase.EXPECT().GetContact(fallbackContact.ID).Return(moira.ContactData{}, fmt.Errorf("contact not found"))
+
+		notification := scheduler.ScheduleNotification(now, event, moira.TriggerData{}, contact, false, 0)
+		So(notification.Contact, ShouldResemble, contact)
+	})
+}
+
 var schedule1 = moira.ScheduleData{
 	StartOffset:    0,   // 0:00 (GMT +5) after
 	EndOffset:      900, // 15:00 (GMT +5)
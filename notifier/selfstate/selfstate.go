@@ -2,6 +2,8 @@ package selfstate
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 	"sync"
 	"time"
 
@@ -99,6 +101,22 @@ func (selfCheck *SelfCheckWorker) check(nowTS int64, lastMetricReceivedTS, redis
 			selfCheck.Log.Errorf("Moira-Checker does not checks triggers more %ds. Send message.", interval)
 			selfCheck.sendErrorMessages("Moira-Checker does not checks triggers", interval, selfCheck.Config.LastCheckDelay)
 			*nextSendErrorMessage = nowTS + selfCheck.Config.NoticeInterval
+			return
+		}
+		if degradedSenders := selfCheck.Notifier.GetDegradedSenders(); len(degradedSenders) > 0 {
+			senderTypes := make([]string, 0, len(degradedSenders))
+			var interval int64
+			for senderType, degradedSince := range degradedSenders {
+				senderTypes = append(senderTypes, senderType)
+				if nowTS-degradedSince.Unix() > interval {
+					interval = nowTS - degradedSince.Unix()
+				}
+			}
+			sort.Strings(senderTypes)
+			message := fmt.Sprintf("Moira-Notifier senders are degraded: %s", strings.Join(senderTypes, ", "))
+			selfCheck.Log.Errorf("%s. Send message.", message)
+			selfCheck.sendErrorMessages(message, interval, 0)
+			*nextSendErrorMessage = nowTS + selfCheck.Config.NoticeInterval
 		}
 	}
 }
@@ -156,6 +156,67 @@ func TestMoiraCheckerDoesNotChecksTriggers(t *testing.T) {
 	mock.mockCtrl.Finish()
 }
 
+func TestNotifierSendersDegraded(t *testing.T) {
+	adminContact := map[string]string{
+		"type":  "admin-mail",
+		"value": "admin@company.com",
+	}
+
+	var (
+		metricsCount         int64
+		checksCount          int64
+		lastMetricReceivedTS int64
+		redisLastCheckTS     int64
+		lastCheckTS          int64
+		nextSendErrorMessage int64
+	)
+
+	mock := configureWorker(t)
+	mock.selfCheckWorker.Start()
+	Convey("Should notify admin", t, func() {
+		var sendingWG sync.WaitGroup
+		mock.database.EXPECT().GetMetricsUpdatesCount().Return(int64(1), nil)
+		mock.database.EXPECT().GetChecksUpdatesCount().Return(int64(1), nil)
+
+		now := time.Now()
+		redisLastCheckTS = now.Unix()
+		lastCheckTS = now.Unix()
+		nextSendErrorMessage = now.Add(-time.Second * 5).Unix()
+		lastMetricReceivedTS = now.Unix()
+		metricsCount = 1
+		checksCount = 1
+
+		mock.notif.EXPECT().GetDegradedSenders().Return(map[string]time.Time{
+			"telegram": now.Add(-time.Second * 30),
+			"slack":    now.Add(-time.Second * 90),
+		})
+		expectedPackage := configureNotificationPackage(adminContact, 0, 90, "Moira-Notifier senders are degraded: slack, telegram")
+
+		mock.notif.EXPECT().Send(&expectedPackage, &sendingWG)
+		mock.selfCheckWorker.check(now.Unix(), &lastMetricReceivedTS, &redisLastCheckTS, &lastCheckTS, &nextSendErrorMessage, &metricsCount, &checksCount)
+
+		So(nextSendErrorMessage, ShouldEqual, now.Unix()+mock.conf.NoticeInterval)
+	})
+
+	Convey("Should not notify admin if all senders are healthy", t, func() {
+		mock.database.EXPECT().GetMetricsUpdatesCount().Return(int64(1), nil)
+		mock.database.EXPECT().GetChecksUpdatesCount().Return(int64(1), nil)
+
+		now := time.Now()
+		nextSendErrorMessage = now.Add(-time.Second * 5).Unix()
+		redisLastCheckTS = now.Unix()
+		lastCheckTS = now.Unix()
+		lastMetricReceivedTS = now.Unix()
+
+		mock.notif.EXPECT().GetDegradedSenders().Return(map[string]time.Time{})
+		mock.selfCheckWorker.check(now.Unix(), &lastMetricReceivedTS, &redisLastCheckTS, &lastCheckTS, &nextSendErrorMessage, &metricsCount, &checksCount)
+
+		So(nextSendErrorMessage, ShouldEqual, now.Add(-time.Second*5).Unix())
+	})
+	mock.selfCheckWorker.Stop()
+	mock.mockCtrl.Finish()
+}
+
 func TestRunGoRoutine(t *testing.T) {
 	adminContact := map[string]string{
 		"type":  "admin-mail",
@@ -194,6 +255,7 @@ func TestRunGoRoutine(t *testing.T) {
 		err := fmt.Errorf("DataBase doesn't work")
 		database.EXPECT().GetMetricsUpdatesCount().Return(int64(1), nil).Times(11)
 		database.EXPECT().GetChecksUpdatesCount().Return(int64(1), err).Times(11)
+		notif.EXPECT().GetDegradedSenders().Return(nil).AnyTimes()
 		notif.EXPECT().Send(gomock.Any(), gomock.Any())
 		selfStateWorker.Start()
 		time.Sleep(time.Second*11 + time.Millisecond*500)
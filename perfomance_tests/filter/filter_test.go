@@ -16,10 +16,16 @@ import (
 	"time"
 )
 
+// metricsCount is number of generated metrics reused by benchmark, so generation does not affect results
+const metricsCount = 100000
+
+// random has fixed seed, so benchmark results of different versions are comparable
+var random = rand.New(rand.NewSource(1))
+
 func BenchmarkProcessIncomingMetric(b *testing.B) {
 	patternsTxt, err := os.Open("patterns.txt")
 	if err != nil {
-		b.Errorf(err.Error())
+		b.Error(err.Error())
 	}
 	patterns := make([]string, 0)
 	patternsReader := bufio.NewReader(patternsTxt)
@@ -45,11 +51,15 @@ func BenchmarkProcessIncomingMetric(b *testing.B) {
 	if err != nil {
 		b.Errorf("Can not create new cache storage %s", err)
 	}
-	testMetricsLines := generateMetrics(patternsStorage, b.N)
+	testMetricsLines := make([][]byte, 0, metricsCount)
+	for _, line := range generateMetrics(patternsStorage, metricsCount) {
+		testMetricsLines = append(testMetricsLines, []byte(line))
+	}
 
+	b.ReportAllocs()
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		patternsStorage.ProcessIncomingMetric([]byte(testMetricsLines[i]))
+		patternsStorage.ProcessIncomingMetric(testMetricsLines[i%metricsCount])
 	}
 }
 
@@ -60,25 +70,22 @@ func generateMetrics(patterns *filter.PatternStorage, count int) []string {
 	for i < count {
 		parts := make([]string, 0, 16)
 
-		node := patterns.PatternTree.Children[rand.Intn(len(patterns.PatternTree.Children))]
-		matched := rand.Float64() < 0.02
+		node := patterns.PatternTree.Children[random.Intn(len(patterns.PatternTree.Children))]
+		matched := random.Float64() < 0.02
 		level := float64(0)
 		for {
-			part := node.Part
-			if len(node.InnerParts) > 0 {
-				part = node.InnerParts[rand.Intn(len(node.InnerParts))]
-			}
-			if !matched && rand.Float64() < 0.2+level {
+			part := sampleGlob(node.Part)
+			if !matched && random.Float64() < 0.2+level {
 				part = RandStringBytes(len(part))
 			}
-			parts = append(parts, strings.Replace(part, "*", "XXXXXXXXX", -1))
+			parts = append(parts, part)
 			if len(node.Children) == 0 {
 				break
 			}
 			level += 0.7
-			node = node.Children[rand.Intn(len(node.Children))]
+			node = node.Children[random.Intn(len(node.Children))]
 		}
-		value := rand.Float32()
+		value := random.Float32()
 		ts := fmt.Sprintf("%d", timestamp.Unix())
 		v := fmt.Sprintf("%f", value)
 		path := strings.Join(parts, ".")
@@ -91,12 +98,76 @@ func generateMetrics(patterns *filter.PatternStorage, count int) []string {
 	return result
 }
 
+// sampleGlob returns random metric part matched by graphite glob
+func sampleGlob(glob string) string {
+	result := make([]byte, 0, len(glob))
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '*':
+			result = append(result, "XXXXXXXXX"...)
+		case '?':
+			result = append(result, 'X')
+		case '[':
+			end := i + strings.IndexByte(glob[i:], ']')
+			if glob[i+1] == '!' || glob[i+1] == '^' {
+				result = append(result, '~')
+			} else {
+				result = append(result, glob[i+1])
+			}
+			i = end
+		case '{':
+			end := i + findClosingBrace(glob[i:])
+			alternatives := splitAlternatives(glob[i+1 : end])
+			result = append(result, sampleGlob(alternatives[random.Intn(len(alternatives))])...)
+			i = end
+		default:
+			result = append(result, glob[i])
+		}
+	}
+	return string(result)
+}
+
+func findClosingBrace(glob string) int {
+	depth := 0
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(glob) - 1
+}
+
+func splitAlternatives(glob string) []string {
+	alternatives := make([]string, 0)
+	depth, start := 0, 0
+	for i := 0; i < len(glob); i++ {
+		switch glob[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, glob[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return append(alternatives, glob[start:])
+}
+
 const letterBytes = "abcdefghijklmnopqrstuvwxyz"
 
 func RandStringBytes(n int) string {
 	b := make([]byte, n)
 	for i := range b {
-		b[i] = letterBytes[rand.Intn(len(letterBytes))]
+		b[i] = letterBytes[random.Intn(len(letterBytes))]
 	}
 	return string(b)
 }
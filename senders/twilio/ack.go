@@ -0,0 +1,133 @@
+package twilio
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/xml"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/moira-alert/moira"
+)
+
+const (
+	twimlPath = "/twiml"
+	ackPath   = "/ack"
+	ackDigit  = "1"
+)
+
+type twimlResponse struct {
+	XMLName xml.Name     `xml:"Response"`
+	Gather  *twimlGather `xml:"Gather,omitempty"`
+	Say     []string     `xml:"Say,omitempty"`
+}
+
+type twimlGather struct {
+	NumDigits int    `xml:"numDigits,attr"`
+	Action    string `xml:"action,attr"`
+	Method    string `xml:"method,attr"`
+	Say       string `xml:"Say"`
+}
+
+// ackServer answers twilio voice call callbacks: it asks callee to press 1 and records trigger acknowledgement
+type ackServer struct {
+	database  moira.Database
+	log       moira.Logger
+	authToken string
+	publicURL string
+}
+
+// twimlURL returns public callback url that twilio requests when call is answered
+func (server *ackServer) twimlURL(triggerID, message string) string {
+	query := url.Values{}
+	query.Set("trigger", triggerID)
+	query.Set("message", message)
+	return server.publicURL + twimlPath + "?" + query.Encode()
+}
+
+func (server *ackServer) ackURL(triggerID string) string {
+	query := url.Values{}
+	query.Set("trigger", triggerID)
+	return server.publicURL + ackPath + "?" + query.Encode()
+}
+
+func (server *ackServer) ServeHTTP(writer http.ResponseWriter, request *http.Request) {
+	if err := request.ParseForm(); err != nil {
+		http.Error(writer, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if !server.isSignatureValid(request) {
+		server.log.Warningf("Invalid twilio signature of request to %s", request.URL.String())
+		http.Error(writer, "invalid signature", http.StatusForbidden)
+		return
+	}
+	triggerID := request.URL.Query().Get("trigger")
+	if triggerID == "" {
+		http.Error(writer, "trigger is required", http.StatusBadRequest)
+		return
+	}
+
+	var response twimlResponse
+	switch request.URL.Path {
+	case twimlPath:
+		response.Gather = &twimlGather{
+			NumDigits: 1,
+			Action:    server.ackURL(triggerID),
+			Method:    http.MethodPost,
+			Say:       fmt.Sprintf("%s Press %s to acknowledge.", request.URL.Query().Get("message"), ackDigit),
+		}
+		response.Say = []string{"No acknowledgement received. Goodbye."}
+	case ackPath:
+		if request.PostForm.Get("Digits") != ackDigit {
+			response.Say = []string{"Notification is not acknowledged. Goodbye."}
+			break
+		}
+		if err := server.database.SetTriggerAck(triggerID, time.Now().Unix()); err != nil {
+			server.log.Errorf("Failed to save acknowledgement of trigger %s: %s", triggerID, err.Error())
+			response.Say = []string{"Failed to acknowledge notification, please, visit Moira web interface."}
+			break
+		}
+		server.log.Infof("Trigger %s acknowledged by %s", triggerID, request.PostForm.Get("To"))
+		response.Say = []string{"Notification acknowledged. Goodbye."}
+	default:
+		http.NotFound(writer, request)
+		return
+	}
+
+	writer.Header().Set("Content-Type", "text/xml")
+	writer.Write([]byte(xml.Header))
+	if err := xml.NewEncoder(writer).Encode(response); err != nil {
+		server.log.Errorf("Failed to write TwiML response: %s", err.Error())
+	}
+}
+
+// isSignatureValid checks X-Twilio-Signature header, see https://www.twilio.com/docs/usage/security#validating-requests
+func (server *ackServer) isSignatureValid(request *http.Request) bool {
+	expected := getSignature(server.authToken, server.publicURL+request.URL.RequestURI(), request.PostForm)
+	return hmac.Equal([]byte(request.Header.Get("X-Twilio-Signature")), []byte(expected))
+}
+
+func getSignature(authToken string, requestURL string, params url.Values) string {
+	keys := make([]string, 0, len(params))
+	for key := range params {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var data bytes.Buffer
+	data.WriteString(requestURL)
+	for _, key := range keys {
+		for _, value := range params[key] {
+			data.WriteString(key)
+			data.WriteString(value)
+		}
+	}
+	mac := hmac.New(sha1.New, []byte(authToken))
+	mac.Write([]byte(data.String()))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
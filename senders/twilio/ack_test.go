@@ -0,0 +1,116 @@
+package twilio
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/op/go-logging"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira"
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+const testAuthToken = "auth-token"
+
+// twilioStub acts like twilio: it requests TwiML on call answer and posts pressed digits to gather action
+type twilioStub struct {
+	digits   string
+	response twimlResponse
+}
+
+func (stub *twilioStub) post(requestURL string, params url.Values) (twimlResponse, error) {
+	var response twimlResponse
+	request, _ := http.NewRequest(http.MethodPost, requestURL, strings.NewReader(params.Encode()))
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.Header.Set("X-Twilio-Signature", getSignature(testAuthToken, requestURL, params))
+	httpResponse, err := http.DefaultClient.Do(request)
+	if err != nil {
+		return response, err
+	}
+	defer httpResponse.Body.Close()
+	body, _ := ioutil.ReadAll(httpResponse.Body)
+	if httpResponse.StatusCode != http.StatusOK {
+		return response, fmt.Errorf("status %d: %s", httpResponse.StatusCode, body)
+	}
+	err = xml.Unmarshal(body, &response)
+	return response, err
+}
+
+func (stub *twilioStub) call(to string, callbackURL string) (string, error) {
+	response, err := stub.post(callbackURL, url.Values{"To": {to}, "CallStatus": {"in-progress"}})
+	if err != nil {
+		return "", err
+	}
+	if response.Gather == nil {
+		return "", fmt.Errorf("no gather in TwiML")
+	}
+	stub.response, err = stub.post(response.Gather.Action, url.Values{"To": {to}, "Digits": {stub.digits}})
+	return "queued", err
+}
+
+func TestVoiceAcknowledgement(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+	logger, _ := logging.GetLogger("Twilio")
+
+	ack := &ackServer{database: dataBase, log: logger, authToken: testAuthToken}
+	server := httptest.NewServer(ack)
+	defer server.Close()
+	ack.publicURL = server.URL
+
+	stub := &twilioStub{}
+	sender := &twilioSenderVoice{
+		twilioSender: twilioSender{log: logger},
+		ack:          ack,
+		makeCall:     stub.call,
+	}
+	trigger := moira.TriggerData{ID: "trigger-id", Name: "Trigger & name"}
+	contact := moira.ContactData{Type: "twilio voice", Value: "+79998887766"}
+
+	Convey("Callee pressed 1, trigger acknowledged", t, func() {
+		stub.digits = "1"
+		dataBase.EXPECT().SetTriggerAck(trigger.ID, gomock.Any()).Return(nil)
+		err := sender.SendEvents(moira.NotificationEvents{}, contact, trigger, false)
+		So(err, ShouldBeNil)
+		So(stub.response.Say, ShouldResemble, []string{"Notification acknowledged. Goodbye."})
+	})
+
+	Convey("Callee pressed other digit, trigger is not acknowledged", t, func() {
+		stub.digits = "2"
+		err := sender.SendEvents(moira.NotificationEvents{}, contact, trigger, false)
+		So(err, ShouldBeNil)
+		So(stub.response.Say, ShouldResemble, []string{"Notification is not acknowledged. Goodbye."})
+	})
+
+	Convey("Database error, callee is informed", t, func() {
+		stub.digits = "1"
+		dataBase.EXPECT().SetTriggerAck(trigger.ID, gomock.Any()).Return(fmt.Errorf("connection refused"))
+		err := sender.SendEvents(moira.NotificationEvents{}, contact, trigger, false)
+		So(err, ShouldBeNil)
+		So(stub.response.Say, ShouldResemble, []string{"Failed to acknowledge notification, please, visit Moira web interface."})
+	})
+
+	Convey("TwiML asks to press 1", t, func() {
+		twimlURL := ack.twimlURL(trigger.ID, "Hello.")
+		response, err := stub.post(twimlURL, url.Values{})
+		So(err, ShouldBeNil)
+		So(response.Gather.NumDigits, ShouldEqual, 1)
+		So(response.Gather.Say, ShouldEqual, "Hello. Press 1 to acknowledge.")
+		So(response.Gather.Action, ShouldEqual, server.URL+"/ack?trigger=trigger-id")
+	})
+
+	Convey("Request without valid signature is rejected", t, func() {
+		response, err := http.PostForm(server.URL+"/ack?trigger=trigger-id", url.Values{"Digits": {"1"}})
+		So(err, ShouldBeNil)
+		So(response.StatusCode, ShouldEqual, http.StatusForbidden)
+	})
+}
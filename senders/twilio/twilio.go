@@ -3,8 +3,11 @@ package twilio
 import (
 	"bytes"
 	"fmt"
+	"net"
+	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
 	twilio "github.com/carlosdp/twiliogo"
@@ -30,6 +33,8 @@ type twilioSenderVoice struct {
 	twilioSender
 	voiceURL      string
 	appendMessage bool
+	ack           *ackServer
+	makeCall      func(to string, callbackURL string) (string, error)
 }
 
 func (smsSender *twilioSenderSms) SendEvents(events moira.NotificationEvents, contact moira.ContactData, trigger moira.TriggerData, throttled bool) error {
@@ -69,25 +74,52 @@ func (smsSender *twilioSenderSms) SendEvents(events moira.NotificationEvents, co
 }
 
 func (voiceSender *twilioSenderVoice) SendEvents(events moira.NotificationEvents, contact moira.ContactData, trigger moira.TriggerData, throttled bool) error {
+	message := fmt.Sprintf("Hi! This is a notification for Moira trigger %s. Please, visit Moira web interface for details.", trigger.Name)
 	voiceURL := voiceSender.voiceURL
-	if voiceSender.appendMessage {
-		voiceURL += url.QueryEscape(fmt.Sprintf("Hi! This is a notification for Moira trigger %s. Please, visit Moira web interface for details.", trigger.Name))
+	if voiceSender.ack != nil {
+		voiceURL = voiceSender.ack.twimlURL(trigger.ID, message)
+	} else if voiceSender.appendMessage {
+		voiceURL += url.QueryEscape(message)
 	}
 
-	twilioCall, err := twilio.NewCall(voiceSender.client, voiceSender.APIFromPhone, contact.Value, twilio.Callback(voiceURL))
+	status, err := voiceSender.makeCall(contact.Value, voiceURL)
 
 	if err != nil {
 		return fmt.Errorf("Failed to make call to contact %s: %s", contact.Value, err.Error())
 	}
 
-	voiceSender.log.Debugf("Call queued to twilio with status %s, callback url %s", twilioCall.Status, voiceURL)
+	voiceSender.log.Debugf("Call queued to twilio with status %s, callback url %s", status, voiceURL)
 
 	return nil
 }
 
+func (voiceSender *twilioSenderVoice) callViaTwilio(to string, callbackURL string) (string, error) {
+	twilioCall, err := twilio.NewCall(voiceSender.client, voiceSender.APIFromPhone, to, twilio.Callback(callbackURL))
+	if err != nil {
+		return "", err
+	}
+	return twilioCall.Status, nil
+}
+
+// startAckServer listens for twilio callbacks of voice calls with acknowledgement
+func (voiceSender *twilioSenderVoice) startAckServer(listen string) error {
+	listener, err := net.Listen("tcp", listen)
+	if err != nil {
+		return err
+	}
+	go func() {
+		if err := http.Serve(listener, voiceSender.ack); err != nil {
+			voiceSender.log.Errorf("Twilio acknowledgement server stopped: %s", err.Error())
+		}
+	}()
+	voiceSender.log.Infof("Twilio acknowledgement server is listening on %s", listen)
+	return nil
+}
+
 // Sender implements moira sender interface via twilio
 type Sender struct {
-	sender sendEventsTwilio
+	DataBase moira.Database
+	sender   sendEventsTwilio
 }
 
 // Init read yaml config
@@ -116,18 +148,32 @@ func (sender *Sender) Init(senderSettings map[string]string, logger moira.Logger
 		sender.sender = &twilioSenderSms{twilioSender{twilioClient, apiFromPhone, logger, location}}
 
 	case "twilio voice":
-		voiceURL := senderSettings["voiceurl"]
-		if voiceURL == "" {
+		voiceSender := &twilioSenderVoice{
+			twilioSender:  twilioSender{twilioClient, apiFromPhone, logger, location},
+			voiceURL:      senderSettings["voiceurl"],
+			appendMessage: senderSettings["append_message"] == "true",
+		}
+		voiceSender.makeCall = voiceSender.callViaTwilio
+
+		if ackURL := senderSettings["ack_url"]; ackURL != "" {
+			ackListen := senderSettings["ack_listen"]
+			if ackListen == "" {
+				return fmt.Errorf("Can not read [%s] ack_listen param from config", apiType)
+			}
+			voiceSender.ack = &ackServer{
+				database:  sender.DataBase,
+				log:       logger,
+				authToken: apiAuthToken,
+				publicURL: strings.TrimSuffix(ackURL, "/"),
+			}
+			if err := voiceSender.startAckServer(ackListen); err != nil {
+				return fmt.Errorf("Can not start [%s] acknowledgement server: %s", apiType, err.Error())
+			}
+		} else if voiceSender.voiceURL == "" {
 			return fmt.Errorf("Can not read [%s] voiceurl param from config", apiType)
 		}
 
-		appendMessage := senderSettings["append_message"] == "true"
-
-		sender.sender = &twilioSenderVoice{
-			twilioSender{twilioClient, apiFromPhone, logger, location},
-			voiceURL,
-			appendMessage,
-		}
+		sender.sender = voiceSender
 
 	default:
 		return fmt.Errorf("Wrong twilio type: %s", apiType)
@@ -0,0 +1,260 @@
+package moira
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Graphite seriesByTag tag expression operators
+const (
+	TagMatchEqual    = "="
+	TagMatchNotEqual = "!="
+	TagMatchRegex    = "=~"
+	TagMatchNotRegex = "!=~"
+)
+
+const (
+	seriesByTagPrefix = "seriesByTag("
+	seriesNameTag     = "name"
+)
+
+// TagSpec represents single tag expression of graphite seriesByTag function like 'dc=~eu.*'
+type TagSpec struct {
+	Tag      string
+	Operator string
+	Value    string
+	regexp   *regexp.Regexp
+}
+
+// ParseTagSpec parses tag expression, regular expressions are anchored at start like in graphite
+func ParseTagSpec(expression string) (TagSpec, error) {
+	var spec TagSpec
+	index := strings.IndexAny(expression, "!=")
+	if index < 1 {
+		return spec, fmt.Errorf("Invalid tag expression '%s'", expression)
+	}
+	spec.Tag = expression[:index]
+	rest := expression[index:]
+	for _, operator := range []string{TagMatchNotRegex, TagMatchNotEqual, TagMatchRegex, TagMatchEqual} {
+		if strings.HasPrefix(rest, operator) {
+			spec.Operator = operator
+			spec.Value = rest[len(operator):]
+			break
+		}
+	}
+	switch spec.Operator {
+	case "":
+		return spec, fmt.Errorf("Invalid tag expression '%s'", expression)
+	case TagMatchRegex, TagMatchNotRegex:
+		compiled, err := regexp.Compile("^(?:" + spec.Value + ")")
+		if err != nil {
+			return spec, fmt.Errorf("Invalid regular expression in tag expression '%s': %s", expression, err.Error())
+		}
+		spec.regexp = compiled
+	}
+	return spec, nil
+}
+
+// String returns tag expression in graphite format
+func (spec TagSpec) String() string {
+	return spec.Tag + spec.Operator + spec.Value
+}
+
+// IsPositive returns true if tag expression can be used to select series from index: tag=value with non-empty value or tag=~regex
+func (spec TagSpec) IsPositive() bool {
+	return (spec.Operator == TagMatchEqual && spec.Value != "") || spec.Operator == TagMatchRegex
+}
+
+// Match checks tag value, value of absent tag is empty string
+func (spec TagSpec) Match(value string) bool {
+	switch spec.Operator {
+	case TagMatchEqual:
+		return value == spec.Value
+	case TagMatchNotEqual:
+		return value != spec.Value
+	case TagMatchRegex:
+		return spec.regexp.MatchString(value)
+	case TagMatchNotRegex:
+		return !spec.regexp.MatchString(value)
+	}
+	return false
+}
+
+// MatchTagSpecs checks that series tags satisfy all tag expressions
+func MatchTagSpecs(specs []TagSpec, tags map[string]string) bool {
+	for _, spec := range specs {
+		if !spec.Match(tags[spec.Tag]) {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSeriesByTag checks if pattern is graphite seriesByTag function call
+func IsSeriesByTag(pattern string) bool {
+	return strings.HasPrefix(pattern, seriesByTagPrefix)
+}
+
+// ParseSeriesByTag parses tag expressions of seriesByTag('name=cpu', 'dc=~eu.*') function call
+// At least one expression must select series by not empty value like in graphite
+func ParseSeriesByTag(pattern string) ([]TagSpec, error) {
+	if !IsSeriesByTag(pattern) || !strings.HasSuffix(pattern, ")") {
+		return nil, fmt.Errorf("Invalid seriesByTag call '%s'", pattern)
+	}
+	args, err := splitQuotedArgs(pattern[len(seriesByTagPrefix) : len(pattern)-1])
+	if err != nil {
+		return nil, fmt.Errorf("Invalid seriesByTag call '%s': %s", pattern, err.Error())
+	}
+	specs := make([]TagSpec, 0, len(args))
+	hasPositive := false
+	for _, arg := range args {
+		spec, err := ParseTagSpec(arg)
+		if err != nil {
+			return nil, err
+		}
+		hasPositive = hasPositive || spec.IsPositive()
+		specs = append(specs, spec)
+	}
+	if !hasPositive {
+		return nil, fmt.Errorf("seriesByTag call '%s' must have at least one tag=value or tag=~regex expression", pattern)
+	}
+	return specs, nil
+}
+
+// FormatSeriesByTag returns seriesByTag function call with given tag expressions
+func FormatSeriesByTag(specs []TagSpec) string {
+	args := make([]string, 0, len(specs))
+	for _, spec := range specs {
+		args = append(args, fmt.Sprintf("'%s'", spec.String()))
+	}
+	return fmt.Sprintf("%s%s)", seriesByTagPrefix, strings.Join(args, ","))
+}
+
+// IsTaggedMetric checks if metric name is graphite tagged series name like cpu;dc=eu;host=a
+func IsTaggedMetric(metric string) bool {
+	return strings.Contains(metric, ";")
+}
+
+// ParseTaggedMetric parses graphite tagged series name and returns series tags including name tag
+func ParseTaggedMetric(metric string) (map[string]string, error) {
+	parts := strings.Split(metric, ";")
+	if parts[0] == "" {
+		return nil, fmt.Errorf("Tagged metric '%s' has empty name", metric)
+	}
+	tags := map[string]string{seriesNameTag: parts[0]}
+	for _, part := range parts[1:] {
+		tag := strings.SplitN(part, "=", 2)
+		if len(tag) != 2 || tag[0] == "" || tag[1] == "" || strings.ContainsAny(tag[0], "!^") || strings.HasPrefix(tag[1], "~") {
+			return nil, fmt.Errorf("Tagged metric '%s' has invalid tag '%s'", metric, part)
+		}
+		if tag[0] == seriesNameTag {
+			return nil, fmt.Errorf("Tagged metric '%s' must not have tag '%s'", metric, seriesNameTag)
+		}
+		tags[tag[0]] = tag[1]
+	}
+	return tags, nil
+}
+
+// FormatTaggedMetric returns graphite tagged series name with tags sorted by name
+func FormatTaggedMetric(tags map[string]string) string {
+	names := make([]string, 0, len(tags))
+	for tag := range tags {
+		if tag != seriesNameTag {
+			names = append(names, tag)
+		}
+	}
+	sort.Strings(names)
+	parts := make([]string, 0, len(tags))
+	parts = append(parts, tags[seriesNameTag])
+	for _, tag := range names {
+		parts = append(parts, tag+"="+tags[tag])
+	}
+	return strings.Join(parts, ";")
+}
+
+// GetSeriesTags returns tags of tagged series as tag=value strings, it is used to match subscriptions on series tags
+func GetSeriesTags(metric string) []string {
+	if !IsTaggedMetric(metric) {
+		return nil
+	}
+	tags, err := ParseTaggedMetric(metric)
+	if err != nil {
+		return nil
+	}
+	result := make([]string, 0, len(tags))
+	for tag, value := range tags {
+		result = append(result, tag+"="+value)
+	}
+	sort.Strings(result)
+	return result
+}
+
+// FindSeriesByTag returns positions of seriesByTag calls in graphite target
+func FindSeriesByTag(target string) ([][2]int, error) {
+	positions := make([][2]int, 0)
+	offset := 0
+	for {
+		start := strings.Index(target[offset:], seriesByTagPrefix)
+		if start < 0 {
+			return positions, nil
+		}
+		start += offset
+		end, err := findClosingParenthesis(target, start+len(seriesByTagPrefix))
+		if err != nil {
+			return nil, err
+		}
+		positions = append(positions, [2]int{start, end + 1})
+		offset = end + 1
+	}
+}
+
+func findClosingParenthesis(target string, from int) (int, error) {
+	var quote byte
+	for i := from; i < len(target); i++ {
+		switch {
+		case quote != 0:
+			if target[i] == quote {
+				quote = 0
+			}
+		case target[i] == '\'' || target[i] == '"':
+			quote = target[i]
+		case target[i] == ')':
+			return i, nil
+		}
+	}
+	return 0, fmt.Errorf("Unclosed seriesByTag call in '%s'", target)
+}
+
+// splitQuotedArgs splits comma separated list of quoted strings
+func splitQuotedArgs(argsString string) ([]string, error) {
+	args := make([]string, 0)
+	rest := strings.TrimSpace(argsString)
+	for rest != "" {
+		quote := rest[0]
+		if quote != '\'' && quote != '"' {
+			return nil, fmt.Errorf("argument must be quoted string")
+		}
+		end := strings.IndexByte(rest[1:], quote)
+		if end < 0 {
+			return nil, fmt.Errorf("unclosed quote")
+		}
+		args = append(args, rest[1:end+1])
+		rest = strings.TrimSpace(rest[end+2:])
+		if rest == "" {
+			break
+		}
+		if rest[0] != ',' {
+			return nil, fmt.Errorf("arguments must be separated by comma")
+		}
+		rest = strings.TrimSpace(rest[1:])
+		if rest == "" {
+			return nil, fmt.Errorf("trailing comma")
+		}
+	}
+	if len(args) == 0 {
+		return nil, fmt.Errorf("at least one tag expression is required")
+	}
+	return args, nil
+}
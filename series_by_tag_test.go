@@ -0,0 +1,85 @@
+package moira
+
+import (
+	"testing"
+
+	. "github.com/smartystreets/goconvey/convey"
+)
+
+func TestParseSeriesByTag(t *testing.T) {
+	Convey("Valid seriesByTag calls", t, func() {
+		specs, err := ParseSeriesByTag("seriesByTag('name=cpu', \"dc=~eu.*\",'host!=a','env!=~dev|test','rack=')")
+		So(err, ShouldBeNil)
+		So(specs, ShouldHaveLength, 5)
+		So(specs[0].Tag, ShouldEqual, "name")
+		So(specs[0].Operator, ShouldEqual, TagMatchEqual)
+		So(specs[1].Operator, ShouldEqual, TagMatchRegex)
+		So(specs[1].Value, ShouldEqual, "eu.*")
+		So(specs[2].Operator, ShouldEqual, TagMatchNotEqual)
+		So(specs[3].Operator, ShouldEqual, TagMatchNotRegex)
+		So(specs[4].Operator, ShouldEqual, TagMatchEqual)
+		So(specs[4].Value, ShouldEqual, "")
+		So(FormatSeriesByTag(specs), ShouldEqual, "seriesByTag('name=cpu','dc=~eu.*','host!=a','env!=~dev|test','rack=')")
+
+		tags := map[string]string{"name": "cpu", "dc": "eu1", "host": "b", "env": "prod"}
+		So(MatchTagSpecs(specs, tags), ShouldBeTrue)
+		tags["rack"] = "1"
+		So(MatchTagSpecs(specs, tags), ShouldBeFalse)
+		delete(tags, "rack")
+		tags["dc"] = "us-eu"
+		So(MatchTagSpecs(specs, tags), ShouldBeFalse)
+		tags["dc"] = "eu1"
+		tags["env"] = "test"
+		So(MatchTagSpecs(specs, tags), ShouldBeFalse)
+	})
+
+	Convey("Invalid seriesByTag calls", t, func() {
+		invalidPatterns := []string{
+			"seriesByTag()",
+			"seriesByTag('host!=a')",
+			"seriesByTag('name=')",
+			"seriesByTag(name=cpu)",
+			"seriesByTag('name=cpu',)",
+			"seriesByTag('name=cpu' 'dc=eu')",
+			"seriesByTag('name=cpu",
+			"seriesByTag('=cpu')",
+			"seriesByTag('name')",
+			"seriesByTag('dc=~eu[')",
+			"sumSeries('name=cpu')",
+		}
+		for _, pattern := range invalidPatterns {
+			_, err := ParseSeriesByTag(pattern)
+			So(err, ShouldNotBeNil)
+		}
+	})
+}
+
+func TestTaggedMetric(t *testing.T) {
+	Convey("Parse and format tagged metric", t, func() {
+		So(IsTaggedMetric("cpu.usage"), ShouldBeFalse)
+		So(IsTaggedMetric("cpu;dc=eu"), ShouldBeTrue)
+
+		tags, err := ParseTaggedMetric("cpu.usage;host=a;dc=eu=1")
+		So(err, ShouldBeNil)
+		So(tags, ShouldResemble, map[string]string{"name": "cpu.usage", "host": "a", "dc": "eu=1"})
+		So(FormatTaggedMetric(tags), ShouldEqual, "cpu.usage;dc=eu=1;host=a")
+		So(GetSeriesTags("cpu.usage;host=a;dc=eu"), ShouldResemble, []string{"dc=eu", "host=a", "name=cpu.usage"})
+		So(GetSeriesTags("cpu.usage"), ShouldBeEmpty)
+	})
+
+	Convey("Invalid tagged metrics", t, func() {
+		for _, metric := range []string{";dc=eu", "cpu;dc", "cpu;dc=", "cpu;=eu", "cpu;name=a", "cpu;dc!=eu", "cpu;dc=~eu"} {
+			_, err := ParseTaggedMetric(metric)
+			So(err, ShouldNotBeNil)
+		}
+	})
+
+	Convey("Find seriesByTag calls in target", t, func() {
+		positions, err := FindSeriesByTag("sumSeries(seriesByTag('name=cpu', 'dc=~(eu|us)'), seriesByTag(\"name=mem\"))")
+		So(err, ShouldBeNil)
+		So(positions, ShouldResemble, [][2]int{{10, 48}, {50, 73}})
+
+		_, err = FindSeriesByTag("seriesByTag('name=cpu'")
+		So(err, ShouldNotBeNil)
+	})
+}
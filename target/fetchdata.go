@@ -8,8 +8,9 @@ import (
 )
 
 // FetchData gets values of given pattern metrics from given interval and returns values and all found pattern metrics
+// Pattern can be graphite pattern or seriesByTag call
 func FetchData(database moira.Database, pattern string, from int64, until int64, allowRealTimeAlerting bool) ([]*expr.MetricData, []string, error) {
-	metrics, err := database.GetPatternMetrics(pattern)
+	metrics, err := getPatternMetrics(database, pattern)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -37,6 +38,13 @@ func FetchData(database moira.Database, pattern string, from int64, until int64,
 	return metricDatas, metrics, nil
 }
 
+func getPatternMetrics(database moira.Database, pattern string) ([]string, error) {
+	if moira.IsSeriesByTag(pattern) {
+		return getSeriesByTag(database, pattern)
+	}
+	return database.GetPatternMetrics(pattern)
+}
+
 func createMetricData(metric string, from int64, until int64, retention int64, values []float64) *expr.MetricData {
 	fetchResponse := pb.FetchResponse{
 		Name:      metric,
@@ -68,7 +76,23 @@ func unpackMetricsValues(metricsData map[string][]*moira.MetricValue, retention
 	for metric, metricData := range metricsData {
 		points := make(map[int64]float64)
 		for _, metricValue := range metricData {
-			points[getTimeSlot(metricValue.RetentionTimestamp)] = metricValue.Value
+			if metricValue.Step <= retention {
+				points[getTimeSlot(metricValue.RetentionTimestamp)] = metricValue.Value
+			}
+		}
+		// downsampled value fills empty time slots covered by its archive step
+		for _, metricValue := range metricData {
+			if metricValue.Step <= retention {
+				continue
+			}
+			for timestamp := metricValue.RetentionTimestamp; timestamp < metricValue.RetentionTimestamp+metricValue.Step && timestamp < until; timestamp += retention {
+				if timestamp < retentionFrom {
+					continue
+				}
+				if _, ok := points[getTimeSlot(timestamp)]; !ok {
+					points[getTimeSlot(timestamp)] = metricValue.Value
+				}
+			}
 		}
 
 		lastTimeSlot := getTimeSlot(until)
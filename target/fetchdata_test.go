@@ -666,3 +666,27 @@ func TestRealTimeShiftedSeries(t *testing.T) {
 func arrToString(arr []float64) string {
 	return fmt.Sprintf("%v", arr)
 }
+
+func TestDownsampledSeries(t *testing.T) {
+	var retention int64 = 10
+	var from int64 = 15
+
+	Convey("Downsampled values fill time slots of their archive step", t, func() {
+		metricData := map[string][]*moira.MetricValue{"metric": {
+			{Timestamp: 0, RetentionTimestamp: 0, Value: 1, Step: 30},
+			{Timestamp: 30, RetentionTimestamp: 30, Value: 2, Step: 30},
+			{Timestamp: 60, RetentionTimestamp: 60, Value: 3},
+			{Timestamp: 70, RetentionTimestamp: 70, Value: 4},
+			{Timestamp: 80, RetentionTimestamp: 80, Value: 5},
+			{Timestamp: 90, RetentionTimestamp: 90, Value: 6},
+		}}
+		val := unpackMetricsValues(metricData, retention, from, 100, false)
+		So(val["metric"], ShouldResemble, []float64{1, 2, 2, 2, 3, 4, 5, 6})
+
+		Convey("Values with metric retention are not replaced", func() {
+			metricData["metric"] = append(metricData["metric"], &moira.MetricValue{Timestamp: 41, RetentionTimestamp: 40, Value: 7})
+			val := unpackMetricsValues(metricData, retention, from, 100, false)
+			So(val["metric"], ShouldResemble, []float64{1, 2, 7, 2, 3, 4, 5, 6})
+		})
+	})
+}
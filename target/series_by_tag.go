@@ -0,0 +1,93 @@
+package target
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/moira-alert/moira"
+)
+
+const seriesByTagPlaceholder = "__moira_series_by_tag_%d"
+
+// rewriteSeriesByTag replaces seriesByTag calls in target by metric name placeholders because carbonapi can not evaluate seriesByTag
+// returns rewritten target and map of placeholders to seriesByTag patterns
+func rewriteSeriesByTag(target string) (string, map[string]string, error) {
+	patterns := make(map[string]string)
+	positions, err := moira.FindSeriesByTag(target)
+	if err != nil || len(positions) == 0 {
+		return target, patterns, err
+	}
+	rewritten := ""
+	offset := 0
+	for i, position := range positions {
+		specs, err := moira.ParseSeriesByTag(target[position[0]:position[1]])
+		if err != nil {
+			return "", nil, err
+		}
+		placeholder := fmt.Sprintf(seriesByTagPlaceholder, i)
+		patterns[placeholder] = moira.FormatSeriesByTag(specs)
+		rewritten += target[offset:position[0]] + placeholder
+		offset = position[1]
+	}
+	return rewritten + target[offset:], patterns, nil
+}
+
+// getSeriesByTag resolves seriesByTag pattern using series tags index
+// series selected by tag=value and tag=~regex expressions are intersected and then checked by all tag expressions
+func getSeriesByTag(database moira.Database, pattern string) ([]string, error) {
+	specs, err := moira.ParseSeriesByTag(pattern)
+	if err != nil {
+		return nil, err
+	}
+	var candidates map[string]bool
+	for _, spec := range specs {
+		if !spec.IsPositive() {
+			continue
+		}
+		values := []string{spec.Value}
+		if spec.Operator == moira.TagMatchRegex {
+			if values, err = getMatchedTagValues(database, spec); err != nil {
+				return nil, err
+			}
+		}
+		series, err := database.GetSeriesByTag(spec.Tag, values)
+		if err != nil {
+			return nil, err
+		}
+		candidates = intersectSeries(candidates, series)
+	}
+
+	metrics := make([]string, 0, len(candidates))
+	for metric := range candidates {
+		tags, err := moira.ParseTaggedMetric(metric)
+		if err == nil && moira.MatchTagSpecs(specs, tags) {
+			metrics = append(metrics, metric)
+		}
+	}
+	sort.Strings(metrics)
+	return metrics, nil
+}
+
+func getMatchedTagValues(database moira.Database, spec moira.TagSpec) ([]string, error) {
+	values, err := database.GetSeriesTagValues(spec.Tag)
+	if err != nil {
+		return nil, err
+	}
+	matched := make([]string, 0, len(values))
+	for _, value := range values {
+		if spec.Match(value) {
+			matched = append(matched, value)
+		}
+	}
+	return matched, nil
+}
+
+func intersectSeries(candidates map[string]bool, series []string) map[string]bool {
+	result := make(map[string]bool)
+	for _, metric := range series {
+		if candidates == nil || candidates[metric] {
+			result[metric] = true
+		}
+	}
+	return result
+}
@@ -0,0 +1,68 @@
+package target
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	. "github.com/smartystreets/goconvey/convey"
+
+	"github.com/moira-alert/moira/mock/moira-alert"
+)
+
+func TestRewriteSeriesByTag(t *testing.T) {
+	Convey("Target without seriesByTag is not changed", t, func() {
+		rewritten, patterns, err := rewriteSeriesByTag("sumSeries(my.metric.*)")
+		So(err, ShouldBeNil)
+		So(rewritten, ShouldEqual, "sumSeries(my.metric.*)")
+		So(patterns, ShouldBeEmpty)
+	})
+
+	Convey("seriesByTag calls are replaced by placeholders", t, func() {
+		rewritten, patterns, err := rewriteSeriesByTag("diffSeries(seriesByTag('name=cpu', \"dc=~(eu|us)\"), seriesByTag('name=mem'))")
+		So(err, ShouldBeNil)
+		So(rewritten, ShouldEqual, "diffSeries(__moira_series_by_tag_0, __moira_series_by_tag_1)")
+		So(patterns, ShouldResemble, map[string]string{
+			"__moira_series_by_tag_0": "seriesByTag('name=cpu','dc=~(eu|us)')",
+			"__moira_series_by_tag_1": "seriesByTag('name=mem')",
+		})
+	})
+
+	Convey("Invalid seriesByTag call", t, func() {
+		_, _, err := rewriteSeriesByTag("seriesByTag('dc!=eu')")
+		So(err, ShouldNotBeNil)
+		_, _, err = rewriteSeriesByTag("seriesByTag('name=cpu'")
+		So(err, ShouldNotBeNil)
+	})
+}
+
+func TestGetSeriesByTag(t *testing.T) {
+	mockCtrl := gomock.NewController(t)
+	defer mockCtrl.Finish()
+	dataBase := mock_moira_alert.NewMockDatabase(mockCtrl)
+
+	Convey("Series are selected by positive expressions and filtered by all expressions", t, func() {
+		dataBase.EXPECT().GetSeriesByTag("name", []string{"cpu"}).Return([]string{"cpu;dc=eu1;host=a", "cpu;dc=eu2;host=b", "cpu;dc=us;host=c", "cpu;host=d"}, nil)
+		dataBase.EXPECT().GetSeriesTagValues("dc").Return([]string{"eu1", "eu2", "us"}, nil)
+		dataBase.EXPECT().GetSeriesByTag("dc", []string{"eu1", "eu2"}).Return([]string{"cpu;dc=eu1;host=a", "cpu;dc=eu2;host=b", "mem;dc=eu1"}, nil)
+
+		metrics, err := getSeriesByTag(dataBase, "seriesByTag('name=cpu','dc=~eu','host!=b')")
+		So(err, ShouldBeNil)
+		So(metrics, ShouldResemble, []string{"cpu;dc=eu1;host=a"})
+	})
+
+	Convey("No series", t, func() {
+		dataBase.EXPECT().GetSeriesByTag("name", []string{"cpu"}).Return([]string{}, nil)
+		metricData, metrics, err := FetchData(dataBase, "seriesByTag('name=cpu')", 17, 67, true)
+		So(err, ShouldBeNil)
+		So(metrics, ShouldBeEmpty)
+		So(metricData, ShouldHaveLength, 1)
+		So(metricData[0].Name, ShouldEqual, "seriesByTag('name=cpu')")
+	})
+
+	Convey("Database error", t, func() {
+		dataBase.EXPECT().GetSeriesTagValues("dc").Return(nil, fmt.Errorf("connection refused"))
+		_, err := getSeriesByTag(dataBase, "seriesByTag('dc=~eu')")
+		So(err, ShouldNotBeNil)
+	})
+}
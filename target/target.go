@@ -36,14 +36,17 @@ func EvaluateTarget(database moira.Database, target string, from int64, until in
 	targets := []string{target}
 	targetIdx := 0
 	for targetIdx < len(targets) {
-		target := targets[targetIdx]
+		target, seriesByTagPatterns, err := rewriteSeriesByTag(targets[targetIdx])
+		if err != nil {
+			return nil, err
+		}
 		targetIdx++
 		expr2, _, err := expr.ParseExpr(target)
 		if err != nil {
 			return nil, err
 		}
 		patterns := expr2.Metrics()
-		metricsMap, metrics, err := getPatternsMetricData(database, patterns, from, until, allowRealTimeAlerting)
+		metricsMap, metrics, err := getPatternsMetricData(database, patterns, seriesByTagPatterns, from, until, allowRealTimeAlerting)
 		if err != nil {
 			return nil, err
 		}
@@ -77,20 +80,20 @@ func EvaluateTarget(database moira.Database, target string, from int64, until in
 			}
 			result.Metrics = append(result.Metrics, metrics...)
 			for _, pattern := range patterns {
-				result.Patterns = append(result.Patterns, pattern.Metric)
+				result.Patterns = append(result.Patterns, getPattern(pattern.Metric, seriesByTagPatterns))
 			}
 		}
 	}
 	return result, nil
 }
 
-func getPatternsMetricData(database moira.Database, patterns []expr.MetricRequest, from int64, until int64, allowRealTimeAlerting bool) (map[expr.MetricRequest][]*expr.MetricData, []string, error) {
+func getPatternsMetricData(database moira.Database, patterns []expr.MetricRequest, seriesByTagPatterns map[string]string, from int64, until int64, allowRealTimeAlerting bool) (map[expr.MetricRequest][]*expr.MetricData, []string, error) {
 	metrics := make([]string, 0)
 	metricsMap := make(map[expr.MetricRequest][]*expr.MetricData)
 	for _, pattern := range patterns {
 		pattern.From += int32(from)
 		pattern.Until += int32(until)
-		metricDatas, patternMetrics, err := FetchData(database, pattern.Metric, int64(pattern.From), int64(pattern.Until), allowRealTimeAlerting)
+		metricDatas, patternMetrics, err := FetchData(database, getPattern(pattern.Metric, seriesByTagPatterns), int64(pattern.From), int64(pattern.Until), allowRealTimeAlerting)
 		if err != nil {
 			return nil, nil, err
 		}
@@ -99,3 +102,11 @@ func getPatternsMetricData(database moira.Database, patterns []expr.MetricReques
 	}
 	return metricsMap, metrics, nil
 }
+
+// getPattern returns seriesByTag pattern for placeholder metric or metric itself
+func getPattern(metric string, seriesByTagPatterns map[string]string) string {
+	if pattern, ok := seriesByTagPatterns[metric]; ok {
+		return pattern
+	}
+	return metric
+}